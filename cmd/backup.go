@@ -5,17 +5,16 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
-	"path/filepath"
 	"strconv"
 	"strings"
 	"time"
 
 	"mysql-schema-sync/internal/application"
 	"mysql-schema-sync/internal/backup"
+	"mysql-schema-sync/internal/database"
 	"mysql-schema-sync/internal/display"
 
 	"github.com/spf13/cobra"
-	"github.com/spf13/viper"
 )
 
 var (
@@ -532,110 +531,19 @@ func runBackupExport(cmd *cobra.Command, args []string) error {
 
 // buildBackupSystemConfig creates backup system configuration from application config
 func buildBackupSystemConfig(config *application.Config) (*backup.BackupSystemConfig, error) {
-	// Load backup configuration from viper or use defaults
-	backupConfig := &backup.BackupSystemConfig{}
-
-	// Set storage configuration
-	backupConfig.Storage = backup.StorageConfig{
-		Provider: backup.StorageProviderLocal, // Default to local
-		Local: &backup.LocalConfig{
-			BasePath:    filepath.Join(os.TempDir(), "mysql-schema-sync-backups"),
-			Permissions: 0755,
-		},
-	}
-
-	// Override with viper configuration if available
-	if viper.IsSet("backup.storage.provider") {
-		provider := viper.GetString("backup.storage.provider")
-		switch strings.ToUpper(provider) {
-		case "LOCAL":
-			backupConfig.Storage.Provider = backup.StorageProviderLocal
-			if viper.IsSet("backup.storage.local") {
-				backupConfig.Storage.Local = &backup.LocalConfig{
-					BasePath:    viper.GetString("backup.storage.local.base_path"),
-					Permissions: os.FileMode(viper.GetInt("backup.storage.local.permissions")),
-				}
-			}
-		case "S3":
-			backupConfig.Storage.Provider = backup.StorageProviderS3
-			backupConfig.Storage.S3 = &backup.S3Config{
-				Bucket:    viper.GetString("backup.storage.s3.bucket"),
-				Region:    viper.GetString("backup.storage.s3.region"),
-				AccessKey: viper.GetString("backup.storage.s3.access_key"),
-				SecretKey: viper.GetString("backup.storage.s3.secret_key"),
-			}
-		case "AZURE":
-			backupConfig.Storage.Provider = backup.StorageProviderAzure
-			backupConfig.Storage.Azure = &backup.AzureConfig{
-				AccountName:   viper.GetString("backup.storage.azure.account_name"),
-				AccountKey:    viper.GetString("backup.storage.azure.account_key"),
-				ContainerName: viper.GetString("backup.storage.azure.container_name"),
-			}
-		case "GCS":
-			backupConfig.Storage.Provider = backup.StorageProviderGCS
-			backupConfig.Storage.GCS = &backup.GCSConfig{
-				Bucket:          viper.GetString("backup.storage.gcs.bucket"),
-				CredentialsPath: viper.GetString("backup.storage.gcs.credentials_path"),
-				ProjectID:       viper.GetString("backup.storage.gcs.project_id"),
-			}
-		}
-	}
+	backupConfig := config.Backup
 
 	// Override storage provider if specified via flag
 	if storageProvider != "" {
-		switch strings.ToUpper(storageProvider) {
-		case "LOCAL":
-			backupConfig.Storage.Provider = backup.StorageProviderLocal
-		case "S3":
-			backupConfig.Storage.Provider = backup.StorageProviderS3
-		case "AZURE":
-			backupConfig.Storage.Provider = backup.StorageProviderAzure
-		case "GCS":
-			backupConfig.Storage.Provider = backup.StorageProviderGCS
+		switch strings.ToLower(storageProvider) {
+		case "local", "s3", "azure", "gcs":
+			backupConfig.Storage.Provider = strings.ToLower(storageProvider)
 		default:
 			return nil, fmt.Errorf("invalid storage provider: %s", storageProvider)
 		}
 	}
 
-	// Set retention configuration
-	backupConfig.Retention = backup.RetentionConfig{
-		MaxBackups:      viper.GetInt("backup.retention.max_backups"),
-		MaxAge:          viper.GetDuration("backup.retention.max_age"),
-		CleanupInterval: viper.GetDuration("backup.retention.cleanup_interval"),
-		KeepDaily:       viper.GetInt("backup.retention.keep_daily"),
-		KeepWeekly:      viper.GetInt("backup.retention.keep_weekly"),
-		KeepMonthly:     viper.GetInt("backup.retention.keep_monthly"),
-	}
-
-	// Set compression configuration
-	backupConfig.Compression = backup.CompressionConfig{
-		Algorithm: backup.CompressionTypeGzip,
-		Level:     6,
-		Enabled:   true,
-		Threshold: 1024, // Compress files larger than 1KB
-	}
-
-	// Set encryption configuration
-	backupConfig.Encryption = backup.EncryptionConfig{
-		Enabled:         viper.GetBool("backup.encryption.enabled"),
-		KeySource:       viper.GetString("backup.encryption.key_source"),
-		KeyPath:         viper.GetString("backup.encryption.key_path"),
-		KeyEnvVar:       viper.GetString("backup.encryption.key_env_var"),
-		RotationEnabled: viper.GetBool("backup.encryption.rotation_enabled"),
-		RotationDays:    viper.GetInt("backup.encryption.rotation_days"),
-	}
-
-	// Set validation configuration
-	backupConfig.Validation = backup.ValidationConfig{
-		Enabled:           true,
-		ValidateOnCreate:  true,
-		ValidateOnRestore: false, // Expensive, disabled by default
-		ChecksumAlgorithm: "sha256",
-		ValidationTimeout: 30 * time.Second,
-		DryRunValidation:  false,
-	}
-
-	return backupConfig, nil
+	return database.BackupSystemConfig(backupConfig)
 }
 
 // parseTags parses tag strings in key=value format
@@ -0,0 +1,162 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"mysql-schema-sync/internal/application"
+	"mysql-schema-sync/internal/database"
+	"mysql-schema-sync/internal/history"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var (
+	// History flags
+	historySince   string
+	historyUser    string
+	historyOutcome string
+	historyPlan    string
+	historyLimit   int
+	historyOutput  string
+	historyTargets []string
+)
+
+// historyCmd lists the migrations recorded in the history table of the targets
+var historyCmd = &cobra.Command{
+	Use:   "history",
+	Short: "List the migrations recorded in the target history",
+	Long: `List the migrations that --history recorded in the history table of the
+target database: who ran them, from where, with which tool version, the plan
+hash, statement count, duration, pre-migration backup and outcome.
+
+Add --targets with the config files of other targets to list their runs in the
+same report. Use "history show <id>" to print the full plan of a run.
+
+Examples:
+  # Failed runs of the last week
+  mysql-schema-sync history --config=prod.yaml --since 7d --outcome failed
+
+  # Runs across several targets as JSON
+  mysql-schema-sync history --config=prod-eu.yaml --targets prod-us.yaml,prod-ap.yaml --output json
+
+  # The full plan of run 42
+  mysql-schema-sync history show 42 --config=prod.yaml`,
+	Annotations: map[string]string{targetOnlyAnnotation: "true"},
+	RunE:        runHistory,
+}
+
+// historyShowCmd prints one recorded run with its full plan
+var historyShowCmd = &cobra.Command{
+	Use:         "show <id>",
+	Short:       "Show a recorded migration with its full plan",
+	Args:        cobra.ExactArgs(1),
+	Annotations: map[string]string{targetOnlyAnnotation: "true"},
+	RunE:        runHistoryShow,
+}
+
+func init() {
+	rootCmd.AddCommand(historyCmd)
+	historyCmd.AddCommand(historyShowCmd)
+
+	historyCmd.PersistentFlags().StringVar(&historyOutput, "output", "text", "history report format (text, json)")
+	historyCmd.Flags().StringVar(&historySince, "since", "", "only list runs started within this age (e.g. 7d, 2w, 12h)")
+	historyCmd.Flags().StringVar(&historyUser, "user", "", "only list runs started by this user")
+	historyCmd.Flags().StringVar(&historyOutcome, "outcome", "", "only list runs with this outcome (succeeded, failed)")
+	historyCmd.Flags().StringVar(&historyPlan, "plan", "", "only list runs of plans whose hash starts with this prefix")
+	historyCmd.Flags().IntVar(&historyLimit, "limit", 50, "maximum number of runs to list (0 lists all)")
+	historyCmd.Flags().StringSliceVar(&historyTargets, "targets", nil, "config files of additional targets to include")
+}
+
+// runHistory lists the recorded runs
+func runHistory(cmd *cobra.Command, args []string) error {
+	format, err := history.ParseFormat(historyOutput)
+	if err != nil {
+		return err
+	}
+
+	filter := history.Filter{User: historyUser, PlanHash: historyPlan, Limit: historyLimit}
+	if filter.Outcome, err = history.ParseOutcome(historyOutcome); err != nil {
+		return fmt.Errorf("invalid --outcome: %w", err)
+	}
+	if historySince != "" {
+		age, err := parseAge(historySince)
+		if err != nil {
+			return fmt.Errorf("invalid --since: %w", err)
+		}
+		filter.Since = time.Now().Add(-age)
+	}
+
+	targets := make([]database.DatabaseConfig, 0, len(historyTargets))
+	for _, path := range historyTargets {
+		target, err := loadTargetConfig(path)
+		if err != nil {
+			return err
+		}
+		targets = append(targets, target)
+	}
+
+	app, err := newHistoryApplication(cmd, format)
+	if err != nil {
+		return err
+	}
+
+	return app.History(filter, targets, format, os.Stdout)
+}
+
+// runHistoryShow prints a recorded run with its plan
+func runHistoryShow(cmd *cobra.Command, args []string) error {
+	format, err := history.ParseFormat(historyOutput)
+	if err != nil {
+		return err
+	}
+
+	id, err := strconv.ParseInt(args[0], 10, 64)
+	if err != nil || id <= 0 {
+		return fmt.Errorf("invalid run ID: %s", args[0])
+	}
+
+	app, err := newHistoryApplication(cmd, format)
+	if err != nil {
+		return err
+	}
+
+	return app.ShowHistory(id, format, os.Stdout)
+}
+
+// newHistoryApplication creates a target-only application whose progress output stays out of
+// machine readable reports
+func newHistoryApplication(cmd *cobra.Command, format history.Format) (*application.Application, error) {
+	return newApplicationForCommand(cmd, func(config *application.Config) {
+		config.TargetOnly = true
+		if format != history.FormatText {
+			config.Quiet = true
+			config.Verbose = false
+		}
+	})
+}
+
+// loadTargetConfig reads the target database settings of another configuration file
+func loadTargetConfig(path string) (database.DatabaseConfig, error) {
+	v := viper.New()
+	v.SetConfigFile(path)
+	if err := v.ReadInConfig(); err != nil {
+		return database.DatabaseConfig{}, fmt.Errorf("failed to read target config %s: %w", path, err)
+	}
+
+	config := &application.Config{}
+	if err := v.Unmarshal(config); err != nil {
+		return database.DatabaseConfig{}, fmt.Errorf("failed to unmarshal target config %s: %w", path, err)
+	}
+	if config.TargetDB.Port == 0 {
+		config.TargetDB.Port = 3306
+	}
+	if err := config.TargetDB.Validate(); err != nil {
+		return database.DatabaseConfig{}, fmt.Errorf("invalid target in %s: %w", path, err)
+	}
+
+	return config.TargetDB, nil
+}
@@ -0,0 +1,82 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"mysql-schema-sync/internal/application"
+	"mysql-schema-sync/internal/lint"
+	"mysql-schema-sync/internal/schema"
+
+	"github.com/spf13/cobra"
+)
+
+// targetOnlyAnnotation marks commands that only connect to the target database, so the source
+// connection flags are not required
+const targetOnlyAnnotation = "target-only"
+
+var (
+	// Lint flags
+	lintOutput string
+	lintFailOn string
+)
+
+// lintCmd reports schema smells in the target database
+var lintCmd = &cobra.Command{
+	Use:   "lint",
+	Short: "Report schema smells in the target database",
+	Long: `Extract the target database and report schema smells: tables without a
+primary key, duplicate and redundant (left-prefix) indexes, foreign keys without
+a supporting index, utf8mb3 columns, FLOAT/DOUBLE money columns, nullable columns
+in unique keys, oversized VARCHARs in indexes and foreign keys whose collation
+differs from the column they reference.
+
+Only the target connection settings are needed. Use --output sarif to upload the
+findings to a code scanning tool and --fail-on to fail CI builds.
+
+Examples:
+  # Lint the target database of a config file
+  mysql-schema-sync lint --config=config.yaml
+
+  # Produce a SARIF report and fail on high severity findings
+  mysql-schema-sync lint --target-host=db --target-user=ci --target-db=app \
+    --output sarif --fail-on high > lint.sarif`,
+	Annotations: map[string]string{targetOnlyAnnotation: "true"},
+	RunE:        runLint,
+}
+
+func init() {
+	rootCmd.AddCommand(lintCmd)
+
+	lintCmd.Flags().StringVar(&lintOutput, "output", "text", "lint report format (text, json, sarif)")
+	lintCmd.Flags().StringVar(&lintFailOn, "fail-on", "", "exit with an error if any finding is at or above this severity (low, medium, high, critical)")
+}
+
+// runLint lints the target database and writes the report to stdout
+func runLint(cmd *cobra.Command, args []string) error {
+	format, err := lint.ParseFormat(lintOutput)
+	if err != nil {
+		return err
+	}
+
+	var failOn schema.WarningSeverity
+	if lintFailOn != "" {
+		if failOn, err = lint.ParseSeverity(lintFailOn); err != nil {
+			return fmt.Errorf("invalid --fail-on: %w", err)
+		}
+	}
+
+	app, err := newApplicationForCommand(cmd, func(config *application.Config) {
+		config.TargetOnly = true
+		// Keep machine readable reports free of progress output
+		if format != lint.FormatText {
+			config.Quiet = true
+			config.Verbose = false
+		}
+	})
+	if err != nil {
+		return err
+	}
+
+	return app.Lint(format, failOn, os.Stdout)
+}
@@ -0,0 +1,16 @@
+package cmd
+
+import "testing"
+
+func TestLintCmd_TargetOnlyFlags(t *testing.T) {
+	cmd, err := parseCommand(t, "lint", "--target-host=db", "--target-user=ci", "--target-db=app", "--output", "sarif")
+	if err != nil {
+		t.Fatalf("Failed to parse the documented lint example: %v", err)
+	}
+	if err := validateFlags(cmd); err != nil {
+		t.Errorf("Expected lint to need only the target flags, got %v", err)
+	}
+	if lintOutput != "sarif" {
+		t.Errorf("Expected SARIF output, got %q", lintOutput)
+	}
+}
@@ -0,0 +1,107 @@
+package cmd
+
+import (
+	"fmt"
+
+	"mysql-schema-sync/internal/application"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	// Plan flags
+	planOut string
+)
+
+// planCmd computes a migration plan and saves it for review
+var planCmd = &cobra.Command{
+	Use:   "plan",
+	Short: "Compute a migration plan and save it for review",
+	Long: `Compare the source and target databases and save the resulting migration plan
+to a file instead of applying it.
+
+The saved plan records fingerprints of both schemas. Applying it later with the
+apply command re-checks the target and refuses to run if it changed, so the
+statements that were reviewed are exactly the statements that run.
+
+Examples:
+  # Save a plan for review
+  mysql-schema-sync plan --config=config.yaml --out plan.json
+
+  # Apply the reviewed plan
+  mysql-schema-sync apply plan.json --config=config.yaml`,
+	RunE: runPlan,
+}
+
+// applyCmd applies a saved migration plan
+var applyCmd = &cobra.Command{
+	Use:   "apply <plan-file>",
+	Short: "Apply a saved migration plan",
+	Long: `Apply a migration plan saved by the plan command.
+
+The target schema is extracted again and compared with the fingerprint recorded
+in the plan. If the target changed since planning, nothing is executed and a new
+plan must be created. Otherwise the saved statements are executed exactly as
+they appear in the file.
+
+Examples:
+  # Apply a reviewed plan
+  mysql-schema-sync apply plan.json --config=config.yaml
+
+  # Check the plan still applies without executing it
+  mysql-schema-sync apply plan.json --config=config.yaml --dry-run`,
+	Args: cobra.ExactArgs(1),
+	RunE: runApply,
+}
+
+func init() {
+	rootCmd.AddCommand(planCmd)
+	rootCmd.AddCommand(applyCmd)
+
+	planCmd.Flags().StringVar(&planOut, "out", "plan.json", "file the migration plan is saved to")
+}
+
+// runPlan computes the migration plan and saves it instead of applying it
+func runPlan(cmd *cobra.Command, args []string) error {
+	app, err := newApplicationForCommand(cmd, func(config *application.Config) {
+		config.PlanOut = planOut
+	})
+	if err != nil {
+		return err
+	}
+
+	return app.Run()
+}
+
+// runApply applies a saved migration plan
+func runApply(cmd *cobra.Command, args []string) error {
+	app, err := newApplicationForCommand(cmd, nil)
+	if err != nil {
+		return err
+	}
+
+	return app.Apply(args[0])
+}
+
+// newApplicationForCommand validates flags, builds the configuration and creates the application
+func newApplicationForCommand(cmd *cobra.Command, configure func(config *application.Config)) (*application.Application, error) {
+	if err := validateFlags(cmd); err != nil {
+		return nil, err
+	}
+
+	config, err := buildConfig(cmd)
+	if err != nil {
+		return nil, fmt.Errorf("configuration error: %w", err)
+	}
+
+	if configure != nil {
+		configure(config)
+	}
+
+	app, err := application.NewApplication(*config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize application: %w", err)
+	}
+
+	return app, nil
+}
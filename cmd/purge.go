@@ -0,0 +1,80 @@
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	// Purge flags
+	purgeOlderThan string
+)
+
+// purgeArchivedCmd drops tables and columns archived by safe-drop mode
+var purgeArchivedCmd = &cobra.Command{
+	Use:   "purge-archived",
+	Short: "Permanently drop tables and columns archived by --safe-drop",
+	Long: `Permanently drop the tables and columns that --safe-drop renamed to
+_archived_<timestamp>_<name> more than --older-than ago.
+
+Use --dry-run to list the archived objects that would be dropped.
+
+Examples:
+  # List objects archived more than 30 days ago
+  mysql-schema-sync purge-archived --config=config.yaml --older-than 30d --dry-run
+
+  # Drop them
+  mysql-schema-sync purge-archived --config=config.yaml --older-than 30d`,
+	RunE: runPurgeArchived,
+}
+
+func init() {
+	rootCmd.AddCommand(purgeArchivedCmd)
+
+	purgeArchivedCmd.Flags().StringVar(&purgeOlderThan, "older-than", "30d", "minimum age of archived objects to drop (e.g. 30d, 2w, 12h)")
+}
+
+// runPurgeArchived drops archived objects older than --older-than
+func runPurgeArchived(cmd *cobra.Command, args []string) error {
+	olderThan, err := parseAge(purgeOlderThan)
+	if err != nil {
+		return fmt.Errorf("invalid --older-than: %w", err)
+	}
+
+	app, err := newApplicationForCommand(cmd, nil)
+	if err != nil {
+		return err
+	}
+
+	return app.PurgeArchived(olderThan)
+}
+
+// parseAge parses a duration that may also use d (days) and w (weeks) units
+func parseAge(value string) (time.Duration, error) {
+	value = strings.TrimSpace(value)
+
+	units := map[string]time.Duration{"d": 24 * time.Hour, "w": 7 * 24 * time.Hour}
+	var age time.Duration
+	if size, ok := units[value[max(len(value)-1, 0):]]; ok {
+		count, err := strconv.Atoi(value[:len(value)-1])
+		if err != nil {
+			return 0, fmt.Errorf("%q is not a valid age", value)
+		}
+		age = time.Duration(count) * size
+	} else {
+		var err error
+		if age, err = time.ParseDuration(value); err != nil {
+			return 0, fmt.Errorf("%q is not a valid age", value)
+		}
+	}
+
+	if age < 0 {
+		return 0, fmt.Errorf("age cannot be negative")
+	}
+
+	return age, nil
+}
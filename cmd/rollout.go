@@ -0,0 +1,95 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"mysql-schema-sync/internal/application"
+	"mysql-schema-sync/internal/rollout"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	// Rollout flags
+	rolloutFleet  string
+	rolloutOutput string
+)
+
+// rolloutCmd applies a saved plan to groups of targets, one group after the other
+var rolloutCmd = &cobra.Command{
+	Use:   "rollout <plan-file>",
+	Short: "Apply a saved migration plan to a fleet of targets in stages",
+	Long: `Apply a migration plan saved by the plan command to the ordered groups of a
+fleet file, e.g. canary, then staging, then the production shards.
+
+The targets of a group are migrated with the group's concurrency. After a group
+succeeded the rollout waits for its soak time before starting the next one. Each
+target is checked against the plan's fingerprint before it runs, and its schema
+is read back afterwards: every target has to end up with the same schema as the
+targets migrated before it. The rollout halts on the first failure or mismatch
+and never starts the groups after it. A consolidated report lists the outcome of
+every target.
+
+Locking, journaling, throttling and history settings apply to every target.
+
+Fleet file:
+  soak: 10m                  # wait after each group, unless the group sets its own
+  groups:
+    - name: canary
+      targets: [canary.yaml] # config files whose target section is used
+      soak: 30m
+    - name: prod
+      concurrency: 4
+      targets:
+        - {name: shard-1, host: shard-1.db, database: app}
+        - {name: shard-2, host: shard-2.db, database: app}
+
+Inline targets take the port, user, password and timeout they leave out from
+the target of --config.
+
+Examples:
+  # Plan against the canary, then roll the plan out to the fleet
+  mysql-schema-sync plan --config=canary.yaml --out plan.json
+  mysql-schema-sync rollout plan.json --config=canary.yaml --fleet fleet.yaml
+
+  # Check every target still matches the plan without executing it
+  mysql-schema-sync rollout plan.json --config=canary.yaml --fleet fleet.yaml --dry-run`,
+	Args: cobra.ExactArgs(1),
+	RunE: runRollout,
+}
+
+func init() {
+	rootCmd.AddCommand(rolloutCmd)
+
+	rolloutCmd.Flags().StringVar(&rolloutFleet, "fleet", "", "fleet file listing the target groups in rollout order")
+	rolloutCmd.Flags().StringVar(&rolloutOutput, "output", "text", "rollout report format (text, json)")
+	rolloutCmd.MarkFlagRequired("fleet")
+}
+
+// runRollout applies the saved plan to every group of the fleet file
+func runRollout(cmd *cobra.Command, args []string) error {
+	format, err := rollout.ParseFormat(rolloutOutput)
+	if err != nil {
+		return err
+	}
+
+	// Inline targets of the fleet file default to the connection settings of the configured target
+	var spec *rollout.Spec
+	var specErr error
+	app, err := newApplicationForCommand(cmd, func(config *application.Config) {
+		spec, specErr = rollout.Load(rolloutFleet, config.TargetDB, loadTargetConfig)
+		if format != rollout.FormatText {
+			config.Quiet = true
+			config.Verbose = false
+		}
+	})
+	if err != nil {
+		return err
+	}
+	if specErr != nil {
+		return fmt.Errorf("failed to load fleet: %w", specErr)
+	}
+
+	return app.Rollout(args[0], spec, format, os.Stdout)
+}
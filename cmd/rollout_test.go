@@ -0,0 +1,22 @@
+package cmd
+
+import "testing"
+
+func TestRolloutCmd_RootFlags(t *testing.T) {
+	cmd, err := parseCommand(t, "rollout", "plan.json", "--config=canary.yaml", "--fleet", "fleet.yaml", "--dry-run")
+	if err != nil {
+		t.Fatalf("Failed to parse the documented rollout example: %v", err)
+	}
+	if cmd != rolloutCmd {
+		t.Fatalf("Expected the rollout command, got %s", cmd.Name())
+	}
+	if !dryRun || rolloutFleet != "fleet.yaml" || cfgFile != "canary.yaml" {
+		t.Errorf("Unexpected flag values: dry-run=%v fleet=%q config=%q", dryRun, rolloutFleet, cfgFile)
+	}
+
+	for _, name := range []string{"lock-timeout", "journal", "max-replica-lag", "history"} {
+		if cmd.Flags().Lookup(name) == nil {
+			t.Errorf("Expected rollout to accept --%s", name)
+		}
+	}
+}
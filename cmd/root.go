@@ -1,9 +1,19 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
 	"mysql-schema-sync/internal/application"
 	"mysql-schema-sync/internal/database"
+	appErrors "mysql-schema-sync/internal/errors"
+	"mysql-schema-sync/internal/history"
+	"mysql-schema-sync/internal/journal"
+	"mysql-schema-sync/internal/lock"
+	"mysql-schema-sync/internal/mdl"
+	"mysql-schema-sync/internal/migration"
+	"mysql-schema-sync/internal/migrationfiles"
+	"mysql-schema-sync/internal/osc"
+	"mysql-schema-sync/internal/throttle"
 	"os"
 	"strings"
 	"time"
@@ -38,6 +48,49 @@ var (
 	timeout     time.Duration
 	logFile     string
 
+	// Planning flags
+	mergeAlters    bool
+	onlineDDL      bool
+	oscTool        string
+	oscThreshold   int64
+	oscPrintOnly   bool
+	oscOnMaster    bool
+	showRollback   bool
+	rollbackFile   string
+	emitDir        string
+	emitFormat     string
+	skipDataChecks bool
+	workers        int
+	verify         bool
+	safeDrop       bool
+	safeDropCols   string
+	policyFile     string
+	targetTags     []string
+	policyOverride string
+	onlyChanges    []string
+	skipChanges    []string
+	reviewChanges  bool
+	useJournal     bool
+	journalTable   string
+	recordHistory  bool
+	takeBackup     bool
+	historyTable   string
+	useLock        bool
+	lockTimeout    time.Duration
+	forceUnlock    string
+	checkMDL       bool
+	lockWait       time.Duration
+	maxTrxAge      time.Duration
+	onBlocked      string
+	blockedWait    time.Duration
+	replicaHosts   []string
+	findReplicas   bool
+	maxReplicaLag  time.Duration
+	maxLoad        string
+	criticalLoad   string
+	throttleQuery  string
+	pauseFile      string
+
 	// Display flags
 	noColor       bool
 	theme         string
@@ -80,20 +133,38 @@ Examples:
   # Verbose output with custom table styling
   mysql-schema-sync --config=config.yaml --verbose --table-style=rounded
 
+  # Apply only the changes to the orders table, leaving out column drops
+  mysql-schema-sync --config=config.yaml --only=orders --skip='*:DROP_COLUMN'
+
+  # Pick the changes to apply interactively
+  mysql-schema-sync --config=config.yaml --review
+
   # Non-interactive mode for automation
   mysql-schema-sync --config=config.yaml --auto-approve --no-interactive`,
 	RunE: runSchemaSync,
 }
 
+// exitCodeVerification is returned when a migration ran but the target still differs from the source
+const exitCodeVerification = 3
+
 // Execute adds all child commands to the root command and sets flags appropriately.
 // This is called by main.main(). It only needs to happen once to the rootCmd.
 func Execute() {
 	err := rootCmd.Execute()
 	if err != nil {
-		os.Exit(1)
+		os.Exit(exitCode(err))
 	}
 }
 
+// exitCode returns the process exit code for an error
+func exitCode(err error) int {
+	var appErr *appErrors.AppError
+	if errors.As(err, &appErr) && appErr.Type == appErrors.ErrorTypeVerification {
+		return exitCodeVerification
+	}
+	return 1
+}
+
 func init() {
 	cobra.OnInitialize(initConfig)
 
@@ -122,6 +193,49 @@ func init() {
 	rootCmd.Flags().DurationVar(&timeout, "timeout", 30*time.Second, "database operation timeout")
 	rootCmd.Flags().StringVar(&logFile, "log-file", "", "write logs to file instead of stdout")
 
+	// Planning flags
+	rootCmd.Flags().BoolVar(&mergeAlters, "merge-alters", false, "merge all changes to a table into a single ALTER TABLE statement")
+	rootCmd.Flags().BoolVar(&onlineDDL, "online-ddl", false, "append explicit ALGORITHM/LOCK clauses predicted for the target MySQL version")
+	rootCmd.Flags().StringVar(&oscTool, "osc-tool", "", "run changes to large tables through an external tool (gh-ost, pt-osc)")
+	rootCmd.Flags().Int64Var(&oscThreshold, "osc-threshold-mb", 1024, "minimum table size in MB (data + indexes) delegated to --osc-tool")
+	rootCmd.Flags().BoolVar(&oscPrintOnly, "osc-print-only", false, "print the SQL and external tool commands instead of executing them")
+	rootCmd.Flags().BoolVar(&oscOnMaster, "osc-allow-on-master", false, "let gh-ost run directly against the target primary instead of a replica")
+	rootCmd.Flags().BoolVar(&showRollback, "show-rollback", false, "display the plan that reverts the migration")
+	rootCmd.Flags().StringVar(&rollbackFile, "rollback-file", "", "write the SQL script that reverts the migration to this file")
+	rootCmd.Flags().StringVar(&emitDir, "emit-migrations", "", "write the plan as migration files to this directory instead of applying it")
+	rootCmd.Flags().StringVar(&emitFormat, "migration-format", "golang-migrate", "migration file format (golang-migrate, flyway, liquibase, atlas, dbmate)")
+	rootCmd.Flags().BoolVar(&skipDataChecks, "skip-data-checks", false, "skip the pre-flight queries that look for rows the changes would reject")
+	rootCmd.Flags().IntVar(&workers, "workers", 1, "number of statements on independent tables executed concurrently")
+	rootCmd.Flags().BoolVar(&verify, "verify", true, "compare source and target again after executing and fail if they still differ")
+	rootCmd.Flags().BoolVar(&safeDrop, "safe-drop", false, "archive dropped tables and columns by renaming them instead of dropping them")
+	rootCmd.Flags().StringVar(&safeDropCols, "safe-drop-columns", "rename", "what --safe-drop does with dropped columns (rename, keep)")
+	rootCmd.Flags().StringVar(&policyFile, "policy", "", "YAML policy file whose rules every migration plan must satisfy")
+	rootCmd.Flags().StringSliceVar(&targetTags, "target-tags", nil, "tags describing the target database, e.g. prod, used to select policy rules")
+	rootCmd.Flags().StringVar(&policyOverride, "override-policy", "", "run the plan despite denied policy rules, recording this reason in the audit log")
+	rootCmd.Flags().StringSliceVar(&onlyChanges, "only", nil, "apply only statements matching these TABLE[:TYPE] selectors, e.g. orders or 'users:ADD_*'")
+	rootCmd.Flags().StringSliceVar(&skipChanges, "skip", nil, "leave out statements matching these TABLE[:TYPE] selectors, e.g. '*:DROP_COLUMN'")
+	rootCmd.Flags().BoolVar(&reviewChanges, "review", false, "pick the changes to apply interactively")
+	rootCmd.Flags().BoolVar(&useJournal, "journal", true, "record executed statements in a journal table on the target so an interrupted plan can resume")
+	rootCmd.Flags().StringVar(&journalTable, "journal-table", journal.DefaultTable, "name of the execution journal table on the target")
+	rootCmd.Flags().BoolVar(&recordHistory, "history", false, "record every executed migration in a history table on the target")
+	rootCmd.Flags().StringVar(&historyTable, "history-table", history.DefaultTable, "name of the migration history table on the target")
+	rootCmd.Flags().BoolVar(&takeBackup, "backup", false, "back up the target schema before executing and stop if the backup fails validation")
+	rootCmd.Flags().BoolVar(&useLock, "lock", true, "hold an advisory lock on the target while planning and executing")
+	rootCmd.Flags().DurationVar(&lockTimeout, "lock-timeout", lock.DefaultWaitTimeout, "how long to wait for another run to release the target lock")
+	rootCmd.Flags().StringVar(&forceUnlock, "force-unlock", "", "kill the connection holding the target lock, recording this reason in the audit log")
+	rootCmd.Flags().BoolVar(&checkMDL, "check-metadata-locks", false, "look for sessions holding locks on a table before altering it")
+	rootCmd.Flags().DurationVar(&lockWait, "lock-wait-timeout", 0, "session lock_wait_timeout of every statement (0 keeps the server setting)")
+	rootCmd.Flags().DurationVar(&maxTrxAge, "max-transaction-age", 0, "ignore blocking transactions younger than this")
+	rootCmd.Flags().StringVar(&onBlocked, "on-blocked", string(mdl.ActionAbort), "when a table is blocked: abort with a report, or wait for the sessions to finish")
+	rootCmd.Flags().DurationVar(&blockedWait, "blocked-wait-timeout", mdl.DefaultWaitTimeout, "how long --on-blocked wait waits for blocking sessions")
+	rootCmd.Flags().StringSliceVar(&replicaHosts, "replicas", nil, "replicas (host[:port]) whose lag pauses execution between statements")
+	rootCmd.Flags().BoolVar(&findReplicas, "discover-replicas", false, "watch the replicas the target lists in SHOW REPLICAS")
+	rootCmd.Flags().DurationVar(&maxReplicaLag, "max-replica-lag", throttle.DefaultMaxLag, "pause execution while any replica lags more than this")
+	rootCmd.Flags().StringVar(&maxLoad, "max-load", "", "pause execution while a status variable reaches its threshold, e.g. Threads_running=25")
+	rootCmd.Flags().StringVar(&criticalLoad, "critical-load", "", "abort the migration when a status variable reaches its threshold, e.g. Threads_running=100")
+	rootCmd.Flags().StringVar(&throttleQuery, "throttle-query", "", "pause execution while this query returns a value greater than zero")
+	rootCmd.Flags().StringVar(&pauseFile, "pause-file", "", "pause execution while this file exists")
+
 	// Display flags
 	rootCmd.Flags().BoolVar(&noColor, "no-color", false, "disable color output")
 	rootCmd.Flags().StringVar(&theme, "theme", "dark", "color theme (dark, light, high-contrast, auto)")
@@ -151,6 +265,44 @@ func init() {
 	viper.BindPFlag("auto_approve", rootCmd.Flags().Lookup("auto-approve"))
 	viper.BindPFlag("timeout", rootCmd.Flags().Lookup("timeout"))
 	viper.BindPFlag("log_file", rootCmd.Flags().Lookup("log-file"))
+	viper.BindPFlag("merge_alterations", rootCmd.Flags().Lookup("merge-alters"))
+	viper.BindPFlag("online_ddl", rootCmd.Flags().Lookup("online-ddl"))
+	viper.BindPFlag("online_schema_change.tool", rootCmd.Flags().Lookup("osc-tool"))
+	viper.BindPFlag("online_schema_change.threshold_mb", rootCmd.Flags().Lookup("osc-threshold-mb"))
+	viper.BindPFlag("online_schema_change.print_only", rootCmd.Flags().Lookup("osc-print-only"))
+	viper.BindPFlag("online_schema_change.allow_on_master", rootCmd.Flags().Lookup("osc-allow-on-master"))
+	viper.BindPFlag("show_rollback", rootCmd.Flags().Lookup("show-rollback"))
+	viper.BindPFlag("rollback_file", rootCmd.Flags().Lookup("rollback-file"))
+	viper.BindPFlag("emit_migrations.dir", rootCmd.Flags().Lookup("emit-migrations"))
+	viper.BindPFlag("emit_migrations.format", rootCmd.Flags().Lookup("migration-format"))
+	viper.BindPFlag("skip_data_checks", rootCmd.Flags().Lookup("skip-data-checks"))
+	viper.BindPFlag("workers", rootCmd.Flags().Lookup("workers"))
+	viper.BindPFlag("verify", rootCmd.Flags().Lookup("verify"))
+	viper.BindPFlag("safe_drop.enabled", rootCmd.Flags().Lookup("safe-drop"))
+	viper.BindPFlag("safe_drop.columns", rootCmd.Flags().Lookup("safe-drop-columns"))
+	viper.BindPFlag("policy.file", rootCmd.Flags().Lookup("policy"))
+	viper.BindPFlag("policy.target_tags", rootCmd.Flags().Lookup("target-tags"))
+	viper.BindPFlag("selection.only", rootCmd.Flags().Lookup("only"))
+	viper.BindPFlag("selection.skip", rootCmd.Flags().Lookup("skip"))
+	viper.BindPFlag("selection.review", rootCmd.Flags().Lookup("review"))
+	viper.BindPFlag("journal.enabled", rootCmd.Flags().Lookup("journal"))
+	viper.BindPFlag("journal.table", rootCmd.Flags().Lookup("journal-table"))
+	viper.BindPFlag("history.enabled", rootCmd.Flags().Lookup("history"))
+	viper.BindPFlag("history.table", rootCmd.Flags().Lookup("history-table"))
+	viper.BindPFlag("backup.enabled", rootCmd.Flags().Lookup("backup"))
+	viper.BindPFlag("lock.enabled", rootCmd.Flags().Lookup("lock"))
+	viper.BindPFlag("lock.wait_timeout", rootCmd.Flags().Lookup("lock-timeout"))
+	viper.BindPFlag("metadata_locks.enabled", rootCmd.Flags().Lookup("check-metadata-locks"))
+	viper.BindPFlag("metadata_locks.lock_wait_timeout", rootCmd.Flags().Lookup("lock-wait-timeout"))
+	viper.BindPFlag("metadata_locks.max_transaction_age", rootCmd.Flags().Lookup("max-transaction-age"))
+	viper.BindPFlag("metadata_locks.on_blocked", rootCmd.Flags().Lookup("on-blocked"))
+	viper.BindPFlag("metadata_locks.wait_timeout", rootCmd.Flags().Lookup("blocked-wait-timeout"))
+	viper.BindPFlag("replication.discover", rootCmd.Flags().Lookup("discover-replicas"))
+	viper.BindPFlag("replication.max_lag", rootCmd.Flags().Lookup("max-replica-lag"))
+	viper.BindPFlag("load.max_load", rootCmd.Flags().Lookup("max-load"))
+	viper.BindPFlag("load.critical_load", rootCmd.Flags().Lookup("critical-load"))
+	viper.BindPFlag("load.query", rootCmd.Flags().Lookup("throttle-query"))
+	viper.BindPFlag("load.pause_file", rootCmd.Flags().Lookup("pause-file"))
 
 	// Bind display flags (only non-inverted ones)
 	viper.BindPFlag("display.theme", rootCmd.Flags().Lookup("theme"))
@@ -159,7 +311,9 @@ func init() {
 	viper.BindPFlag("display.max_table_width", rootCmd.Flags().Lookup("max-table-width"))
 
 	// Mark required flags when not using config file
-	rootCmd.MarkFlagsRequiredTogether("source-host", "source-user", "source-db", "target-host", "target-user", "target-db")
+	// The source and target groups are separate, so commands that only connect to the target accept its flags alone
+	rootCmd.MarkFlagsRequiredTogether("source-host", "source-user", "source-db")
+	rootCmd.MarkFlagsRequiredTogether("target-host", "target-user", "target-db")
 
 	// Add usage examples
 	rootCmd.SetUsageTemplate(getUsageTemplate())
@@ -198,14 +352,16 @@ func validateFlags(cmd *cobra.Command) error {
 	if cfgFile == "" {
 		missingFlags := []string{}
 
-		if sourceHost == "" {
-			missingFlags = append(missingFlags, "--source-host")
-		}
-		if sourceUsername == "" {
-			missingFlags = append(missingFlags, "--source-user")
-		}
-		if sourceDatabase == "" {
-			missingFlags = append(missingFlags, "--source-db")
+		if cmd.Annotations[targetOnlyAnnotation] != "true" {
+			if sourceHost == "" {
+				missingFlags = append(missingFlags, "--source-host")
+			}
+			if sourceUsername == "" {
+				missingFlags = append(missingFlags, "--source-user")
+			}
+			if sourceDatabase == "" {
+				missingFlags = append(missingFlags, "--source-db")
+			}
 		}
 		if targetHost == "" {
 			missingFlags = append(missingFlags, "--target-host")
@@ -353,6 +509,161 @@ func buildConfig(cmd *cobra.Command) (*application.Config, error) {
 	if logFile != "" {
 		config.LogFile = logFile
 	}
+	if cmd.Flags().Changed("merge-alters") {
+		config.MergeAlterations = mergeAlters
+	}
+	if cmd.Flags().Changed("online-ddl") {
+		config.OnlineDDL = onlineDDL
+	}
+	if cmd.Flags().Changed("osc-tool") {
+		config.OnlineSchemaChange.Tool = osc.Tool(oscTool)
+	}
+	if cmd.Flags().Changed("osc-threshold-mb") {
+		config.OnlineSchemaChange.ThresholdMB = oscThreshold
+	}
+	if cmd.Flags().Changed("osc-print-only") {
+		config.OnlineSchemaChange.PrintOnly = oscPrintOnly
+	}
+	if cmd.Flags().Changed("osc-allow-on-master") {
+		config.OnlineSchemaChange.AllowOnMaster = oscOnMaster
+	}
+	if cmd.Flags().Changed("show-rollback") {
+		config.ShowRollback = showRollback
+	}
+	if rollbackFile != "" {
+		config.RollbackFile = rollbackFile
+	}
+	if emitDir != "" {
+		config.EmitMigrations.Dir = emitDir
+	}
+	if cmd.Flags().Changed("migration-format") {
+		config.EmitMigrations.Format = migrationfiles.Format(emitFormat)
+	}
+	if cmd.Flags().Changed("skip-data-checks") {
+		config.SkipDataChecks = skipDataChecks
+	}
+	if cmd.Flags().Changed("workers") {
+		config.Workers = workers
+	}
+	if cmd.Flags().Changed("verify") {
+		config.Verify = verify
+	}
+	if cmd.Flags().Changed("safe-drop") {
+		config.SafeDrop.Enabled = safeDrop
+	}
+	if cmd.Flags().Changed("safe-drop-columns") {
+		config.SafeDrop.Columns = migration.ColumnArchiveMode(safeDropCols)
+	}
+	if policyFile != "" {
+		config.Policy.File = policyFile
+	}
+	if cmd.Flags().Changed("target-tags") {
+		config.Policy.TargetTags = targetTags
+	}
+	if cmd.Flags().Changed("override-policy") {
+		if strings.TrimSpace(policyOverride) == "" {
+			return nil, fmt.Errorf("--override-policy requires a reason")
+		}
+		config.Policy.Override = policyOverride
+	}
+	if cmd.Flags().Changed("only") {
+		config.Selection.Only = onlyChanges
+	}
+	if cmd.Flags().Changed("skip") {
+		config.Selection.Skip = skipChanges
+	}
+	if cmd.Flags().Changed("review") {
+		config.Selection.Review = reviewChanges
+	}
+	if cmd.Flags().Changed("journal") {
+		config.Journal.Enabled = useJournal
+	}
+	if cmd.Flags().Changed("journal-table") {
+		config.Journal.Table = journalTable
+	}
+	if cmd.Flags().Changed("history") {
+		config.History.Enabled = recordHistory
+	}
+	if cmd.Flags().Changed("history-table") {
+		config.History.Table = historyTable
+	}
+	if cmd.Flags().Changed("backup") {
+		config.Backup.Enabled = takeBackup
+	}
+	if cmd.Flags().Changed("lock") {
+		config.Lock.Enabled = useLock
+	}
+	if cmd.Flags().Changed("lock-timeout") {
+		config.Lock.WaitTimeout = lockTimeout
+	}
+	if cmd.Flags().Changed("force-unlock") {
+		if strings.TrimSpace(forceUnlock) == "" {
+			return nil, fmt.Errorf("--force-unlock requires a reason")
+		}
+		config.Lock.ForceUnlock = forceUnlock
+	}
+	if cmd.Flags().Changed("check-metadata-locks") {
+		config.MetadataLocks.Enabled = checkMDL
+	}
+	if cmd.Flags().Changed("lock-wait-timeout") {
+		config.MetadataLocks.LockWaitTimeout = lockWait
+	}
+	if cmd.Flags().Changed("max-transaction-age") {
+		config.MetadataLocks.MaxTransactionAge = maxTrxAge
+	}
+	if cmd.Flags().Changed("on-blocked") {
+		config.MetadataLocks.OnBlocked = mdl.Action(onBlocked)
+	}
+	if cmd.Flags().Changed("blocked-wait-timeout") {
+		config.MetadataLocks.WaitTimeout = blockedWait
+	}
+	if cmd.Flags().Changed("replicas") {
+		config.Replication.Replicas = make([]throttle.Replica, 0, len(replicaHosts))
+		for _, host := range replicaHosts {
+			replica, err := throttle.ParseReplica(host)
+			if err != nil {
+				return nil, fmt.Errorf("invalid --replicas: %w", err)
+			}
+			config.Replication.Replicas = append(config.Replication.Replicas, replica)
+		}
+	}
+	if cmd.Flags().Changed("discover-replicas") {
+		config.Replication.Discover = findReplicas
+	}
+	if cmd.Flags().Changed("max-replica-lag") {
+		config.Replication.MaxLag = maxReplicaLag
+	}
+	if maxLoad != "" {
+		config.Load.MaxLoad = maxLoad
+	}
+	if criticalLoad != "" {
+		config.Load.CriticalLoad = criticalLoad
+	}
+	if throttleQuery != "" {
+		config.Load.Query = throttleQuery
+	}
+	if pauseFile != "" {
+		config.Load.PauseFile = pauseFile
+	}
+	config.ToolVersion = version
+
+	tool, err := osc.ParseTool(string(config.OnlineSchemaChange.Tool))
+	if err != nil {
+		return nil, err
+	}
+	config.OnlineSchemaChange.Tool = tool
+
+	format, err := migrationfiles.ParseFormat(string(config.EmitMigrations.Format))
+	if err != nil {
+		return nil, err
+	}
+	config.EmitMigrations.Format = format
+
+	columnMode, err := migration.ParseColumnArchiveMode(string(config.SafeDrop.Columns))
+	if err != nil {
+		return nil, err
+	}
+	config.SafeDrop.Columns = columnMode
 
 	// Set display defaults if not loaded from config
 	setDisplayDefaults(&config.Display)
@@ -481,6 +792,63 @@ Operation Flags:
   --timeout duration        Database operation timeout (default 30s)
   --log-file string         Write logs to file instead of stdout
 
+Planning Flags:
+  --merge-alters            Merge all changes to a table into a single ALTER TABLE
+  --online-ddl              Use explicit ALGORITHM/LOCK clauses and fail instead of copying
+  --osc-tool string         Run changes to large tables through gh-ost or pt-osc
+  --osc-threshold-mb int    Minimum table size in MB delegated to --osc-tool (default 1024)
+  --osc-print-only          Print the SQL and external tool commands without executing them
+  --osc-allow-on-master     Let gh-ost run against the target primary instead of a replica
+  --show-rollback           Display the plan that reverts the migration
+  --rollback-file string    Write the SQL script that reverts the migration to a file
+  --emit-migrations string  Write the plan as migration files to a directory instead of applying it
+  --migration-format string Migration file format: golang-migrate, flyway, liquibase, atlas, dbmate
+                            (default "golang-migrate")
+  --skip-data-checks        Skip the pre-flight queries for NULLs, long values, duplicates and orphans
+  --workers int             Execute statements on independent tables concurrently (default 1)
+  --verify                  Compare source and target again after executing and exit with
+                            code 3 if the target still differs (default true)
+  --safe-drop               Rename dropped tables and columns to _archived_<timestamp>_<name>
+  --safe-drop-columns string
+                            Dropped columns with --safe-drop: rename or keep (default "rename")
+  --policy string           YAML policy file whose deny rules block the migration
+  --target-tags strings     Tags of the target database (e.g. prod) used to select policy rules
+  --override-policy string  Run despite denied policy rules; the reason is logged and audited
+  --only strings            Apply only statements matching TABLE[:TYPE] glob selectors
+  --skip strings            Leave out statements matching TABLE[:TYPE] glob selectors
+  --review                  Pick the changes to apply interactively
+  --journal                 Record executed statements on the target to resume interrupted
+                            runs of the same plan (default true)
+  --journal-table string    Execution journal table (default "schema_sync_journal")
+  --history                 Record every executed migration in a history table on the target
+  --history-table string    Migration history table (default "schema_sync_history")
+  --backup                  Back up the target schema before executing; the backup ID is
+                            printed for the rollback command
+  --lock                    Hold an advisory lock on the target while planning and executing
+                            (default true)
+  --lock-timeout duration   Wait for another run to release the target lock (default 30s)
+  --force-unlock string     Kill the connection holding the target lock; the reason is logged
+                            and audited
+  --check-metadata-locks    Look for sessions holding locks on a table before altering it
+  --lock-wait-timeout duration
+                            Session lock_wait_timeout of every statement, e.g. 30s; 0 keeps
+                            the server setting
+  --max-transaction-age duration
+                            Ignore blocking transactions younger than this; 0 reports every
+                            open transaction
+  --on-blocked string       When a table is blocked: abort or wait (default "abort")
+  --blocked-wait-timeout duration
+                            How long --on-blocked wait waits (default 5m0s)
+  --replicas strings        Replicas (host[:port]) whose lag pauses execution between statements
+  --discover-replicas       Watch the replicas the target lists in SHOW REPLICAS
+  --max-replica-lag duration
+                            Pause while any replica lags more than this (default 10s)
+  --max-load string         Pause while a status variable reaches its threshold,
+                            e.g. Threads_running=25,Innodb_row_lock_current_waits=10
+  --critical-load string    Abort when a status variable reaches its threshold
+  --throttle-query string   Pause while this query returns a value greater than zero
+  --pause-file string       Pause while this file exists (touch to pause, remove to resume)
+
 Visual Enhancement Flags:
   --no-color                Disable color output
   --theme string            Color theme: dark, light, high-contrast, auto (default "dark")
@@ -643,6 +1011,97 @@ auto_approve: false       # Automatically approve changes without confirmation
 timeout: 30s              # Global timeout for operations
 log_file: ""              # Optional log file path (empty = stdout)
 
+# Planning settings
+merge_alterations: false  # Merge all changes to a table into one ALTER TABLE (one rebuild per table)
+online_ddl: false         # Append ALGORITHM/LOCK clauses; fail instead of falling back to COPY
+show_rollback: false      # Display the plan that reverts the migration
+rollback_file: ""         # Write the rollback SQL script here before the migration runs
+skip_data_checks: false   # Skip querying the target for rows the changes would reject
+workers: 1                # Statements on independent tables executed concurrently
+verify: true              # Re-compare after executing; exit with code 3 if the target still differs
+
+# Archive dropped tables and columns instead of dropping them (remove later with purge-archived)
+safe_drop:
+  enabled: false
+  columns: rename         # rename: rename to _archived_<timestamp>_<name>; keep: leave in place
+
+# Guardrails evaluated against every migration plan (override with --override-policy "<reason>")
+policy:
+  file: ""                # YAML policy file, e.g. rules of type no_drop_table, no_drop_column,
+                          # require_primary_key, no_modify_column, foreign_key_name
+  target_tags: []         # Tags of the target database, e.g. [prod]
+  audit_log: ""           # Append every policy override to this file as a JSON line
+
+# Apply only part of the plan; statements the chosen ones depend on are added automatically
+selection:
+  only: []                # TABLE[:TYPE] glob selectors, e.g. [orders, "users:ADD_*"]
+  skip: []                # Selectors of statements to leave out, e.g. ["*:DROP_COLUMN"]
+  review: false           # Pick the changes to apply interactively
+
+# Execution journal on the target; re-running an interrupted plan resumes after the applied statements
+journal:
+  enabled: true
+  table: schema_sync_journal
+
+# Migration history on the target, listed by the history command
+history:
+  enabled: false
+  table: schema_sync_history
+
+# Validated backup of the target schema before every execution, restorable with the rollback command
+backup:
+  enabled: false
+  storage:
+    provider: local       # local, s3, azure, gcs
+    local:
+      base_path: ./backups
+
+# Advisory lock that keeps concurrent runs off the target (break a stale one with --force-unlock "<reason>")
+lock:
+  enabled: true
+  wait_timeout: 30s       # How long to wait for another run to release the lock
+  audit_log: ""           # Append every forced unlock to this file as a JSON line
+                          # (default ~/.mysql-schema-sync-lock-audit.jsonl)
+
+# Checks for long transactions and metadata locks before each statement
+metadata_locks:
+  enabled: false
+  lock_wait_timeout: 0s   # Session lock_wait_timeout of every statement, e.g. 30s (0 = server setting)
+  max_transaction_age: 0s # Ignore blocking transactions younger than this (0 = report every one)
+  on_blocked: abort       # abort: stop and report the blocking sessions; wait: wait for them
+  wait_timeout: 5m        # How long on_blocked: wait waits
+
+# Replicas of the target watched between statements; execution pauses while they lag and
+# stops if a replication SQL thread fails
+replication:
+  replicas: []            # e.g. [{host: replica1, port: 3306}]; credentials default to the target's
+  discover: false         # Also watch the replicas listed by SHOW REPLICAS (needs report_host)
+  max_lag: 10s            # Pause while any replica lags more than this
+  check_interval: 1s      # How often lag is checked while paused
+  max_wait: 0s            # Stop if replicas do not catch up within this time (0 = wait indefinitely)
+
+# Target load checked between statements, modeled on gh-ost --max-load/--critical-load
+load:
+  max_load: ""            # Pause while reached, e.g. Threads_running=25,Innodb_row_lock_current_waits=10
+  critical_load: ""       # Abort when reached, e.g. Threads_running=100
+  query: ""               # Pause while this query returns a value greater than zero
+  pause_file: ""          # Pause while this file exists; remove it to resume
+  check_interval: 1s      # How often load is checked while paused
+
+# Write the plan as versioned migration files instead of applying it
+emit_migrations:
+  dir: ""                 # Output directory (empty = apply the plan directly)
+  format: golang-migrate  # golang-migrate, flyway, liquibase, atlas or dbmate
+
+# Online schema change tool for large tables
+online_schema_change:
+  tool: ""                # gh-ost, pt-osc or empty to always use direct DDL
+  threshold_mb: 1024      # Tables at or above this size (data + indexes) use the tool
+  binary_path: ""         # Optional path to the tool executable
+  extra_args: []          # Additional arguments passed to the tool
+  print_only: false       # Print commands instead of executing them
+  allow_on_master: false  # Let gh-ost run against the target primary instead of a replica
+
 # Visual enhancement settings
 display:
   # Color and theming
@@ -700,4 +1159,11 @@ func init() {
 	// Add subcommands
 	rootCmd.AddCommand(createVersionCommand())
 	rootCmd.AddCommand(createConfigCommand())
+
+	// plan, apply, rollout, purge-archived and lint accept the same connection, planning and display flags as the root command
+	planCmd.Flags().AddFlagSet(rootCmd.Flags())
+	applyCmd.Flags().AddFlagSet(rootCmd.Flags())
+	rolloutCmd.Flags().AddFlagSet(rootCmd.Flags())
+	purgeArchivedCmd.Flags().AddFlagSet(rootCmd.Flags())
+	lintCmd.Flags().AddFlagSet(rootCmd.Flags())
 }
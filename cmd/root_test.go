@@ -0,0 +1,83 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// parseCommand resolves and parses a command line the way Execute does before running the
+// command, and restores the flags it set when the test ends
+func parseCommand(t *testing.T, args ...string) (*cobra.Command, error) {
+	t.Helper()
+
+	cmd, rest, err := rootCmd.Find(args)
+	if err != nil {
+		return nil, err
+	}
+	t.Cleanup(func() {
+		cmd.Flags().Visit(func(flag *pflag.Flag) {
+			flag.Value.Set(flag.DefValue)
+			flag.Changed = false
+		})
+	})
+
+	if err := cmd.ParseFlags(rest); err != nil {
+		return cmd, err
+	}
+	if err := cmd.ValidateArgs(cmd.Flags().Args()); err != nil {
+		return cmd, err
+	}
+	if err := cmd.ValidateRequiredFlags(); err != nil {
+		return cmd, err
+	}
+	return cmd, cmd.ValidateFlagGroups()
+}
+
+func TestPlanCmd_RequiresSourceFlags(t *testing.T) {
+	cmd, err := parseCommand(t, "plan", "--target-host=db", "--target-user=ci", "--target-db=app")
+	if err != nil {
+		t.Fatalf("Failed to parse plan flags: %v", err)
+	}
+	if err := validateFlags(cmd); err == nil || !strings.Contains(err.Error(), "--source-host") {
+		t.Errorf("Expected plan to require the source flags, got %v", err)
+	}
+
+	if _, err := parseCommand(t, "plan", "--source-host=db", "--target-host=db", "--target-user=ci", "--target-db=app"); err == nil {
+		t.Error("Expected an incomplete source group to be rejected")
+	}
+}
+
+func TestRootCmd_MetadataLockChecksAreOptIn(t *testing.T) {
+	cmd, err := parseCommand(t, "plan", "--source-host=db", "--source-user=ci", "--source-db=app",
+		"--target-host=db", "--target-user=ci", "--target-db=app_staging")
+	if err != nil {
+		t.Fatalf("Failed to parse plan flags: %v", err)
+	}
+
+	config, err := buildConfig(cmd)
+	if err != nil {
+		t.Fatalf("buildConfig() error = %v", err)
+	}
+	if config.MetadataLocks.Enabled || config.MetadataLocks.LockWaitTimeout != 0 {
+		t.Errorf("Expected metadata lock checks and lock_wait_timeout to be opt-in, got %+v", config.MetadataLocks)
+	}
+}
+
+func TestRootCmd_OSCOnMasterIsOptIn(t *testing.T) {
+	cmd, err := parseCommand(t, "plan", "--source-host=db", "--source-user=ci", "--source-db=app",
+		"--target-host=db", "--target-user=ci", "--target-db=app_staging")
+	if err != nil {
+		t.Fatalf("Failed to parse plan flags: %v", err)
+	}
+
+	config, err := buildConfig(cmd)
+	if err != nil {
+		t.Fatalf("buildConfig() error = %v", err)
+	}
+	if config.OnlineSchemaChange.AllowOnMaster {
+		t.Error("Expected gh-ost to need --osc-allow-on-master to run against the primary")
+	}
+}
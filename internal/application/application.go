@@ -4,17 +4,31 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
 	"os"
 	"os/signal"
 	"syscall"
 	"time"
 
+	"mysql-schema-sync/internal/config"
 	"mysql-schema-sync/internal/database"
 	"mysql-schema-sync/internal/display"
 	appErrors "mysql-schema-sync/internal/errors"
 	"mysql-schema-sync/internal/execution"
+	"mysql-schema-sync/internal/history"
+	"mysql-schema-sync/internal/journal"
+	"mysql-schema-sync/internal/lint"
+	"mysql-schema-sync/internal/lock"
 	"mysql-schema-sync/internal/logging"
+	"mysql-schema-sync/internal/mdl"
+	"mysql-schema-sync/internal/migration"
+	"mysql-schema-sync/internal/migrationfiles"
+	"mysql-schema-sync/internal/osc"
+	"mysql-schema-sync/internal/planfile"
+	"mysql-schema-sync/internal/policy"
+	"mysql-schema-sync/internal/rollout"
 	"mysql-schema-sync/internal/schema"
+	"mysql-schema-sync/internal/throttle"
 )
 
 // Application represents the main application
@@ -23,6 +37,7 @@ type Application struct {
 	logger          *logging.Logger
 	shutdownHandler *appErrors.GracefulShutdownHandler
 	displayService  display.DisplayService
+	showRollback    bool
 }
 
 // Config holds the application configuration
@@ -36,6 +51,51 @@ type Config struct {
 	LogFile     string                  `mapstructure:"log_file" yaml:"log_file"`
 	Timeout     time.Duration           `mapstructure:"timeout" yaml:"timeout"`
 	Display     DisplayConfig           `mapstructure:"display" yaml:"display"`
+
+	// MergeAlterations coalesces all operations on a table into a single ALTER TABLE
+	MergeAlterations bool `mapstructure:"merge_alterations" yaml:"merge_alterations"`
+	// OnlineDDL appends explicit ALGORITHM/LOCK clauses predicted for the target server
+	OnlineDDL bool `mapstructure:"online_ddl" yaml:"online_ddl"`
+	// OnlineSchemaChange delegates changes to large tables to gh-ost or pt-online-schema-change
+	OnlineSchemaChange osc.Config `mapstructure:"online_schema_change" yaml:"online_schema_change"`
+	// ShowRollback displays the plan that reverts the migration
+	ShowRollback bool `mapstructure:"show_rollback" yaml:"show_rollback"`
+	// RollbackFile is where the SQL script reverting the migration is written
+	RollbackFile string `mapstructure:"rollback_file" yaml:"rollback_file"`
+	// EmitMigrations writes the plan as migration framework files instead of applying it
+	EmitMigrations migrationfiles.Config `mapstructure:"emit_migrations" yaml:"emit_migrations"`
+	// PlanOut saves the plan to this file for a later apply instead of applying it
+	PlanOut string `mapstructure:"-" yaml:"-"`
+	// SkipDataChecks disables the pre-flight queries that look for rows the changes would reject
+	SkipDataChecks bool `mapstructure:"skip_data_checks" yaml:"skip_data_checks"`
+	// Workers is the number of statements on independent tables executed concurrently
+	Workers int `mapstructure:"workers" yaml:"workers"`
+	// Verify compares source and target again after executing and fails if the target still differs
+	Verify bool `mapstructure:"verify" yaml:"verify"`
+	// SafeDrop archives dropped tables and columns by renaming them instead of dropping them
+	SafeDrop migration.SafeDropConfig `mapstructure:"safe_drop" yaml:"safe_drop"`
+	// Policy enforces the rules of a policy file on every migration plan
+	Policy policy.Config `mapstructure:"policy" yaml:"policy"`
+	// Selection applies only the chosen changes of the plan
+	Selection migration.SelectionConfig `mapstructure:"selection" yaml:"selection"`
+	// Journal records executed statements on the target so interrupted migrations can resume
+	Journal journal.Config `mapstructure:"journal" yaml:"journal"`
+	// History records every executed migration in a table on the target
+	History history.Config `mapstructure:"history" yaml:"history"`
+	// Backup stores a validated backup of the target schema before the first statement runs
+	Backup config.BackupConfig `mapstructure:"backup" yaml:"backup"`
+	// Lock keeps concurrent runs off the target while planning and executing
+	Lock lock.Config `mapstructure:"lock" yaml:"lock"`
+	// MetadataLocks checks for sessions blocking each statement's table and bounds lock waits
+	MetadataLocks mdl.Config `mapstructure:"metadata_locks" yaml:"metadata_locks"`
+	// Replication pauses between statements while replicas of the target lag behind
+	Replication throttle.ReplicationConfig `mapstructure:"replication" yaml:"replication"`
+	// Load pauses between statements while the target is busy or an operator paused the sync
+	Load throttle.LoadConfig `mapstructure:"load" yaml:"load"`
+	// ToolVersion is the version of mysql-schema-sync recorded in the migration history
+	ToolVersion string `mapstructure:"-" yaml:"-"`
+	// TargetOnly skips the source database settings for commands that only use the target
+	TargetOnly bool `mapstructure:"-" yaml:"-"`
 }
 
 // DisplayConfig is an alias to the display package's DisplayConfig
@@ -69,6 +129,9 @@ func NewApplication(config Config) (*Application, error) {
 	if err := displayConfig.Validate(); err != nil {
 		return nil, fmt.Errorf("display configuration validation failed: %w", err)
 	}
+	if config.Selection.Review && !displayConfig.IsInteractiveEnabled() {
+		return nil, fmt.Errorf("--review requires interactive mode")
+	}
 
 	displayService := display.NewDisplayService(displayConfig)
 
@@ -80,6 +143,29 @@ func NewApplication(config Config) (*Application, error) {
 		AutoApprove: config.AutoApprove,
 		Timeout:     config.Timeout,
 		LogLevel:    logLevel,
+
+		MergeAlterations: config.MergeAlterations,
+		OnlineDDL:        config.OnlineDDL,
+
+		OnlineSchemaChange: config.OnlineSchemaChange,
+		RollbackFile:       config.RollbackFile,
+		EmitMigrations:     config.EmitMigrations,
+		PlanOut:            config.PlanOut,
+		SkipDataChecks:     config.SkipDataChecks,
+		Workers:            config.Workers,
+		Verify:             config.Verify,
+		SafeDrop:           config.SafeDrop,
+		Policy:             config.Policy,
+		Selection:          config.Selection,
+		Journal:            config.Journal,
+		History:            config.History,
+		Backup:             config.Backup,
+		Lock:               config.Lock,
+		MetadataLocks:      config.MetadataLocks,
+		Replication:        config.Replication,
+		Load:               config.Load,
+		ToolVersion:        config.ToolVersion,
+		TargetOnly:         config.TargetOnly,
 	}
 
 	// Create executor
@@ -101,6 +187,7 @@ func NewApplication(config Config) (*Application, error) {
 		logger:          executor.GetLogger(),
 		shutdownHandler: executor.GetShutdownHandler(),
 		displayService:  displayService,
+		showRollback:    config.ShowRollback,
 	}
 
 	return app, nil
@@ -120,6 +207,7 @@ func (app *Application) Run() error {
 	// Execute the schema synchronization
 	result, err := app.executor.Execute(ctx)
 	if err != nil {
+		app.displayResidualDiff(result)
 		app.handleExecutionError(err)
 		return err
 	}
@@ -132,6 +220,155 @@ func (app *Application) Run() error {
 	return nil
 }
 
+// Apply executes a saved plan file after verifying that the target schema has not changed
+func (app *Application) Apply(planPath string) error {
+	app.logger.Info("MySQL Schema Sync applying saved plan")
+	app.displayService.Info(fmt.Sprintf("Applying saved plan %s", planPath))
+
+	pf, err := planfile.Load(planPath)
+	if err != nil {
+		appErr := appErrors.NewAppError(appErrors.ErrorTypeValidation, "failed to load saved plan", err)
+		app.handleExecutionError(appErr)
+		return appErr
+	}
+
+	app.setupSignalHandling()
+
+	result, err := app.executor.Apply(context.Background(), pf)
+	if err != nil {
+		app.handleExecutionError(err)
+		return err
+	}
+
+	app.displayResults(result)
+
+	app.logger.Info("MySQL Schema Sync completed")
+	app.displayService.Success("MySQL Schema Sync completed")
+	return nil
+}
+
+// Rollout applies a saved plan file to the groups of a rollout and writes the consolidated report
+// to out, also when the rollout halted
+func (app *Application) Rollout(planPath string, spec *rollout.Spec, format rollout.Format, out io.Writer) error {
+	app.logger.Info("MySQL Schema Sync rolling out saved plan")
+	app.displayService.Info(fmt.Sprintf("Rolling out saved plan %s to %d targets in %d groups", planPath, spec.TargetCount(), len(spec.Groups)))
+
+	pf, err := planfile.Load(planPath)
+	if err != nil {
+		appErr := appErrors.NewAppError(appErrors.ErrorTypeValidation, "failed to load saved plan", err)
+		app.handleExecutionError(appErr)
+		return appErr
+	}
+
+	app.setupSignalHandling()
+
+	report, err := app.executor.Rollout(context.Background(), pf, spec)
+	if report != nil {
+		if writeErr := rollout.WriteReport(out, report, format); writeErr != nil {
+			return fmt.Errorf("failed to write rollout report: %w", writeErr)
+		}
+	}
+	if err != nil {
+		app.handleExecutionError(err)
+		return err
+	}
+
+	app.logger.Info("MySQL Schema Sync completed")
+	app.displayService.Success(fmt.Sprintf("Rollout completed: %s", report.Summary()))
+	return nil
+}
+
+// PurgeArchived permanently drops the tables and columns archived by safe-drop mode more than
+// olderThan ago
+func (app *Application) PurgeArchived(olderThan time.Duration) error {
+	app.logger.Info("MySQL Schema Sync purging archived objects")
+	app.displayService.Info(fmt.Sprintf("Purging objects archived more than %s ago", olderThan))
+
+	app.setupSignalHandling()
+
+	result, err := app.executor.PurgeArchived(context.Background(), olderThan)
+	if err != nil {
+		app.handleExecutionError(err)
+		return err
+	}
+
+	app.displayResults(result)
+
+	if len(result.PurgedObjects) == 0 {
+		app.displayService.Info("No archived objects are old enough to purge")
+	}
+
+	app.logger.Info("MySQL Schema Sync completed")
+	app.displayService.Success("MySQL Schema Sync completed")
+	return nil
+}
+
+// Lint reports the schema smells of the target database to out in the given format. It fails
+// if any finding is at or above failOn, unless failOn is empty.
+func (app *Application) Lint(format lint.Format, failOn schema.WarningSeverity, out io.Writer) error {
+	app.logger.Info("MySQL Schema Sync linting target schema")
+
+	report, err := app.executor.Lint(context.Background())
+	if err != nil {
+		app.handleExecutionError(err)
+		return err
+	}
+
+	switch format {
+	case lint.FormatJSON:
+		err = report.WriteJSON(out)
+	case lint.FormatSARIF:
+		err = report.WriteSARIF(out)
+	default:
+		validator := schema.NewSchemaValidator(false)
+		_, err = fmt.Fprintf(out, "Schema lint of %s: %s\n\n%s\n", report.Database,
+			validator.GetValidationSummary(report.ValidationResult()),
+			validator.FormatValidationResult(report.ValidationResult(), false))
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write lint report: %w", err)
+	}
+
+	if failOn != "" {
+		if count := report.CountAtLeast(failOn); count > 0 {
+			return appErrors.NewAppError(appErrors.ErrorTypeValidation,
+				fmt.Sprintf("%d lint findings at or above %s severity", count, failOn), nil)
+		}
+	}
+
+	return nil
+}
+
+// History writes the recorded runs of the target, and of any additional targets, to out
+func (app *Application) History(filter history.Filter, targets []database.DatabaseConfig, format history.Format, out io.Writer) error {
+	app.logger.Info("MySQL Schema Sync reading migration history")
+
+	runs, err := app.executor.History(context.Background(), filter, targets)
+	if err != nil {
+		app.handleExecutionError(err)
+		return err
+	}
+
+	if err := history.WriteRuns(out, runs, format); err != nil {
+		return fmt.Errorf("failed to write migration history: %w", err)
+	}
+	return nil
+}
+
+// ShowHistory writes a recorded run of the target with its full plan to out
+func (app *Application) ShowHistory(id int64, format history.Format, out io.Writer) error {
+	run, err := app.executor.HistoryRun(context.Background(), id)
+	if err != nil {
+		app.handleExecutionError(err)
+		return err
+	}
+
+	if err := history.WriteRun(out, run, format); err != nil {
+		return fmt.Errorf("failed to write migration history: %w", err)
+	}
+	return nil
+}
+
 // setupSignalHandling sets up graceful shutdown on interrupt signals
 func (app *Application) setupSignalHandling() {
 	// Create a channel to receive OS signals
@@ -217,6 +454,13 @@ func (app *Application) provideTroubleshootingHints(appErr *appErrors.AppError)
 			"Check for syntax errors or unsupported features",
 			"Verify database permissions for schema modifications",
 		}
+
+	case appErrors.ErrorTypeVerification:
+		hints = []string{
+			"The migration ran, but the target server did not take every change as planned",
+			"Check whether the server normalizes the listed types or ignores the listed constraints",
+			"Adjust the source schema or the comparison settings so both sides agree",
+		}
 	}
 
 	if len(hints) > 0 {
@@ -250,6 +494,52 @@ func (app *Application) displayResults(result *execution.ExecutionResult) {
 		app.displayService.PrintSection("Warnings", result.Warnings)
 	}
 
+	if result.MigrationPlan != nil {
+		if estimate := result.MigrationPlan.Estimate(); estimate.SizeKnown {
+			app.displayService.PrintSection(fmt.Sprintf("Execution Estimate (%s)", estimate.String()),
+				formatStatementEstimates(result.MigrationPlan.Statements))
+		}
+	}
+
+	if result.PolicyOverride != nil {
+		app.displayService.Warning(fmt.Sprintf("Policy overridden by %s: %s", result.PolicyOverride.User, result.PolicyOverride.Reason))
+		app.displayService.PrintSection(fmt.Sprintf("Overridden Policy Violations (%d)", len(result.PolicyOverride.Violations)),
+			result.PolicyOverride.Violations)
+	}
+
+	if len(result.ExternalCommands) > 0 {
+		app.displayService.PrintSection(fmt.Sprintf("Migration Commands (%d, not executed)", len(result.ExternalCommands)), result.ExternalCommands)
+	}
+
+	if result.BackupID != "" {
+		app.displayService.Info(fmt.Sprintf("Pre-migration backup: %s", result.BackupID))
+	}
+
+	if result.PlanFile != "" {
+		app.displayService.Info(fmt.Sprintf("Plan saved to %s (apply it with: mysql-schema-sync apply %s)", result.PlanFile, result.PlanFile))
+	}
+
+	if len(result.EmittedFiles) > 0 {
+		app.displayService.PrintSection(fmt.Sprintf("Migration Files (%d, not applied)", len(result.EmittedFiles)), result.EmittedFiles)
+	}
+
+	if result.MigrationPlan != nil && len(result.MigrationPlan.Archived) > 0 {
+		app.displayService.PrintSection(fmt.Sprintf("Archived Instead of Dropped (%d)", len(result.MigrationPlan.Archived)),
+			formatArchivedObjects(result.MigrationPlan.Archived))
+	}
+
+	if len(result.PurgedObjects) > 0 {
+		title := fmt.Sprintf("Purged Archived Objects (%d)", len(result.PurgedObjects))
+		if len(result.ExecutedStatements) == 0 {
+			title = fmt.Sprintf("Archived Objects to Purge (%d, not dropped)", len(result.PurgedObjects))
+		}
+		app.displayService.PrintSection(title, formatArchivedObjects(result.PurgedObjects))
+	}
+
+	if app.showRollback && result.MigrationPlan != nil {
+		app.displayRollback(result.MigrationPlan.Rollback)
+	}
+
 	if len(result.ExecutedStatements) > 0 {
 		app.displayService.PrintSection(fmt.Sprintf("Executed Statements (%d)", len(result.ExecutedStatements)), nil)
 		app.displayService.PrintSQL(result.ExecutedStatements)
@@ -260,6 +550,73 @@ func (app *Application) displayResults(result *execution.ExecutionResult) {
 	}
 }
 
+// displayResidualDiff lists the differences verification found on the target after the migration
+func (app *Application) displayResidualDiff(result *execution.ExecutionResult) {
+	if result == nil || result.ResidualDiff == nil {
+		return
+	}
+
+	differences := migration.DescribeDiff(result.ResidualDiff)
+	app.displayService.PrintSection(fmt.Sprintf("Residual Differences After Migration (%d)", len(differences)), differences)
+	if len(result.ExecutedStatements) > 0 {
+		app.displayService.PrintSection(fmt.Sprintf("Executed Statements (%d)", len(result.ExecutedStatements)), nil)
+		app.displayService.PrintSQL(result.ExecutedStatements)
+	}
+}
+
+// displayRollback displays the plan that reverts the migration, flagging data that cannot be restored
+func (app *Application) displayRollback(rollback *migration.MigrationPlan) {
+	if rollback == nil || len(rollback.Statements) == 0 {
+		return
+	}
+
+	steps := make([]string, 0, len(rollback.Statements))
+	for i, stmt := range rollback.Statements {
+		step := fmt.Sprintf("%d. %s", i+1, stmt.Description)
+		if stmt.DataLoss {
+			step += " (data not restored)"
+		}
+		steps = append(steps, step)
+	}
+
+	app.displayService.PrintSection(fmt.Sprintf("Rollback Plan (%d)", len(rollback.Statements)), steps)
+	app.displayService.PrintSQL(rollback.ExecutableSQL())
+
+	if len(rollback.Warnings) > 0 {
+		app.displayService.PrintSection("Rollback Warnings", rollback.Warnings)
+	}
+}
+
+// formatStatementEstimates formats the estimated impact of each statement for display
+func formatStatementEstimates(statements []migration.MigrationStatement) []string {
+	lines := make([]string, 0, len(statements))
+	for _, stmt := range statements {
+		if stmt.Estimate == nil {
+			continue
+		}
+		lines = append(lines, fmt.Sprintf("%s: %s", stmt.Description, stmt.Estimate.String()))
+	}
+	return lines
+}
+
+// formatArchivedObjects describes archived tables and columns for display
+func formatArchivedObjects(archived []migration.ArchivedObject) []string {
+	lines := make([]string, 0, len(archived))
+	for _, object := range archived {
+		switch {
+		case object.IsTable():
+			lines = append(lines, fmt.Sprintf("table %s -> %s (archived %s)",
+				object.TableName, object.ArchivedName, object.ArchivedAt.Format(time.RFC3339)))
+		case object.ArchivedName == "":
+			lines = append(lines, fmt.Sprintf("column %s.%s (left in place)", object.TableName, object.ColumnName))
+		default:
+			lines = append(lines, fmt.Sprintf("column %s.%s -> %s (archived %s)",
+				object.TableName, object.ColumnName, object.ArchivedName, object.ArchivedAt.Format(time.RFC3339)))
+		}
+	}
+	return lines
+}
+
 // displaySchemaDiff displays schema differences
 func (app *Application) displaySchemaDiff(diff *schema.SchemaDiff) {
 	if diff == nil {
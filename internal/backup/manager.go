@@ -2,6 +2,7 @@ package backup
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"os"
 	"os/user"
@@ -125,16 +126,76 @@ func (bm *backupManager) SetDisplayService(displayService display.DisplayService
 	}
 }
 
-// CreateBackup creates a new backup
-// TODO: Implement when dependencies are resolved
+// CreateBackup creates a new backup of the schema snapshot given in the configuration
+// TODO: Extract the schema from the database when no snapshot is given, once dependencies are resolved
 func (bm *backupManager) CreateBackup(ctx context.Context, config BackupConfig) (*Backup, error) {
-	return nil, fmt.Errorf("not implemented - dependencies need to be resolved")
+	return bm.createBackup(ctx, config, nil)
+}
+
+// createBackup stores a backup of the configured schema snapshot. prepare completes the backup
+// before its checksum is calculated and it is stored.
+func (bm *backupManager) createBackup(ctx context.Context, config BackupConfig, prepare func(*Backup)) (*Backup, error) {
+	if config.SchemaSnapshot == nil {
+		return nil, fmt.Errorf("not implemented - dependencies need to be resolved")
+	}
+	if config.DatabaseConfig.Database == "" {
+		return nil, NewValidationError("database name is required", nil)
+	}
+
+	snapshot, err := json.Marshal(config.SchemaSnapshot)
+	if err != nil {
+		return nil, NewValidationError("failed to serialize schema snapshot", err)
+	}
+
+	// Keep the snapshot in the form it is read back in, so the checksum holds after retrieval
+	var schemaSnapshot interface{}
+	if err := json.Unmarshal(snapshot, &schemaSnapshot); err != nil {
+		return nil, NewValidationError("failed to serialize schema snapshot", err)
+	}
+
+	id := GenerateBackupID()
+	backup := &Backup{
+		ID: id,
+		Metadata: &BackupMetadata{
+			ID:              id,
+			DatabaseName:    config.DatabaseConfig.Database,
+			CreatedAt:       time.Now(),
+			CreatedBy:       bm.getCurrentUser(),
+			Description:     config.Description,
+			Tags:            config.Tags,
+			Size:            int64(len(snapshot)),
+			CompressionType: config.CompressionType,
+			Status:          BackupStatusCompleted,
+		},
+		SchemaSnapshot: schemaSnapshot,
+	}
+	if prepare != nil {
+		prepare(backup)
+	}
+
+	bm.logInfo(fmt.Sprintf("Storing backup %s of database %s", backup.ID, config.DatabaseConfig.Database))
+	if err := bm.storageProvider.Store(ctx, backup); err != nil {
+		return nil, NewStorageError("failed to store backup", err)
+	}
 
+	return backup, nil
+}
+
+// reversiblePlan is implemented by migration plans that carry their own rollback
+type reversiblePlan interface {
+	Hash() string
+	RollbackSQL() []string
 }
 
 // CreatePreMigrationBackup creates a backup before migration with migration context
-// TODO: Implement when migration dependency is resolved
 func (bm *backupManager) CreatePreMigrationBackup(ctx context.Context, config BackupConfig, migrationPlan interface{}) (*Backup, error) {
+	planHash := "not-implemented"
+	var rollbackSQL []string
+	if plan, ok := migrationPlan.(reversiblePlan); ok {
+		planHash = plan.Hash()
+		rollbackSQL = plan.RollbackSQL()
+	}
+
 	// Add migration context to the backup config
 	if config.Description == "" {
 		config.Description = "Automatic pre-migration backup"
@@ -146,26 +207,29 @@ func (bm *backupManager) CreatePreMigrationBackup(ctx context.Context, config Ba
 		config.Tags = make(map[string]string)
 	}
 	config.Tags["type"] = "pre-migration"
-	config.Tags["migration_plan_hash"] = "not-implemented" // TODO: Implement when migration dependency is resolved
+	config.Tags["migration_plan_hash"] = planHash
 
-	// Create the backup
-	backup, err := bm.CreateBackup(ctx, config)
-	if err != nil {
-		return nil, err
+	source := config.Source
+	if source == "" {
+		source = config.DatabaseConfig.Database
+	}
+	toolVersion := config.ToolVersion
+	if toolVersion == "" {
+		toolVersion = "unknown"
 	}
 
-	// Add migration context to metadata
-	if backup.Metadata != nil {
+	// The rollback and migration context are stored with the backup so reverting does not require
+	// re-planning
+	return bm.createBackup(ctx, config, func(backup *Backup) {
+		backup.RollbackSQL = rollbackSQL
 		backup.Metadata.MigrationContext = &MigrationContext{
-			PlanHash:       "not-implemented", // TODO: Implement when migration dependency is resolved
-			SourceSchema:   config.DatabaseConfig.Database,
+			PlanHash:       planHash,
+			SourceSchema:   source,
 			PreMigrationID: backup.ID,
 			MigrationTime:  time.Now(),
-			ToolVersion:    "1.0.0", // TODO: Get from build info
+			ToolVersion:    toolVersion,
 		}
-	}
-
-	return backup, nil
+	})
 }
 
 // CreateManualBackup creates a manual backup with custom description and tags
@@ -16,6 +16,8 @@ type Backup struct {
 	Procedures      []ProcedureDefinition `json:"procedures"`
 	Functions       []FunctionDefinition  `json:"functions"`
 	Checksum        string                `json:"checksum"`
+	// RollbackSQL reverts the migration this backup was taken for
+	RollbackSQL []string `json:"rollback_sql,omitempty"`
 }
 
 // BackupMetadata contains metadata about a backup
@@ -53,6 +55,12 @@ type BackupConfig struct {
 	EncryptionKey   []byte
 	Description     string
 	Tags            map[string]string
+	// SchemaSnapshot is a schema the caller already extracted; it is backed up as is
+	SchemaSnapshot interface{}
+	// Source identifies the schema a pre-migration backup's migration comes from
+	Source string
+	// ToolVersion is the version of mysql-schema-sync taking a pre-migration backup
+	ToolVersion string
 }
 
 // StorageConfig defines storage provider configuration
@@ -112,6 +112,9 @@ func (cs *confirmationService) DisplayChangeSummary(diff *schema.SchemaDiff, pla
 	fmt.Println(strings.Repeat("-", 30))
 	fmt.Printf("Total statements to execute: %d\n", len(plan.Statements))
 	fmt.Printf("Destructive operations: %d\n", destructiveCount)
+	if blocking := plan.GetBlockingStatements(); len(blocking) > 0 {
+		fmt.Printf("Statements blocking writes: %s\n", cs.formatter.Colorize(fmt.Sprintf("%d", len(blocking)), "red"))
+	}
 	fmt.Printf("Estimated execution time: %s\n", cs.estimateExecutionTime(plan))
 	fmt.Println()
 
@@ -174,9 +177,13 @@ func (cs *confirmationService) countDestructiveOperations(plan *migration.Migrat
 	return count
 }
 
-// estimateExecutionTime provides a rough estimate of execution time
+// estimateExecutionTime estimates the execution time from the target table sizes, falling back
+// to a heuristic on the statement count when no sizes are known
 func (cs *confirmationService) estimateExecutionTime(plan *migration.MigrationPlan) string {
-	// Simple heuristic based on statement count and types
+	if estimate := plan.Estimate(); estimate.SizeKnown {
+		return estimate.String()
+	}
+
 	statementCount := len(plan.Statements)
 
 	if statementCount == 0 {
@@ -249,6 +256,19 @@ func (cs *confirmationService) ConfirmWithDetails(diff *schema.SchemaDiff, plan
 	}
 }
 
+// formatAlgorithm describes the expected online DDL behaviour of a statement
+func (cs *confirmationService) formatAlgorithm(stmt migration.MigrationStatement) string {
+	text := string(stmt.Algorithm)
+	if stmt.Lock != "" {
+		text += fmt.Sprintf(", LOCK=%s", stmt.Lock)
+	}
+
+	if stmt.BlocksWrites() {
+		return cs.formatter.Colorize(text+" (blocks writes)", "red")
+	}
+	return cs.formatter.Colorize(text+" (online)", "green")
+}
+
 // displaySQLDetails shows the detailed SQL statements that will be executed
 func (cs *confirmationService) displaySQLDetails(plan *migration.MigrationPlan) {
 	fmt.Println("\n" + cs.formatter.Colorize("SQL Statements to be executed:", "bold"))
@@ -266,6 +286,30 @@ func (cs *confirmationService) displaySQLDetails(plan *migration.MigrationPlan)
 			fmt.Printf("   %s\n", cs.formatter.Colorize("⚠ DESTRUCTIVE OPERATION", "red"))
 		}
 
+		// Show the expected online DDL algorithm and whether writes are blocked
+		if stmt.Algorithm != "" {
+			fmt.Printf("   Algorithm: %s\n", cs.formatAlgorithm(stmt))
+		}
+
+		// Show the expected rebuild, data volume and duration
+		if stmt.Estimate != nil {
+			fmt.Printf("   Estimate: %s\n", stmt.Estimate.String())
+		}
+
+		// Flag statements that run without foreign key checks to break a dependency cycle
+		if stmt.DisableForeignKeyChecks {
+			fmt.Printf("   %s\n", cs.formatter.Colorize("Runs with FOREIGN_KEY_CHECKS=0 (circular foreign key dependency)", "yellow"))
+		}
+
+		// List the operations folded into a merged ALTER TABLE
+		for _, op := range stmt.Operations {
+			marker := "-"
+			if op.IsDestructive {
+				marker = cs.formatter.Colorize("⚠", "red")
+			}
+			fmt.Printf("   %s %s\n", marker, op.Description)
+		}
+
 		// Display the SQL
 		fmt.Printf("   SQL: %s\n", cs.formatter.Colorize(stmt.SQL, "blue"))
 	}
@@ -156,6 +156,31 @@ func TestEstimateExecutionTime(t *testing.T) {
 	}
 }
 
+func TestEstimateExecutionTime_TableSizes(t *testing.T) {
+	service := NewConfirmationService(false)
+	cs := service.(*confirmationService)
+
+	plan := &migration.MigrationPlan{
+		Statements: []migration.MigrationStatement{
+			{
+				Type:      migration.StatementTypeAddColumn,
+				TableName: "orders",
+				Estimate: &migration.StatementEstimate{
+					Rebuild:     true,
+					SizeKnown:   true,
+					BytesToCopy: 3 * 1024 * 1024 * 1024,
+					Seconds:     61.2,
+				},
+			},
+		},
+	}
+
+	expected := "~1m0s (1 table rebuilds, 3.0 GB to copy)"
+	if result := cs.estimateExecutionTime(plan); result != expected {
+		t.Errorf("estimateExecutionTime() = %s, expected %s", result, expected)
+	}
+}
+
 func TestParseConfirmationInput(t *testing.T) {
 	service := NewConfirmationService(false)
 	cs := service.(*confirmationService)
@@ -296,6 +321,41 @@ func TestDisplaySQLDetails(t *testing.T) {
 	cs.displaySQLDetails(plan)
 }
 
+func TestFormatAlgorithm(t *testing.T) {
+	service := NewConfirmationService(false)
+	cs := service.(*confirmationService)
+
+	tests := []struct {
+		name     string
+		stmt     migration.MigrationStatement
+		expected string
+	}{
+		{
+			name:     "instant",
+			stmt:     migration.MigrationStatement{Algorithm: migration.AlgorithmInstant},
+			expected: "INSTANT (online)",
+		},
+		{
+			name:     "inplace without lock",
+			stmt:     migration.MigrationStatement{Algorithm: migration.AlgorithmInplace, Lock: migration.LockNone},
+			expected: "INPLACE, LOCK=NONE (online)",
+		},
+		{
+			name:     "copy",
+			stmt:     migration.MigrationStatement{Algorithm: migration.AlgorithmCopy, Lock: migration.LockShared},
+			expected: "COPY, LOCK=SHARED (blocks writes)",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := cs.formatAlgorithm(tt.stmt); got != tt.expected {
+				t.Errorf("formatAlgorithm() = %q, want %q", got, tt.expected)
+			}
+		})
+	}
+}
+
 // Helper function to create a test schema diff
 func createTestSchemaDiff() *schema.SchemaDiff {
 	return &schema.SchemaDiff{
@@ -19,6 +19,16 @@ func NewConfigConverter() *ConfigConverter {
 	return &ConfigConverter{}
 }
 
+// BackupSystemConfig returns the backup system configuration for the backup settings, with defaults
+// for the settings they leave out. Pre-migration backups and the backup and rollback commands all
+// build their configuration with it, so that they use the same storage.
+func BackupSystemConfig(backupConfig config.BackupConfig) (*backup.BackupSystemConfig, error) {
+	// Defaults apply whether or not pre-migration backups are enabled
+	backupConfig.Enabled = true
+	backupConfig.SetDefaults()
+	return NewConfigConverter().ToBackupSystemConfig(&backupConfig)
+}
+
 // ToBackupSystemConfig converts config.BackupConfig to backup.BackupSystemConfig
 func (cc *ConfigConverter) ToBackupSystemConfig(dbConfig *config.BackupConfig) (*backup.BackupSystemConfig, error) {
 	if dbConfig == nil {
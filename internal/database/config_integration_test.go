@@ -5,6 +5,7 @@ import (
 	"path/filepath"
 	"testing"
 
+	"mysql-schema-sync/internal/backup"
 	"mysql-schema-sync/internal/config"
 
 	"github.com/stretchr/testify/assert"
@@ -84,6 +85,23 @@ func TestBackupConfigDefaults(t *testing.T) {
 	assert.Equal(t, "sha256", cfg.Validation.ChecksumAlgorithm)
 }
 
+func TestBackupSystemConfig(t *testing.T) {
+	// Backup commands use the defaults even when pre-migration backups are disabled
+	systemConfig, err := BackupSystemConfig(config.BackupConfig{})
+	require.NoError(t, err)
+
+	assert.Equal(t, backup.StorageProviderLocal, systemConfig.Storage.Provider)
+	require.NotNil(t, systemConfig.Storage.Local)
+	assert.Equal(t, "./backups", systemConfig.Storage.Local.BasePath)
+	assert.Equal(t, os.FileMode(0755), systemConfig.Storage.Local.Permissions)
+
+	systemConfig, err = BackupSystemConfig(config.BackupConfig{
+		Storage: config.StorageConfig{Provider: "local", Local: &config.LocalConfig{BasePath: "/var/backups/schema"}},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "/var/backups/schema", systemConfig.Storage.Local.BasePath)
+}
+
 func TestCLIConfigWithBackup(t *testing.T) {
 	cfg := CLIConfig{
 		SourceDB: DatabaseConfig{
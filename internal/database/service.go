@@ -3,6 +3,7 @@ package database
 import (
 	"context"
 	"database/sql"
+	"database/sql/driver"
 	"fmt"
 	"mysql-schema-sync/internal/errors"
 	"mysql-schema-sync/internal/logging"
@@ -18,6 +19,7 @@ type DatabaseService interface {
 	Close(db *sql.DB) error
 	GetVersion(db *sql.DB) (string, error)
 	ExecuteSQL(db *sql.DB, statements []string) error
+	ExecuteSessionSQL(ctx context.Context, db *sql.DB, session, statements, reset []string) error
 }
 
 // Service implements the DatabaseService interface
@@ -278,8 +280,53 @@ func (s *Service) ExecuteSQL(db *sql.DB, statements []string) error {
 		s.displayService.Info(fmt.Sprintf("Executing %d SQL statements...", len(statements)))
 	}
 
+	return s.executeStatements(db.Begin, statements)
+}
+
+// ExecuteSessionSQL executes SQL statements like ExecuteSQL on a dedicated connection that first
+// applies the session settings. The reset statements restore the settings afterwards, also when a
+// statement fails; if they fail too, the connection is discarded instead of returning to the pool
+// with the settings still in effect.
+func (s *Service) ExecuteSessionSQL(ctx context.Context, db *sql.DB, session, statements, reset []string) error {
+	if db == nil {
+		return errors.NewAppError(errors.ErrorTypeValidation, "database connection is nil", nil)
+	}
+	if len(session) == 0 {
+		return s.ExecuteSQL(db, statements)
+	}
+
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return errors.WrapError(err, "failed to get a database connection")
+	}
+	defer func() {
+		// Restore the settings even if the context was cancelled
+		for _, stmt := range reset {
+			if _, resetErr := conn.ExecContext(context.Background(), stmt); resetErr != nil {
+				s.logger.WithFields(map[string]interface{}{
+					"statement": stmt,
+					"error":     resetErr.Error(),
+				}).Warn("Failed to restore session setting, discarding the connection")
+				_ = conn.Raw(func(interface{}) error { return driver.ErrBadConn })
+				break
+			}
+		}
+		conn.Close()
+	}()
+
+	for _, stmt := range session {
+		if _, err := conn.ExecContext(ctx, stmt); err != nil {
+			return errors.WrapError(err, fmt.Sprintf("failed to apply session setting %q", stmt))
+		}
+	}
+
+	return s.executeStatements(func() (*sql.Tx, error) { return conn.BeginTx(ctx, nil) }, statements)
+}
+
+// executeStatements executes SQL statements in a single transaction started by begin
+func (s *Service) executeStatements(begin func() (*sql.Tx, error), statements []string) error {
 	// Start a transaction for atomic execution
-	tx, err := db.Begin()
+	tx, err := begin()
 	if err != nil {
 		wrappedErr := errors.WrapError(err, "failed to begin transaction")
 		if s.displayService != nil {
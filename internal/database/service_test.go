@@ -1,12 +1,16 @@
 package database
 
 import (
+	"context"
 	"database/sql"
+	"fmt"
+	"regexp"
 	"testing"
 	"time"
 
 	"mysql-schema-sync/internal/logging"
 
+	"github.com/DATA-DOG/go-sqlmock"
 	_ "github.com/go-sql-driver/mysql"
 )
 
@@ -137,6 +141,46 @@ func TestExecuteSQL_EmptyStringStatements(t *testing.T) {
 	}
 }
 
+func TestExecuteSessionSQL_RestoresSettings(t *testing.T) {
+	tests := []struct {
+		name    string
+		failing bool
+	}{
+		{name: "statement succeeds"},
+		{name: "statement fails", failing: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			if err != nil {
+				t.Fatalf("Failed to create mock database: %v", err)
+			}
+			defer db.Close()
+
+			mock.ExpectExec(regexp.QuoteMeta("SET FOREIGN_KEY_CHECKS=0")).WillReturnResult(sqlmock.NewResult(0, 0))
+			mock.ExpectBegin()
+			if tt.failing {
+				mock.ExpectExec(regexp.QuoteMeta("DROP TABLE `orders`")).WillReturnError(fmt.Errorf("table is locked"))
+				mock.ExpectRollback()
+			} else {
+				mock.ExpectExec(regexp.QuoteMeta("DROP TABLE `orders`")).WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectCommit()
+			}
+			mock.ExpectExec(regexp.QuoteMeta("SET FOREIGN_KEY_CHECKS=1")).WillReturnResult(sqlmock.NewResult(0, 0))
+
+			err = NewService().ExecuteSessionSQL(context.Background(), db,
+				[]string{"SET FOREIGN_KEY_CHECKS=0"}, []string{"DROP TABLE `orders`"}, []string{"SET FOREIGN_KEY_CHECKS=1"})
+			if tt.failing != (err != nil) {
+				t.Fatalf("ExecuteSessionSQL() error = %v", err)
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("Unfulfilled expectations: %v", err)
+			}
+		})
+	}
+}
+
 // DSN and Validate tests are already covered in config_test.go
 
 // Mock database tests - these test the service logic without requiring a real database
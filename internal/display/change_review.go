@@ -1,8 +1,10 @@
 package display
 
 import (
+	"bufio"
 	"fmt"
 	"io"
+	"os"
 	"strconv"
 	"strings"
 )
@@ -51,6 +53,7 @@ type ChangeReviewDialog struct {
 	iconSystem      IconSystem
 	theme           ColorTheme
 	writer          io.Writer
+	reader          *bufio.Reader
 }
 
 // ChangeReviewResult represents the result of a change review
@@ -78,6 +81,7 @@ func NewChangeReviewDialog(colorSystem ColorSystem, iconSystem IconSystem, theme
 		iconSystem:      iconSystem,
 		theme:           theme,
 		writer:          writer,
+		reader:          bufio.NewReader(os.Stdin),
 		AllowIndividual: true,
 		ShowSummary:     true,
 	}
@@ -105,6 +109,12 @@ func (crd *ChangeReviewDialog) AddChange(change *Change) *ChangeReviewDialog {
 	return crd
 }
 
+// SetReader sets where the answers to the dialog are read from
+func (crd *ChangeReviewDialog) SetReader(reader io.Reader) *ChangeReviewDialog {
+	crd.reader = bufio.NewReader(reader)
+	return crd
+}
+
 // SetAllowIndividual sets whether individual change selection is allowed
 func (crd *ChangeReviewDialog) SetAllowIndividual(allow bool) *ChangeReviewDialog {
 	crd.AllowIndividual = allow
@@ -121,10 +131,13 @@ func (crd *ChangeReviewDialog) Show() (*ChangeReviewResult, error) {
 			return nil, fmt.Errorf("failed to read input: %w", err)
 		}
 
-		result := crd.parseInput(input)
+		result, valid := crd.handleInput(input)
 		if result != nil {
 			return result, nil
 		}
+		if valid {
+			continue
+		}
 
 		// Invalid input, show error and continue
 		crd.showError("Invalid input. Please try again.")
@@ -365,11 +378,10 @@ func (crd *ChangeReviewDialog) renderPrompt() {
 	fmt.Fprint(crd.writer, promptText)
 }
 
-// readInput reads user input
+// readInput reads a line of user input
 func (crd *ChangeReviewDialog) readInput() (string, error) {
-	var input string
-	_, err := fmt.Scanln(&input)
-	if err != nil {
+	input, err := crd.reader.ReadString('\n')
+	if err != nil && (err != io.EOF || input == "") {
 		return "", err
 	}
 	return strings.TrimSpace(input), nil
@@ -377,24 +389,31 @@ func (crd *ChangeReviewDialog) readInput() (string, error) {
 
 // parseInput parses user input and returns the appropriate result
 func (crd *ChangeReviewDialog) parseInput(input string) *ChangeReviewResult {
+	result, _ := crd.handleInput(input)
+	return result
+}
+
+// handleInput applies user input to the dialog. It returns the final result once the review is
+// finished, and whether the input was understood.
+func (crd *ChangeReviewDialog) handleInput(input string) (*ChangeReviewResult, bool) {
 	input = strings.ToLower(strings.TrimSpace(input))
 
 	switch {
 	case input == "a" || input == "all":
 		crd.selectAll()
-		return nil // Continue dialog
+		return nil, true // Continue dialog
 
 	case input == "n" || input == "none":
 		crd.selectNone()
-		return nil // Continue dialog
+		return nil, true // Continue dialog
 
 	case input == "i" || input == "invert":
 		crd.invertSelection()
-		return nil // Continue dialog
+		return nil, true // Continue dialog
 
 	case input == "d" || input == "details":
 		crd.showSelectedDetails()
-		return nil // Continue dialog
+		return nil, true // Continue dialog
 
 	case strings.HasPrefix(input, "d") && len(input) > 1:
 		// Show details for specific change
@@ -403,24 +422,24 @@ func (crd *ChangeReviewDialog) parseInput(input string) *ChangeReviewResult {
 				crd.showChangeDetails(crd.Changes[index-1])
 			}
 		}
-		return nil // Continue dialog
+		return nil, true // Continue dialog
 
 	case input == "y" || input == "yes":
-		return crd.buildResult(true)
+		return crd.buildResult(true), true
 
 	case input == "q" || input == "quit":
-		return crd.buildResult(false)
+		return crd.buildResult(false), true
 
 	default:
 		// Handle individual change selection
 		if crd.AllowIndividual {
 			if crd.handleIndividualSelection(input) {
-				return nil // Continue dialog
+				return nil, true // Continue dialog
 			}
 		}
 	}
 
-	return nil // Invalid input
+	return nil, false // Invalid input
 }
 
 // handleIndividualSelection handles individual change selection commands
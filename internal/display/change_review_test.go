@@ -328,3 +328,22 @@ func TestChangeReviewDialog_HelperMethods(t *testing.T) {
 		t.Error("Should return non-empty icon for ChangeImpactCritical")
 	}
 }
+
+func TestChangeReviewDialog_ShowReadsAnswers(t *testing.T) {
+	var buf bytes.Buffer
+	dialog := NewChangeReviewDialog(NewColorSystem(DefaultColorTheme()), NewIconSystem(), DefaultColorTheme(), &buf).
+		SetReader(strings.NewReader("n\n2\ny\n"))
+	dialog.AddChange(&Change{Title: "Add column nickname", IsSelected: true})
+	dialog.AddChange(&Change{Title: "Drop column legacy", IsSelected: true})
+
+	result, err := dialog.Show()
+	if err != nil {
+		t.Fatalf("Show() error = %v", err)
+	}
+	if !result.Approved || len(result.SelectedChanges) != 1 || result.SelectedChanges[0].Title != "Drop column legacy" {
+		t.Errorf("Expected only the second change to be approved, got %+v", result.SelectedChanges)
+	}
+	if strings.Contains(buf.String(), "Invalid input") {
+		t.Error("Expected valid commands not to be reported as invalid input")
+	}
+}
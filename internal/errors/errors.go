@@ -30,6 +30,8 @@ const (
 	ErrorTypePermission ErrorType = "permission"
 	// ErrorTypeTimeout represents timeout errors
 	ErrorTypeTimeout ErrorType = "timeout"
+	// ErrorTypeVerification represents a target that still differs from the source after a migration
+	ErrorTypeVerification ErrorType = "verification"
 	// ErrorTypeInterruption represents user interruption
 	ErrorTypeInterruption ErrorType = "interruption"
 	// ErrorTypeUnknown represents unknown errors
@@ -176,6 +178,10 @@ func (ec *ErrorClassifier) classifyMySQLError(err error) *AppError {
 			return NewAppError(ErrorTypeSQL,
 				"SQL syntax error", err).
 				WithContext("mysql_error_code", mysqlErr.Number)
+		case 1845, 1846: // ALTER operation not supported for the requested ALGORITHM/LOCK
+			return NewAppError(ErrorTypeSQL,
+				"Server rejected the requested online DDL algorithm or lock level", err).
+				WithContext("mysql_error_code", mysqlErr.Number)
 		case 2003: // Can't connect to MySQL server
 			return NewRecoverableError(ErrorTypeConnection,
 				"Cannot connect to MySQL server - server may be down or unreachable", err).
@@ -447,6 +453,26 @@ func IsRecoverableError(err error) bool {
 	return false
 }
 
+// IsOnlineDDLRejected checks if the server refused an ALTER TABLE because the requested
+// ALGORITHM or LOCK is not supported for the operation
+func IsOnlineDDLRejected(err error) bool {
+	var mysqlErr *mysql.MySQLError
+	if errors.As(err, &mysqlErr) {
+		return mysqlErr.Number == 1845 || mysqlErr.Number == 1846
+	}
+	return false
+}
+
+// IsLockWaitTimeout checks if a statement gave up waiting for a lock after lock_wait_timeout or
+// innodb_lock_wait_timeout
+func IsLockWaitTimeout(err error) bool {
+	var mysqlErr *mysql.MySQLError
+	if errors.As(err, &mysqlErr) {
+		return mysqlErr.Number == 1205
+	}
+	return false
+}
+
 // GetErrorType returns the error type of an error
 func GetErrorType(err error) ErrorType {
 	var appErr *AppError
@@ -86,6 +86,12 @@ func TestErrorClassifier_ClassifyMySQLError(t *testing.T) {
 			expectedType: ErrorTypeSchema,
 			recoverable:  false,
 		},
+		{
+			name:         "online DDL algorithm not supported",
+			mysqlErr:     &mysql.MySQLError{Number: 1846, Message: "ALGORITHM=INPLACE is not supported"},
+			expectedType: ErrorTypeSQL,
+			recoverable:  false,
+		},
 		{
 			name:         "can't connect to server",
 			mysqlErr:     &mysql.MySQLError{Number: 2003, Message: "Can't connect to MySQL server"},
@@ -473,6 +479,75 @@ func TestIsRecoverableError(t *testing.T) {
 	}
 }
 
+func TestIsOnlineDDLRejected(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{
+			name: "algorithm not supported",
+			err:  &mysql.MySQLError{Number: 1845, Message: "ALGORITHM=INSTANT is not supported for this operation"},
+			want: true,
+		},
+		{
+			name: "wrapped algorithm not supported with reason",
+			err:  WrapError(&mysql.MySQLError{Number: 1846, Message: "LOCK=NONE is not supported"}, "failed to execute"),
+			want: true,
+		},
+		{
+			name: "other mysql error",
+			err:  &mysql.MySQLError{Number: 1064, Message: "syntax error"},
+			want: false,
+		},
+		{
+			name: "nil error",
+			err:  nil,
+			want: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsOnlineDDLRejected(tt.err); got != tt.want {
+				t.Errorf("IsOnlineDDLRejected() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsLockWaitTimeout(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{
+			name: "wrapped lock wait timeout",
+			err:  WrapError(&mysql.MySQLError{Number: 1205, Message: "Lock wait timeout exceeded; try restarting transaction"}, "failed to execute"),
+			want: true,
+		},
+		{
+			name: "other mysql error",
+			err:  &mysql.MySQLError{Number: 1064, Message: "syntax error"},
+			want: false,
+		},
+		{
+			name: "nil error",
+			err:  nil,
+			want: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsLockWaitTimeout(tt.err); got != tt.want {
+				t.Errorf("IsLockWaitTimeout() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
 func TestGetErrorType(t *testing.T) {
 	tests := []struct {
 		name string
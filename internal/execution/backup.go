@@ -0,0 +1,104 @@
+package execution
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"mysql-schema-sync/internal/backup"
+	"mysql-schema-sync/internal/config"
+	"mysql-schema-sync/internal/database"
+	"mysql-schema-sync/internal/display"
+	"mysql-schema-sync/internal/errors"
+	"mysql-schema-sync/internal/migration"
+	"mysql-schema-sync/internal/schema"
+)
+
+// preMigrationBackups creates and validates the backups taken before a migration runs
+type preMigrationBackups interface {
+	CreatePreMigrationBackup(ctx context.Context, config backup.BackupConfig, migrationPlan interface{}) (*backup.Backup, error)
+	ValidateBackup(ctx context.Context, backupID string) (*backup.ValidationResult, error)
+}
+
+// backupConfig returns the backup configuration with defaults for the settings it leaves out
+func (e *Executor) backupConfig() config.BackupConfig {
+	backupConfig := e.config.Backup
+	backupConfig.SetDefaults()
+	return backupConfig
+}
+
+// createBackup backs up the target schema before the first statement of the plan runs and
+// validates the stored backup. The migration must not run without a valid backup, so any failure
+// is returned. It returns the ID of the backup, or an empty ID if backups are disabled.
+func (e *Executor) createBackup(ctx context.Context, targetSchema *schema.Schema, migrationPlan *migration.MigrationPlan, source string) (string, error) {
+	if !e.config.Backup.Enabled {
+		return "", nil
+	}
+	if targetSchema == nil {
+		return "", errors.NewAppError(errors.ErrorTypeValidation, "pre-migration backup requires the target schema", nil)
+	}
+
+	if e.backups == nil {
+		systemConfig, err := database.BackupSystemConfig(e.config.Backup)
+		if err != nil {
+			return "", errors.NewAppError(errors.ErrorTypeValidation, "invalid backup configuration", err)
+		}
+		manager, err := backup.NewBackupManager(systemConfig)
+		if err != nil {
+			return "", errors.WrapError(err, "failed to create backup manager")
+		}
+		backups, ok := manager.(preMigrationBackups)
+		if !ok {
+			return "", errors.NewAppError(errors.ErrorTypeUnknown, "backup manager cannot create pre-migration backups", nil)
+		}
+		e.backups = backups
+	}
+
+	e.logger.WithFields(map[string]interface{}{
+		"database":  e.config.TargetDB.Database,
+		"plan_hash": migrationPlan.Hash(),
+	}).Info("Creating pre-migration backup")
+
+	var spinner display.SpinnerHandle
+	if e.displayService != nil {
+		spinner = e.displayService.StartSpinner(fmt.Sprintf("Backing up target database (%s)...", e.config.TargetDB.Database))
+	}
+	stopSpinner := func(message string) {
+		if e.displayService != nil {
+			e.displayService.StopSpinner(spinner, message)
+		}
+	}
+
+	created, err := e.backups.CreatePreMigrationBackup(ctx, backup.BackupConfig{
+		DatabaseConfig: backup.DatabaseConfig{
+			Host:     e.config.TargetDB.Host,
+			Port:     e.config.TargetDB.Port,
+			Username: e.config.TargetDB.Username,
+			Database: e.config.TargetDB.Database,
+		},
+		SchemaSnapshot: targetSchema,
+		Source:         source,
+		ToolVersion:    e.config.ToolVersion,
+	}, migrationPlan)
+	if err != nil {
+		stopSpinner("")
+		return "", errors.NewAppError(errors.ErrorTypeValidation, "pre-migration backup failed, the migration was not started", err)
+	}
+
+	validation, err := e.backups.ValidateBackup(ctx, created.ID)
+	if err != nil {
+		stopSpinner("")
+		return "", errors.NewAppError(errors.ErrorTypeValidation,
+			fmt.Sprintf("pre-migration backup %s could not be validated, the migration was not started", created.ID), err)
+	}
+	if !validation.Valid {
+		stopSpinner("")
+		return "", errors.NewAppError(errors.ErrorTypeValidation,
+			fmt.Sprintf("pre-migration backup %s failed validation, the migration was not started: %s",
+				created.ID, strings.Join(validation.Errors, "; ")), nil)
+	}
+
+	stopSpinner(fmt.Sprintf("Pre-migration backup created: %s", created.ID))
+	e.logger.WithField("backup_id", created.ID).Info("Pre-migration backup created and validated")
+	return created.ID, nil
+}
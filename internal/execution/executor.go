@@ -4,14 +4,28 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"io"
+	"os"
+	"strings"
 	"time"
 
+	"mysql-schema-sync/internal/config"
 	"mysql-schema-sync/internal/database"
 	"mysql-schema-sync/internal/display"
 	"mysql-schema-sync/internal/errors"
+	"mysql-schema-sync/internal/history"
+	"mysql-schema-sync/internal/journal"
+	"mysql-schema-sync/internal/lint"
+	"mysql-schema-sync/internal/lock"
 	"mysql-schema-sync/internal/logging"
+	"mysql-schema-sync/internal/mdl"
 	"mysql-schema-sync/internal/migration"
+	"mysql-schema-sync/internal/migrationfiles"
+	"mysql-schema-sync/internal/osc"
+	"mysql-schema-sync/internal/planfile"
+	"mysql-schema-sync/internal/policy"
 	"mysql-schema-sync/internal/schema"
+	"mysql-schema-sync/internal/throttle"
 )
 
 // ExecutionConfig holds configuration for the execution service
@@ -22,6 +36,49 @@ type ExecutionConfig struct {
 	AutoApprove bool
 	Timeout     time.Duration
 	LogLevel    logging.LogLevel
+
+	// MergeAlterations coalesces all operations on a table into a single ALTER TABLE
+	MergeAlterations bool
+	// OnlineDDL appends explicit ALGORITHM/LOCK clauses predicted for the target server version
+	OnlineDDL bool
+	// OnlineSchemaChange delegates changes to large tables to gh-ost or pt-online-schema-change
+	OnlineSchemaChange osc.Config
+	// RollbackFile is where the SQL script reverting the migration is written before it runs
+	RollbackFile string
+	// EmitMigrations writes the plan as migration framework files instead of applying it
+	EmitMigrations migrationfiles.Config
+	// PlanOut saves the plan with schema fingerprints to this file instead of applying it
+	PlanOut string
+	// Verify re-compares source and target after execution and fails if the target still differs
+	Verify bool
+	// SkipDataChecks disables the pre-flight queries that look for rows the changes would reject
+	SkipDataChecks bool
+	// Workers is the number of statements on independent tables executed concurrently
+	Workers int
+	// SafeDrop archives dropped tables and columns by renaming them instead of dropping them
+	SafeDrop migration.SafeDropConfig
+	// Policy enforces the rules of a policy file on every migration plan
+	Policy policy.Config
+	// Selection applies only the chosen changes of the plan
+	Selection migration.SelectionConfig
+	// Journal records every executed statement on the target so interrupted migrations can resume
+	Journal journal.Config
+	// History records every executed migration in a table on the target
+	History history.Config
+	// Backup stores a validated backup of the target schema before the first statement runs
+	Backup config.BackupConfig
+	// Lock keeps concurrent runs off the target while planning and executing
+	Lock lock.Config
+	// MetadataLocks checks for sessions blocking each statement's table and bounds lock waits
+	MetadataLocks mdl.Config
+	// Replication pauses between statements while replicas of the target lag behind
+	Replication throttle.ReplicationConfig
+	// Load pauses between statements while the target is busy or an operator paused the sync
+	Load throttle.LoadConfig
+	// ToolVersion is the version of mysql-schema-sync recorded in the migration history
+	ToolVersion string
+	// TargetOnly skips the source database settings for commands that only use the target
+	TargetOnly bool
 }
 
 // ExecutionResult holds the result of an execution
@@ -29,7 +86,16 @@ type ExecutionResult struct {
 	Success            bool
 	SchemaDiff         *schema.SchemaDiff
 	MigrationPlan      *migration.MigrationPlan
+	DataValidation     *schema.ValidationResult
+	PolicyValidation   *schema.ValidationResult
+	PolicyOverride     *policy.Override
+	ResidualDiff       *schema.SchemaDiff
 	ExecutedStatements []string
+	ExternalCommands   []string
+	EmittedFiles       []string
+	PlanFile           string
+	PurgedObjects      []migration.ArchivedObject
+	BackupID           string
 	Warnings           []string
 	Duration           time.Duration
 	Error              error
@@ -45,6 +111,12 @@ type Executor struct {
 	retryHandler     *errors.RetryHandler
 	shutdownHandler  *errors.GracefulShutdownHandler
 	displayService   display.DisplayService
+	oscRunner        *osc.Runner
+	policy           *policy.Policy
+	// reviewInput replaces stdin as the source of change review answers
+	reviewInput io.Reader
+	// backups creates the pre-migration backups; it is set up on first use
+	backups preMigrationBackups
 }
 
 // NewExecutor creates a new executor with the given configuration
@@ -65,6 +137,19 @@ func NewExecutor(config ExecutionConfig) (*Executor, error) {
 	dbService := database.NewServiceWithLogger(logger)
 	schemaService := schema.NewServiceWithLogger(logger)
 	migrationService := migration.NewMigrationServiceWithLogger(logger)
+	if config.SafeDrop.Enabled {
+		if err := migrationService.EnableSafeDrop(config.SafeDrop); err != nil {
+			return nil, fmt.Errorf("failed to enable safe-drop mode: %w", err)
+		}
+	}
+
+	var rules *policy.Policy
+	if config.Policy.Enabled() {
+		rules, err = policy.Load(config.Policy.File)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load policy: %w", err)
+		}
+	}
 
 	// Create retry handler with custom configuration
 	retryConfig := errors.RetryConfig{
@@ -86,6 +171,8 @@ func NewExecutor(config ExecutionConfig) (*Executor, error) {
 		migrationService: migrationService,
 		retryHandler:     retryHandler,
 		shutdownHandler:  shutdownHandler,
+		oscRunner:        osc.NewRunner(logger),
+		policy:           rules,
 	}
 
 	return executor, nil
@@ -132,6 +219,15 @@ func (e *Executor) Execute(ctx context.Context) (*ExecutionResult, error) {
 		return nil
 	})
 
+	// Hold the target lock while planning and executing
+	targetLock, err := e.acquireLock(ctx, targetDB)
+	if err != nil {
+		result.Error = err
+		result.Duration = time.Since(startTime)
+		return result, err
+	}
+	defer e.releaseLock(targetLock)
+
 	// Step 2: Extract schemas
 	sourceSchemaDef, targetSchemaDef, err := e.extractSchemas(ctx, sourceDB, targetDB)
 	if err != nil {
@@ -148,6 +244,7 @@ func (e *Executor) Execute(ctx context.Context) (*ExecutionResult, error) {
 		return result, err
 	}
 	result.SchemaDiff = schemaDiff
+	plannedDiff := schemaDiff
 
 	// Check if there are any differences
 	if e.schemaService.IsSchemaDiffEmpty(schemaDiff) {
@@ -157,23 +254,129 @@ func (e *Executor) Execute(ctx context.Context) (*ExecutionResult, error) {
 		return result, nil
 	}
 
-	// Step 4: Create migration plan
-	migrationPlan, err := e.createMigrationPlan(schemaDiff)
+	// Step 4: Create the migration plan for the selected changes
+	if err := e.configureDialect(targetDB); err != nil {
+		result.Error = err
+		result.Duration = time.Since(startTime)
+		return result, err
+	}
+
+	migrationPlan, schemaDiff, err := e.createMigrationPlan(schemaDiff)
 	if err != nil {
 		result.Error = err
 		result.Duration = time.Since(startTime)
 		return result, err
 	}
+	result.SchemaDiff = schemaDiff
+
+	// Step 5: Check that the target data can take the changes
+	if !e.config.SkipDataChecks {
+		validation, err := e.checkTargetData(targetDB, targetSchemaDef, schemaDiff)
+		result.DataValidation = validation
+		if err != nil {
+			result.Error = err
+			result.Duration = time.Since(startTime)
+			return result, err
+		}
+	}
+
+	migration.EstimatePlan(migrationPlan, targetSchemaDef.TableSizes)
 	result.MigrationPlan = migrationPlan
 	result.Warnings = migrationPlan.Warnings
 
-	// Step 5: Execute migration (if not dry run and approved)
-	if !e.config.DryRun {
-		if err := e.executeMigration(ctx, targetDB, migrationPlan); err != nil {
+	if e.policy != nil {
+		validation, err := e.checkPolicy(targetDB, schemaDiff, migrationPlan, result)
+		result.PolicyValidation = validation
+		if err != nil {
+			result.Error = err
+			result.Duration = time.Since(startTime)
+			return result, err
+		}
+	}
+
+	// Dropped columns left in place by safe-drop mode and fully skipped plans need no statements
+	if len(migrationPlan.Statements) == 0 {
+		e.logger.Info("Migration plan has no statements to execute")
+		result.Success = true
+		result.Duration = time.Since(startTime)
+		return result, nil
+	}
+
+	// Step 6: Save the plan for a later apply, or apply it now
+	if e.config.PlanOut != "" {
+		if err := e.savePlan(migrationPlan, sourceSchemaDef, targetSchemaDef); err != nil {
+			result.Error = err
+			result.Duration = time.Since(startTime)
+			return result, err
+		}
+		result.PlanFile = e.config.PlanOut
+	} else if err := e.runPlan(ctx, targetDB, targetSchemaDef, migrationPlan, databaseIdentity(e.config.SourceDB), result); err != nil {
+		result.Error = err
+		result.Duration = time.Since(startTime)
+		return result, err
+	}
+
+	// Step 7: Check that the executed plan left the target in sync with the source
+	if e.config.Verify && len(result.ExecutedStatements) > 0 {
+		if err := e.verifyTarget(ctx, sourceDB, targetDB, plannedDiff, schemaDiff, migrationPlan, result); err != nil {
 			result.Error = err
 			result.Duration = time.Since(startTime)
 			return result, err
 		}
+	}
+
+	result.Success = true
+	result.Duration = time.Since(startTime)
+
+	e.logger.WithFields(map[string]interface{}{
+		"duration":         result.Duration.String(),
+		"statements_count": len(result.ExecutedStatements),
+		"warnings_count":   len(result.Warnings),
+		"dry_run":          e.config.DryRun,
+	}).Info("Schema synchronization completed successfully")
+
+	return result, nil
+}
+
+// runPlan exports the rollback and then emits, prints or executes the migration plan as configured.
+// The target schema is backed up before execution if backups are enabled. The source identifies
+// where the plan came from in the migration history.
+func (e *Executor) runPlan(ctx context.Context, targetDB *sql.DB, targetSchema *schema.Schema, migrationPlan *migration.MigrationPlan, source string, result *ExecutionResult) error {
+	// Write the rollback before anything runs so it is available even if the migration fails
+	if e.config.RollbackFile != "" {
+		if err := e.exportRollback(migrationPlan); err != nil {
+			return err
+		}
+	}
+
+	if e.config.EmitMigrations.Enabled() {
+		files, err := e.emitMigrations(migrationPlan)
+		if err != nil {
+			return err
+		}
+		result.EmittedFiles = files
+	} else if e.config.OnlineSchemaChange.Enabled() && e.config.OnlineSchemaChange.PrintOnly {
+		commands, err := e.buildMigrationCommands(targetDB, migrationPlan)
+		if err != nil {
+			return err
+		}
+		result.ExternalCommands = commands
+	} else if !e.config.DryRun {
+		backupID, err := e.createBackup(ctx, targetSchema, migrationPlan, source)
+		if err != nil {
+			return err
+		}
+		result.BackupID = backupID
+		if backupID != "" && e.displayService != nil {
+			e.displayService.Info(fmt.Sprintf("Restore the target to its state before the migration with: mysql-schema-sync rollback execute %s", backupID))
+		}
+
+		startTime := time.Now()
+		err = e.executeMigration(ctx, targetDB, migrationPlan)
+		e.recordHistory(ctx, targetDB, migrationPlan, source, backupID, startTime, err)
+		if err != nil {
+			return err
+		}
 
 		// Extract executed statements for result
 		for _, stmt := range migrationPlan.Statements {
@@ -181,19 +384,291 @@ func (e *Executor) Execute(ctx context.Context) (*ExecutionResult, error) {
 		}
 	}
 
+	return nil
+}
+
+// savePlan writes the migration plan and the schema fingerprints to the configured plan file
+func (e *Executor) savePlan(migrationPlan *migration.MigrationPlan, sourceSchema, targetSchema *schema.Schema) error {
+	pf := planfile.New(migrationPlan, sourceSchema, targetSchema)
+	if err := pf.Save(e.config.PlanOut); err != nil {
+		return errors.NewAppError(errors.ErrorTypeValidation, "failed to save migration plan", err)
+	}
+
+	e.logger.WithFields(map[string]interface{}{
+		"file":               e.config.PlanOut,
+		"plan_hash":          pf.PlanHash,
+		"target_fingerprint": pf.TargetFingerprint,
+	}).Info("Migration plan saved")
+
+	return nil
+}
+
+// Apply executes a saved plan exactly as reviewed, after verifying that the target schema has not
+// changed since the plan was created
+func (e *Executor) Apply(ctx context.Context, pf *planfile.PlanFile) (*ExecutionResult, error) {
+	startTime := time.Now()
+	result := &ExecutionResult{
+		Success:            false,
+		ExecutedStatements: make([]string, 0),
+		Warnings:           make([]string, 0),
+	}
+
+	if pf == nil {
+		err := errors.NewAppError(errors.ErrorTypeValidation, "plan file cannot be nil", nil)
+		result.Error = err
+		return result, err
+	}
+
+	// Set up graceful shutdown
+	e.shutdownHandler.Start()
+	defer e.shutdownHandler.Stop()
+
+	if e.config.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, e.config.Timeout)
+		defer cancel()
+	}
+
+	e.logger.WithFields(map[string]interface{}{
+		"plan_hash":       pf.PlanHash,
+		"planned_target":  pf.TargetDatabase,
+		"planned_at":      pf.CreatedAt.Format(time.RFC3339),
+		"statement_count": len(pf.Plan.Statements),
+	}).Info("Applying saved migration plan")
+
+	targetDB, err := e.connectToTarget(ctx)
+	if err != nil {
+		result.Error = err
+		result.Duration = time.Since(startTime)
+		return result, err
+	}
+	e.shutdownHandler.RegisterShutdownFunc(func() error {
+		e.dbService.Close(targetDB)
+		return nil
+	})
+
+	targetLock, err := e.acquireLock(ctx, targetDB)
+	if err != nil {
+		result.Error = err
+		result.Duration = time.Since(startTime)
+		return result, err
+	}
+	defer e.releaseLock(targetLock)
+
+	// Re-extract the target and refuse to run if it drifted from what was reviewed
+	var targetSchema *schema.Schema
+	err = e.retryHandler.Retry(ctx, func() error {
+		targetSchema, err = e.schemaService.ExtractSchemaFromDB(targetDB, e.config.TargetDB.Database)
+		return err
+	})
+	if err != nil {
+		err = errors.WrapError(err, "failed to extract target schema")
+		result.Error = err
+		result.Duration = time.Since(startTime)
+		return result, err
+	}
+
+	e.hideInternalTables(targetSchema)
+
+	// A partially applied plan changed the target on purpose; the journal verifies it instead
+	resuming, err := e.journaledRun(ctx, targetDB, pf.PlanHash)
+	if err != nil {
+		result.Error = err
+		result.Duration = time.Since(startTime)
+		return result, err
+	}
+
+	if !resuming {
+		if err := pf.CheckTarget(targetSchema); err != nil {
+			appErr := errors.NewAppError(errors.ErrorTypeValidation, err.Error(), err)
+			result.Error = appErr
+			result.Duration = time.Since(startTime)
+			return result, appErr
+		}
+	}
+
+	result.MigrationPlan = pf.Plan
+	result.Warnings = pf.Plan.Warnings
+
+	if err := e.runPlan(ctx, targetDB, targetSchema, pf.Plan, pf.SourceDatabase, result); err != nil {
+		result.Error = err
+		result.Duration = time.Since(startTime)
+		return result, err
+	}
+
 	result.Success = true
 	result.Duration = time.Since(startTime)
 
 	e.logger.WithFields(map[string]interface{}{
 		"duration":         result.Duration.String(),
 		"statements_count": len(result.ExecutedStatements),
-		"warnings_count":   len(result.Warnings),
 		"dry_run":          e.config.DryRun,
-	}).Info("Schema synchronization completed successfully")
+	}).Info("Saved migration plan applied successfully")
 
 	return result, nil
 }
 
+// PurgeArchived permanently drops the tables and columns that safe-drop mode archived more than
+// olderThan ago. In dry-run mode the archived objects are only listed.
+func (e *Executor) PurgeArchived(ctx context.Context, olderThan time.Duration) (*ExecutionResult, error) {
+	startTime := time.Now()
+	result := &ExecutionResult{
+		Success:            false,
+		ExecutedStatements: make([]string, 0),
+		Warnings:           make([]string, 0),
+	}
+
+	e.shutdownHandler.Start()
+	defer e.shutdownHandler.Stop()
+
+	if e.config.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, e.config.Timeout)
+		defer cancel()
+	}
+
+	targetDB, err := e.connectToTarget(ctx)
+	if err != nil {
+		result.Error = err
+		result.Duration = time.Since(startTime)
+		return result, err
+	}
+	e.shutdownHandler.RegisterShutdownFunc(func() error {
+		e.dbService.Close(targetDB)
+		return nil
+	})
+
+	targetLock, err := e.acquireLock(ctx, targetDB)
+	if err != nil {
+		result.Error = err
+		result.Duration = time.Since(startTime)
+		return result, err
+	}
+	defer e.releaseLock(targetLock)
+
+	var targetSchema *schema.Schema
+	err = e.retryHandler.Retry(ctx, func() error {
+		targetSchema, err = e.schemaService.ExtractSchemaFromDB(targetDB, e.config.TargetDB.Database)
+		return err
+	})
+	if err != nil {
+		err = errors.WrapError(err, "failed to extract target schema")
+		result.Error = err
+		result.Duration = time.Since(startTime)
+		return result, err
+	}
+
+	cutoff := time.Now().UTC().Add(-olderThan)
+	archived := migration.FindArchived(targetSchema, cutoff)
+	result.PurgedObjects = archived
+
+	e.logger.WithFields(map[string]interface{}{
+		"cutoff":         cutoff.Format(time.RFC3339),
+		"archived_count": len(archived),
+	}).Info("Found archived objects to purge")
+
+	if len(archived) == 0 {
+		result.Success = true
+		result.Duration = time.Since(startTime)
+		return result, nil
+	}
+
+	purgePlan, err := migration.PlanArchivePurge(targetSchema, archived)
+	if err != nil {
+		err = errors.WrapError(err, "failed to plan purge of archived objects")
+		result.Error = err
+		result.Duration = time.Since(startTime)
+		return result, err
+	}
+	result.MigrationPlan = purgePlan
+
+	if !e.config.DryRun {
+		if err := e.executeMigration(ctx, targetDB, purgePlan); err != nil {
+			result.Error = err
+			result.Duration = time.Since(startTime)
+			return result, err
+		}
+		for _, stmt := range purgePlan.Statements {
+			result.ExecutedStatements = append(result.ExecutedStatements, stmt.SQL)
+		}
+	}
+
+	result.Success = true
+	result.Duration = time.Since(startTime)
+	return result, nil
+}
+
+// Lint extracts the target database and reports its schema smells
+func (e *Executor) Lint(ctx context.Context) (*lint.Report, error) {
+	e.shutdownHandler.Start()
+	defer e.shutdownHandler.Stop()
+
+	if e.config.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, e.config.Timeout)
+		defer cancel()
+	}
+
+	targetDB, err := e.connectToTarget(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer e.dbService.Close(targetDB)
+
+	databaseName := e.config.TargetDB.Database
+	var targetSchema *schema.Schema
+	var foreignKeys []*schema.Constraint
+	var charsets map[string]map[string]*schema.ColumnCharset
+	err = e.retryHandler.Retry(ctx, func() error {
+		if targetSchema, err = e.schemaService.ExtractSchemaFromDB(targetDB, databaseName); err != nil {
+			return err
+		}
+		if foreignKeys, err = e.schemaService.GetForeignKeys(targetDB, databaseName); err != nil {
+			return err
+		}
+		charsets, err = e.schemaService.GetColumnCharsets(targetDB, databaseName)
+		return err
+	})
+	if err != nil {
+		return nil, errors.WrapError(err, "failed to extract schema for linting")
+	}
+
+	for _, constraint := range foreignKeys {
+		if table, exists := targetSchema.Tables[constraint.TableName]; exists {
+			table.AddConstraint(constraint)
+		}
+	}
+
+	report := lint.NewLinter().Lint(targetSchema, charsets)
+
+	e.logger.WithFields(map[string]interface{}{
+		"database":       databaseName,
+		"tables_count":   len(targetSchema.Tables),
+		"findings_count": len(report.Findings),
+	}).Info("Schema lint completed")
+
+	return report, nil
+}
+
+// connectToTarget establishes a connection to the target database only
+func (e *Executor) connectToTarget(ctx context.Context) (*sql.DB, error) {
+	var targetDB *sql.DB
+	var err error
+
+	err = e.retryHandler.Retry(ctx, func() error {
+		targetDB, err = e.dbService.Connect(e.config.TargetDB)
+		return err
+	})
+	if err != nil {
+		if e.displayService != nil {
+			e.displayService.Error(fmt.Sprintf("Failed to connect to target database: %s", e.config.TargetDB.Host))
+		}
+		return nil, errors.WrapError(err, "failed to connect to target database")
+	}
+
+	return targetDB, nil
+}
+
 // connectToDatabases establishes connections to both source and target databases
 func (e *Executor) connectToDatabases(ctx context.Context) (*sql.DB, *sql.DB, error) {
 	e.logger.Info("Connecting to databases")
@@ -292,6 +767,15 @@ func (e *Executor) extractSchemas(ctx context.Context, sourceDB, targetDB *sql.D
 		return nil, nil, errors.WrapError(err, "failed to extract target schema")
 	}
 
+	// Table sizes only feed the execution estimates, so a failure is not fatal
+	sizes, err := e.schemaService.GetTableSizes(targetDB, e.config.TargetDB.Database)
+	if err != nil {
+		e.logger.WithField("error", err.Error()).Warn("Failed to read target table sizes, execution estimates will ignore table sizes")
+	} else {
+		targetSchema.TableSizes = sizes
+	}
+	e.hideInternalTables(targetSchema)
+
 	if e.displayService != nil {
 		e.displayService.StopSpinner(spinner, fmt.Sprintf("Schema extraction completed (%d source tables, %d target tables)", len(sourceSchema.Tables), len(targetSchema.Tables)))
 	}
@@ -304,6 +788,22 @@ func (e *Executor) extractSchemas(ctx context.Context, sourceDB, targetDB *sql.D
 	return sourceSchema, targetSchema, nil
 }
 
+// hideInternalTables removes the journal and history tables from an extracted target schema so
+// they are never diffed. The tables stay hidden when the journal or history is disabled, and the
+// default names are hidden as well, because earlier runs may have created them.
+func (e *Executor) hideInternalTables(targetSchema *schema.Schema) {
+	if targetSchema == nil {
+		return
+	}
+	for _, name := range []string{
+		e.config.Journal.TableName(), journal.DefaultTable,
+		e.config.History.TableName(), history.DefaultTable,
+	} {
+		delete(targetSchema.Tables, name)
+		delete(targetSchema.TableSizes, name)
+	}
+}
+
 // compareSchemas compares the extracted schemas
 func (e *Executor) compareSchemas(sourceSchema, targetSchema *schema.Schema) (*schema.SchemaDiff, error) {
 	e.logger.Info("Comparing schemas")
@@ -334,18 +834,155 @@ func (e *Executor) compareSchemas(sourceSchema, targetSchema *schema.Schema) (*s
 	return schemaDiff, nil
 }
 
+// checkTargetData runs the pre-flight data checks and fails if any rows would violate the changes
+func (e *Executor) checkTargetData(targetDB *sql.DB, targetSchema *schema.Schema, schemaDiff *schema.SchemaDiff) (*schema.ValidationResult, error) {
+	e.logger.Info("Checking target data against schema changes")
+
+	var spinner display.SpinnerHandle
+	if e.displayService != nil {
+		spinner = e.displayService.StartSpinner("Checking target data...")
+	}
+
+	validation, err := e.schemaService.CheckData(targetDB, targetSchema, schemaDiff)
+	if err != nil {
+		if e.displayService != nil {
+			e.displayService.StopSpinner(spinner, "")
+		}
+		return nil, errors.WrapError(err, "pre-flight data checks failed")
+	}
+
+	if e.displayService != nil {
+		e.displayService.StopSpinner(spinner, "Target data checked")
+	}
+
+	if !validation.IsValid {
+		validator := schema.NewSchemaValidator(false)
+		appErr := errors.NewAppError(errors.ErrorTypeValidation,
+			fmt.Sprintf("target data violates %d of the planned changes", len(validation.Errors)), nil)
+		appErr.UserMessage = fmt.Sprintf("Pre-flight data checks failed; fix the data or the schema and try again\n%s",
+			validator.FormatValidationResult(validation, false))
+		appErr.WithContext("violations", len(validation.Errors))
+		return validation, appErr
+	}
+
+	e.logger.Info("Target data is compatible with the schema changes")
+	return validation, nil
+}
+
+// checkPolicy evaluates the policy rules against the plan and fails on deny violations unless an
+// override reason was given, in which case the override is logged and audited
+func (e *Executor) checkPolicy(targetDB *sql.DB, schemaDiff *schema.SchemaDiff, migrationPlan *migration.MigrationPlan, result *ExecutionResult) (*schema.ValidationResult, error) {
+	e.logger.Info("Evaluating migration plan against policy")
+
+	target := policy.Target{Tags: e.config.Policy.TargetTags}
+	if e.policy.NeedsTableSizes() {
+		sizes, err := e.schemaService.GetTableSizes(targetDB, e.config.TargetDB.Database)
+		if err != nil {
+			return nil, errors.WrapError(err, "failed to retrieve table sizes for policy evaluation")
+		}
+		target.TableSizes = sizes
+	}
+
+	validation := e.policy.Evaluate(schemaDiff, migrationPlan, target)
+	for _, warning := range validation.Warnings {
+		result.Warnings = append(result.Warnings, warning.Message)
+	}
+
+	if validation.IsValid {
+		e.logger.WithField("warnings_count", len(validation.Warnings)).Info("Migration plan complies with policy")
+		return validation, nil
+	}
+
+	if e.config.Policy.Override == "" {
+		validator := schema.NewSchemaValidator(false)
+		appErr := errors.NewAppError(errors.ErrorTypeValidation,
+			fmt.Sprintf("migration plan violates %d policy rules", len(validation.Errors)), nil)
+		appErr.UserMessage = fmt.Sprintf("Migration plan denied by policy; change the plan or rerun with --override-policy \"<reason>\"\n%s",
+			validator.FormatValidationResult(validation, false))
+		appErr.WithContext("violations", len(validation.Errors))
+		return validation, appErr
+	}
+
+	override := policy.NewOverride(e.config.Policy.Override, e.config.TargetDB.Database, migrationPlan, validation)
+	result.PolicyOverride = &override
+
+	e.logger.WithFields(map[string]interface{}{
+		"user":       override.User,
+		"reason":     override.Reason,
+		"target":     override.Target,
+		"plan_hash":  override.PlanHash,
+		"violations": strings.Join(override.Violations, "; "),
+	}).Warn("Policy violations overridden")
+
+	if e.config.Policy.AuditLog != "" {
+		if err := policy.AppendAudit(e.config.Policy.AuditLog, override); err != nil {
+			return validation, errors.NewAppError(errors.ErrorTypeValidation, "failed to audit policy override", err)
+		}
+	}
+
+	return validation, nil
+}
+
+// configureDialect makes planning generate SQL for the target server version. Without online DDL
+// the version is optional and planning falls back to the generic dialect if it cannot be read.
+func (e *Executor) configureDialect(targetDB *sql.DB) error {
+	version, err := e.dbService.GetVersion(targetDB)
+	if err != nil {
+		if e.config.OnlineDDL {
+			return errors.WrapError(err, "failed to determine target server version for online DDL")
+		}
+		e.logger.WithField("error", err.Error()).Warn("Failed to determine target server version, generating version-independent SQL")
+		return nil
+	}
+
+	if err := e.migrationService.SetServerVersion(version); err != nil {
+		if e.config.OnlineDDL {
+			return errors.WrapError(err, "failed to configure SQL dialect for the target server")
+		}
+		e.logger.WithField("server_version", version).Warn("Unrecognized target server version, generating version-independent SQL")
+		return nil
+	}
+
+	if e.config.OnlineDDL {
+		if err := e.migrationService.EnableOnlineDDL(version); err != nil {
+			return errors.WrapError(err, "failed to enable online DDL")
+		}
+		e.logger.WithField("server_version", version).Info("Online DDL enabled")
+	}
+
+	return nil
+}
+
 // createMigrationPlan creates a migration plan from the schema differences
-func (e *Executor) createMigrationPlan(schemaDiff *schema.SchemaDiff) (*migration.MigrationPlan, error) {
+func (e *Executor) createMigrationPlan(schemaDiff *schema.SchemaDiff) (*migration.MigrationPlan, *schema.SchemaDiff, error) {
 	e.logger.Info("Creating migration plan")
 
 	migrationPlan, err := e.migrationService.PlanMigration(schemaDiff)
 	if err != nil {
-		return nil, errors.WrapError(err, "failed to create migration plan")
+		return nil, nil, errors.WrapError(err, "failed to create migration plan")
+	}
+
+	// Narrow the plan to the selected changes before statements are merged
+	if e.config.Selection.Enabled() && len(migrationPlan.Statements) > 0 {
+		migrationPlan, schemaDiff, err = e.selectChanges(schemaDiff, migrationPlan)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	// Merge per-table operations so each table is rebuilt at most once
+	if e.config.MergeAlterations {
+		migrationPlan, err = e.migrationService.OptimizePlan(migrationPlan)
+		if err != nil {
+			return nil, nil, errors.WrapError(err, "failed to merge table alterations")
+		}
 	}
 
 	// Validate the migration plan
-	if err := e.migrationService.ValidatePlan(migrationPlan); err != nil {
-		return nil, errors.WrapError(err, "migration plan validation failed")
+	if len(migrationPlan.Statements) > 0 {
+		if err := e.migrationService.ValidatePlan(migrationPlan); err != nil {
+			return nil, nil, errors.WrapError(err, "migration plan validation failed")
+		}
 	}
 
 	e.logger.WithFields(map[string]interface{}{
@@ -353,7 +990,138 @@ func (e *Executor) createMigrationPlan(schemaDiff *schema.SchemaDiff) (*migratio
 		"warnings_count":   len(migrationPlan.Warnings),
 	}).Info("Migration plan created and validated")
 
-	return migrationPlan, nil
+	return migrationPlan, schemaDiff, nil
+}
+
+// selectChanges narrows the plan to the statements chosen by the --only and --skip selectors
+// and, in review mode, by the user
+func (e *Executor) selectChanges(schemaDiff *schema.SchemaDiff, migrationPlan *migration.MigrationPlan) (*migration.MigrationPlan, *schema.SchemaDiff, error) {
+	filter, err := migration.NewStatementFilter(e.config.Selection.Only, e.config.Selection.Skip)
+	if err != nil {
+		return nil, nil, errors.NewAppError(errors.ErrorTypeValidation, err.Error(), err)
+	}
+
+	chosen := filter.Selects
+	if e.config.Selection.Review {
+		reviewed, err := e.reviewChanges(migrationPlan, filter)
+		if err != nil {
+			return nil, nil, err
+		}
+		chosen = func(stmt migration.MigrationStatement) bool {
+			return reviewed[stmt.Description]
+		}
+	}
+
+	return e.migrationService.SelectChanges(schemaDiff, migrationPlan, chosen, filter.Skips)
+}
+
+// reviewChanges shows the planned statements in the change review dialog, preselecting those the
+// filter selects, and returns the descriptions of the statements the user approved
+func (e *Executor) reviewChanges(migrationPlan *migration.MigrationPlan, filter *migration.StatementFilter) (map[string]bool, error) {
+	if e.displayService == nil {
+		return nil, errors.NewAppError(errors.ErrorTypeValidation, "change review requires an interactive display", nil)
+	}
+
+	dialog := e.displayService.NewChangeReviewDialog().
+		SetTitle("Review Migration Changes").
+		SetMessage("Select the changes to apply. Statements the selected changes depend on are added automatically.")
+	if e.reviewInput != nil {
+		dialog.SetReader(e.reviewInput)
+	}
+	for _, stmt := range migrationPlan.Statements {
+		dialog.AddChange(reviewChange(stmt, filter.Selects(stmt)))
+	}
+
+	review, err := dialog.Show()
+	if err != nil {
+		return nil, errors.NewAppError(errors.ErrorTypeInterruption, "change review failed", err)
+	}
+	if !review.Approved {
+		return nil, errors.NewAppError(errors.ErrorTypeInterruption, "migration cancelled during change review", nil)
+	}
+
+	approved := make(map[string]bool, len(review.SelectedChanges))
+	for _, change := range review.SelectedChanges {
+		approved[change.Title] = true
+	}
+	return approved, nil
+}
+
+// reviewChange describes a migration statement for the change review dialog
+func reviewChange(stmt migration.MigrationStatement, selected bool) *display.Change {
+	change := &display.Change{
+		Type:          display.ReviewChangeTypeModify,
+		Title:         stmt.Description,
+		Description:   stmt.SQL,
+		IsDestructive: stmt.IsDestructive,
+		IsSelected:    selected,
+		Category:      stmt.TableName,
+		Impact:        display.ChangeImpactLow,
+	}
+
+	switch stmt.Type {
+	case migration.StatementTypeCreateTable, migration.StatementTypeAddColumn,
+		migration.StatementTypeCreateIndex, migration.StatementTypeAddConstraint:
+		change.Type = display.ReviewChangeTypeAdd
+	case migration.StatementTypeDropTable, migration.StatementTypeDropColumn,
+		migration.StatementTypeDropIndex, migration.StatementTypeDropConstraint:
+		change.Type = display.ReviewChangeTypeRemove
+	case migration.StatementTypeRenameIndex:
+		change.Type = display.ReviewChangeTypeRename
+	}
+
+	switch {
+	case stmt.IsDestructive:
+		change.Impact = display.ChangeImpactCritical
+	case stmt.Type == migration.StatementTypeModifyColumn:
+		change.Impact = display.ChangeImpactMedium
+	}
+
+	if stmt.Algorithm != "" {
+		change.Details = append(change.Details, fmt.Sprintf("ALGORITHM=%s, LOCK=%s", stmt.Algorithm, stmt.Lock))
+	}
+	return change
+}
+
+// exportRollback writes the rollback plan of the migration to the configured file as a SQL script
+func (e *Executor) exportRollback(migrationPlan *migration.MigrationPlan) error {
+	if migrationPlan.Rollback == nil {
+		return errors.NewAppError(errors.ErrorTypeValidation, "migration plan has no rollback plan", nil)
+	}
+
+	title := fmt.Sprintf("Rollback for migration plan %s", migrationPlan.Hash())
+	script := migrationPlan.Rollback.SQLScript(title)
+	if err := os.WriteFile(e.config.RollbackFile, []byte(script), 0644); err != nil {
+		return errors.NewAppError(errors.ErrorTypeValidation, fmt.Sprintf("failed to write rollback file %s", e.config.RollbackFile), err)
+	}
+
+	e.logger.WithFields(map[string]interface{}{
+		"file":       e.config.RollbackFile,
+		"statements": len(migrationPlan.Rollback.Statements),
+	}).Info("Rollback plan exported")
+
+	return nil
+}
+
+// emitMigrations writes the plan as migration files for the configured framework
+func (e *Executor) emitMigrations(migrationPlan *migration.MigrationPlan) ([]string, error) {
+	emitted, err := migrationfiles.NewEmitter(e.config.EmitMigrations).Emit(migrationPlan)
+	if err != nil {
+		return nil, errors.NewAppError(errors.ErrorTypeValidation, "failed to emit migration files", err)
+	}
+
+	fields := map[string]interface{}{
+		"dir":    e.config.EmitMigrations.Dir,
+		"format": string(e.config.EmitMigrations.Format),
+		"files":  len(emitted.Files),
+	}
+	if emitted.AlreadyEmitted {
+		e.logger.WithFields(fields).Info("Migration files for this plan already exist")
+	} else {
+		e.logger.WithFields(fields).Info("Migration files written")
+	}
+
+	return emitted.Files, nil
 }
 
 // executeMigration executes the migration plan on the target database
@@ -368,47 +1136,266 @@ func (e *Executor) executeMigration(ctx context.Context, targetDB *sql.DB, migra
 
 	e.logger.WithField("statements_count", len(migrationPlan.Statements)).Info("Executing migration")
 
-	// Extract SQL statements
-	sqlStatements := make([]string, len(migrationPlan.Statements))
-	for i, stmt := range migrationPlan.Statements {
-		sqlStatements[i] = stmt.SQL
+	delegator, err := e.newDelegator(targetDB)
+	if err != nil {
+		return err
+	}
+
+	total := len(migrationPlan.Statements)
+	planHash := migrationPlan.Hash()
+
+	// Pick up where an interrupted run of the same plan stopped
+	var jrnl *journal.Journal
+	applied := make([]bool, total)
+	if e.config.Journal.Enabled {
+		jrnl = journal.New(targetDB, e.config.Journal.TableName())
+		applied, err = e.prepareJournal(ctx, jrnl, targetDB, planHash, migrationPlan)
+		if err != nil {
+			return err
+		}
+	}
+	remaining := 0
+	for _, done := range applied {
+		if !done {
+			remaining++
+		}
+	}
+	if remaining == 0 {
+		e.logger.WithField("plan_hash", planHash).Info("Migration plan was already applied according to the journal")
+		if e.displayService != nil {
+			e.displayService.Info("All statements of this plan were already applied")
+		}
+		return nil
+	}
+
+	var checker *mdl.Checker
+	if e.config.MetadataLocks.Enabled {
+		checker = mdl.NewChecker(targetDB, e.config.MetadataLocks)
+	}
+
+	monitor, closeReplicas, err := e.connectReplicas(ctx, targetDB)
+	if err != nil {
+		return err
+	}
+	defer closeReplicas()
+
+	var loadMonitor *throttle.LoadMonitor
+	if e.config.Load.Enabled() {
+		if loadMonitor, err = throttle.NewLoadMonitor(targetDB, e.config.Load); err != nil {
+			return errors.NewAppError(errors.ErrorTypeValidation, err.Error(), err)
+		}
+	}
+
+	run := &statementRun{
+		targetDB:    targetDB,
+		plan:        migrationPlan,
+		planHash:    planHash,
+		journal:     jrnl,
+		delegator:   delegator,
+		checker:     checker,
+		monitor:     monitor,
+		loadMonitor: loadMonitor,
+	}
+
+	if e.config.Workers > 1 {
+		return e.executeParallel(ctx, run, applied, remaining)
 	}
 
 	// Create progress bar if display service is available
 	var progressBar *display.ProgressBar
 	if e.displayService != nil {
-		progressBar = e.displayService.NewProgressBar(len(sqlStatements), "Executing migration statements")
+		progressBar = e.displayService.NewProgressBar(total, "Executing migration statements")
 	}
 
 	// Execute statements one by one to show progress
-	for i, stmt := range sqlStatements {
-		if e.displayService != nil {
-			progressBar.Update(i, fmt.Sprintf("Executing statement %d/%d", i+1, len(sqlStatements)))
+	for i := 0; i < total; i++ {
+		if applied[i] {
+			continue
 		}
 
-		// Execute single statement with retry logic
-		err := e.retryHandler.Retry(ctx, func() error {
-			return e.dbService.ExecuteSQL(targetDB, []string{stmt})
-		})
-
+		lag, err := e.throttle(ctx, run, i, progressBar)
 		if err != nil {
-			if e.displayService != nil {
-				progressBar.Finish("Migration failed")
-				e.displayService.Error(fmt.Sprintf("Failed to execute statement %d: %s", i+1, stmt))
+			if progressBar != nil {
+				progressBar.Finish("Migration stopped")
 			}
-			return errors.WrapError(err, fmt.Sprintf("failed to execute migration statement %d", i+1))
+			return err
+		}
+
+		if progressBar != nil {
+			progressBar.Update(i, fmt.Sprintf("Executing statement %d/%d%s", i+1, total, lag))
+		}
+
+		if err := e.runStatement(ctx, run, i, progressBar); err != nil {
+			return err
 		}
 	}
 
 	if e.displayService != nil {
 		progressBar.Finish("Migration executed successfully")
-		e.displayService.Success(fmt.Sprintf("Successfully executed %d migration statements", len(sqlStatements)))
+		e.displayService.Success(fmt.Sprintf("Successfully executed %d migration statements", remaining))
 	}
 
 	e.logger.Info("Migration executed successfully")
 	return nil
 }
 
+// statementRun holds what executing the statements of a plan needs
+type statementRun struct {
+	targetDB    *sql.DB
+	plan        *migration.MigrationPlan
+	planHash    string
+	journal     *journal.Journal
+	delegator   *osc.Delegator
+	checker     *mdl.Checker
+	monitor     *throttle.Monitor
+	loadMonitor *throttle.LoadMonitor
+}
+
+// throttle waits before a statement while replicas lag or the target is busy. It returns the
+// replica lag to show next to the progress.
+func (e *Executor) throttle(ctx context.Context, run *statementRun, index int, progressBar *display.ProgressBar) (string, error) {
+	total := len(run.plan.Statements)
+
+	lag := ""
+	if run.monitor != nil {
+		summary, err := e.waitForReplicas(ctx, run.monitor, index, total, progressBar)
+		if err != nil {
+			return "", err
+		}
+		lag = fmt.Sprintf(" (replica lag: %s)", summary)
+	}
+
+	if run.loadMonitor != nil {
+		if err := e.waitForLoad(ctx, run.loadMonitor, index, total, progressBar); err != nil {
+			return "", err
+		}
+	}
+
+	return lag, nil
+}
+
+// runStatement executes one statement of the plan, directly or through the online schema change
+// tool, and records it in the journal
+func (e *Executor) runStatement(ctx context.Context, run *statementRun, index int, progressBar *display.ProgressBar) error {
+	stmt := run.plan.Statements[index]
+	total := len(run.plan.Statements)
+
+	delegated := run.delegator != nil && run.delegator.ShouldDelegate(stmt)
+	if !delegated {
+		deadline := time.Now().Add(e.config.MetadataLocks.Timeout())
+		if err := e.awaitMetadataLocks(ctx, run.checker, stmt, index, deadline); err != nil {
+			if progressBar != nil {
+				progressBar.Finish("Migration blocked")
+			}
+			return err
+		}
+	}
+
+	if run.journal != nil {
+		if err := run.journal.Start(ctx, run.planHash, index); err != nil {
+			return errors.WrapError(err, "failed to update the execution journal")
+		}
+	}
+
+	var err error
+	if delegated {
+		err = e.runOnlineSchemaChange(ctx, run.delegator, stmt, index, total, progressBar)
+	} else {
+		err = e.executeStatement(ctx, run.targetDB, run.checker, stmt, index)
+	}
+
+	if run.journal != nil {
+		if err != nil {
+			if journalErr := run.journal.Fail(ctx, run.planHash, index, err); journalErr != nil {
+				e.logger.WithField("error", journalErr.Error()).Warn("Failed to record the failed statement in the execution journal")
+			}
+		} else if journalErr := run.journal.Complete(ctx, run.planHash, index); journalErr != nil {
+			return errors.WrapError(journalErr, fmt.Sprintf("statement %d was applied but could not be recorded in the execution journal", index+1))
+		}
+	}
+
+	if err != nil {
+		if progressBar != nil {
+			progressBar.Finish("Migration failed")
+		}
+		if e.displayService != nil {
+			e.displayService.Error(fmt.Sprintf("Failed to execute statement %d: %s", index+1, stmt.SQL))
+		}
+
+		// Never retry without the clause: that would silently fall back to a blocking COPY
+		if stmt.Algorithm != "" && errors.IsOnlineDDLRejected(err) {
+			return errors.WrapError(err, fmt.Sprintf("server rejected ALGORITHM=%s for migration statement %d; aborting instead of falling back to COPY", stmt.Algorithm, index+1))
+		}
+
+		return errors.WrapError(err, fmt.Sprintf("failed to execute migration statement %d", index+1))
+	}
+
+	return nil
+}
+
+// newDelegator creates the online schema change delegator, or returns nil if no tool is configured
+func (e *Executor) newDelegator(targetDB *sql.DB) (*osc.Delegator, error) {
+	if !e.config.OnlineSchemaChange.Enabled() {
+		return nil, nil
+	}
+
+	sizes, err := e.schemaService.GetTableSizes(targetDB, e.config.TargetDB.Database)
+	if err != nil {
+		return nil, errors.WrapError(err, "failed to retrieve table sizes for online schema change")
+	}
+
+	return osc.NewDelegator(e.config.OnlineSchemaChange, sizes), nil
+}
+
+// runOnlineSchemaChange applies a statement through the configured external tool
+func (e *Executor) runOnlineSchemaChange(ctx context.Context, delegator *osc.Delegator, stmt migration.MigrationStatement, index, total int, progressBar *display.ProgressBar) error {
+	command, err := delegator.BuildCommand(stmt, e.config.TargetDB)
+	if err != nil {
+		return errors.NewAppError(errors.ErrorTypeValidation, err.Error(), err)
+	}
+
+	e.logger.WithFields(map[string]interface{}{
+		"table": command.Table,
+		"tool":  string(command.Tool),
+	}).Info("Delegating statement to online schema change tool")
+
+	err = e.oscRunner.Run(ctx, command, func(percent float64, line string) {
+		if progressBar != nil {
+			progressBar.Update(index, fmt.Sprintf("Statement %d/%d via %s: %.1f%%", index+1, total, command.Tool, percent))
+		}
+	})
+	if err != nil {
+		return errors.NewAppError(errors.ErrorTypeSQL, fmt.Sprintf("online schema change of table %s failed", command.Table), err)
+	}
+
+	return nil
+}
+
+// buildMigrationCommands lists the direct SQL and external tool commands that would apply the plan
+func (e *Executor) buildMigrationCommands(targetDB *sql.DB, migrationPlan *migration.MigrationPlan) ([]string, error) {
+	delegator, err := e.newDelegator(targetDB)
+	if err != nil {
+		return nil, err
+	}
+
+	commands := make([]string, 0, len(migrationPlan.Statements))
+	for _, stmt := range migrationPlan.Statements {
+		entry := strings.Join(stmt.ExecutableSQL(), ";\n") + ";"
+		if delegator != nil && delegator.ShouldDelegate(stmt) {
+			command, err := delegator.BuildCommand(stmt, e.config.TargetDB)
+			if err != nil {
+				return nil, errors.NewAppError(errors.ErrorTypeValidation, err.Error(), err)
+			}
+			entry = command.String()
+		}
+		commands = append(commands, entry)
+	}
+
+	e.logger.WithField("commands_count", len(commands)).Info("Built migration commands without executing them")
+
+	return commands, nil
+}
+
 // GetLogger returns the logger instance
 func (e *Executor) GetLogger() *logging.Logger {
 	return e.logger
@@ -456,11 +1443,13 @@ func (e *Executor) HandleError(err error) error {
 
 // ValidateConfig validates the execution configuration
 func (e *Executor) ValidateConfig() error {
-	if e.config.SourceDB.Host == "" {
-		return errors.NewAppError(errors.ErrorTypeValidation, "source database host is required", nil)
-	}
-	if e.config.SourceDB.Database == "" {
-		return errors.NewAppError(errors.ErrorTypeValidation, "source database name is required", nil)
+	if !e.config.TargetOnly {
+		if e.config.SourceDB.Host == "" {
+			return errors.NewAppError(errors.ErrorTypeValidation, "source database host is required", nil)
+		}
+		if e.config.SourceDB.Database == "" {
+			return errors.NewAppError(errors.ErrorTypeValidation, "source database name is required", nil)
+		}
 	}
 	if e.config.TargetDB.Host == "" {
 		return errors.NewAppError(errors.ErrorTypeValidation, "target database host is required", nil)
@@ -468,6 +1457,54 @@ func (e *Executor) ValidateConfig() error {
 	if e.config.TargetDB.Database == "" {
 		return errors.NewAppError(errors.ErrorTypeValidation, "target database name is required", nil)
 	}
+	if err := e.config.OnlineSchemaChange.Validate(); err != nil {
+		return errors.NewAppError(errors.ErrorTypeValidation, err.Error(), err)
+	}
+	if err := e.config.EmitMigrations.Validate(); err != nil {
+		return errors.NewAppError(errors.ErrorTypeValidation, err.Error(), err)
+	}
+	if err := e.config.SafeDrop.Validate(); err != nil {
+		return errors.NewAppError(errors.ErrorTypeValidation, err.Error(), err)
+	}
+	if err := e.config.Policy.Validate(); err != nil {
+		return errors.NewAppError(errors.ErrorTypeValidation, err.Error(), err)
+	}
+	if e.config.Workers < 0 {
+		return errors.NewAppError(errors.ErrorTypeValidation, "workers cannot be negative", nil)
+	}
+	if err := e.config.Load.Validate(); err != nil {
+		return errors.NewAppError(errors.ErrorTypeValidation, err.Error(), err)
+	}
+	backupConfig := e.backupConfig()
+	if err := backupConfig.Validate(); err != nil {
+		return errors.NewAppError(errors.ErrorTypeValidation, err.Error(), err)
+	}
+
+	if err := e.config.Replication.Validate(); err != nil {
+		return errors.NewAppError(errors.ErrorTypeValidation, err.Error(), err)
+	}
+
+	if err := e.config.MetadataLocks.Validate(); err != nil {
+		return errors.NewAppError(errors.ErrorTypeValidation, err.Error(), err)
+	}
+
+	if err := e.config.Lock.Validate(); err != nil {
+		return errors.NewAppError(errors.ErrorTypeValidation, err.Error(), err)
+	}
+
+	if err := e.config.History.Validate(); err != nil {
+		return errors.NewAppError(errors.ErrorTypeValidation, err.Error(), err)
+	}
+
+	if err := e.config.Journal.Validate(); err != nil {
+		return errors.NewAppError(errors.ErrorTypeValidation, err.Error(), err)
+	}
+	if err := e.config.Selection.Validate(); err != nil {
+		return errors.NewAppError(errors.ErrorTypeValidation, err.Error(), err)
+	}
+	if e.config.Selection.Review && e.config.AutoApprove {
+		return errors.NewAppError(errors.ErrorTypeValidation, "change review cannot be combined with auto-approve", nil)
+	}
 
 	e.logger.Debug("Configuration validation passed")
 	return nil
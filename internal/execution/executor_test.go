@@ -1,13 +1,38 @@
 package execution
 
 import (
+	"bytes"
+	"context"
+	"database/sql"
 	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"strings"
 	"testing"
 	"time"
 
+	"mysql-schema-sync/internal/backup"
+	"mysql-schema-sync/internal/config"
 	"mysql-schema-sync/internal/database"
+	"mysql-schema-sync/internal/display"
 	appErrors "mysql-schema-sync/internal/errors"
+	"mysql-schema-sync/internal/history"
+	"mysql-schema-sync/internal/journal"
+	"mysql-schema-sync/internal/lock"
 	"mysql-schema-sync/internal/logging"
+	"mysql-schema-sync/internal/mdl"
+	"mysql-schema-sync/internal/migration"
+	"mysql-schema-sync/internal/osc"
+	"mysql-schema-sync/internal/planfile"
+	"mysql-schema-sync/internal/policy"
+	"mysql-schema-sync/internal/rollout"
+	"mysql-schema-sync/internal/schema"
+	"mysql-schema-sync/internal/throttle"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/go-sql-driver/mysql"
 )
 
 func TestNewExecutor(t *testing.T) {
@@ -93,6 +118,17 @@ func TestExecutor_ValidateConfig(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		{
+			name: "target only without source",
+			config: ExecutionConfig{
+				TargetDB: database.DatabaseConfig{
+					Host:     "localhost",
+					Database: "target_db",
+				},
+				TargetOnly: true,
+			},
+			wantErr: false,
+		},
 		{
 			name: "missing source host",
 			config: ExecutionConfig{
@@ -165,6 +201,43 @@ func TestExecutor_ValidateConfig(t *testing.T) {
 			wantErr: true,
 			errType: appErrors.ErrorTypeValidation,
 		},
+		{
+			name: "unsupported online schema change tool",
+			config: ExecutionConfig{
+				SourceDB: database.DatabaseConfig{
+					Host:     "localhost",
+					Database: "source_db",
+				},
+				TargetDB: database.DatabaseConfig{
+					Host:     "localhost",
+					Database: "target_db",
+				},
+				OnlineSchemaChange: osc.Config{Tool: osc.Tool("lhm")},
+			},
+			wantErr: true,
+			errType: appErrors.ErrorTypeValidation,
+		},
+		{
+			name: "invalid selector",
+			config: ExecutionConfig{
+				SourceDB:  database.DatabaseConfig{Host: "localhost", Database: "source_db"},
+				TargetDB:  database.DatabaseConfig{Host: "localhost", Database: "target_db"},
+				Selection: migration.SelectionConfig{Only: []string{"users:DROP_EVERYTHING"}},
+			},
+			wantErr: true,
+			errType: appErrors.ErrorTypeValidation,
+		},
+		{
+			name: "review with auto-approve",
+			config: ExecutionConfig{
+				SourceDB:    database.DatabaseConfig{Host: "localhost", Database: "source_db"},
+				TargetDB:    database.DatabaseConfig{Host: "localhost", Database: "target_db"},
+				AutoApprove: true,
+				Selection:   migration.SelectionConfig{Review: true},
+			},
+			wantErr: true,
+			errType: appErrors.ErrorTypeValidation,
+		},
 	}
 
 	for _, tt := range tests {
@@ -195,6 +268,976 @@ func TestExecutor_ValidateConfig(t *testing.T) {
 	}
 }
 
+func TestExecutor_BuildMigrationCommands(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock database: %v", err)
+	}
+	defer db.Close()
+
+	mock.ExpectQuery("SELECT TABLE_NAME, COALESCE").
+		WithArgs("target_db").
+		WillReturnRows(sqlmock.NewRows([]string{"TABLE_NAME", "TABLE_ROWS", "DATA_LENGTH", "INDEX_LENGTH"}).
+			AddRow("events", 50000000, int64(8)*1024*1024*1024, 0).
+			AddRow("users", 100, 16384, 0))
+
+	executor, err := NewExecutor(ExecutionConfig{
+		SourceDB: database.DatabaseConfig{Host: "localhost", Database: "source_db"},
+		TargetDB: database.DatabaseConfig{Host: "localhost", Port: 3306, Username: "user", Password: "pass", Database: "target_db"},
+		OnlineSchemaChange: osc.Config{
+			Tool:        osc.ToolGhost,
+			ThresholdMB: 1024,
+			PrintOnly:   true,
+		},
+	})
+	if err != nil {
+		t.Fatalf("NewExecutor() error = %v", err)
+	}
+
+	plan := migration.NewMigrationPlan()
+	for _, stmt := range []migration.MigrationStatement{
+		{SQL: "ALTER TABLE `events` ADD COLUMN `note` TEXT NULL", Type: migration.StatementTypeAddColumn, Description: "Add column note to table events", TableName: "events"},
+		{SQL: "ALTER TABLE `users` ADD COLUMN `note` TEXT NULL", Type: migration.StatementTypeAddColumn, Description: "Add column note to table users", TableName: "users"},
+	} {
+		if err := plan.AddStatement(stmt); err != nil {
+			t.Fatalf("AddStatement() error = %v", err)
+		}
+	}
+
+	commands, err := executor.buildMigrationCommands(db, plan)
+	if err != nil {
+		t.Fatalf("buildMigrationCommands() error = %v", err)
+	}
+
+	if len(commands) != 2 {
+		t.Fatalf("Expected 2 commands, got %d", len(commands))
+	}
+
+	if !strings.HasPrefix(commands[0], "gh-ost ") || !strings.Contains(commands[0], "--table=events") {
+		t.Errorf("Expected large table change to be delegated to gh-ost, got %s", commands[0])
+	}
+
+	if commands[1] != "ALTER TABLE `users` ADD COLUMN `note` TEXT NULL;" {
+		t.Errorf("Expected small table change to stay direct SQL, got %s", commands[1])
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
+func TestExecutor_ConfigureDialect(t *testing.T) {
+	check := &schema.Constraint{Name: "chk_price", TableName: "products", Type: schema.ConstraintTypeCheck, CheckExpression: "price >= 0"}
+
+	tests := []struct {
+		name         string
+		onlineDDL    bool
+		version      string
+		versionErr   error
+		wantErr      bool
+		expectedDrop string
+	}{
+		{
+			name:         "MariaDB dialect",
+			version:      "10.6.12-MariaDB-log",
+			expectedDrop: "ALTER TABLE `products` DROP CONSTRAINT `chk_price`",
+		},
+		{
+			name:         "version unavailable falls back to generic dialect",
+			versionErr:   errors.New("access denied"),
+			expectedDrop: "ALTER TABLE `products` DROP CHECK `chk_price`",
+		},
+		{
+			name:       "version unavailable with online DDL",
+			onlineDDL:  true,
+			versionErr: errors.New("access denied"),
+			wantErr:    true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			if err != nil {
+				t.Fatalf("Failed to create mock database: %v", err)
+			}
+			defer db.Close()
+
+			query := mock.ExpectQuery("SELECT VERSION")
+			if tt.versionErr != nil {
+				query.WillReturnError(tt.versionErr)
+			} else {
+				query.WillReturnRows(sqlmock.NewRows([]string{"VERSION()"}).AddRow(tt.version))
+			}
+
+			executor, err := NewExecutor(ExecutionConfig{
+				SourceDB:  database.DatabaseConfig{Host: "localhost", Database: "source_db"},
+				TargetDB:  database.DatabaseConfig{Host: "localhost", Database: "target_db"},
+				OnlineDDL: tt.onlineDDL,
+			})
+			if err != nil {
+				t.Fatalf("NewExecutor() error = %v", err)
+			}
+
+			err = executor.configureDialect(db)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("configureDialect() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			sql, err := executor.migrationService.GenerateDropConstraintSQL(check)
+			if err != nil {
+				t.Fatalf("GenerateDropConstraintSQL() error = %v", err)
+			}
+			if sql != tt.expectedDrop {
+				t.Errorf("Expected %s, got %s", tt.expectedDrop, sql)
+			}
+		})
+	}
+}
+
+func TestExecutor_ExportRollback(t *testing.T) {
+	rollbackFile := filepath.Join(t.TempDir(), "rollback.sql")
+
+	executor, err := NewExecutor(ExecutionConfig{
+		SourceDB:     database.DatabaseConfig{Host: "localhost", Database: "source_db"},
+		TargetDB:     database.DatabaseConfig{Host: "localhost", Database: "target_db"},
+		RollbackFile: rollbackFile,
+	})
+	if err != nil {
+		t.Fatalf("NewExecutor() error = %v", err)
+	}
+
+	plan := migration.NewMigrationPlan()
+	if err := executor.exportRollback(plan); err == nil {
+		t.Error("Expected error for plan without rollback")
+	}
+
+	plan.Rollback = migration.NewMigrationPlan()
+	if err := plan.Rollback.AddStatement(migration.MigrationStatement{
+		SQL:         "ALTER TABLE `users` ADD COLUMN `legacy` INT NULL",
+		Type:        migration.StatementTypeAddColumn,
+		Description: "Add column legacy to table users",
+		TableName:   "users",
+		DataLoss:    true,
+	}); err != nil {
+		t.Fatalf("AddStatement() error = %v", err)
+	}
+
+	if err := executor.exportRollback(plan); err != nil {
+		t.Fatalf("exportRollback() error = %v", err)
+	}
+
+	content, err := os.ReadFile(rollbackFile)
+	if err != nil {
+		t.Fatalf("Failed to read rollback file: %v", err)
+	}
+
+	if !strings.Contains(string(content), "ALTER TABLE `users` ADD COLUMN `legacy` INT NULL;") ||
+		!strings.Contains(string(content), "not restored") {
+		t.Errorf("Unexpected rollback file content:\n%s", content)
+	}
+}
+
+func TestExecutor_CheckPolicy(t *testing.T) {
+	dir := t.TempDir()
+	policyFile := filepath.Join(dir, "policy.yaml")
+	if err := os.WriteFile(policyFile, []byte("rules:\n  - {name: no-drops, type: no_drop_table, severity: deny}\n"), 0644); err != nil {
+		t.Fatalf("Failed to write policy: %v", err)
+	}
+
+	plan := &migration.MigrationPlan{Statements: []migration.MigrationStatement{
+		{SQL: "DROP TABLE `sessions`", Type: migration.StatementTypeDropTable, TableName: "sessions", IsDestructive: true},
+	}}
+
+	newExecutor := func(config policy.Config) *Executor {
+		executor, err := NewExecutor(ExecutionConfig{
+			SourceDB: database.DatabaseConfig{Host: "localhost", Database: "source_db"},
+			TargetDB: database.DatabaseConfig{Host: "localhost", Database: "target_db"},
+			Policy:   config,
+		})
+		if err != nil {
+			t.Fatalf("NewExecutor() error = %v", err)
+		}
+		return executor
+	}
+
+	t.Run("denied", func(t *testing.T) {
+		executor := newExecutor(policy.Config{File: policyFile})
+		result := &ExecutionResult{}
+
+		validation, err := executor.checkPolicy(nil, &schema.SchemaDiff{}, plan, result)
+		if err == nil {
+			t.Fatal("Expected the policy to deny the plan")
+		}
+		if validation == nil || len(validation.Errors) != 1 {
+			t.Fatalf("Expected one policy violation, got %+v", validation)
+		}
+		if !strings.Contains(appErrors.FormatUserError(err), "[no-drops] Plan drops table 'sessions'") {
+			t.Errorf("Unexpected error: %s", appErrors.FormatUserError(err))
+		}
+	})
+
+	t.Run("overridden", func(t *testing.T) {
+		auditLog := filepath.Join(dir, "audit.log")
+		executor := newExecutor(policy.Config{File: policyFile, AuditLog: auditLog, Override: "approved by DBA"})
+		result := &ExecutionResult{}
+
+		if _, err := executor.checkPolicy(nil, &schema.SchemaDiff{}, plan, result); err != nil {
+			t.Fatalf("Expected the override to allow the plan, got %v", err)
+		}
+		if result.PolicyOverride == nil || result.PolicyOverride.Reason != "approved by DBA" {
+			t.Errorf("Expected the override to be recorded, got %+v", result.PolicyOverride)
+		}
+
+		data, err := os.ReadFile(auditLog)
+		if err != nil {
+			t.Fatalf("Expected an audit log entry: %v", err)
+		}
+		if !strings.Contains(string(data), "approved by DBA") {
+			t.Errorf("Unexpected audit log: %s", data)
+		}
+	})
+
+	t.Run("invalid policy file", func(t *testing.T) {
+		_, err := NewExecutor(ExecutionConfig{Policy: policy.Config{File: filepath.Join(dir, "missing.yaml")}})
+		if err == nil {
+			t.Error("Expected NewExecutor() to fail for a missing policy file")
+		}
+	})
+}
+
+func TestExecutor_CheckTargetData(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock database: %v", err)
+	}
+	defer db.Close()
+
+	mock.ExpectQuery("SELECT COUNT\\(\\*\\) FROM `users` WHERE `email` IS NULL").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(2))
+
+	executor, err := NewExecutor(ExecutionConfig{
+		SourceDB: database.DatabaseConfig{Host: "localhost", Database: "source_db"},
+		TargetDB: database.DatabaseConfig{Host: "localhost", Database: "target_db"},
+	})
+	if err != nil {
+		t.Fatalf("NewExecutor() error = %v", err)
+	}
+
+	target := schema.NewSchema("target_db")
+	users := schema.NewTable("users")
+	users.AddColumn(&schema.Column{Name: "email", DataType: "varchar(255)", IsNullable: true})
+	target.AddTable(users)
+
+	diff := &schema.SchemaDiff{
+		ModifiedTables: []*schema.TableDiff{{
+			TableName: "users",
+			ModifiedColumns: []*schema.ColumnDiff{{
+				ColumnName: "email",
+				OldColumn:  &schema.Column{Name: "email", DataType: "varchar(255)", IsNullable: true},
+				NewColumn:  &schema.Column{Name: "email", DataType: "varchar(255)", IsNullable: false},
+			}},
+		}},
+	}
+
+	validation, err := executor.checkTargetData(db, target, diff)
+	if err == nil {
+		t.Fatal("Expected pre-flight data checks to block the migration")
+	}
+	if validation == nil || len(validation.Errors) != 1 {
+		t.Fatalf("Expected one data violation, got %+v", validation)
+	}
+	if appErrors.GetErrorType(err) != appErrors.ErrorTypeValidation ||
+		!strings.Contains(appErrors.FormatUserError(err), "cannot be made NOT NULL: 2 rows contain NULL") {
+		t.Errorf("Unexpected error: %v (%s)", err, appErrors.FormatUserError(err))
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
+func TestAppliedStatements(t *testing.T) {
+	statements := []migration.MigrationStatement{
+		{Type: migration.StatementTypeCreateTable, Object: "table:customers", Description: "Create table customers"},
+		{Type: migration.StatementTypeAddColumn, Object: "column:orders.note", Description: "Add column note to table orders"},
+		{Type: migration.StatementTypeCreateIndex, Object: "index:orders.idx_note", Description: "Create index idx_note on orders"},
+	}
+
+	target := schema.NewSchema("target_db")
+	customers := schema.NewTable("customers")
+	customers.AddColumn(&schema.Column{Name: "id", DataType: "int"})
+	target.AddTable(customers)
+	orders := schema.NewTable("orders")
+	orders.AddColumn(&schema.Column{Name: "note", DataType: "text", IsNullable: true})
+	target.AddTable(orders)
+
+	entries := func(statuses ...journal.Status) []journal.Entry {
+		result := make([]journal.Entry, len(statuses))
+		for i, status := range statuses {
+			result[i] = journal.Entry{PlanHash: "abc", Index: i, Status: status}
+		}
+		return result
+	}
+
+	t.Run("interrupted statement that landed", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("Failed to create mock database: %v", err)
+		}
+		defer db.Close()
+
+		mock.ExpectExec("UPDATE `schema_sync_journal` SET `status` = \\?, `finished_at`").
+			WithArgs("completed", sqlmock.AnyArg(), nil, "abc", 1).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		applied, err := appliedStatements(context.Background(), journal.New(db, ""), "abc", statements,
+			entries(journal.StatusCompleted, journal.StatusRunning, journal.StatusPending), target)
+		if err != nil {
+			t.Fatalf("appliedStatements() error = %v", err)
+		}
+		if !reflect.DeepEqual(applied, []bool{true, true, false}) {
+			t.Errorf("Expected to resume at statement 2, got %v", applied)
+		}
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Errorf("Unfulfilled expectations: %v", err)
+		}
+	})
+
+	t.Run("failed statement", func(t *testing.T) {
+		applied, err := appliedStatements(context.Background(), nil, "abc", statements,
+			entries(journal.StatusCompleted, journal.StatusFailed, journal.StatusPending), target)
+		if err != nil {
+			t.Fatalf("appliedStatements() error = %v", err)
+		}
+		if !reflect.DeepEqual(applied, []bool{true, false, false}) {
+			t.Errorf("Expected to resume at statement 1, got %v", applied)
+		}
+	})
+
+	t.Run("statements completed out of order", func(t *testing.T) {
+		applied, err := appliedStatements(context.Background(), nil, "abc", statements,
+			entries(journal.StatusCompleted, journal.StatusCompleted, journal.StatusPending), target)
+		if err != nil {
+			t.Fatalf("appliedStatements() error = %v", err)
+		}
+		if !reflect.DeepEqual(applied, []bool{true, true, false}) {
+			t.Errorf("Expected only the pending statement to run, got %v", applied)
+		}
+
+		applied, err = appliedStatements(context.Background(), nil, "abc", statements,
+			entries(journal.StatusFailed, journal.StatusCompleted, journal.StatusPending), target)
+		if err != nil {
+			t.Fatalf("appliedStatements() error = %v", err)
+		}
+		if !reflect.DeepEqual(applied, []bool{false, true, false}) {
+			t.Errorf("Expected the completed statement after a failed one to be skipped, got %v", applied)
+		}
+	})
+
+	t.Run("completed change missing from target", func(t *testing.T) {
+		_, err := appliedStatements(context.Background(), nil, "abc", statements,
+			entries(journal.StatusCompleted, journal.StatusCompleted, journal.StatusCompleted), target)
+		if err == nil || !strings.Contains(appErrors.FormatUserError(err), "Create index idx_note on orders") {
+			t.Errorf("Expected the missing index to stop the resume, got %v", err)
+		}
+	})
+
+	t.Run("interrupted statement that cannot be verified", func(t *testing.T) {
+		modify := []migration.MigrationStatement{
+			{Type: migration.StatementTypeModifyColumn, Object: "column:orders.note", Description: "Modify column note"},
+		}
+		_, err := appliedStatements(context.Background(), nil, "abc", modify, entries(journal.StatusRunning), target)
+		if err == nil || appErrors.GetErrorType(err) != appErrors.ErrorTypeValidation {
+			t.Errorf("Expected an unverifiable statement to stop the resume, got %v", err)
+		}
+	})
+}
+
+func TestExecutor_PrepareJournalReappliesFinishedPlan(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock database: %v", err)
+	}
+	defer db.Close()
+
+	executor, err := NewExecutor(ExecutionConfig{
+		SourceDB: database.DatabaseConfig{Host: "localhost", Port: 3306, Database: "source_db"},
+		TargetDB: database.DatabaseConfig{Host: "localhost", Port: 3306, Database: "target_db"},
+		Journal:  journal.Config{Enabled: true},
+	})
+	if err != nil {
+		t.Fatalf("NewExecutor() error = %v", err)
+	}
+
+	plan := migration.NewMigrationPlan()
+	plan.AddStatement(migration.MigrationStatement{SQL: "ALTER TABLE `orders` ADD COLUMN `note` TEXT NULL",
+		Type: migration.StatementTypeAddColumn, Object: "column:orders.note", Description: "Add column note to table orders"})
+	plan.AddStatement(migration.MigrationStatement{SQL: "CREATE INDEX `idx_note` ON `orders` (`note`(32))",
+		Type: migration.StatementTypeCreateIndex, Object: "index:orders.idx_note", Description: "Create index idx_note on orders"})
+	hash := plan.Hash()
+
+	// The plan was applied and then reverted by hand, so the same plan comes up again
+	finished := func() *sqlmock.Rows {
+		started := time.Date(2026, 10, 18, 9, 0, 0, 0, time.UTC)
+		return sqlmock.NewRows([]string{"statement_index", "sql_text", "status", "started_at", "finished_at", "error"}).
+			AddRow(0, plan.Statements[0].SQL, "completed", started, started, "").
+			AddRow(1, plan.Statements[1].SQL, "completed", started, started, "")
+	}
+
+	mock.ExpectExec("CREATE TABLE IF NOT EXISTS `schema_sync_journal`").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery("FROM `schema_sync_journal` WHERE `plan_hash` = \\?").WithArgs(hash).WillReturnRows(finished())
+
+	resuming, err := executor.journaledRun(context.Background(), db, hash)
+	if err != nil {
+		t.Fatalf("journaledRun() error = %v", err)
+	}
+	if resuming {
+		t.Error("Expected a finished run not to be resumed")
+	}
+
+	mock.ExpectExec("CREATE TABLE IF NOT EXISTS `schema_sync_journal`").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery("FROM `schema_sync_journal` WHERE `plan_hash` = \\?").WithArgs(hash).WillReturnRows(finished())
+	mock.ExpectExec("DELETE FROM `schema_sync_journal` WHERE `plan_hash` = \\?").WithArgs(hash).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectQuery("GROUP BY `plan_hash`").
+		WillReturnRows(sqlmock.NewRows([]string{"plan_hash", "count", "completed", "started_at"}))
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT INTO `schema_sync_journal`").WithArgs(hash, 0, plan.Statements[0].SQL, "pending").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec("INSERT INTO `schema_sync_journal`").WithArgs(hash, 1, plan.Statements[1].SQL, "pending").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	applied, err := executor.prepareJournal(context.Background(), journal.New(db, ""), db, hash, plan)
+	if err != nil {
+		t.Fatalf("prepareJournal() error = %v", err)
+	}
+	if !reflect.DeepEqual(applied, []bool{false, false}) {
+		t.Errorf("Expected every statement to run again, got %v", applied)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
+func TestExecutor_RecordHistory(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock database: %v", err)
+	}
+	defer db.Close()
+
+	executor, err := NewExecutor(ExecutionConfig{
+		SourceDB:    database.DatabaseConfig{Host: "localhost", Port: 3306, Database: "source_db"},
+		TargetDB:    database.DatabaseConfig{Host: "localhost", Port: 3306, Database: "target_db"},
+		History:     history.Config{Enabled: true},
+		ToolVersion: "1.4.0",
+	})
+	if err != nil {
+		t.Fatalf("NewExecutor() error = %v", err)
+	}
+
+	plan := migration.NewMigrationPlan()
+	plan.AddStatement(migration.MigrationStatement{SQL: "DROP TABLE `old`", Type: migration.StatementTypeDropTable, Description: "Drop table old"})
+
+	mock.ExpectExec("CREATE TABLE IF NOT EXISTS `schema_sync_history`").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("INSERT INTO `schema_sync_history`").
+		WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), history.CurrentUser(), sqlmock.AnyArg(), "1.4.0", "localhost:3306/source_db",
+			plan.Hash(), 1, "backup-20240501-120000-abcd1234", "failed", "table is locked", sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+
+	executor.recordHistory(context.Background(), db, plan, databaseIdentity(executor.config.SourceDB), "backup-20240501-120000-abcd1234",
+		time.Now(), errors.New("table is locked"))
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
+func TestExecutor_AcquireLock(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock database: %v", err)
+	}
+	defer db.Close()
+
+	executor, err := NewExecutor(ExecutionConfig{
+		SourceDB: database.DatabaseConfig{Host: "localhost", Database: "source_db"},
+		TargetDB: database.DatabaseConfig{Host: "localhost", Database: "target_db"},
+		Lock:     lock.Config{Enabled: true, WaitTimeout: 5 * time.Second},
+	})
+	if err != nil {
+		t.Fatalf("NewExecutor() error = %v", err)
+	}
+
+	mock.ExpectQuery("SELECT GET_LOCK").
+		WithArgs("mysql-schema-sync:target_db", int64(5)).
+		WillReturnRows(sqlmock.NewRows([]string{"lock"}).AddRow(0))
+	mock.ExpectQuery("SELECT IS_USED_LOCK").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(42))
+	mock.ExpectQuery("FROM information_schema.PROCESSLIST").
+		WillReturnRows(sqlmock.NewRows([]string{"USER", "HOST", "TIME"}).AddRow("ci", "ci-1:5000", 30))
+	mock.ExpectQuery("FROM performance_schema.user_variables_by_thread").
+		WillReturnRows(sqlmock.NewRows([]string{"VARIABLE_VALUE"}))
+
+	_, err = executor.acquireLock(context.Background(), db)
+	if appErrors.GetErrorType(err) != appErrors.ErrorTypeTimeout {
+		t.Fatalf("Expected a timeout error, got %v", err)
+	}
+	message := appErrors.FormatUserError(err)
+	if !strings.Contains(message, "connection 42 (ci@ci-1:5000)") || !strings.Contains(message, "--force-unlock") {
+		t.Errorf("Expected the error to name the holder and the escape hatch, got %q", message)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
+func TestExecutor_AwaitMetadataLocks(t *testing.T) {
+	metadataLockPollInterval = time.Millisecond
+	defer func() { metadataLockPollInterval = 2 * time.Second }()
+
+	stmt := migration.MigrationStatement{Type: migration.StatementTypeAddColumn, TableName: "orders", Description: "Add column note to table orders"}
+	transactions := []string{"trx_mysql_thread_id", "USER", "HOST", "age", "trx_state", "trx_query"}
+	locks := []string{"PROCESSLIST_ID", "PROCESSLIST_USER", "PROCESSLIST_HOST", "LOCK_TYPE", "PROCESSLIST_INFO"}
+
+	tests := []struct {
+		name    string
+		action  mdl.Action
+		cleared bool
+		wantErr bool
+	}{
+		{"abort reports blockers", mdl.ActionAbort, false, true},
+		{"wait until cleared", mdl.ActionWait, true, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			if err != nil {
+				t.Fatalf("Failed to create mock database: %v", err)
+			}
+			defer db.Close()
+
+			config := mdl.Config{Enabled: true, OnBlocked: tt.action}
+			executor, err := NewExecutor(ExecutionConfig{
+				SourceDB:      database.DatabaseConfig{Host: "localhost", Database: "source_db"},
+				TargetDB:      database.DatabaseConfig{Host: "localhost", Database: "target_db"},
+				MetadataLocks: config,
+			})
+			if err != nil {
+				t.Fatalf("NewExecutor() error = %v", err)
+			}
+
+			mock.ExpectQuery("FROM information_schema.innodb_trx").
+				WillReturnRows(sqlmock.NewRows(transactions).AddRow(11, "app", "app-1:4000", 900, "RUNNING", ""))
+			mock.ExpectQuery("FROM performance_schema.metadata_locks").
+				WithArgs("target_db", "orders").
+				WillReturnRows(sqlmock.NewRows(locks).AddRow(11, "app", "app-1:4000", "SHARED_READ", ""))
+			if tt.cleared {
+				mock.ExpectQuery("FROM information_schema.innodb_trx").
+					WillReturnRows(sqlmock.NewRows(transactions))
+				mock.ExpectQuery("FROM performance_schema.metadata_locks").
+					WillReturnRows(sqlmock.NewRows(locks))
+			}
+
+			err = executor.awaitMetadataLocks(context.Background(), mdl.NewChecker(db, config), stmt, 2, time.Now().Add(time.Minute))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("awaitMetadataLocks() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				message := appErrors.FormatUserError(err)
+				if !strings.Contains(message, "Statement 3") || !strings.Contains(message, "connection 11 (app@app-1:4000)") {
+					t.Errorf("Expected a report of the blocking session, got %q", message)
+				}
+			}
+
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("Unfulfilled expectations: %v", err)
+			}
+		})
+	}
+}
+
+func TestExecutor_WaitForReplicas(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock database: %v", err)
+	}
+	defer db.Close()
+
+	config := throttle.ReplicationConfig{Replicas: []throttle.Replica{{Host: "replica1", Port: 3306}}, CheckInterval: time.Millisecond}
+	executor, err := NewExecutor(ExecutionConfig{
+		SourceDB:    database.DatabaseConfig{Host: "localhost", Database: "source_db"},
+		TargetDB:    database.DatabaseConfig{Host: "localhost", Database: "target_db"},
+		Replication: config,
+	})
+	if err != nil {
+		t.Fatalf("NewExecutor() error = %v", err)
+	}
+
+	columns := []string{"Replica_IO_Running", "Replica_SQL_Running", "Seconds_Behind_Source", "Last_SQL_Errno", "Last_SQL_Error"}
+	mock.ExpectQuery("SHOW REPLICA STATUS").
+		WillReturnRows(sqlmock.NewRows(columns).AddRow("Yes", "Yes", 3, 0, ""))
+	mock.ExpectQuery("SHOW REPLICA STATUS").
+		WillReturnRows(sqlmock.NewRows(columns).AddRow("Yes", "No", nil, 1062, "Duplicate entry '1' for key 'PRIMARY'"))
+
+	monitor := throttle.NewMonitor(config, map[string]*sql.DB{"replica1:3306": db})
+
+	summary, err := executor.waitForReplicas(context.Background(), monitor, 0, 2, nil)
+	if err != nil {
+		t.Fatalf("waitForReplicas() error = %v", err)
+	}
+	if summary != "replica1:3306 3s behind" {
+		t.Errorf("Unexpected lag summary %q", summary)
+	}
+
+	_, err = executor.waitForReplicas(context.Background(), monitor, 1, 2, nil)
+	if appErrors.GetErrorType(err) != appErrors.ErrorTypeSQL ||
+		!strings.Contains(appErrors.FormatUserError(err), "stopped before statement 2 because replication broke") {
+		t.Errorf("Expected a broken replica to stop the migration, got %v", err)
+	}
+}
+
+func TestExecutor_WaitForLoad(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock database: %v", err)
+	}
+	defer db.Close()
+
+	config := throttle.LoadConfig{MaxLoad: "Threads_running=25", CriticalLoad: "Threads_running=100", CheckInterval: time.Millisecond}
+	executor, err := NewExecutor(ExecutionConfig{
+		SourceDB: database.DatabaseConfig{Host: "localhost", Database: "source_db"},
+		TargetDB: database.DatabaseConfig{Host: "localhost", Database: "target_db"},
+		Load:     config,
+	})
+	if err != nil {
+		t.Fatalf("NewExecutor() error = %v", err)
+	}
+
+	columns := []string{"VARIABLE_NAME", "VARIABLE_VALUE"}
+	mock.ExpectQuery("performance_schema.global_status").WillReturnRows(sqlmock.NewRows(columns).AddRow("Threads_running", "30"))
+	mock.ExpectQuery("performance_schema.global_status").WillReturnRows(sqlmock.NewRows(columns).AddRow("Threads_running", "5"))
+	mock.ExpectQuery("performance_schema.global_status").WillReturnRows(sqlmock.NewRows(columns).AddRow("Threads_running", "120"))
+
+	monitor, err := throttle.NewLoadMonitor(db, config)
+	if err != nil {
+		t.Fatalf("NewLoadMonitor() error = %v", err)
+	}
+
+	if err := executor.waitForLoad(context.Background(), monitor, 0, 2, nil); err != nil {
+		t.Fatalf("waitForLoad() error = %v", err)
+	}
+
+	err = executor.waitForLoad(context.Background(), monitor, 1, 2, nil)
+	if appErrors.GetErrorType(err) != appErrors.ErrorTypeTimeout ||
+		!strings.Contains(appErrors.FormatUserError(err), "stopped before statement 2 because the target reached critical load") {
+		t.Errorf("Expected critical load to stop the migration, got %v", err)
+	}
+}
+
+func TestExecutor_Rollout_Validation(t *testing.T) {
+	pf := &planfile.PlanFile{PlanHash: "abc", Plan: migration.NewMigrationPlan()}
+	spec := &rollout.Spec{Groups: []rollout.Group{{
+		Name:    "canary",
+		Targets: []rollout.Target{{Name: "canary", DatabaseConfig: database.DatabaseConfig{Host: "canary", Port: 3306, Username: "user", Database: "app"}}},
+	}}}
+
+	tests := []struct {
+		name    string
+		config  lock.Config
+		pf      *planfile.PlanFile
+		spec    *rollout.Spec
+		wantErr string
+	}{
+		{name: "missing plan", spec: spec, wantErr: "plan file cannot be nil"},
+		{name: "empty rollout", pf: pf, spec: &rollout.Spec{}, wantErr: "rollout has no groups"},
+		{name: "force unlock", config: lock.Config{ForceUnlock: "stuck"}, pf: pf, spec: spec, wantErr: "--force-unlock cannot be used with a rollout"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			executor, err := NewExecutor(ExecutionConfig{
+				SourceDB: database.DatabaseConfig{Host: "localhost", Database: "source_db"},
+				TargetDB: database.DatabaseConfig{Host: "localhost", Database: "target_db"},
+				Lock:     tt.config,
+			})
+			if err != nil {
+				t.Fatalf("NewExecutor() error = %v", err)
+			}
+
+			report, err := executor.Rollout(context.Background(), tt.pf, tt.spec)
+			if report != nil || appErrors.GetErrorType(err) != appErrors.ErrorTypeValidation || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Rollout() = %v, %v, want validation error %q", report, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestExecutor_ExecuteParallel(t *testing.T) {
+	statements := []migration.MigrationStatement{
+		{SQL: "ALTER TABLE `orders` ADD COLUMN `note` text", Type: migration.StatementTypeAddColumn, TableName: "orders",
+			Object: "column:orders.note", Description: "Add column note to table orders"},
+		{SQL: "ALTER TABLE `invoices` ADD COLUMN `note` text", Type: migration.StatementTypeAddColumn, TableName: "invoices",
+			Object: "column:invoices.note", Description: "Add column note to table invoices"},
+		{SQL: "CREATE INDEX `idx_note` ON `orders` (`note`)", Type: migration.StatementTypeCreateIndex, TableName: "orders",
+			Object: "index:orders.idx_note", Dependencies: []string{"table:orders", "column:orders.note"}, Description: "Create index idx_note on orders"},
+	}
+
+	tests := []struct {
+		name     string
+		failing  string
+		executed []string
+		wantErr  string
+	}{
+		{
+			name:     "independent tables",
+			executed: []string{"ALTER TABLE `orders`", "ALTER TABLE `invoices`", "CREATE INDEX `idx_note`"},
+		},
+		{
+			name:     "failure stops dependent statements",
+			failing:  "ALTER TABLE `orders`",
+			executed: []string{"ALTER TABLE `invoices`"},
+			wantErr:  "failed to execute migration statement 1",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			if err != nil {
+				t.Fatalf("Failed to create mock database: %v", err)
+			}
+			defer db.Close()
+			mock.MatchExpectationsInOrder(false)
+
+			if tt.failing != "" {
+				mock.ExpectBegin()
+				mock.ExpectExec(regexp.QuoteMeta(tt.failing)).
+					WillReturnError(&mysql.MySQLError{Number: 1064, Message: "You have an error in your SQL syntax"})
+				mock.ExpectRollback()
+			}
+			for _, sql := range tt.executed {
+				mock.ExpectBegin()
+				mock.ExpectExec(regexp.QuoteMeta(sql)).WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectCommit()
+			}
+
+			executor, err := NewExecutor(ExecutionConfig{
+				SourceDB: database.DatabaseConfig{Host: "localhost", Database: "source_db"},
+				TargetDB: database.DatabaseConfig{Host: "localhost", Database: "target_db"},
+				Workers:  2,
+			})
+			if err != nil {
+				t.Fatalf("NewExecutor() error = %v", err)
+			}
+
+			plan := migration.NewMigrationPlan()
+			plan.Statements = statements
+			run := &statementRun{targetDB: db, plan: plan, planHash: plan.Hash()}
+
+			err = executor.executeParallel(context.Background(), run, make([]bool, len(statements)), len(statements))
+			if tt.wantErr == "" && err != nil {
+				t.Fatalf("executeParallel() error = %v", err)
+			}
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Fatalf("executeParallel() error = %v, want %q", err, tt.wantErr)
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("Unfulfilled expectations: %v", err)
+			}
+		})
+	}
+}
+
+func TestExecutor_CheckResidual(t *testing.T) {
+	executor, err := NewExecutor(ExecutionConfig{
+		SourceDB: database.DatabaseConfig{Host: "localhost", Database: "source_db"},
+		TargetDB: database.DatabaseConfig{Host: "localhost", Database: "target_db"},
+		Verify:   true,
+	})
+	if err != nil {
+		t.Fatalf("NewExecutor() error = %v", err)
+	}
+
+	check := &schema.Constraint{Name: "chk_total", TableName: "orders", Type: schema.ConstraintTypeCheck, CheckExpression: "total >= 0"}
+	planned := &schema.SchemaDiff{ModifiedTables: []*schema.TableDiff{
+		{TableName: "orders", AddedConstraints: []*schema.Constraint{check}},
+	}}
+	plan := migration.NewMigrationPlan()
+
+	result := &ExecutionResult{}
+	if err := executor.checkResidual(&schema.SchemaDiff{}, planned, planned, plan, result); err != nil {
+		t.Fatalf("checkResidual() error = %v", err)
+	}
+	if result.ResidualDiff != nil {
+		t.Errorf("Expected no residual differences, got %+v", result.ResidualDiff)
+	}
+
+	// The server accepted the CHECK constraint without enforcing it, so it is still missing
+	err = executor.checkResidual(planned, planned, planned, plan, result)
+	if appErrors.GetErrorType(err) != appErrors.ErrorTypeVerification ||
+		!strings.Contains(appErrors.FormatUserError(err), "(1 differences)") {
+		t.Errorf("Expected a verification error, got %v", err)
+	}
+	if result.ResidualDiff == nil || len(result.ResidualDiff.ModifiedTables) != 1 {
+		t.Errorf("Expected the constraint as residual difference, got %+v", result.ResidualDiff)
+	}
+}
+
+// invalidBackups creates backups that never pass validation
+type invalidBackups struct{}
+
+func (invalidBackups) CreatePreMigrationBackup(ctx context.Context, config backup.BackupConfig, migrationPlan interface{}) (*backup.Backup, error) {
+	return &backup.Backup{ID: "backup-1"}, nil
+}
+
+func (invalidBackups) ValidateBackup(ctx context.Context, backupID string) (*backup.ValidationResult, error) {
+	return &backup.ValidationResult{Valid: false, Errors: []string{"checksum mismatch"}}, nil
+}
+
+func TestExecutor_CreateBackup(t *testing.T) {
+	dir := t.TempDir()
+	newExecutor := func(enabled bool) *Executor {
+		executor, err := NewExecutor(ExecutionConfig{
+			SourceDB: database.DatabaseConfig{Host: "localhost", Port: 3306, Database: "source_db"},
+			TargetDB: database.DatabaseConfig{Host: "localhost", Port: 3306, Database: "target_db"},
+			Backup: config.BackupConfig{
+				Enabled: enabled,
+				Storage: config.StorageConfig{Provider: "local", Local: &config.LocalConfig{BasePath: dir}},
+			},
+			ToolVersion: "1.4.0",
+		})
+		if err != nil {
+			t.Fatalf("NewExecutor() error = %v", err)
+		}
+		return executor
+	}
+
+	plan := migration.NewMigrationPlan()
+	plan.AddStatement(*migration.NewMigrationStatement("ALTER TABLE `users` ADD COLUMN `email` varchar(255)", migration.StatementTypeAddColumn, "Add column email"))
+	plan.Rollback = migration.NewMigrationPlan()
+	plan.Rollback.AddStatement(*migration.NewMigrationStatement("ALTER TABLE `users` DROP COLUMN `email`", migration.StatementTypeDropColumn, "Drop column email"))
+	targetSchema := &schema.Schema{Name: "target_db", Tables: map[string]*schema.Table{
+		"users": {Name: "users", Columns: map[string]*schema.Column{"id": {Name: "id", DataType: "int"}}},
+	}}
+
+	backupID, err := newExecutor(false).createBackup(context.Background(), targetSchema, plan, "localhost:3306/source_db")
+	if err != nil || backupID != "" {
+		t.Fatalf("Expected no backup while backups are disabled, got %q, %v", backupID, err)
+	}
+
+	executor := newExecutor(true)
+	if err := executor.ValidateConfig(); err != nil {
+		t.Fatalf("ValidateConfig() error = %v", err)
+	}
+	backupID, err = executor.createBackup(context.Background(), targetSchema, plan, "localhost:3306/source_db")
+	if err != nil {
+		t.Fatalf("createBackup() error = %v", err)
+	}
+
+	storage, err := backup.NewLocalStorageProvider(&backup.LocalConfig{BasePath: dir, Permissions: 0755})
+	if err != nil {
+		t.Fatalf("NewLocalStorageProvider() error = %v", err)
+	}
+	stored, err := storage.Retrieve(context.Background(), backupID)
+	if err != nil {
+		t.Fatalf("Retrieve() error = %v", err)
+	}
+	migrationContext := stored.Metadata.MigrationContext
+	if migrationContext == nil || migrationContext.PlanHash != plan.Hash() ||
+		migrationContext.SourceSchema != "localhost:3306/source_db" || migrationContext.ToolVersion != "1.4.0" {
+		t.Errorf("Expected the plan hash and source in the migration context, got %+v", migrationContext)
+	}
+	if stored.Metadata.DatabaseName != "target_db" || len(stored.RollbackSQL) != 1 {
+		t.Errorf("Expected a backup of target_db with the rollback, got %+v", stored)
+	}
+
+	// A backup that fails validation keeps the migration from starting
+	executor.backups = invalidBackups{}
+	_, err = executor.createBackup(context.Background(), targetSchema, plan, "localhost:3306/source_db")
+	if appErrors.GetErrorType(err) != appErrors.ErrorTypeValidation ||
+		!strings.Contains(appErrors.FormatUserError(err), "backup-1 failed validation, the migration was not started: checksum mismatch") {
+		t.Errorf("Expected the invalid backup to stop the migration, got %v", err)
+	}
+}
+
+func TestExecutor_HideInternalTables(t *testing.T) {
+	executor, err := NewExecutor(ExecutionConfig{
+		SourceDB: database.DatabaseConfig{Host: "localhost", Database: "source_db"},
+		TargetDB: database.DatabaseConfig{Host: "localhost", Database: "target_db"},
+		History:  history.Config{Table: "migration_runs"},
+	})
+	if err != nil {
+		t.Fatalf("NewExecutor() error = %v", err)
+	}
+
+	// Journal and history are disabled, but tables left by earlier runs must not be diffed
+	target := schema.NewSchema("target_db")
+	for _, name := range []string{"orders", journal.DefaultTable, history.DefaultTable, "migration_runs"} {
+		target.Tables[name] = schema.NewTable(name)
+	}
+
+	executor.hideInternalTables(target)
+
+	if len(target.Tables) != 1 || target.Tables["orders"] == nil {
+		t.Errorf("Expected only the orders table to remain, got %v", target.Tables)
+	}
+}
+
+func TestExecutor_ForceUnlockAlwaysAudits(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock database: %v", err)
+	}
+	defer db.Close()
+
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	executor, err := NewExecutor(ExecutionConfig{
+		SourceDB: database.DatabaseConfig{Host: "localhost", Database: "source_db"},
+		TargetDB: database.DatabaseConfig{Host: "localhost", Database: "target_db"},
+		Lock:     lock.Config{Enabled: true, ForceUnlock: "CI job was cancelled"},
+	})
+	if err != nil {
+		t.Fatalf("NewExecutor() error = %v", err)
+	}
+
+	mock.ExpectQuery("SELECT IS_USED_LOCK").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(42))
+	mock.ExpectQuery("FROM information_schema.PROCESSLIST").
+		WillReturnRows(sqlmock.NewRows([]string{"USER", "HOST", "TIME"}).AddRow("ci", "ci-1:5000", 30))
+	mock.ExpectQuery("FROM performance_schema.user_variables_by_thread").
+		WillReturnRows(sqlmock.NewRows([]string{"VARIABLE_VALUE"}))
+	mock.ExpectExec("KILL CONNECTION 42").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	if err := executor.forceUnlock(context.Background(), db, lock.Name("target_db")); err != nil {
+		t.Fatalf("forceUnlock() error = %v", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(home, lock.DefaultAuditLog))
+	if err != nil {
+		t.Fatalf("Expected the forced unlock to be audited without a configured audit log: %v", err)
+	}
+	if !strings.Contains(string(data), "CI job was cancelled") || !strings.Contains(string(data), `"connection_id":42`) {
+		t.Errorf("Unexpected audit record %s", data)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
 func TestExecutor_HandleError(t *testing.T) {
 	config := ExecutionConfig{
 		SourceDB: database.DatabaseConfig{
@@ -390,3 +1433,85 @@ func TestExecutor_Execute_Integration(t *testing.T) {
 	}
 }
 */
+
+func TestExecutor_CreateMigrationPlan_Selection(t *testing.T) {
+	diff := &schema.SchemaDiff{
+		ModifiedTables: []*schema.TableDiff{
+			{
+				TableName: "users",
+				AddedColumns: []*schema.Column{
+					{Name: "nickname", DataType: "varchar(50)", IsNullable: true},
+				},
+				RemovedColumns: []*schema.Column{
+					{Name: "legacy", DataType: "int", IsNullable: true},
+				},
+			},
+		},
+	}
+
+	newExecutor := func(t *testing.T, selection migration.SelectionConfig, answers string) *Executor {
+		t.Helper()
+		executor, err := NewExecutor(ExecutionConfig{
+			SourceDB:  database.DatabaseConfig{Host: "localhost", Database: "source_db"},
+			TargetDB:  database.DatabaseConfig{Host: "localhost", Database: "target_db"},
+			Selection: selection,
+		})
+		if err != nil {
+			t.Fatalf("NewExecutor() error = %v", err)
+		}
+		config := display.DefaultDisplayConfig()
+		config.Writer = &bytes.Buffer{}
+		executor.SetDisplayService(display.NewDisplayService(config))
+		executor.reviewInput = strings.NewReader(answers)
+		return executor
+	}
+
+	t.Run("skip selector", func(t *testing.T) {
+		executor := newExecutor(t, migration.SelectionConfig{Skip: []string{"users:DROP_COLUMN"}}, "")
+
+		plan, selectedDiff, err := executor.createMigrationPlan(diff)
+		if err != nil {
+			t.Fatalf("createMigrationPlan() error = %v", err)
+		}
+		if len(plan.Statements) != 1 || plan.Statements[0].Type != migration.StatementTypeAddColumn {
+			t.Errorf("Expected only the column addition, got %+v", plan.Statements)
+		}
+		if len(selectedDiff.ModifiedTables) != 1 || len(selectedDiff.ModifiedTables[0].RemovedColumns) != 0 {
+			t.Errorf("Expected the skipped drop to be left out of the diff, got %+v", selectedDiff.ModifiedTables)
+		}
+	})
+
+	t.Run("review keeps preselection", func(t *testing.T) {
+		executor := newExecutor(t, migration.SelectionConfig{Skip: []string{"users:DROP_COLUMN"}, Review: true}, "y\n")
+
+		plan, _, err := executor.createMigrationPlan(diff)
+		if err != nil {
+			t.Fatalf("createMigrationPlan() error = %v", err)
+		}
+		if len(plan.Statements) != 1 || plan.Statements[0].Type != migration.StatementTypeAddColumn {
+			t.Errorf("Expected only the column addition, got %+v", plan.Statements)
+		}
+	})
+
+	t.Run("review selects everything", func(t *testing.T) {
+		executor := newExecutor(t, migration.SelectionConfig{Only: []string{"users:ADD_COLUMN"}, Review: true}, "a\ny\n")
+
+		plan, _, err := executor.createMigrationPlan(diff)
+		if err != nil {
+			t.Fatalf("createMigrationPlan() error = %v", err)
+		}
+		if len(plan.Statements) != 2 {
+			t.Errorf("Expected both statements after selecting all, got %+v", plan.Statements)
+		}
+	})
+
+	t.Run("review cancelled", func(t *testing.T) {
+		executor := newExecutor(t, migration.SelectionConfig{Review: true}, "q\n")
+
+		_, _, err := executor.createMigrationPlan(diff)
+		var appErr *appErrors.AppError
+		if !errors.As(err, &appErr) || appErr.Type != appErrors.ErrorTypeInterruption {
+			t.Errorf("Expected an interruption error, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,184 @@
+package execution
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"sort"
+	"time"
+
+	"mysql-schema-sync/internal/database"
+	"mysql-schema-sync/internal/errors"
+	"mysql-schema-sync/internal/history"
+	"mysql-schema-sync/internal/migration"
+)
+
+// recordHistory adds an executed migration to the history table on the target, along with the
+// pre-migration backup taken for it if any. The migration has already run, so failing to record it
+// is only reported.
+func (e *Executor) recordHistory(ctx context.Context, targetDB *sql.DB, migrationPlan *migration.MigrationPlan, source, backupID string, startTime time.Time, runErr error) {
+	if !e.config.History.Enabled {
+		return
+	}
+
+	hostname, _ := os.Hostname()
+	run := &history.Run{
+		StartedAt:   startTime,
+		Duration:    time.Since(startTime),
+		User:        history.CurrentUser(),
+		Host:        hostname,
+		ToolVersion: e.config.ToolVersion,
+		Source:      source,
+		PlanHash:    migrationPlan.Hash(),
+		Statements:  len(migrationPlan.Statements),
+		BackupID:    backupID,
+		Outcome:     history.OutcomeSucceeded,
+		Plan:        migrationPlan,
+	}
+	if runErr != nil {
+		run.Outcome = history.OutcomeFailed
+		run.Error = runErr.Error()
+	}
+
+	// A cancelled migration is still worth recording
+	if ctx.Err() != nil {
+		ctx = context.Background()
+	}
+
+	store := history.New(targetDB, e.config.History.TableName())
+	err := store.Ensure(ctx)
+	if err == nil {
+		_, err = store.Add(ctx, run)
+	}
+	if err != nil {
+		e.logger.WithField("error", err.Error()).Warn("Failed to record the migration in the history table")
+		if e.displayService != nil {
+			e.displayService.Warning(fmt.Sprintf("The migration could not be recorded in %s: %v", store.Table(), err))
+		}
+		return
+	}
+
+	e.logger.WithFields(map[string]interface{}{
+		"history_id": run.ID,
+		"outcome":    run.Outcome,
+	}).Info("Migration recorded in history")
+}
+
+// History lists the recorded runs of the configured target and of any additional targets, newest
+// first
+func (e *Executor) History(ctx context.Context, filter history.Filter, targets []database.DatabaseConfig) ([]*history.Run, error) {
+	if e.config.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, e.config.Timeout)
+		defer cancel()
+	}
+
+	targets = append([]database.DatabaseConfig{e.config.TargetDB}, targets...)
+	runs := make([]*history.Run, 0)
+	for _, target := range targets {
+		targetRuns, err := e.targetHistory(ctx, target, filter)
+		if err != nil {
+			return nil, err
+		}
+		runs = append(runs, targetRuns...)
+	}
+
+	sort.SliceStable(runs, func(i, j int) bool {
+		return runs[i].StartedAt.After(runs[j].StartedAt)
+	})
+	if filter.Limit > 0 && len(runs) > filter.Limit {
+		runs = runs[:filter.Limit]
+	}
+
+	return runs, nil
+}
+
+// HistoryRun returns a recorded run of the configured target with its full plan
+func (e *Executor) HistoryRun(ctx context.Context, id int64) (*history.Run, error) {
+	if e.config.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, e.config.Timeout)
+		defer cancel()
+	}
+
+	targetDB, err := e.connect(ctx, e.config.TargetDB)
+	if err != nil {
+		return nil, err
+	}
+	defer e.dbService.Close(targetDB)
+
+	run, err := history.New(targetDB, e.config.History.TableName()).Get(ctx, id)
+	if err != nil {
+		return nil, errors.WrapError(err, "failed to read migration history")
+	}
+	if run == nil {
+		return nil, errors.NewAppError(errors.ErrorTypeValidation,
+			fmt.Sprintf("no run %d in the migration history of %s", id, databaseIdentity(e.config.TargetDB)), nil)
+	}
+	run.Target = databaseIdentity(e.config.TargetDB)
+
+	return run, nil
+}
+
+// targetHistory reads the recorded runs of one target. A target without a history table has no runs.
+func (e *Executor) targetHistory(ctx context.Context, target database.DatabaseConfig, filter history.Filter) ([]*history.Run, error) {
+	targetDB, err := e.connect(ctx, target)
+	if err != nil {
+		return nil, err
+	}
+	defer e.dbService.Close(targetDB)
+
+	tableName := e.config.History.TableName()
+	exists, err := e.historyTableExists(ctx, targetDB, target.Database, tableName)
+	if err != nil {
+		return nil, errors.WrapError(err, fmt.Sprintf("failed to read migration history of %s", databaseIdentity(target)))
+	}
+	if !exists {
+		e.logger.WithField("target", databaseIdentity(target)).Info("Target has no migration history table")
+		return nil, nil
+	}
+
+	runs, err := history.New(targetDB, tableName).List(ctx, filter)
+	if err != nil {
+		return nil, errors.WrapError(err, fmt.Sprintf("failed to read migration history of %s", databaseIdentity(target)))
+	}
+	for _, run := range runs {
+		run.Target = databaseIdentity(target)
+	}
+
+	return runs, nil
+}
+
+// historyTableExists checks the information schema for the history table
+func (e *Executor) historyTableExists(ctx context.Context, db *sql.DB, databaseName, tableName string) (bool, error) {
+	var count int
+	err := db.QueryRowContext(ctx,
+		"SELECT COUNT(*) FROM information_schema.TABLES WHERE TABLE_SCHEMA = ? AND TABLE_NAME = ?",
+		databaseName, tableName).Scan(&count)
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// connect establishes a connection to the given database with retries
+func (e *Executor) connect(ctx context.Context, config database.DatabaseConfig) (*sql.DB, error) {
+	var db *sql.DB
+	var err error
+
+	err = e.retryHandler.Retry(ctx, func() error {
+		db, err = e.dbService.Connect(config)
+		return err
+	})
+	if err != nil {
+		return nil, errors.WrapError(err, fmt.Sprintf("failed to connect to %s", databaseIdentity(config)))
+	}
+
+	return db, nil
+}
+
+// databaseIdentity identifies a database in messages and the migration history
+func databaseIdentity(config database.DatabaseConfig) string {
+	return fmt.Sprintf("%s:%d/%s", config.Host, config.Port, config.Database)
+}
@@ -0,0 +1,191 @@
+package execution
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"mysql-schema-sync/internal/errors"
+	"mysql-schema-sync/internal/journal"
+	"mysql-schema-sync/internal/migration"
+	"mysql-schema-sync/internal/schema"
+)
+
+// prepareJournal records the plan in the execution journal and returns which statements are
+// already applied. A plan that an earlier run left incomplete resumes with the statements that did
+// not verifiably land; incomplete runs of other plans are superseded, since this plan was computed
+// from the schema they left behind. A finished run of the same plan is retired: the plan hash only
+// covers the diff, so the same plan recurs once its changes were reverted.
+func (e *Executor) prepareJournal(ctx context.Context, jrnl *journal.Journal, targetDB *sql.DB, planHash string, migrationPlan *migration.MigrationPlan) ([]bool, error) {
+	if err := jrnl.Ensure(ctx); err != nil {
+		return nil, errors.WrapError(err, "failed to prepare the execution journal")
+	}
+
+	entries, err := jrnl.Entries(ctx, planHash)
+	if err != nil {
+		return nil, errors.WrapError(err, "failed to read the execution journal")
+	}
+
+	if len(entries) > 0 && journal.Finished(entries) {
+		if err := jrnl.Retire(ctx, planHash); err != nil {
+			return nil, errors.WrapError(err, "failed to update the execution journal")
+		}
+		entries = nil
+	}
+
+	if len(entries) == 0 {
+		if err := e.supersedeIncompleteRuns(ctx, jrnl); err != nil {
+			return nil, err
+		}
+
+		statements := make([]string, len(migrationPlan.Statements))
+		for i, stmt := range migrationPlan.Statements {
+			statements[i] = stmt.SQL
+		}
+		if err := jrnl.Record(ctx, planHash, statements); err != nil {
+			return nil, errors.WrapError(err, "failed to record the plan in the execution journal")
+		}
+		return make([]bool, len(migrationPlan.Statements)), nil
+	}
+
+	if len(entries) != len(migrationPlan.Statements) {
+		return nil, errors.NewAppError(errors.ErrorTypeValidation,
+			fmt.Sprintf("execution journal has %d statements for plan %s but the plan has %d", len(entries), planHash, len(migrationPlan.Statements)), nil)
+	}
+
+	var targetSchema *schema.Schema
+	err = e.retryHandler.Retry(ctx, func() error {
+		targetSchema, err = e.schemaService.ExtractSchemaFromDB(targetDB, e.config.TargetDB.Database)
+		return err
+	})
+	if err != nil {
+		return nil, errors.WrapError(err, "failed to extract target schema to verify the journaled statements")
+	}
+
+	applied, err := appliedStatements(ctx, jrnl, planHash, migrationPlan.Statements, entries, targetSchema)
+	if err != nil {
+		return nil, err
+	}
+
+	count, next := 0, -1
+	for i, done := range applied {
+		if done {
+			count++
+		} else if next < 0 {
+			next = i
+		}
+	}
+
+	e.logger.WithFields(map[string]interface{}{
+		"plan_hash":  planHash,
+		"applied":    count,
+		"statements": len(entries),
+	}).Info("Resuming partially applied migration plan")
+	if e.displayService != nil && next >= 0 {
+		e.displayService.Info(fmt.Sprintf("Resuming migration at statement %d of %d (%d already applied)", next+1, len(entries), count))
+	}
+
+	return applied, nil
+}
+
+// appliedStatements verifies the journaled statements against the target schema and returns which
+// of them are applied. An interrupted statement whose change is visible in the target is marked
+// completed; one whose outcome cannot be verified stops the resume. Parallel runs complete
+// statements out of order, so applied statements may follow ones that still have to run.
+func appliedStatements(ctx context.Context, jrnl *journal.Journal, planHash string, statements []migration.MigrationStatement, entries []journal.Entry, target *schema.Schema) ([]bool, error) {
+	applied := make([]bool, len(entries))
+	for i, entry := range entries {
+		switch entry.Status {
+		case journal.StatusCompleted:
+			applied[i] = true
+		case journal.StatusRunning:
+			landed, known := migration.StatementApplied(statements[i], target)
+			if !known {
+				return nil, errors.NewAppError(errors.ErrorTypeValidation,
+					fmt.Sprintf("statement %d (%s) was interrupted and whether it was applied cannot be verified; check the target and create a new plan", i+1, statements[i].Description), nil)
+			}
+			if landed {
+				if err := jrnl.Complete(ctx, planHash, i); err != nil {
+					return nil, errors.WrapError(err, "failed to update the execution journal")
+				}
+				applied[i] = true
+			}
+		}
+	}
+
+	// Only the last completed statement on an object describes its current state
+	verified := make(map[string]bool)
+	for i := len(statements) - 1; i >= 0; i-- {
+		stmt := statements[i]
+		if !applied[i] || stmt.Object == "" || verified[stmt.Object] {
+			continue
+		}
+		verified[stmt.Object] = true
+
+		if landed, known := migration.StatementApplied(stmt, target); known && !landed {
+			return nil, errors.NewAppError(errors.ErrorTypeValidation,
+				fmt.Sprintf("statement %d (%s) is journaled as completed but its change is missing from the target; the target was changed since, create a new plan", i+1, stmt.Description), nil)
+		}
+	}
+
+	return applied, nil
+}
+
+// supersedeIncompleteRuns warns about and retires the runs of other plans that never finished
+func (e *Executor) supersedeIncompleteRuns(ctx context.Context, jrnl *journal.Journal) error {
+	runs, err := jrnl.Incomplete(ctx)
+	if err != nil {
+		return errors.WrapError(err, "failed to read the execution journal")
+	}
+
+	for _, run := range runs {
+		message := fmt.Sprintf("An earlier migration (plan %s) stopped after %d of %d statements; this plan was computed from the schema it left behind",
+			shortHash(run.PlanHash), run.Completed, run.Statements)
+		e.logger.WithField("plan_hash", run.PlanHash).Warn(message)
+		if e.displayService != nil {
+			e.displayService.Warning(message)
+		}
+
+		if err := jrnl.Supersede(ctx, run.PlanHash); err != nil {
+			return errors.WrapError(err, "failed to update the execution journal")
+		}
+	}
+
+	return nil
+}
+
+// journaledRun returns true if the execution journal shows that the plan was partially applied and
+// not finished since
+func (e *Executor) journaledRun(ctx context.Context, targetDB *sql.DB, planHash string) (bool, error) {
+	if !e.config.Journal.Enabled {
+		return false, nil
+	}
+
+	jrnl := journal.New(targetDB, e.config.Journal.TableName())
+	if err := jrnl.Ensure(ctx); err != nil {
+		return false, errors.WrapError(err, "failed to prepare the execution journal")
+	}
+
+	entries, err := jrnl.Entries(ctx, planHash)
+	if err != nil {
+		return false, errors.WrapError(err, "failed to read the execution journal")
+	}
+
+	if journal.Finished(entries) {
+		return false, nil
+	}
+	for _, entry := range entries {
+		if entry.Status != journal.StatusPending {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// shortHash abbreviates a plan hash for messages
+func shortHash(hash string) string {
+	if len(hash) > 12 {
+		return hash[:12]
+	}
+	return hash
+}
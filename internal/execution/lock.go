@@ -0,0 +1,93 @@
+package execution
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"mysql-schema-sync/internal/errors"
+	"mysql-schema-sync/internal/lock"
+)
+
+// acquireLock takes the advisory lock of the target database, so that concurrent runs cannot
+// interleave their DDL. It returns nil if locking is disabled.
+func (e *Executor) acquireLock(ctx context.Context, targetDB *sql.DB) (*lock.Lock, error) {
+	if !e.config.Lock.Enabled {
+		return nil, nil
+	}
+
+	name := lock.Name(e.config.TargetDB.Database)
+	if e.config.Lock.ForceUnlock != "" {
+		if err := e.forceUnlock(ctx, targetDB, name); err != nil {
+			return nil, err
+		}
+	}
+
+	e.logger.WithFields(map[string]interface{}{
+		"lock":    name,
+		"timeout": e.config.Lock.Timeout().String(),
+	}).Info("Acquiring target lock")
+
+	targetLock, err := lock.Acquire(ctx, targetDB, name, e.config.Lock.Timeout())
+	if err != nil {
+		if held, ok := err.(*lock.HeldError); ok {
+			appErr := errors.NewAppError(errors.ErrorTypeTimeout, held.Error(), held)
+			appErr.UserMessage = fmt.Sprintf("Another mysql-schema-sync run is working on %s: %s. Wait for it to finish, "+
+				"or if it died without releasing the lock, rerun with --force-unlock \"<reason>\"", e.config.TargetDB.Database, held)
+			if held.Holder != nil {
+				appErr.WithContext("holder_connection_id", held.Holder.ConnectionID)
+				appErr.WithContext("holder_host", held.Holder.Host)
+			}
+			return nil, appErr
+		}
+		return nil, errors.WrapError(err, "failed to acquire target lock")
+	}
+
+	e.logger.WithField("lock", name).Info("Target lock acquired")
+	return targetLock, nil
+}
+
+// releaseLock releases the advisory lock of the target database. MySQL releases it with the
+// connection anyway, so a failure is only logged.
+func (e *Executor) releaseLock(targetLock *lock.Lock) {
+	if targetLock == nil {
+		return
+	}
+	if err := targetLock.Release(context.Background()); err != nil {
+		e.logger.WithField("error", err.Error()).Warn("Failed to release target lock")
+		return
+	}
+	e.logger.WithField("lock", targetLock.Name()).Info("Target lock released")
+}
+
+// forceUnlock kills the connection holding the lock of the target database, recording who did
+// it and why
+func (e *Executor) forceUnlock(ctx context.Context, targetDB *sql.DB, name string) error {
+	holder, err := lock.ForceUnlock(ctx, targetDB, name)
+	if err != nil {
+		return errors.WrapError(err, "failed to force unlock of target lock")
+	}
+	if holder == nil {
+		e.logger.WithField("lock", name).Info("Target lock is free, nothing to force unlock")
+		return nil
+	}
+
+	unlock := lock.NewUnlock(e.config.Lock.ForceUnlock, e.config.TargetDB.Database, name, holder)
+	e.logger.WithFields(map[string]interface{}{
+		"user":                 unlock.User,
+		"reason":               unlock.Reason,
+		"target":               unlock.Target,
+		"lock":                 name,
+		"holder_connection_id": holder.ConnectionID,
+		"holder_host":          holder.Host,
+	}).Warn("Target lock forcibly released")
+	if e.displayService != nil {
+		e.displayService.Warning(fmt.Sprintf("Killed %s to release lock %s", holder, name))
+	}
+
+	if err := lock.AppendAudit(e.config.Lock.AuditLogPath(), unlock); err != nil {
+		return errors.NewAppError(errors.ErrorTypeValidation, "failed to audit forced unlock", err)
+	}
+
+	return nil
+}
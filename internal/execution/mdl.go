@@ -0,0 +1,115 @@
+package execution
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"mysql-schema-sync/internal/errors"
+	"mysql-schema-sync/internal/mdl"
+	"mysql-schema-sync/internal/migration"
+)
+
+// metadataLockPollInterval is how often blocked tables are checked again while waiting
+var metadataLockPollInterval = 2 * time.Second
+
+// executeStatement runs a statement with the configured session lock_wait_timeout. Unless the
+// metadata lock action is wait, a statement that timed out waiting for a lock fails; otherwise it
+// runs again once the blocking sessions are gone.
+func (e *Executor) executeStatement(ctx context.Context, targetDB *sql.DB, checker *mdl.Checker, stmt migration.MigrationStatement, index int) error {
+	session, reset := stmt.SessionSQL()
+	if sessionSQL := e.config.MetadataLocks.SessionSQL(); sessionSQL != "" {
+		session = append([]string{sessionSQL}, session...)
+		reset = append(reset, e.config.MetadataLocks.ResetSQL())
+	}
+
+	deadline := time.Now().Add(e.config.MetadataLocks.Timeout())
+	for {
+		err := e.retryHandler.Retry(ctx, func() error {
+			// Session settings only apply to a dedicated connection, which is restored afterwards
+			return e.dbService.ExecuteSessionSQL(ctx, targetDB, session, []string{stmt.SQL}, reset)
+		})
+		if err == nil || !errors.IsLockWaitTimeout(err) || checker == nil ||
+			e.config.MetadataLocks.Action() != mdl.ActionWait || time.Now().After(deadline) {
+			return err
+		}
+
+		e.logger.WithFields(map[string]interface{}{
+			"statement": index + 1,
+			"table":     stmt.TableName,
+		}).Warn("Statement timed out waiting for a metadata lock, waiting for the blocking sessions")
+		if err := e.awaitMetadataLocks(ctx, checker, stmt, index, deadline); err != nil {
+			return err
+		}
+	}
+}
+
+// awaitMetadataLocks checks for sessions that would block the statement's table. Depending on the
+// configured action it aborts with a report of the blocking sessions, or waits for them to finish
+// until the deadline.
+func (e *Executor) awaitMetadataLocks(ctx context.Context, checker *mdl.Checker, stmt migration.MigrationStatement, index int, deadline time.Time) error {
+	// A table that is being created cannot be locked by anyone yet
+	if checker == nil || stmt.TableName == "" || stmt.Type == migration.StatementTypeCreateTable {
+		return nil
+	}
+
+	waiting := false
+	for {
+		blockers, err := checker.Blockers(ctx, e.config.TargetDB.Database, stmt.TableName)
+		if err != nil {
+			// The check is a safeguard; lock_wait_timeout still bounds the statement
+			e.logger.WithFields(map[string]interface{}{
+				"table": stmt.TableName,
+				"error": err.Error(),
+			}).Warn("Failed to check for sessions blocking the table, running the statement anyway")
+			return nil
+		}
+		if len(blockers) == 0 {
+			if waiting {
+				e.logger.WithField("table", stmt.TableName).Info("Blocking sessions finished")
+			}
+			return nil
+		}
+
+		if e.config.MetadataLocks.Action() != mdl.ActionWait || !time.Now().Before(deadline) {
+			return blockedError(stmt, index, blockers)
+		}
+
+		if !waiting {
+			waiting = true
+			e.logger.WithFields(map[string]interface{}{
+				"table":    stmt.TableName,
+				"blockers": len(blockers),
+				"deadline": deadline.Format(time.RFC3339),
+			}).Warn("Waiting for sessions blocking the table")
+			if e.displayService != nil {
+				e.displayService.Warning(fmt.Sprintf("Statement %d waits for %d sessions holding locks on %s", index+1, len(blockers), stmt.TableName))
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return errors.NewAppError(errors.ErrorTypeInterruption, "migration cancelled while waiting for blocking sessions", ctx.Err())
+		case <-time.After(metadataLockPollInterval):
+		}
+	}
+}
+
+// blockedError reports the sessions that block a statement
+func blockedError(stmt migration.MigrationStatement, index int, blockers []mdl.Blocker) error {
+	lines := make([]string, len(blockers))
+	for i, blocker := range blockers {
+		lines[i] = "  - " + blocker.String()
+	}
+
+	appErr := errors.NewAppError(errors.ErrorTypeTimeout,
+		fmt.Sprintf("statement %d on table %s is blocked by %d sessions", index+1, stmt.TableName, len(blockers)), nil)
+	appErr.UserMessage = fmt.Sprintf("Statement %d (%s) would wait for the metadata lock on %s and block all traffic to it.\n"+
+		"Blocking sessions:\n%s\nFinish or kill them (KILL <connection>), or set metadata_locks.on_blocked to wait.",
+		index+1, stmt.Description, stmt.TableName, strings.Join(lines, "\n"))
+	appErr.WithContext("table", stmt.TableName)
+	appErr.WithContext("blockers", len(blockers))
+	return appErr
+}
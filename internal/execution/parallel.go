@@ -0,0 +1,157 @@
+package execution
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"mysql-schema-sync/internal/display"
+	"mysql-schema-sync/internal/errors"
+	"mysql-schema-sync/internal/migration"
+)
+
+// statementResult reports a statement finished by a worker
+type statementResult struct {
+	worker int
+	index  int
+	err    error
+}
+
+// executeParallel runs the statements on a bounded pool of workers. Statements start in plan order
+// as soon as every earlier statement on one of their tables has finished, so independent tables are
+// changed concurrently while the dependency order holds. The first failure stops scheduling: running
+// statements finish, nothing new starts, and every failure is reported.
+func (e *Executor) executeParallel(ctx context.Context, run *statementRun, applied []bool, remaining int) error {
+	total := len(run.plan.Statements)
+	workers := e.config.Workers
+	if workers > remaining {
+		workers = remaining
+	}
+
+	e.logger.WithFields(map[string]interface{}{
+		"statements_count": remaining,
+		"workers":          workers,
+	}).Info("Executing migration in parallel")
+
+	schedule := migration.NewSchedule(run.plan.Statements, applied)
+
+	var progress *display.MultiProgress
+	bars := make([]*display.ProgressBar, workers)
+	if e.displayService != nil {
+		progress = e.displayService.NewMultiProgress()
+		for i := range bars {
+			bars[i] = e.displayService.NewProgressBar(remaining, fmt.Sprintf("Worker %d: idle", i+1))
+			progress.AddBar(bars[i])
+		}
+		progress.Start()
+		defer progress.Stop()
+	}
+	finished := make([]int, workers)
+	update := func(worker int, message string) {
+		if progress != nil {
+			bars[worker].Update(finished[worker], fmt.Sprintf("Worker %d: %s", worker+1, message))
+			progress.Render()
+		}
+	}
+
+	results := make(chan statementResult, workers)
+	idle := make([]int, 0, workers)
+	for i := workers - 1; i >= 0; i-- {
+		idle = append(idle, i)
+	}
+
+	running, completed := 0, 0
+	var stopErr error
+	failures := make([]statementResult, 0)
+	for {
+		// Hand ready statements to idle workers until a failure stops the schedule
+		for stopErr == nil && len(failures) == 0 && len(idle) > 0 {
+			index, ok := schedule.Next()
+			if !ok {
+				break
+			}
+
+			if _, err := e.throttle(ctx, run, index, nil); err != nil {
+				stopErr = err
+				break
+			}
+
+			worker := idle[len(idle)-1]
+			idle = idle[:len(idle)-1]
+			running++
+
+			stmt := run.plan.Statements[index]
+			update(worker, fmt.Sprintf("statement %d/%d on %s", index+1, total, stmt.TableName))
+			go func(worker, index int) {
+				results <- statementResult{worker: worker, index: index, err: e.runStatement(ctx, run, index, nil)}
+			}(worker, index)
+		}
+
+		if running == 0 {
+			break
+		}
+
+		result := <-results
+		running--
+		idle = append(idle, result.worker)
+		if result.err != nil {
+			failures = append(failures, result)
+			update(result.worker, fmt.Sprintf("statement %d failed", result.index+1))
+			continue
+		}
+
+		schedule.Finish(result.index)
+		completed++
+		finished[result.worker]++
+		update(result.worker, "idle")
+	}
+
+	if len(failures) > 0 {
+		return e.parallelFailure(failures, completed, remaining)
+	}
+	if stopErr != nil {
+		return stopErr
+	}
+	if completed < remaining {
+		return errors.NewAppError(errors.ErrorTypeUnknown,
+			fmt.Sprintf("parallel execution stopped with %d of %d statements not scheduled", remaining-completed, remaining), nil)
+	}
+
+	if e.displayService != nil {
+		e.displayService.Success(fmt.Sprintf("Successfully executed %d migration statements with %d workers", completed, workers))
+	}
+
+	e.logger.Info("Migration executed successfully")
+	return nil
+}
+
+// parallelFailure reports the statements that failed in plan order. The returned error is the one
+// of the earliest failed statement, so the outcome does not depend on which worker failed first.
+func (e *Executor) parallelFailure(failures []statementResult, completed, remaining int) error {
+	sort.Slice(failures, func(a, b int) bool {
+		return failures[a].index < failures[b].index
+	})
+
+	statements := make([]string, len(failures))
+	for i, failure := range failures {
+		statements[i] = fmt.Sprintf("%d", failure.index+1)
+		e.logger.WithFields(map[string]interface{}{
+			"statement": failure.index + 1,
+			"error":     failure.err.Error(),
+		}).Error("Migration statement failed")
+	}
+
+	notStarted := remaining - completed - len(failures)
+	summary := fmt.Sprintf("%d statements failed (%s), %d completed, %d not started",
+		len(failures), strings.Join(statements, ", "), completed, notStarted)
+	e.logger.Error("Parallel migration stopped: " + summary)
+	if e.displayService != nil {
+		e.displayService.Error("Parallel migration stopped: " + summary)
+	}
+
+	if len(failures) == 1 {
+		return failures[0].err
+	}
+	return errors.WrapError(failures[0].err, summary)
+}
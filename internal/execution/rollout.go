@@ -0,0 +1,167 @@
+package execution
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"mysql-schema-sync/internal/errors"
+	"mysql-schema-sync/internal/planfile"
+	"mysql-schema-sync/internal/rollout"
+	"mysql-schema-sync/internal/schema"
+)
+
+// Rollout applies a saved plan to every target of the rollout, one group after the other. Each
+// target is migrated by its own executor with this executor's settings, so locking, journaling,
+// throttling and history apply per target. The rollout halts as soon as a target fails or ends
+// up with a different schema than the targets migrated before it.
+func (e *Executor) Rollout(ctx context.Context, pf *planfile.PlanFile, spec *rollout.Spec) (*rollout.Report, error) {
+	if pf == nil {
+		return nil, errors.NewAppError(errors.ErrorTypeValidation, "plan file cannot be nil", nil)
+	}
+	if spec == nil {
+		return nil, errors.NewAppError(errors.ErrorTypeValidation, "rollout cannot be nil", nil)
+	}
+	if err := spec.Validate(); err != nil {
+		return nil, errors.NewAppError(errors.ErrorTypeValidation, err.Error(), err)
+	}
+	if e.config.Lock.ForceUnlock != "" {
+		return nil, errors.NewAppError(errors.ErrorTypeValidation, "--force-unlock cannot be used with a rollout; release the lock on the target itself", nil)
+	}
+
+	e.logger.WithFields(map[string]interface{}{
+		"plan_hash":       pf.PlanHash,
+		"groups":          len(spec.Groups),
+		"targets":         spec.TargetCount(),
+		"statement_count": len(pf.Plan.Statements),
+		"dry_run":         e.config.DryRun,
+	}).Info("Starting rollout")
+
+	runner := rollout.NewRunner(spec, &rolloutObserver{executor: e})
+	report := runner.Run(ctx, pf.PlanHash, func(ctx context.Context, target rollout.Target) rollout.Outcome {
+		return e.rolloutTarget(ctx, pf, target)
+	})
+
+	if report.Succeeded() {
+		e.logger.WithFields(map[string]interface{}{
+			"duration": report.Duration.String(),
+			"targets":  report.Summary(),
+		}).Info("Rollout completed successfully")
+		return report, nil
+	}
+
+	errorType := errors.ErrorTypeSQL
+	if ctx.Err() != nil {
+		errorType = errors.ErrorTypeInterruption
+	}
+	appErr := errors.NewAppError(errorType, fmt.Sprintf("rollout halted: %s", report.HaltReason), nil)
+	appErr.UserMessage = fmt.Sprintf("Rollout halted: %s. Targets: %s. Groups after the halt were not started.",
+		report.HaltReason, report.Summary())
+	return report, appErr
+}
+
+// rolloutTarget applies the plan to one target of the rollout and reads back the resulting schema
+// fingerprint, which the rollout compares across targets
+func (e *Executor) rolloutTarget(ctx context.Context, pf *planfile.PlanFile, target rollout.Target) rollout.Outcome {
+	config := e.config
+	config.TargetDB = target.DatabaseConfig
+
+	child, err := NewExecutor(config)
+	if err != nil {
+		return rollout.Outcome{Err: err}
+	}
+
+	result, err := child.Apply(ctx, pf)
+	outcome := rollout.Outcome{Err: err}
+	if result != nil {
+		outcome.Statements = len(result.ExecutedStatements)
+	}
+	if err != nil {
+		return outcome
+	}
+
+	targetSchema, err := child.readTargetSchema(ctx)
+	if err != nil {
+		outcome.Err = errors.WrapError(err, "plan applied but the resulting target schema could not be verified")
+		return outcome
+	}
+	outcome.Fingerprint = targetSchema.Fingerprint()
+
+	return outcome
+}
+
+// readTargetSchema connects to the target and extracts its schema without the tool's own tables
+func (e *Executor) readTargetSchema(ctx context.Context) (*schema.Schema, error) {
+	targetDB, err := e.connectToTarget(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer e.dbService.Close(targetDB)
+
+	var targetSchema *schema.Schema
+	err = e.retryHandler.Retry(ctx, func() error {
+		targetSchema, err = e.schemaService.ExtractSchemaFromDB(targetDB, e.config.TargetDB.Database)
+		return err
+	})
+	if err != nil {
+		return nil, errors.WrapError(err, "failed to extract target schema")
+	}
+
+	e.hideInternalTables(targetSchema)
+	return targetSchema, nil
+}
+
+// rolloutObserver reports the progress of a rollout to the log and the display
+type rolloutObserver struct {
+	executor *Executor
+}
+
+// GroupStarted reports the start of a group
+func (o *rolloutObserver) GroupStarted(group rollout.Group) {
+	o.executor.logger.WithFields(map[string]interface{}{
+		"group":       group.Name,
+		"targets":     len(group.Targets),
+		"concurrency": group.ConcurrencyLimit(),
+	}).Info("Rolling out to group")
+	if o.executor.displayService != nil {
+		o.executor.displayService.Info(fmt.Sprintf("Rolling out to group %s (%d targets, %d at a time)",
+			group.Name, len(group.Targets), group.ConcurrencyLimit()))
+	}
+}
+
+// TargetFinished reports the outcome of a target
+func (o *rolloutObserver) TargetFinished(group rollout.Group, result rollout.TargetResult) {
+	entry := o.executor.logger.WithFields(map[string]interface{}{
+		"group":      group.Name,
+		"target":     result.Target,
+		"status":     string(result.Status),
+		"statements": result.Statements,
+		"duration":   result.Duration.String(),
+	})
+	if result.Status != rollout.StatusSucceeded {
+		entry.WithField("error", result.Error).Error("Rollout target failed")
+	} else {
+		entry.Info("Rollout target migrated")
+	}
+
+	if o.executor.displayService == nil {
+		return
+	}
+	if result.Status != rollout.StatusSucceeded {
+		o.executor.displayService.Error(fmt.Sprintf("%s/%s %s: %s", group.Name, result.Target, result.Status, result.Error))
+	} else {
+		o.executor.displayService.Success(fmt.Sprintf("%s/%s migrated in %s", group.Name, result.Target,
+			result.Duration.Round(time.Millisecond)))
+	}
+}
+
+// Soaking reports the wait before the next group
+func (o *rolloutObserver) Soaking(group rollout.Group, soak time.Duration) {
+	o.executor.logger.WithFields(map[string]interface{}{
+		"group": group.Name,
+		"soak":  soak.String(),
+	}).Info("Soaking before the next group")
+	if o.executor.displayService != nil {
+		o.executor.displayService.Info(fmt.Sprintf("Group %s succeeded, soaking for %s before the next group", group.Name, soak))
+	}
+}
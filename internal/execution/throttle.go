@@ -0,0 +1,172 @@
+package execution
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"mysql-schema-sync/internal/database"
+	"mysql-schema-sync/internal/display"
+	"mysql-schema-sync/internal/errors"
+	"mysql-schema-sync/internal/throttle"
+)
+
+// connectReplicas connects to the configured and discovered replicas of the target. It returns
+// a nil monitor if no replica is watched, and a function closing the connections.
+func (e *Executor) connectReplicas(ctx context.Context, targetDB *sql.DB) (*throttle.Monitor, func(), error) {
+	noop := func() {}
+	config := e.config.Replication
+	if !config.Enabled() {
+		return nil, noop, nil
+	}
+
+	replicas := append([]throttle.Replica(nil), config.Replicas...)
+	if config.Discover {
+		discovered, skipped, err := throttle.DiscoverReplicas(ctx, targetDB)
+		if err != nil {
+			return nil, noop, errors.WrapError(err, "failed to discover replicas")
+		}
+		if len(skipped) > 0 {
+			message := fmt.Sprintf("Replicas without report_host cannot be watched: %v", skipped)
+			e.logger.Warn(message)
+			if e.displayService != nil {
+				e.displayService.Warning(message)
+			}
+		}
+		replicas = append(replicas, discovered...)
+	}
+
+	connections := make(map[string]*sql.DB)
+	closeAll := func() {
+		for _, db := range connections {
+			e.dbService.Close(db)
+		}
+	}
+
+	for _, replica := range replicas {
+		if replica.Port == 0 {
+			replica.Port = 3306
+		}
+		name := replica.Address()
+		if connections[name] != nil {
+			continue
+		}
+
+		replicaConfig := database.DatabaseConfig{
+			Host:     replica.Host,
+			Port:     replica.Port,
+			Username: replica.Username,
+			Password: replica.Password,
+			Database: e.config.TargetDB.Database,
+			Timeout:  e.config.TargetDB.Timeout,
+		}
+		if replicaConfig.Username == "" {
+			replicaConfig.Username = e.config.TargetDB.Username
+			replicaConfig.Password = e.config.TargetDB.Password
+		}
+
+		db, err := e.connect(ctx, replicaConfig)
+		if err != nil {
+			closeAll()
+			return nil, noop, err
+		}
+		connections[name] = db
+	}
+
+	if len(connections) == 0 {
+		e.logger.Warn("No replicas found to watch for lag")
+		return nil, noop, nil
+	}
+
+	e.logger.WithFields(map[string]interface{}{
+		"replicas": len(connections),
+		"max_lag":  config.Lag().String(),
+	}).Info("Watching replica lag")
+
+	return throttle.NewMonitor(config, connections), closeAll, nil
+}
+
+// waitForReplicas pauses before a statement until every replica is within the maximum lag, and
+// returns the lag summary shown with the progress. It aborts if a replica's SQL thread fails.
+func (e *Executor) waitForReplicas(ctx context.Context, monitor *throttle.Monitor, index, total int, progressBar *display.ProgressBar) (string, error) {
+	summary := ""
+	paused := false
+	err := monitor.Wait(ctx, func(statuses []throttle.ReplicaStatus, lagging bool) {
+		summary = throttle.Summary(statuses)
+		if !lagging {
+			return
+		}
+
+		if !paused {
+			paused = true
+			e.logger.WithFields(map[string]interface{}{
+				"statement": index + 1,
+				"lag":       summary,
+				"max_lag":   e.config.Replication.Lag().String(),
+			}).Warn("Pausing migration until replicas catch up")
+		}
+		if progressBar != nil {
+			progressBar.Update(index, fmt.Sprintf("Paused before statement %d/%d: %s (max %s)", index+1, total, summary, e.config.Replication.Lag()))
+		}
+	})
+	if err == nil {
+		if paused {
+			e.logger.WithField("lag", summary).Info("Replicas caught up, resuming migration")
+		}
+		return summary, nil
+	}
+
+	switch err := err.(type) {
+	case *throttle.BrokenError:
+		appErr := errors.NewAppError(errors.ErrorTypeSQL, err.Error(), err)
+		appErr.UserMessage = fmt.Sprintf("Migration stopped before statement %d because replication broke: %s. "+
+			"Fix the replica before continuing; the remaining statements were not executed.", index+1, err)
+		return summary, appErr
+	case *throttle.LaggingError:
+		return summary, errors.NewAppError(errors.ErrorTypeTimeout,
+			fmt.Sprintf("migration stopped before statement %d: %s", index+1, err), err)
+	}
+	if ctx.Err() != nil {
+		return summary, errors.NewAppError(errors.ErrorTypeInterruption, "migration cancelled while waiting for replicas", err)
+	}
+	return summary, errors.WrapError(err, "failed to check replica lag")
+}
+
+// waitForLoad pauses before a statement while the target is too busy or the operator's pause file
+// exists. It aborts the migration when a critical load threshold is reached.
+func (e *Executor) waitForLoad(ctx context.Context, monitor *throttle.LoadMonitor, index, total int, progressBar *display.ProgressBar) error {
+	paused := false
+	err := monitor.Wait(ctx, func(status throttle.LoadStatus) {
+		if !paused {
+			paused = true
+			e.logger.WithFields(map[string]interface{}{
+				"statement": index + 1,
+				"reason":    status.Reason(),
+			}).Warn("Pausing migration while the target is busy")
+			if e.displayService != nil && status.PauseFile {
+				e.displayService.Info(fmt.Sprintf("Paused by %s; remove it to resume", e.config.Load.PauseFile))
+			}
+		}
+		if progressBar != nil {
+			progressBar.Update(index, fmt.Sprintf("Paused before statement %d/%d: %s", index+1, total, status.Reason()))
+		}
+	})
+	if err == nil {
+		if paused {
+			e.logger.WithField("statement", index+1).Info("Target load recovered, resuming migration")
+		}
+		return nil
+	}
+
+	if critical, ok := err.(*throttle.CriticalLoadError); ok {
+		appErr := errors.NewAppError(errors.ErrorTypeTimeout, critical.Error(), critical)
+		appErr.UserMessage = fmt.Sprintf("Migration stopped before statement %d because the target reached critical load: %s. "+
+			"The remaining statements were not executed.", index+1, strings.Join(critical.Status.Critical, ", "))
+		return appErr
+	}
+	if ctx.Err() != nil {
+		return errors.NewAppError(errors.ErrorTypeInterruption, "migration cancelled while paused", err)
+	}
+	return errors.WrapError(err, "failed to check target load")
+}
@@ -0,0 +1,52 @@
+package execution
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"mysql-schema-sync/internal/errors"
+	"mysql-schema-sync/internal/migration"
+	"mysql-schema-sync/internal/schema"
+)
+
+// verifyTarget extracts and compares both schemas again after the migration and fails if the
+// target still differs from the source where the plan should have synchronized it. planned is the
+// diff the plan was created from and applied the part of it that was selected.
+func (e *Executor) verifyTarget(ctx context.Context, sourceDB, targetDB *sql.DB, planned, applied *schema.SchemaDiff, migrationPlan *migration.MigrationPlan, result *ExecutionResult) error {
+	e.logger.Info("Verifying target schema after migration")
+
+	sourceSchema, targetSchema, err := e.extractSchemas(ctx, sourceDB, targetDB)
+	if err != nil {
+		return errors.WrapError(err, "failed to verify target schema")
+	}
+
+	diff, err := e.compareSchemas(sourceSchema, targetSchema)
+	if err != nil {
+		return errors.WrapError(err, "failed to verify target schema")
+	}
+
+	return e.checkResidual(diff, planned, applied, migrationPlan, result)
+}
+
+// checkResidual reports the differences the migration left behind and returns a verification
+// error if there are any
+func (e *Executor) checkResidual(diff, planned, applied *schema.SchemaDiff, migrationPlan *migration.MigrationPlan, result *ExecutionResult) error {
+	residual := migration.ResidualDiff(diff, planned, applied, migrationPlan)
+	if e.schemaService.IsSchemaDiffEmpty(residual) {
+		e.logger.Info("Target schema verified against the source")
+		if e.displayService != nil {
+			e.displayService.Success("Verified that the target schema matches the source")
+		}
+		return nil
+	}
+
+	result.ResidualDiff = residual
+	differences := migration.DescribeDiff(residual)
+	for _, difference := range differences {
+		e.logger.WithField("difference", difference).Error("Target schema differs from the source after migration")
+	}
+
+	return errors.NewAppError(errors.ErrorTypeVerification,
+		fmt.Sprintf("target schema still differs from the source after the migration (%d differences)", len(differences)), nil)
+}
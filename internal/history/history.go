@@ -0,0 +1,270 @@
+package history
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"os/user"
+	"strings"
+	"time"
+
+	"mysql-schema-sync/internal/migration"
+)
+
+// DefaultTable is the name of the history table created in the target database
+const DefaultTable = "schema_sync_history"
+
+// Outcome is the result of a recorded run
+type Outcome string
+
+const (
+	OutcomeSucceeded Outcome = "succeeded"
+	OutcomeFailed    Outcome = "failed"
+)
+
+// Config controls the migration history
+type Config struct {
+	// Enabled records every executed migration in the history table on the target
+	Enabled bool `mapstructure:"enabled" yaml:"enabled"`
+	// Table is the name of the history table
+	Table string `mapstructure:"table" yaml:"table"`
+}
+
+// TableName returns the configured history table, or the default one
+func (c Config) TableName() string {
+	if c.Table == "" {
+		return DefaultTable
+	}
+	return c.Table
+}
+
+// Validate validates the history configuration
+func (c Config) Validate() error {
+	name := c.TableName()
+	if len(name) > 64 {
+		return fmt.Errorf("history table name %q is longer than 64 characters", name)
+	}
+	if strings.ContainsAny(name, "`. ") {
+		return fmt.Errorf("history table name %q must not contain backticks, dots or spaces", name)
+	}
+	return nil
+}
+
+// Run is the history record of one migration executed against a target
+type Run struct {
+	ID          int64                    `json:"id"`
+	Target      string                   `json:"target,omitempty"`
+	StartedAt   time.Time                `json:"started_at"`
+	Duration    time.Duration            `json:"duration_ns"`
+	User        string                   `json:"user"`
+	Host        string                   `json:"host"`
+	ToolVersion string                   `json:"tool_version"`
+	Source      string                   `json:"source"`
+	PlanHash    string                   `json:"plan_hash"`
+	Statements  int                      `json:"statements"`
+	BackupID    string                   `json:"backup_id,omitempty"`
+	Outcome     Outcome                  `json:"outcome"`
+	Error       string                   `json:"error,omitempty"`
+	Plan        *migration.MigrationPlan `json:"plan,omitempty"`
+}
+
+// Filter selects runs from the history. Zero values match every run.
+type Filter struct {
+	Since    time.Time
+	User     string
+	Outcome  Outcome
+	PlanHash string
+	Limit    int
+}
+
+// ParseOutcome converts a user supplied outcome name into an Outcome
+func ParseOutcome(name string) (Outcome, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "":
+		return "", nil
+	case "succeeded", "success":
+		return OutcomeSucceeded, nil
+	case "failed", "failure":
+		return OutcomeFailed, nil
+	default:
+		return "", fmt.Errorf("unsupported outcome: %s (supported: succeeded, failed)", name)
+	}
+}
+
+// CurrentUser returns the name of the operating system user running the tool
+func CurrentUser() string {
+	if currentUser, err := user.Current(); err == nil {
+		return currentUser.Username
+	}
+	return "unknown"
+}
+
+// Store keeps the migration history in a table of the target database
+type Store struct {
+	db    *sql.DB
+	table string
+}
+
+// New creates a Store that keeps its records in the given table
+func New(db *sql.DB, table string) *Store {
+	if table == "" {
+		table = DefaultTable
+	}
+	return &Store{db: db, table: table}
+}
+
+// Table returns the name of the history table
+func (s *Store) Table() string {
+	return s.table
+}
+
+// Ensure creates the history table if it does not exist
+func (s *Store) Ensure(ctx context.Context) error {
+	query := fmt.Sprintf("CREATE TABLE IF NOT EXISTS `%s` ("+
+		"`id` BIGINT NOT NULL AUTO_INCREMENT, "+
+		"`started_at` DATETIME(6) NOT NULL, "+
+		"`duration_ms` BIGINT NOT NULL, "+
+		"`user` VARCHAR(255) NOT NULL, "+
+		"`host` VARCHAR(255) NOT NULL, "+
+		"`tool_version` VARCHAR(64) NOT NULL, "+
+		"`source` VARCHAR(512) NOT NULL, "+
+		"`plan_hash` CHAR(64) NOT NULL, "+
+		"`statement_count` INT NOT NULL, "+
+		"`backup_id` VARCHAR(255) NULL, "+
+		"`outcome` VARCHAR(16) NOT NULL, "+
+		"`error` TEXT NULL, "+
+		"`plan` LONGTEXT NOT NULL, "+
+		"PRIMARY KEY (`id`), "+
+		"KEY `idx_started_at` (`started_at`)"+
+		") ENGINE=InnoDB", s.table)
+
+	if _, err := s.db.ExecContext(ctx, query); err != nil {
+		return fmt.Errorf("failed to create history table %s: %w", s.table, err)
+	}
+	return nil
+}
+
+// Add records a run and returns its ID
+func (s *Store) Add(ctx context.Context, run *Run) (int64, error) {
+	plan, err := json.Marshal(run.Plan)
+	if err != nil {
+		return 0, fmt.Errorf("failed to encode migration plan: %w", err)
+	}
+
+	var backupID, message interface{}
+	if run.BackupID != "" {
+		backupID = run.BackupID
+	}
+	if run.Error != "" {
+		message = run.Error
+	}
+
+	query := fmt.Sprintf("INSERT INTO `%s` (`started_at`, `duration_ms`, `user`, `host`, `tool_version`, `source`, "+
+		"`plan_hash`, `statement_count`, `backup_id`, `outcome`, `error`, `plan`) "+
+		"VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)", s.table)
+	res, err := s.db.ExecContext(ctx, query, run.StartedAt.UTC(), run.Duration.Milliseconds(), run.User, run.Host,
+		run.ToolVersion, run.Source, run.PlanHash, run.Statements, backupID, string(run.Outcome), message, string(plan))
+	if err != nil {
+		return 0, fmt.Errorf("failed to record run in history: %w", err)
+	}
+
+	id, err := res.LastInsertId()
+	if err != nil {
+		return 0, fmt.Errorf("failed to read history record ID: %w", err)
+	}
+	run.ID = id
+	return id, nil
+}
+
+// List returns the runs matching the filter, newest first, without their plans
+func (s *Store) List(ctx context.Context, filter Filter) ([]*Run, error) {
+	conditions := make([]string, 0)
+	args := make([]interface{}, 0)
+	if !filter.Since.IsZero() {
+		conditions = append(conditions, "`started_at` >= ?")
+		args = append(args, filter.Since.UTC())
+	}
+	if filter.User != "" {
+		conditions = append(conditions, "`user` = ?")
+		args = append(args, filter.User)
+	}
+	if filter.Outcome != "" {
+		conditions = append(conditions, "`outcome` = ?")
+		args = append(args, string(filter.Outcome))
+	}
+	if filter.PlanHash != "" {
+		conditions = append(conditions, "`plan_hash` LIKE ?")
+		args = append(args, filter.PlanHash+"%")
+	}
+
+	query := fmt.Sprintf("SELECT %s FROM `%s`", runColumns, s.table)
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	query += " ORDER BY `started_at` DESC, `id` DESC"
+	if filter.Limit > 0 {
+		query += fmt.Sprintf(" LIMIT %d", filter.Limit)
+	}
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read history: %w", err)
+	}
+	defer rows.Close()
+
+	runs := make([]*Run, 0)
+	for rows.Next() {
+		run, err := scanRun(rows)
+		if err != nil {
+			return nil, err
+		}
+		runs = append(runs, run)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read history: %w", err)
+	}
+	return runs, nil
+}
+
+// Get returns a run with its full plan. It returns nil if the history has no run with the ID.
+func (s *Store) Get(ctx context.Context, id int64) (*Run, error) {
+	query := fmt.Sprintf("SELECT %s, `plan` FROM `%s` WHERE `id` = ?", runColumns, s.table)
+
+	var plan string
+	run, err := scanRun(s.db.QueryRowContext(ctx, query, id), &plan)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal([]byte(plan), &run.Plan); err != nil {
+		return nil, fmt.Errorf("failed to decode plan of run %d: %w", id, err)
+	}
+	return run, nil
+}
+
+// runColumns are the columns of a run in the order scanRun reads them
+const runColumns = "`id`, `started_at`, `duration_ms`, `user`, `host`, `tool_version`, `source`, " +
+	"`plan_hash`, `statement_count`, COALESCE(`backup_id`, ''), `outcome`, COALESCE(`error`, '')"
+
+// scanRun reads a run from a row of runColumns followed by extra columns
+func scanRun(row interface{ Scan(...interface{}) error }, extra ...interface{}) (*Run, error) {
+	run := &Run{}
+	var durationMs int64
+	var outcome string
+	dest := append([]interface{}{&run.ID, &run.StartedAt, &durationMs, &run.User, &run.Host, &run.ToolVersion,
+		&run.Source, &run.PlanHash, &run.Statements, &run.BackupID, &outcome, &run.Error}, extra...)
+	if err := row.Scan(dest...); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to scan history record: %w", err)
+	}
+	run.Duration = time.Duration(durationMs) * time.Millisecond
+	run.Outcome = Outcome(outcome)
+	return run, nil
+}
@@ -0,0 +1,168 @@
+package history
+
+import (
+	"context"
+	"encoding/json"
+	"regexp"
+	"testing"
+	"time"
+
+	"mysql-schema-sync/internal/migration"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+var runRowColumns = []string{"id", "started_at", "duration_ms", "user", "host", "tool_version", "source",
+	"plan_hash", "statement_count", "backup_id", "outcome", "error"}
+
+func TestParseOutcome(t *testing.T) {
+	tests := []struct {
+		name     string
+		expected Outcome
+		wantErr  bool
+	}{
+		{"", "", false},
+		{"succeeded", OutcomeSucceeded, false},
+		{"FAILED", OutcomeFailed, false},
+		{"failure", OutcomeFailed, false},
+		{"aborted", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			outcome, err := ParseOutcome(tt.name)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseOutcome(%q) error = %v, wantErr %v", tt.name, err, tt.wantErr)
+			}
+			if outcome != tt.expected {
+				t.Errorf("ParseOutcome(%q) = %s, want %s", tt.name, outcome, tt.expected)
+			}
+		})
+	}
+}
+
+func TestStore_Add(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create sqlmock: %v", err)
+	}
+	defer db.Close()
+
+	started := time.Date(2026, 10, 18, 9, 0, 0, 0, time.UTC)
+	plan := migration.NewMigrationPlan()
+	plan.AddStatement(migration.MigrationStatement{SQL: "DROP TABLE `old`", Type: migration.StatementTypeDropTable, Description: "Drop table old"})
+
+	mock.ExpectExec(regexp.QuoteMeta("CREATE TABLE IF NOT EXISTS `schema_sync_history`")).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO `schema_sync_history`")).
+		WithArgs(started, int64(1500), "deploy", "ci-1", "1.4.0", "src:3306/app", "abc", 1, nil, "failed", "lock wait timeout", sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(7, 1))
+
+	store := New(db, "")
+	if err := store.Ensure(context.Background()); err != nil {
+		t.Fatalf("Ensure() error = %v", err)
+	}
+
+	run := &Run{
+		StartedAt:   started,
+		Duration:    1500 * time.Millisecond,
+		User:        "deploy",
+		Host:        "ci-1",
+		ToolVersion: "1.4.0",
+		Source:      "src:3306/app",
+		PlanHash:    "abc",
+		Statements:  1,
+		Outcome:     OutcomeFailed,
+		Error:       "lock wait timeout",
+		Plan:        plan,
+	}
+	id, err := store.Add(context.Background(), run)
+	if err != nil {
+		t.Fatalf("Add() error = %v", err)
+	}
+	if id != 7 || run.ID != 7 {
+		t.Errorf("Expected run ID 7, got %d (%d)", id, run.ID)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
+func TestStore_List(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create sqlmock: %v", err)
+	}
+	defer db.Close()
+
+	since := time.Date(2026, 10, 11, 0, 0, 0, 0, time.UTC)
+	started := time.Date(2026, 10, 18, 9, 0, 0, 0, time.UTC)
+
+	mock.ExpectQuery(regexp.QuoteMeta("FROM `history` WHERE `started_at` >= ? AND `outcome` = ? AND `plan_hash` LIKE ? "+
+		"ORDER BY `started_at` DESC, `id` DESC LIMIT 10")).
+		WithArgs(since, "failed", "abc%").
+		WillReturnRows(sqlmock.NewRows(runRowColumns).
+			AddRow(3, started, 2500, "deploy", "ci-1", "1.4.0", "src:3306/app", "abcdef", 12, "", "failed", "boom"))
+
+	runs, err := New(db, "history").List(context.Background(), Filter{
+		Since:    since,
+		Outcome:  OutcomeFailed,
+		PlanHash: "abc",
+		Limit:    10,
+	})
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if len(runs) != 1 {
+		t.Fatalf("Expected one run, got %d", len(runs))
+	}
+	if runs[0].Duration != 2500*time.Millisecond || runs[0].Outcome != OutcomeFailed || runs[0].Statements != 12 {
+		t.Errorf("Unexpected run %+v", runs[0])
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
+func TestStore_Get(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create sqlmock: %v", err)
+	}
+	defer db.Close()
+
+	plan := migration.NewMigrationPlan()
+	plan.AddStatement(migration.MigrationStatement{SQL: "DROP TABLE `old`", Type: migration.StatementTypeDropTable, Description: "Drop table old"})
+	encoded, err := json.Marshal(plan)
+	if err != nil {
+		t.Fatalf("Failed to encode plan: %v", err)
+	}
+	started := time.Date(2026, 10, 18, 9, 0, 0, 0, time.UTC)
+
+	mock.ExpectQuery(regexp.QuoteMeta("FROM `schema_sync_history` WHERE `id` = ?")).
+		WithArgs(int64(3)).
+		WillReturnRows(sqlmock.NewRows(append(runRowColumns, "plan")).
+			AddRow(3, started, 10, "deploy", "ci-1", "1.4.0", "src:3306/app", "abc", 1, "backup-1", "succeeded", "", string(encoded)))
+	mock.ExpectQuery(regexp.QuoteMeta("FROM `schema_sync_history` WHERE `id` = ?")).
+		WithArgs(int64(4)).
+		WillReturnRows(sqlmock.NewRows(append(runRowColumns, "plan")))
+
+	store := New(db, "")
+	run, err := store.Get(context.Background(), 3)
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if run == nil || run.BackupID != "backup-1" || run.Plan == nil || len(run.Plan.Statements) != 1 {
+		t.Fatalf("Unexpected run %+v", run)
+	}
+	if run.Plan.Statements[0].SQL != "DROP TABLE `old`" {
+		t.Errorf("Expected the plan to round trip, got %+v", run.Plan.Statements[0])
+	}
+
+	missing, err := store.Get(context.Background(), 4)
+	if err != nil || missing != nil {
+		t.Errorf("Expected no run and no error for an unknown ID, got %+v, %v", missing, err)
+	}
+}
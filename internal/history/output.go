@@ -0,0 +1,105 @@
+package history
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// Format is an output format of the history command
+type Format string
+
+const (
+	FormatText Format = "text"
+	FormatJSON Format = "json"
+)
+
+// ParseFormat converts a user supplied format name into a Format
+func ParseFormat(name string) (Format, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "", "text":
+		return FormatText, nil
+	case "json":
+		return FormatJSON, nil
+	default:
+		return "", fmt.Errorf("unsupported history format: %s (supported: text, json)", name)
+	}
+}
+
+// WriteRuns writes a list of runs as a table, or as a JSON array
+func WriteRuns(w io.Writer, runs []*Run, format Format) error {
+	if format == FormatJSON {
+		return writeJSON(w, runs)
+	}
+
+	if len(runs) == 0 {
+		_, err := fmt.Fprintln(w, "No recorded runs")
+		return err
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "ID\tTARGET\tSTARTED\tUSER\tOUTCOME\tSTATEMENTS\tDURATION\tPLAN\tVERSION")
+	for _, run := range runs {
+		fmt.Fprintf(tw, "%d\t%s\t%s\t%s\t%s\t%d\t%s\t%s\t%s\n", run.ID, run.Target,
+			run.StartedAt.Local().Format(time.DateTime), run.User, run.Outcome, run.Statements,
+			run.Duration.Round(time.Millisecond), shortHash(run.PlanHash), run.ToolVersion)
+	}
+	return tw.Flush()
+}
+
+// WriteRun writes the details and the full plan of a run
+func WriteRun(w io.Writer, run *Run, format Format) error {
+	if format == FormatJSON {
+		return writeJSON(w, run)
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tw, "Run:\t%d\n", run.ID)
+	if run.Target != "" {
+		fmt.Fprintf(tw, "Target:\t%s\n", run.Target)
+	}
+	fmt.Fprintf(tw, "Started:\t%s\n", run.StartedAt.Local().Format(time.RFC3339))
+	fmt.Fprintf(tw, "Duration:\t%s\n", run.Duration.Round(time.Millisecond))
+	fmt.Fprintf(tw, "User:\t%s@%s\n", run.User, run.Host)
+	fmt.Fprintf(tw, "Tool version:\t%s\n", run.ToolVersion)
+	fmt.Fprintf(tw, "Source:\t%s\n", run.Source)
+	fmt.Fprintf(tw, "Plan hash:\t%s\n", run.PlanHash)
+	if run.BackupID != "" {
+		fmt.Fprintf(tw, "Backup:\t%s\n", run.BackupID)
+	}
+	fmt.Fprintf(tw, "Outcome:\t%s\n", run.Outcome)
+	if run.Error != "" {
+		fmt.Fprintf(tw, "Error:\t%s\n", run.Error)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	if run.Plan == nil {
+		return nil
+	}
+
+	fmt.Fprintf(w, "\nPlan (%d statements):\n", len(run.Plan.Statements))
+	for i, stmt := range run.Plan.Statements {
+		fmt.Fprintf(w, "\n-- %d. %s\n%s;\n", i+1, stmt.Description, stmt.SQL)
+	}
+	return nil
+}
+
+// writeJSON writes a value as indented JSON
+func writeJSON(w io.Writer, value interface{}) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(value)
+}
+
+// shortHash abbreviates a plan hash for display
+func shortHash(hash string) string {
+	if len(hash) > 12 {
+		return hash[:12]
+	}
+	return hash
+}
@@ -0,0 +1,66 @@
+package history
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"mysql-schema-sync/internal/migration"
+)
+
+func TestWriteRuns(t *testing.T) {
+	runs := []*Run{{
+		ID:          3,
+		Target:      "db1:3306/app",
+		StartedAt:   time.Date(2026, 10, 18, 9, 0, 0, 0, time.UTC),
+		Duration:    2500 * time.Millisecond,
+		User:        "deploy",
+		ToolVersion: "1.4.0",
+		PlanHash:    "0123456789abcdef",
+		Statements:  12,
+		Outcome:     OutcomeFailed,
+	}}
+
+	var text bytes.Buffer
+	if err := WriteRuns(&text, runs, FormatText); err != nil {
+		t.Fatalf("WriteRuns() error = %v", err)
+	}
+	for _, expected := range []string{"TARGET", "db1:3306/app", "deploy", "failed", "2.5s", "0123456789ab "} {
+		if !strings.Contains(text.String(), expected) {
+			t.Errorf("Expected %q in the text report, got:\n%s", expected, text.String())
+		}
+	}
+
+	var encoded bytes.Buffer
+	if err := WriteRuns(&encoded, runs, FormatJSON); err != nil {
+		t.Fatalf("WriteRuns() error = %v", err)
+	}
+	var decoded []map[string]interface{}
+	if err := json.Unmarshal(encoded.Bytes(), &decoded); err != nil || len(decoded) != 1 || decoded[0]["outcome"] != "failed" {
+		t.Errorf("Unexpected JSON report %s (%v)", encoded.String(), err)
+	}
+
+	var empty bytes.Buffer
+	WriteRuns(&empty, nil, FormatText)
+	if !strings.Contains(empty.String(), "No recorded runs") {
+		t.Errorf("Expected an empty history to be reported, got %q", empty.String())
+	}
+}
+
+func TestWriteRun(t *testing.T) {
+	plan := migration.NewMigrationPlan()
+	plan.AddStatement(migration.MigrationStatement{SQL: "DROP TABLE `old`", Type: migration.StatementTypeDropTable, Description: "Drop table old"})
+
+	var out bytes.Buffer
+	err := WriteRun(&out, &Run{ID: 3, PlanHash: "abc", BackupID: "backup-1", Outcome: OutcomeSucceeded, Plan: plan}, FormatText)
+	if err != nil {
+		t.Fatalf("WriteRun() error = %v", err)
+	}
+	for _, expected := range []string{"Backup:", "backup-1", "Plan (1 statements)", "-- 1. Drop table old", "DROP TABLE `old`;"} {
+		if !strings.Contains(out.String(), expected) {
+			t.Errorf("Expected %q in the run details, got:\n%s", expected, out.String())
+		}
+	}
+}
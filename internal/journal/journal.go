@@ -0,0 +1,262 @@
+package journal
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// DefaultTable is the name of the journal table created in the target database
+const DefaultTable = "schema_sync_journal"
+
+// Status is the execution state of a journaled statement
+type Status string
+
+const (
+	StatusPending    Status = "pending"
+	StatusRunning    Status = "running"
+	StatusCompleted  Status = "completed"
+	StatusFailed     Status = "failed"
+	StatusSuperseded Status = "superseded"
+)
+
+// Config controls the execution journal
+type Config struct {
+	// Enabled records every executed statement in the journal table on the target
+	Enabled bool `mapstructure:"enabled" yaml:"enabled"`
+	// Table is the name of the journal table
+	Table string `mapstructure:"table" yaml:"table"`
+}
+
+// TableName returns the configured journal table, or the default one
+func (c Config) TableName() string {
+	if c.Table == "" {
+		return DefaultTable
+	}
+	return c.Table
+}
+
+// Validate validates the journal configuration
+func (c Config) Validate() error {
+	name := c.TableName()
+	if len(name) > 64 {
+		return fmt.Errorf("journal table name %q is longer than 64 characters", name)
+	}
+	if strings.ContainsAny(name, "`. ") {
+		return fmt.Errorf("journal table name %q must not contain backticks, dots or spaces", name)
+	}
+	return nil
+}
+
+// Entry is the journal record of one statement of a migration plan
+type Entry struct {
+	PlanHash   string
+	Index      int
+	SQL        string
+	Status     Status
+	StartedAt  *time.Time
+	FinishedAt *time.Time
+	Error      string
+}
+
+// Run summarises the journal records of one migration plan
+type Run struct {
+	PlanHash   string
+	Statements int
+	Completed  int
+	StartedAt  *time.Time
+}
+
+// Finished returns true if none of the entries is left to run: every statement either completed
+// or was superseded. A finished run is not resumed; running its plan again starts a new run.
+func Finished(entries []Entry) bool {
+	for _, entry := range entries {
+		if entry.Status != StatusCompleted && entry.Status != StatusSuperseded {
+			return false
+		}
+	}
+	return true
+}
+
+// Journal records the execution of migration statements in a table of the target database, so
+// that an interrupted migration can be detected and resumed
+type Journal struct {
+	db    *sql.DB
+	table string
+	now   func() time.Time
+}
+
+// New creates a Journal that keeps its records in the given table
+func New(db *sql.DB, table string) *Journal {
+	if table == "" {
+		table = DefaultTable
+	}
+	return &Journal{db: db, table: table, now: time.Now}
+}
+
+// Table returns the name of the journal table
+func (j *Journal) Table() string {
+	return j.table
+}
+
+// Ensure creates the journal table if it does not exist
+func (j *Journal) Ensure(ctx context.Context) error {
+	query := fmt.Sprintf("CREATE TABLE IF NOT EXISTS `%s` ("+
+		"`plan_hash` CHAR(64) NOT NULL, "+
+		"`statement_index` INT NOT NULL, "+
+		"`sql_text` MEDIUMTEXT NOT NULL, "+
+		"`status` VARCHAR(16) NOT NULL, "+
+		"`started_at` DATETIME(6) NULL, "+
+		"`finished_at` DATETIME(6) NULL, "+
+		"`error` TEXT NULL, "+
+		"PRIMARY KEY (`plan_hash`, `statement_index`)"+
+		") ENGINE=InnoDB", j.table)
+
+	if _, err := j.db.ExecContext(ctx, query); err != nil {
+		return fmt.Errorf("failed to create journal table %s: %w", j.table, err)
+	}
+	return nil
+}
+
+// Record adds the statements of a plan to the journal as pending
+func (j *Journal) Record(ctx context.Context, planHash string, statements []string) error {
+	tx, err := j.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin journal transaction: %w", err)
+	}
+
+	query := fmt.Sprintf("INSERT INTO `%s` (`plan_hash`, `statement_index`, `sql_text`, `status`) VALUES (?, ?, ?, ?)", j.table)
+	for i, statement := range statements {
+		if _, err := tx.ExecContext(ctx, query, planHash, i, statement, string(StatusPending)); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("failed to record statement %d in journal: %w", i+1, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit journal records: %w", err)
+	}
+	return nil
+}
+
+// Retire removes the records of a finished run of a plan, so that the plan can be recorded again
+func (j *Journal) Retire(ctx context.Context, planHash string) error {
+	query := fmt.Sprintf("DELETE FROM `%s` WHERE `plan_hash` = ?", j.table)
+	if _, err := j.db.ExecContext(ctx, query, planHash); err != nil {
+		return fmt.Errorf("failed to retire journaled plan %s: %w", planHash, err)
+	}
+	return nil
+}
+
+// Entries returns the journal records of a plan in statement order
+func (j *Journal) Entries(ctx context.Context, planHash string) ([]Entry, error) {
+	query := fmt.Sprintf("SELECT `statement_index`, `sql_text`, `status`, `started_at`, `finished_at`, COALESCE(`error`, '') "+
+		"FROM `%s` WHERE `plan_hash` = ? ORDER BY `statement_index`", j.table)
+
+	rows, err := j.db.QueryContext(ctx, query, planHash)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read journal: %w", err)
+	}
+	defer rows.Close()
+
+	entries := make([]Entry, 0)
+	for rows.Next() {
+		entry := Entry{PlanHash: planHash}
+		var status string
+		var startedAt, finishedAt sql.NullTime
+		if err := rows.Scan(&entry.Index, &entry.SQL, &status, &startedAt, &finishedAt, &entry.Error); err != nil {
+			return nil, fmt.Errorf("failed to scan journal entry: %w", err)
+		}
+		entry.Status = Status(status)
+		if startedAt.Valid {
+			entry.StartedAt = &startedAt.Time
+		}
+		if finishedAt.Valid {
+			entry.FinishedAt = &finishedAt.Time
+		}
+		entries = append(entries, entry)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read journal: %w", err)
+	}
+	return entries, nil
+}
+
+// Incomplete returns the plans whose statements were not all completed, oldest first, leaving out
+// runs that were superseded
+func (j *Journal) Incomplete(ctx context.Context) ([]Run, error) {
+	query := fmt.Sprintf("SELECT `plan_hash`, COUNT(*), SUM(`status` = ?), MIN(`started_at`) FROM `%s` "+
+		"GROUP BY `plan_hash` HAVING SUM(`status` IN (?, ?)) < COUNT(*) ORDER BY MIN(`started_at`)", j.table)
+
+	rows, err := j.db.QueryContext(ctx, query, string(StatusCompleted), string(StatusCompleted), string(StatusSuperseded))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read journal: %w", err)
+	}
+	defer rows.Close()
+
+	runs := make([]Run, 0)
+	for rows.Next() {
+		var run Run
+		var startedAt sql.NullTime
+		if err := rows.Scan(&run.PlanHash, &run.Statements, &run.Completed, &startedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan journal run: %w", err)
+		}
+		if startedAt.Valid {
+			run.StartedAt = &startedAt.Time
+		}
+		runs = append(runs, run)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read journal: %w", err)
+	}
+	return runs, nil
+}
+
+// Start marks a statement as running
+func (j *Journal) Start(ctx context.Context, planHash string, index int) error {
+	query := fmt.Sprintf("UPDATE `%s` SET `status` = ?, `started_at` = ?, `finished_at` = NULL, `error` = NULL "+
+		"WHERE `plan_hash` = ? AND `statement_index` = ?", j.table)
+	if _, err := j.db.ExecContext(ctx, query, string(StatusRunning), j.now().UTC(), planHash, index); err != nil {
+		return fmt.Errorf("failed to journal start of statement %d: %w", index+1, err)
+	}
+	return nil
+}
+
+// Complete marks a statement as completed
+func (j *Journal) Complete(ctx context.Context, planHash string, index int) error {
+	return j.finish(ctx, planHash, index, StatusCompleted, nil)
+}
+
+// Fail marks a statement as failed with the given error
+func (j *Journal) Fail(ctx context.Context, planHash string, index int, cause error) error {
+	return j.finish(ctx, planHash, index, StatusFailed, cause)
+}
+
+// finish records the outcome of a statement
+func (j *Journal) finish(ctx context.Context, planHash string, index int, status Status, cause error) error {
+	var message interface{}
+	if cause != nil {
+		message = cause.Error()
+	}
+
+	query := fmt.Sprintf("UPDATE `%s` SET `status` = ?, `finished_at` = ?, `error` = ? "+
+		"WHERE `plan_hash` = ? AND `statement_index` = ?", j.table)
+	if _, err := j.db.ExecContext(ctx, query, string(status), j.now().UTC(), message, planHash, index); err != nil {
+		return fmt.Errorf("failed to journal outcome of statement %d: %w", index+1, err)
+	}
+	return nil
+}
+
+// Supersede marks the unfinished statements of a plan as superseded, so that the plan is no
+// longer reported as incomplete
+func (j *Journal) Supersede(ctx context.Context, planHash string) error {
+	query := fmt.Sprintf("UPDATE `%s` SET `status` = ? WHERE `plan_hash` = ? AND `status` <> ?", j.table)
+	if _, err := j.db.ExecContext(ctx, query, string(StatusSuperseded), planHash, string(StatusCompleted)); err != nil {
+		return fmt.Errorf("failed to supersede journaled plan %s: %w", planHash, err)
+	}
+	return nil
+}
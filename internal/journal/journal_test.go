@@ -0,0 +1,196 @@
+package journal
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func TestConfig_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		table   string
+		wantErr bool
+	}{
+		{"default", "", false},
+		{"custom", "deploy_journal", false},
+		{"qualified", "ops.journal", true},
+		{"backtick", "jour`nal", true},
+		{"too long", strings.Repeat("j", 65), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Config{Enabled: true, Table: tt.table}.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	if name := (Config{}).TableName(); name != DefaultTable {
+		t.Errorf("Expected default table %s, got %s", DefaultTable, name)
+	}
+}
+
+func TestJournal_RecordAndEntries(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create sqlmock: %v", err)
+	}
+	defer db.Close()
+
+	jrnl := New(db, "")
+	ctx := context.Background()
+
+	mock.ExpectExec(regexp.QuoteMeta("CREATE TABLE IF NOT EXISTS `schema_sync_journal`")).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO `schema_sync_journal`")).
+		WithArgs("abc", 0, "CREATE TABLE a (id int)", "pending").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO `schema_sync_journal`")).
+		WithArgs("abc", 1, "DROP TABLE b", "pending").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	started := time.Date(2026, 10, 18, 9, 0, 0, 0, time.UTC)
+	mock.ExpectQuery(regexp.QuoteMeta("FROM `schema_sync_journal` WHERE `plan_hash` = ?")).
+		WithArgs("abc").
+		WillReturnRows(sqlmock.NewRows([]string{"statement_index", "sql_text", "status", "started_at", "finished_at", "error"}).
+			AddRow(0, "CREATE TABLE a (id int)", "completed", started, started, "").
+			AddRow(1, "DROP TABLE b", "failed", started, started, "table is locked"))
+
+	if err := jrnl.Ensure(ctx); err != nil {
+		t.Fatalf("Ensure() error = %v", err)
+	}
+	if err := jrnl.Record(ctx, "abc", []string{"CREATE TABLE a (id int)", "DROP TABLE b"}); err != nil {
+		t.Fatalf("Record() error = %v", err)
+	}
+
+	entries, err := jrnl.Entries(ctx, "abc")
+	if err != nil {
+		t.Fatalf("Entries() error = %v", err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("Expected 2 entries, got %d", len(entries))
+	}
+	if entries[0].Status != StatusCompleted || entries[0].StartedAt == nil {
+		t.Errorf("Unexpected first entry %+v", entries[0])
+	}
+	if entries[1].Status != StatusFailed || entries[1].Error != "table is locked" {
+		t.Errorf("Unexpected second entry %+v", entries[1])
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
+func TestJournal_RecordRollsBackOnError(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create sqlmock: %v", err)
+	}
+	defer db.Close()
+
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT INTO").WillReturnError(errors.New("disk full"))
+	mock.ExpectRollback()
+
+	if err := New(db, "journal").Record(context.Background(), "abc", []string{"DROP TABLE b"}); err == nil {
+		t.Error("Expected Record() to fail")
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
+func TestJournal_StatementLifecycle(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create sqlmock: %v", err)
+	}
+	defer db.Close()
+
+	now := time.Date(2026, 10, 18, 9, 0, 0, 0, time.UTC)
+	jrnl := New(db, "journal")
+	jrnl.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE `journal` SET `status` = ?, `started_at` = ?")).
+		WithArgs("running", now, "abc", 3).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE `journal` SET `status` = ?, `finished_at` = ?, `error` = ?")).
+		WithArgs("failed", now, "lock wait timeout", "abc", 3).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE `journal` SET `status` = ?, `finished_at` = ?, `error` = ?")).
+		WithArgs("completed", now, nil, "abc", 3).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE `journal` SET `status` = ? WHERE `plan_hash` = ? AND `status` <> ?")).
+		WithArgs("superseded", "old", "completed").
+		WillReturnResult(sqlmock.NewResult(0, 2))
+
+	if err := jrnl.Start(ctx, "abc", 3); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	if err := jrnl.Fail(ctx, "abc", 3, errors.New("lock wait timeout")); err != nil {
+		t.Fatalf("Fail() error = %v", err)
+	}
+	if err := jrnl.Complete(ctx, "abc", 3); err != nil {
+		t.Fatalf("Complete() error = %v", err)
+	}
+	if err := jrnl.Supersede(ctx, "old"); err != nil {
+		t.Fatalf("Supersede() error = %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
+func TestJournal_Incomplete(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create sqlmock: %v", err)
+	}
+	defer db.Close()
+
+	mock.ExpectQuery(regexp.QuoteMeta("GROUP BY `plan_hash`")).
+		WithArgs("completed", "completed", "superseded").
+		WillReturnRows(sqlmock.NewRows([]string{"plan_hash", "count", "completed", "started_at"}).
+			AddRow("abc", 60, 36, time.Date(2026, 10, 18, 9, 0, 0, 0, time.UTC)))
+
+	runs, err := New(db, "journal").Incomplete(context.Background())
+	if err != nil {
+		t.Fatalf("Incomplete() error = %v", err)
+	}
+	if len(runs) != 1 || runs[0].PlanHash != "abc" || runs[0].Statements != 60 || runs[0].Completed != 36 {
+		t.Errorf("Unexpected runs %+v", runs)
+	}
+}
+
+func TestFinished(t *testing.T) {
+	entries := func(statuses ...Status) []Entry {
+		result := make([]Entry, len(statuses))
+		for i, status := range statuses {
+			result[i] = Entry{PlanHash: "abc", Index: i, Status: status}
+		}
+		return result
+	}
+
+	if !Finished(entries(StatusCompleted, StatusCompleted)) {
+		t.Error("Expected a completed run to be finished")
+	}
+	if !Finished(entries(StatusCompleted, StatusSuperseded)) {
+		t.Error("Expected a superseded run to be finished")
+	}
+	if Finished(entries(StatusCompleted, StatusFailed)) || Finished(entries(StatusRunning)) || Finished(entries(StatusPending)) {
+		t.Error("Expected a run with statements left to run not to be finished")
+	}
+}
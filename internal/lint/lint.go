@@ -0,0 +1,460 @@
+package lint
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"mysql-schema-sync/internal/schema"
+)
+
+// Rule identifiers reported with every finding
+const (
+	RuleMissingPrimaryKey    = "missing-primary-key"
+	RuleDuplicateIndex       = "duplicate-index"
+	RuleRedundantIndex       = "redundant-index"
+	RuleUnindexedForeignKey  = "unindexed-foreign-key"
+	RuleUTF8MB3Column        = "utf8mb3-column"
+	RuleFloatMoneyColumn     = "float-money-column"
+	RuleNullableUniqueColumn = "nullable-unique-column"
+	RuleOversizedIndexColumn = "oversized-index-column"
+	RuleMixedCollationJoin   = "mixed-collation-join"
+)
+
+// maxIndexColumnBytes is the size from which an indexed string column is reported. Every entry
+// of the index may carry the full value, so longer keys bloat the index; VARCHAR(255) in utf8mb4
+// stays just below the limit.
+const maxIndexColumnBytes = 1024
+
+// RuleInfo describes a lint rule
+type RuleInfo struct {
+	ID          string
+	Description string
+	Type        schema.WarningType
+	Severity    schema.WarningSeverity
+}
+
+// Rules lists every lint rule in the order they are checked
+var Rules = []RuleInfo{
+	{RuleMissingPrimaryKey, "Tables should have a primary key", schema.WarningTypePerformance, schema.SeverityHigh},
+	{RuleDuplicateIndex, "Indexes should not duplicate another index", schema.WarningTypePerformance, schema.SeverityMedium},
+	{RuleRedundantIndex, "Indexes should not be a left prefix of another index", schema.WarningTypePerformance, schema.SeverityLow},
+	{RuleUnindexedForeignKey, "Foreign key columns should be the left prefix of an index", schema.WarningTypePerformance, schema.SeverityHigh},
+	{RuleUTF8MB3Column, "Text columns should use utf8mb4 rather than the deprecated utf8mb3", schema.WarningTypeCompatibility, schema.SeverityMedium},
+	{RuleFloatMoneyColumn, "Monetary columns should use DECIMAL rather than FLOAT or DOUBLE", schema.WarningTypeDataLoss, schema.SeverityHigh},
+	{RuleNullableUniqueColumn, "Columns of unique keys should be NOT NULL", schema.WarningTypeCompatibility, schema.SeverityMedium},
+	{RuleOversizedIndexColumn, "Indexed string columns should fit in 1 KB or use a prefix index", schema.WarningTypePerformance, schema.SeverityMedium},
+	{RuleMixedCollationJoin, "Foreign key columns should use the collation of the columns they reference", schema.WarningTypePerformance, schema.SeverityMedium},
+}
+
+// ruleInfo returns the description of a rule
+func ruleInfo(id string) RuleInfo {
+	for _, rule := range Rules {
+		if rule.ID == id {
+			return rule
+		}
+	}
+	return RuleInfo{ID: id, Type: schema.WarningTypePerformance, Severity: schema.SeverityLow}
+}
+
+// moneyColumnPattern matches column names that usually hold monetary amounts
+var moneyColumnPattern = regexp.MustCompile(`(?i)(^|_)(price|amount|cost|total|balance|fee|fees|salary|money|tax|payment|revenue|discount|subtotal)(_|$)`)
+
+// stringLengthPattern extracts the length of CHAR and VARCHAR column types
+var stringLengthPattern = regexp.MustCompile(`(?i)^(?:var)?char\((\d+)\)`)
+
+// Finding is a schema smell reported by a lint rule
+type Finding struct {
+	Rule string
+	schema.Warning
+}
+
+// Report holds the findings of linting one database
+type Report struct {
+	Database string
+	Findings []Finding
+}
+
+// ValidationResult returns the findings as validation warnings so they can be formatted like
+// any other validation result
+func (r *Report) ValidationResult() *schema.ValidationResult {
+	result := &schema.ValidationResult{
+		IsValid:  true,
+		Warnings: make([]schema.Warning, 0, len(r.Findings)),
+		Errors:   make([]schema.ValidationError, 0),
+	}
+	for _, finding := range r.Findings {
+		warning := finding.Warning
+		warning.Message = fmt.Sprintf("[%s] %s", finding.Rule, warning.Message)
+		result.Warnings = append(result.Warnings, warning)
+	}
+	return result
+}
+
+// CountAtLeast returns the number of findings at or above the given severity
+func (r *Report) CountAtLeast(severity schema.WarningSeverity) int {
+	count := 0
+	for _, finding := range r.Findings {
+		if severityRank(finding.Severity) >= severityRank(severity) {
+			count++
+		}
+	}
+	return count
+}
+
+// ParseSeverity converts a user supplied severity name into a WarningSeverity
+func ParseSeverity(name string) (schema.WarningSeverity, error) {
+	switch strings.ToUpper(strings.TrimSpace(name)) {
+	case "LOW":
+		return schema.SeverityLow, nil
+	case "MEDIUM":
+		return schema.SeverityMedium, nil
+	case "HIGH":
+		return schema.SeverityHigh, nil
+	case "CRITICAL":
+		return schema.SeverityCritical, nil
+	default:
+		return "", fmt.Errorf("unsupported severity: %s (supported: low, medium, high, critical)", name)
+	}
+}
+
+// severityRank orders severities from least to most severe
+func severityRank(severity schema.WarningSeverity) int {
+	switch severity {
+	case schema.SeverityLow:
+		return 1
+	case schema.SeverityMedium:
+		return 2
+	case schema.SeverityHigh:
+		return 3
+	case schema.SeverityCritical:
+		return 4
+	default:
+		return 0
+	}
+}
+
+// Linter reports schema smells in a single database
+type Linter struct{}
+
+// NewLinter creates a new Linter
+func NewLinter() *Linter {
+	return &Linter{}
+}
+
+// Lint checks every table of the schema. Foreign keys are read from the table constraints and
+// charsets holds the character set and collation of string columns keyed by table and column.
+func (l *Linter) Lint(s *schema.Schema, charsets map[string]map[string]*schema.ColumnCharset) *Report {
+	report := &Report{Database: s.Name, Findings: make([]Finding, 0)}
+
+	tableNames := make([]string, 0, len(s.Tables))
+	for name := range s.Tables {
+		tableNames = append(tableNames, name)
+	}
+	sort.Strings(tableNames)
+
+	for _, name := range tableNames {
+		table := s.Tables[name]
+		columnCharsets := charsets[name]
+
+		l.checkPrimaryKey(table, report)
+		l.checkIndexes(table, report)
+		l.checkForeignKeys(s, table, charsets, report)
+		l.checkColumns(table, columnCharsets, report)
+		l.checkUniqueKeys(table, report)
+		l.checkIndexedColumnSizes(table, columnCharsets, report)
+	}
+
+	return report
+}
+
+// add appends a finding using the rule's type and severity
+func (r *Report) add(rule, table, column, message, suggestion string) {
+	info := ruleInfo(rule)
+	r.Findings = append(r.Findings, Finding{
+		Rule: rule,
+		Warning: schema.Warning{
+			Type:       info.Type,
+			Severity:   info.Severity,
+			Message:    message,
+			TableName:  table,
+			ColumnName: column,
+			Suggestion: suggestion,
+		},
+	})
+}
+
+// checkPrimaryKey reports tables without a primary key
+func (l *Linter) checkPrimaryKey(table *schema.Table, report *Report) {
+	for _, index := range table.Indexes {
+		if index.IsPrimary {
+			return
+		}
+	}
+	report.add(RuleMissingPrimaryKey, table.Name, "",
+		fmt.Sprintf("Table '%s' has no primary key", table.Name),
+		"Add a primary key; without one InnoDB clusters on a hidden row ID and row-based replicas scan the table for every change")
+}
+
+// checkIndexes reports indexes that duplicate or are a left prefix of another index
+func (l *Linter) checkIndexes(table *schema.Table, report *Report) {
+	indexes := sortedIndexes(table)
+
+	for i, index := range indexes {
+		if index.IsPrimary || !isBTree(index) {
+			continue
+		}
+
+		var duplicateOf, prefixOf *schema.Index
+		for j, other := range indexes {
+			if i == j || !isBTree(other) {
+				continue
+			}
+
+			if sameColumns(index.Columns, other.Columns) {
+				// Keep the stronger index, or the first one by name when both are equivalent
+				if other.IsPrimary || (other.IsUnique && !index.IsUnique) ||
+					(other.IsUnique == index.IsUnique && j < i) {
+					duplicateOf = other
+					break
+				}
+				continue
+			}
+
+			if !index.IsUnique && prefixOf == nil && isLeftPrefix(index.Columns, other.Columns) {
+				prefixOf = other
+			}
+		}
+
+		switch {
+		case duplicateOf != nil:
+			report.add(RuleDuplicateIndex, table.Name, "",
+				fmt.Sprintf("Index '%s' on table '%s' duplicates index '%s' (%s)",
+					index.Name, table.Name, duplicateOf.Name, strings.Join(index.Columns, ", ")),
+				fmt.Sprintf("Drop index '%s'; it slows down writes without serving any extra query", index.Name))
+		case prefixOf != nil:
+			report.add(RuleRedundantIndex, table.Name, "",
+				fmt.Sprintf("Index '%s' on table '%s' is a left prefix of index '%s'", index.Name, table.Name, prefixOf.Name),
+				fmt.Sprintf("Drop index '%s'; queries on (%s) can use '%s'",
+					index.Name, strings.Join(index.Columns, ", "), prefixOf.Name))
+		}
+	}
+}
+
+// checkForeignKeys reports foreign keys without a supporting index and foreign key columns whose
+// collation differs from the column they reference
+func (l *Linter) checkForeignKeys(s *schema.Schema, table *schema.Table, charsets map[string]map[string]*schema.ColumnCharset, report *Report) {
+	for _, constraint := range sortedForeignKeys(table) {
+		supported := false
+		for _, index := range table.Indexes {
+			if isLeftPrefix(constraint.Columns, index.Columns) {
+				supported = true
+				break
+			}
+		}
+		if !supported {
+			report.add(RuleUnindexedForeignKey, table.Name, strings.Join(constraint.Columns, ", "),
+				fmt.Sprintf("Foreign key '%s' on table '%s' (%s) has no supporting index",
+					constraint.Name, table.Name, strings.Join(constraint.Columns, ", ")),
+				"Add an index starting with the foreign key columns so parent deletes and joins do not scan the table")
+		}
+
+		for i, column := range constraint.Columns {
+			if i >= len(constraint.ReferencedColumns) {
+				break
+			}
+			refColumn := constraint.ReferencedColumns[i]
+			local := charsets[table.Name][column]
+			referenced := charsets[constraint.ReferencedTable][refColumn]
+			if local == nil || referenced == nil || strings.EqualFold(local.Collation, referenced.Collation) {
+				continue
+			}
+			if _, exists := s.Tables[constraint.ReferencedTable]; !exists {
+				continue
+			}
+
+			report.add(RuleMixedCollationJoin, table.Name, column,
+				fmt.Sprintf("Column '%s.%s' uses collation %s but references '%s.%s' with collation %s",
+					table.Name, column, local.Collation, constraint.ReferencedTable, refColumn, referenced.Collation),
+				"Use the same character set and collation on both sides so joins can use the index")
+		}
+	}
+}
+
+// checkColumns reports utf8mb3 columns and floating point columns holding money
+func (l *Linter) checkColumns(table *schema.Table, charsets map[string]*schema.ColumnCharset, report *Report) {
+	for _, column := range sortedColumns(table) {
+		if charset := charsets[column.Name]; charset != nil {
+			switch strings.ToLower(charset.CharacterSet) {
+			case "utf8", "utf8mb3":
+				report.add(RuleUTF8MB3Column, table.Name, column.Name,
+					fmt.Sprintf("Column '%s.%s' uses the utf8mb3 character set", table.Name, column.Name),
+					"Convert the column to utf8mb4; utf8mb3 cannot store 4-byte characters such as emoji and is deprecated")
+			}
+		}
+
+		baseType := strings.ToLower(column.DataType)
+		if idx := strings.IndexAny(baseType, "( "); idx >= 0 {
+			baseType = baseType[:idx]
+		}
+		switch baseType {
+		case "float", "double", "real":
+			if moneyColumnPattern.MatchString(column.Name) {
+				report.add(RuleFloatMoneyColumn, table.Name, column.Name,
+					fmt.Sprintf("Column '%s.%s' stores a monetary amount as %s", table.Name, column.Name, column.DataType),
+					"Use DECIMAL; binary floating point cannot represent most decimal amounts exactly")
+			}
+		}
+	}
+}
+
+// checkUniqueKeys reports nullable columns in unique keys, which let duplicate rows in as NULLs
+// never compare equal
+func (l *Linter) checkUniqueKeys(table *schema.Table, report *Report) {
+	for _, index := range sortedIndexes(table) {
+		if !index.IsUnique || index.IsPrimary {
+			continue
+		}
+		for _, columnName := range index.Columns {
+			column := table.Columns[columnName]
+			if column == nil || !column.IsNullable {
+				continue
+			}
+			report.add(RuleNullableUniqueColumn, table.Name, columnName,
+				fmt.Sprintf("Unique key '%s' on table '%s' includes nullable column '%s'", index.Name, table.Name, columnName),
+				"Make the column NOT NULL; a unique key accepts any number of rows with NULL in it")
+		}
+	}
+}
+
+// checkIndexedColumnSizes reports indexed string columns longer than maxIndexColumnBytes
+func (l *Linter) checkIndexedColumnSizes(table *schema.Table, charsets map[string]*schema.ColumnCharset, report *Report) {
+	seen := make(map[string]bool)
+	for _, index := range sortedIndexes(table) {
+		if !isBTree(index) {
+			continue
+		}
+		for _, columnName := range index.Columns {
+			column := table.Columns[columnName]
+			if column == nil || seen[columnName] {
+				continue
+			}
+
+			match := stringLengthPattern.FindStringSubmatch(column.DataType)
+			if match == nil {
+				continue
+			}
+			length, err := strconv.Atoi(match[1])
+			if err != nil {
+				continue
+			}
+
+			charset := ""
+			if info := charsets[columnName]; info != nil {
+				charset = info.CharacterSet
+			}
+			bytes := length * bytesPerChar(charset)
+			if bytes <= maxIndexColumnBytes {
+				continue
+			}
+
+			seen[columnName] = true
+			report.add(RuleOversizedIndexColumn, table.Name, columnName,
+				fmt.Sprintf("Index '%s' on table '%s' includes %s column '%s' of up to %d bytes",
+					index.Name, table.Name, column.DataType, columnName, bytes),
+				fmt.Sprintf("Shorten the column or index a prefix, e.g. (%s(%d))", columnName, maxIndexColumnBytes/bytesPerChar(charset)))
+		}
+	}
+}
+
+// bytesPerChar returns the maximum bytes per character of a character set; unknown character
+// sets are assumed to be utf8mb4
+func bytesPerChar(charset string) int {
+	switch strings.ToLower(charset) {
+	case "latin1", "ascii", "binary", "latin2", "cp1250", "cp1251", "cp1252":
+		return 1
+	case "ucs2":
+		return 2
+	case "utf8", "utf8mb3":
+		return 3
+	default:
+		return 4
+	}
+}
+
+// isBTree returns true for indexes that can serve left-prefix lookups
+func isBTree(index *schema.Index) bool {
+	switch strings.ToUpper(index.IndexType) {
+	case "FULLTEXT", "SPATIAL":
+		return false
+	default:
+		return true
+	}
+}
+
+// sameColumns returns true if both indexes cover the same columns in the same order
+func sameColumns(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if !strings.EqualFold(a[i], b[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// isLeftPrefix returns true if prefix matches the first columns of columns
+func isLeftPrefix(prefix, columns []string) bool {
+	if len(prefix) == 0 || len(prefix) > len(columns) {
+		return false
+	}
+	for i := range prefix {
+		if !strings.EqualFold(prefix[i], columns[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// sortedIndexes returns the indexes of a table ordered by name, with the primary key first
+func sortedIndexes(table *schema.Table) []*schema.Index {
+	indexes := append([]*schema.Index(nil), table.Indexes...)
+	sort.Slice(indexes, func(i, j int) bool {
+		if indexes[i].IsPrimary != indexes[j].IsPrimary {
+			return indexes[i].IsPrimary
+		}
+		return indexes[i].Name < indexes[j].Name
+	})
+	return indexes
+}
+
+// sortedColumns returns the columns of a table in ordinal order
+func sortedColumns(table *schema.Table) []*schema.Column {
+	columns := make([]*schema.Column, 0, len(table.Columns))
+	for _, column := range table.Columns {
+		columns = append(columns, column)
+	}
+	sort.Slice(columns, func(i, j int) bool {
+		if columns[i].Position != columns[j].Position {
+			return columns[i].Position < columns[j].Position
+		}
+		return columns[i].Name < columns[j].Name
+	})
+	return columns
+}
+
+// sortedForeignKeys returns the foreign keys of a table ordered by name
+func sortedForeignKeys(table *schema.Table) []*schema.Constraint {
+	var constraints []*schema.Constraint
+	for _, constraint := range table.Constraints {
+		if constraint.Type == schema.ConstraintTypeForeignKey {
+			constraints = append(constraints, constraint)
+		}
+	}
+	sort.Slice(constraints, func(i, j int) bool { return constraints[i].Name < constraints[j].Name })
+	return constraints
+}
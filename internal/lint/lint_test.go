@@ -0,0 +1,137 @@
+package lint
+
+import (
+	"strings"
+	"testing"
+
+	"mysql-schema-sync/internal/schema"
+)
+
+// newLintSchema builds a schema exhibiting every smell the linter reports
+func newLintSchema() (*schema.Schema, map[string]map[string]*schema.ColumnCharset) {
+	s := schema.NewSchema("app")
+
+	users := schema.NewTable("users")
+	users.AddColumn(&schema.Column{Name: "id", DataType: "int", Position: 1})
+	users.AddColumn(&schema.Column{Name: "email", DataType: "varchar(255)", IsNullable: true, Position: 2})
+	users.AddColumn(&schema.Column{Name: "code", DataType: "varchar(64)", Position: 3})
+	users.AddColumn(&schema.Column{Name: "bio", DataType: "varchar(500)", IsNullable: true, Position: 4})
+	users.AddIndex(&schema.Index{Name: "PRIMARY", TableName: "users", Columns: []string{"id"}, IsPrimary: true, IsUnique: true, IndexType: "BTREE"})
+	users.AddIndex(&schema.Index{Name: "uk_email", TableName: "users", Columns: []string{"email"}, IsUnique: true, IndexType: "BTREE"})
+	users.AddIndex(&schema.Index{Name: "idx_email", TableName: "users", Columns: []string{"email"}, IndexType: "BTREE"})
+	users.AddIndex(&schema.Index{Name: "idx_code", TableName: "users", Columns: []string{"code"}, IndexType: "BTREE"})
+	users.AddIndex(&schema.Index{Name: "idx_code_email", TableName: "users", Columns: []string{"code", "email"}, IndexType: "BTREE"})
+	users.AddIndex(&schema.Index{Name: "idx_bio", TableName: "users", Columns: []string{"bio"}, IndexType: "BTREE"})
+	users.AddIndex(&schema.Index{Name: "ft_bio", TableName: "users", Columns: []string{"bio"}, IndexType: "FULLTEXT"})
+	s.AddTable(users)
+
+	orders := schema.NewTable("orders")
+	orders.AddColumn(&schema.Column{Name: "user_code", DataType: "varchar(64)", Position: 1})
+	orders.AddColumn(&schema.Column{Name: "total_amount", DataType: "float", Position: 2})
+	orders.AddColumn(&schema.Column{Name: "weight", DataType: "double", Position: 3})
+	orders.AddConstraint(&schema.Constraint{Name: "fk_orders_user_code", TableName: "orders", Type: schema.ConstraintTypeForeignKey,
+		Columns: []string{"user_code"}, ReferencedTable: "users", ReferencedColumns: []string{"code"}})
+	s.AddTable(orders)
+
+	charsets := map[string]map[string]*schema.ColumnCharset{
+		"users": {
+			"email": {CharacterSet: "utf8mb4", Collation: "utf8mb4_0900_ai_ci"},
+			"code":  {CharacterSet: "utf8mb4", Collation: "utf8mb4_0900_ai_ci"},
+			"bio":   {CharacterSet: "utf8mb4", Collation: "utf8mb4_0900_ai_ci"},
+		},
+		"orders": {
+			"user_code": {CharacterSet: "utf8mb3", Collation: "utf8mb3_general_ci"},
+		},
+	}
+
+	return s, charsets
+}
+
+func TestLinter_Lint(t *testing.T) {
+	s, charsets := newLintSchema()
+	report := NewLinter().Lint(s, charsets)
+
+	got := make([]string, 0, len(report.Findings))
+	for _, finding := range report.Findings {
+		got = append(got, finding.Rule+" "+finding.TableName+"."+finding.ColumnName)
+	}
+
+	want := []string{
+		"missing-primary-key orders.",
+		"unindexed-foreign-key orders.user_code",
+		"mixed-collation-join orders.user_code",
+		"utf8mb3-column orders.user_code",
+		"float-money-column orders.total_amount",
+		"redundant-index users.",
+		"duplicate-index users.",
+		"nullable-unique-column users.email",
+		"oversized-index-column users.bio",
+	}
+
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("Unexpected findings:\n%s\n\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+
+	for _, finding := range report.Findings {
+		switch finding.Rule {
+		case RuleDuplicateIndex:
+			if !strings.Contains(finding.Message, "Index 'idx_email'") || !strings.Contains(finding.Message, "'uk_email'") {
+				t.Errorf("Expected the non-unique index to be reported as the duplicate: %s", finding.Message)
+			}
+		case RuleRedundantIndex:
+			if !strings.Contains(finding.Message, "Index 'idx_code'") || !strings.Contains(finding.Message, "'idx_code_email'") {
+				t.Errorf("Unexpected redundant index finding: %s", finding.Message)
+			}
+		case RuleOversizedIndexColumn:
+			if !strings.Contains(finding.Message, "2000 bytes") || !strings.Contains(finding.Suggestion, "bio(256)") {
+				t.Errorf("Unexpected oversized column finding: %s / %s", finding.Message, finding.Suggestion)
+			}
+		}
+		if finding.Severity != ruleInfo(finding.Rule).Severity {
+			t.Errorf("Expected %s findings to use the rule severity", finding.Rule)
+		}
+	}
+}
+
+func TestLinter_CleanSchema(t *testing.T) {
+	s := schema.NewSchema("app")
+	table := schema.NewTable("accounts")
+	table.AddColumn(&schema.Column{Name: "id", DataType: "bigint", Position: 1})
+	table.AddColumn(&schema.Column{Name: "balance", DataType: "decimal(12,2)", Position: 2})
+	table.AddIndex(&schema.Index{Name: "PRIMARY", TableName: "accounts", Columns: []string{"id"}, IsPrimary: true, IsUnique: true, IndexType: "BTREE"})
+	s.AddTable(table)
+
+	report := NewLinter().Lint(s, nil)
+	if len(report.Findings) != 0 {
+		t.Errorf("Expected no findings, got %+v", report.Findings)
+	}
+}
+
+func TestReport_CountAtLeast(t *testing.T) {
+	s, charsets := newLintSchema()
+	report := NewLinter().Lint(s, charsets)
+
+	high := report.CountAtLeast(schema.SeverityHigh)
+	low := report.CountAtLeast(schema.SeverityLow)
+	if high != 3 || low != len(report.Findings) {
+		t.Errorf("CountAtLeast() = %d high, %d low of %d", high, low, len(report.Findings))
+	}
+	if report.CountAtLeast(schema.SeverityCritical) != 0 {
+		t.Error("Expected no critical findings")
+	}
+
+	result := report.ValidationResult()
+	if !result.IsValid || len(result.Warnings) != len(report.Findings) ||
+		!strings.HasPrefix(result.Warnings[0].Message, "[missing-primary-key] ") {
+		t.Errorf("Unexpected validation result: %+v", result)
+	}
+}
+
+func TestParseSeverity(t *testing.T) {
+	if severity, err := ParseSeverity("High"); err != nil || severity != schema.SeverityHigh {
+		t.Errorf("ParseSeverity() = %v, %v", severity, err)
+	}
+	if _, err := ParseSeverity("severe"); err == nil {
+		t.Error("Expected an error for an unknown severity")
+	}
+}
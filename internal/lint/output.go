@@ -0,0 +1,180 @@
+package lint
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"mysql-schema-sync/internal/schema"
+)
+
+// Format identifies a lint report output format
+type Format string
+
+const (
+	FormatText  Format = "text"
+	FormatJSON  Format = "json"
+	FormatSARIF Format = "sarif"
+)
+
+// ParseFormat converts a user supplied format name into a Format
+func ParseFormat(name string) (Format, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "", "text":
+		return FormatText, nil
+	case "json":
+		return FormatJSON, nil
+	case "sarif":
+		return FormatSARIF, nil
+	default:
+		return "", fmt.Errorf("unsupported lint format: %s (supported: text, json, sarif)", name)
+	}
+}
+
+// jsonFinding is the JSON representation of a finding
+type jsonFinding struct {
+	Rule       string `json:"rule"`
+	Type       string `json:"type"`
+	Severity   string `json:"severity"`
+	Table      string `json:"table"`
+	Column     string `json:"column,omitempty"`
+	Message    string `json:"message"`
+	Suggestion string `json:"suggestion,omitempty"`
+}
+
+// WriteJSON writes the report as indented JSON
+func (r *Report) WriteJSON(w io.Writer) error {
+	findings := make([]jsonFinding, 0, len(r.Findings))
+	for _, finding := range r.Findings {
+		findings = append(findings, jsonFinding{
+			Rule:       finding.Rule,
+			Type:       string(finding.Type),
+			Severity:   string(finding.Severity),
+			Table:      finding.TableName,
+			Column:     finding.ColumnName,
+			Message:    finding.Message,
+			Suggestion: finding.Suggestion,
+		})
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(struct {
+		Database string        `json:"database"`
+		Findings []jsonFinding `json:"findings"`
+	}{r.Database, findings})
+}
+
+// SARIF 2.1.0 types, limited to the properties the report uses
+type (
+	sarifLog struct {
+		Schema  string     `json:"$schema"`
+		Version string     `json:"version"`
+		Runs    []sarifRun `json:"runs"`
+	}
+	sarifRun struct {
+		Tool    sarifTool     `json:"tool"`
+		Results []sarifResult `json:"results"`
+	}
+	sarifTool struct {
+		Driver sarifDriver `json:"driver"`
+	}
+	sarifDriver struct {
+		Name  string      `json:"name"`
+		Rules []sarifRule `json:"rules"`
+	}
+	sarifRule struct {
+		ID                   string            `json:"id"`
+		ShortDescription     sarifMessage      `json:"shortDescription"`
+		DefaultConfiguration sarifRuleDefaults `json:"defaultConfiguration"`
+	}
+	sarifRuleDefaults struct {
+		Level string `json:"level"`
+	}
+	sarifMessage struct {
+		Text string `json:"text"`
+	}
+	sarifResult struct {
+		RuleID    string          `json:"ruleId"`
+		RuleIndex int             `json:"ruleIndex"`
+		Level     string          `json:"level"`
+		Message   sarifMessage    `json:"message"`
+		Locations []sarifLocation `json:"locations"`
+	}
+	sarifLocation struct {
+		LogicalLocations []sarifLogicalLocation `json:"logicalLocations"`
+	}
+	sarifLogicalLocation struct {
+		Name               string `json:"name"`
+		FullyQualifiedName string `json:"fullyQualifiedName"`
+		Kind               string `json:"kind"`
+	}
+)
+
+// WriteSARIF writes the report as a SARIF 2.1.0 log for code scanning tools. Findings are located
+// by logical locations naming the table or column.
+func (r *Report) WriteSARIF(w io.Writer) error {
+	rules := make([]sarifRule, 0, len(Rules))
+	ruleIndex := make(map[string]int, len(Rules))
+	for i, rule := range Rules {
+		ruleIndex[rule.ID] = i
+		rules = append(rules, sarifRule{
+			ID:                   rule.ID,
+			ShortDescription:     sarifMessage{Text: rule.Description},
+			DefaultConfiguration: sarifRuleDefaults{Level: sarifLevel(rule.Severity)},
+		})
+	}
+
+	results := make([]sarifResult, 0, len(r.Findings))
+	for _, finding := range r.Findings {
+		location := sarifLogicalLocation{
+			Name:               finding.TableName,
+			FullyQualifiedName: r.Database + "." + finding.TableName,
+			Kind:               "table",
+		}
+		if finding.ColumnName != "" && !strings.Contains(finding.ColumnName, ",") {
+			location.Name = finding.ColumnName
+			location.FullyQualifiedName += "." + finding.ColumnName
+			location.Kind = "column"
+		}
+
+		message := finding.Message
+		if finding.Suggestion != "" {
+			message += ". " + finding.Suggestion
+		}
+
+		results = append(results, sarifResult{
+			RuleID:    finding.Rule,
+			RuleIndex: ruleIndex[finding.Rule],
+			Level:     sarifLevel(finding.Severity),
+			Message:   sarifMessage{Text: message},
+			Locations: []sarifLocation{{LogicalLocations: []sarifLogicalLocation{location}}},
+		})
+	}
+
+	log := sarifLog{
+		Schema:  "https://json.schemastore.org/sarif-2.1.0.json",
+		Version: "2.1.0",
+		Runs: []sarifRun{{
+			Tool:    sarifTool{Driver: sarifDriver{Name: "mysql-schema-sync", Rules: rules}},
+			Results: results,
+		}},
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(log)
+}
+
+// sarifLevel maps a warning severity to a SARIF result level
+func sarifLevel(severity schema.WarningSeverity) string {
+	switch severity {
+	case schema.SeverityCritical, schema.SeverityHigh:
+		return "error"
+	case schema.SeverityMedium:
+		return "warning"
+	default:
+		return "note"
+	}
+}
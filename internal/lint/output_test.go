@@ -0,0 +1,97 @@
+package lint
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+)
+
+func TestParseFormat(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    Format
+		wantErr bool
+	}{
+		{"", FormatText, false},
+		{"JSON", FormatJSON, false},
+		{"sarif", FormatSARIF, false},
+		{"xml", "", true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseFormat(tt.input)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("ParseFormat(%q) = %q, %v", tt.input, got, err)
+		}
+	}
+}
+
+func TestReport_WriteJSON(t *testing.T) {
+	s, charsets := newLintSchema()
+	report := NewLinter().Lint(s, charsets)
+
+	var buf bytes.Buffer
+	if err := report.WriteJSON(&buf); err != nil {
+		t.Fatalf("WriteJSON() error = %v", err)
+	}
+
+	var decoded struct {
+		Database string `json:"database"`
+		Findings []struct {
+			Rule     string `json:"rule"`
+			Severity string `json:"severity"`
+			Table    string `json:"table"`
+			Column   string `json:"column"`
+		} `json:"findings"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil {
+		t.Fatalf("Failed to decode JSON report: %v", err)
+	}
+
+	if decoded.Database != "app" || len(decoded.Findings) != len(report.Findings) {
+		t.Fatalf("Unexpected JSON report: %s", buf.String())
+	}
+	first := decoded.Findings[0]
+	if first.Rule != RuleMissingPrimaryKey || first.Severity != "HIGH" || first.Table != "orders" {
+		t.Errorf("Unexpected first finding: %+v", first)
+	}
+}
+
+func TestReport_WriteSARIF(t *testing.T) {
+	s, charsets := newLintSchema()
+	report := NewLinter().Lint(s, charsets)
+
+	var buf bytes.Buffer
+	if err := report.WriteSARIF(&buf); err != nil {
+		t.Fatalf("WriteSARIF() error = %v", err)
+	}
+
+	var decoded sarifLog
+	if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil {
+		t.Fatalf("Failed to decode SARIF report: %v", err)
+	}
+
+	if decoded.Version != "2.1.0" || len(decoded.Runs) != 1 {
+		t.Fatalf("Unexpected SARIF log: %s", buf.String())
+	}
+	run := decoded.Runs[0]
+	if len(run.Tool.Driver.Rules) != len(Rules) || len(run.Results) != len(report.Findings) {
+		t.Fatalf("Expected %d rules and %d results, got %d and %d",
+			len(Rules), len(report.Findings), len(run.Tool.Driver.Rules), len(run.Results))
+	}
+
+	for _, result := range run.Results {
+		if run.Tool.Driver.Rules[result.RuleIndex].ID != result.RuleID {
+			t.Errorf("Result %s points at rule %d", result.RuleID, result.RuleIndex)
+		}
+		if result.RuleID == RuleFloatMoneyColumn {
+			location := result.Locations[0].LogicalLocations[0]
+			if result.Level != "error" || location.FullyQualifiedName != "app.orders.total_amount" || location.Kind != "column" {
+				t.Errorf("Unexpected SARIF result: %+v", result)
+			}
+		}
+		if result.RuleID == RuleRedundantIndex && result.Level != "note" {
+			t.Errorf("Expected low severity findings to be notes, got %s", result.Level)
+		}
+	}
+}
@@ -0,0 +1,285 @@
+package lock
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/user"
+	"path/filepath"
+	"time"
+)
+
+// namePrefix starts the advisory lock name of every target database
+const namePrefix = "mysql-schema-sync:"
+
+// maxNameLength is the longest lock name MySQL accepts
+const maxNameLength = 64
+
+// DefaultWaitTimeout is how long to wait for another run to release the lock
+const DefaultWaitTimeout = 30 * time.Second
+
+// DefaultAuditLog is the file in the home directory that forced unlocks are appended to unless
+// another audit log is configured
+const DefaultAuditLog = ".mysql-schema-sync-lock-audit.jsonl"
+
+// acquiredVariable is the user variable in which the lock connection keeps the time it took the
+// lock; other sessions read it from performance_schema
+const acquiredVariable = "schema_sync_lock_acquired"
+
+// acquiredFormat is the format of UTC_TIMESTAMP(6) as a string
+const acquiredFormat = "2006-01-02 15:04:05.999999"
+
+// Config controls the advisory lock that keeps concurrent runs off the same target
+type Config struct {
+	// Enabled holds the lock on the target while planning and executing
+	Enabled bool `mapstructure:"enabled" yaml:"enabled"`
+	// WaitTimeout is how long to wait for another run to release the lock
+	WaitTimeout time.Duration `mapstructure:"wait_timeout" yaml:"wait_timeout"`
+	// ForceUnlock is the reason for killing the connection that holds the lock
+	ForceUnlock string `mapstructure:"-" yaml:"-"`
+	// AuditLog is the file every forced unlock is appended to as a JSON line, DefaultAuditLog in
+	// the home directory if empty
+	AuditLog string `mapstructure:"audit_log" yaml:"audit_log"`
+}
+
+// AuditLogPath returns the configured audit log, or the default one. Without a home directory
+// the default audit log is kept in the working directory.
+func (c Config) AuditLogPath() string {
+	if c.AuditLog != "" {
+		return c.AuditLog
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return DefaultAuditLog
+	}
+	return filepath.Join(home, DefaultAuditLog)
+}
+
+// Timeout returns the configured wait timeout, or the default one
+func (c Config) Timeout() time.Duration {
+	if c.WaitTimeout == 0 {
+		return DefaultWaitTimeout
+	}
+	return c.WaitTimeout
+}
+
+// Validate validates the lock configuration
+func (c Config) Validate() error {
+	if c.WaitTimeout < 0 {
+		return fmt.Errorf("lock wait timeout cannot be negative: %s", c.WaitTimeout)
+	}
+	return nil
+}
+
+// Name returns the advisory lock name of a target database. Names that would exceed the MySQL
+// limit are shortened with a hash of the database name.
+func Name(database string) string {
+	name := namePrefix + database
+	if len(name) <= maxNameLength {
+		return name
+	}
+	sum := sha256.Sum256([]byte(database))
+	return namePrefix + hex.EncodeToString(sum[:])[:maxNameLength-len(namePrefix)]
+}
+
+// Holder describes the connection that holds a lock
+type Holder struct {
+	ConnectionID int64  `json:"connection_id"`
+	User         string `json:"user,omitempty"`
+	Host         string `json:"host,omitempty"`
+	// Since is when the lock was acquired; it is zero if performance_schema does not show it
+	Since time.Time `json:"since,omitempty"`
+	// Idle is how long the connection has been in its current state, usually since its last statement
+	Idle time.Duration `json:"idle_ns,omitempty"`
+}
+
+// String describes the holder for messages
+func (h *Holder) String() string {
+	description := fmt.Sprintf("connection %d", h.ConnectionID)
+	if h.User != "" || h.Host != "" {
+		description += fmt.Sprintf(" (%s@%s)", h.User, h.Host)
+	}
+	if !h.Since.IsZero() {
+		description += fmt.Sprintf(" since %s", h.Since.Local().Format(time.RFC3339))
+	}
+	if h.Idle > 0 {
+		description += fmt.Sprintf(", idle for %s", h.Idle)
+	}
+	return description
+}
+
+// HeldError reports that another connection kept the lock for the whole wait timeout
+type HeldError struct {
+	Name   string
+	Holder *Holder
+}
+
+func (e *HeldError) Error() string {
+	if e.Holder == nil {
+		return fmt.Sprintf("lock %s is held by another connection", e.Name)
+	}
+	return fmt.Sprintf("lock %s is held by %s", e.Name, e.Holder)
+}
+
+// Lock is an acquired advisory lock. It lives on a dedicated connection, since MySQL releases
+// the lock when the session that took it ends.
+type Lock struct {
+	conn *sql.Conn
+	name string
+}
+
+// Acquire takes the named lock, waiting up to timeout for another connection to release it
+func Acquire(ctx context.Context, db *sql.DB, name string, timeout time.Duration) (*Lock, error) {
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open lock connection: %w", err)
+	}
+
+	var acquired sql.NullInt64
+	seconds := int64(timeout.Round(time.Second) / time.Second)
+	if err := conn.QueryRowContext(ctx, "SELECT GET_LOCK(?, ?)", name, seconds).Scan(&acquired); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to acquire lock %s: %w", name, err)
+	}
+
+	if !acquired.Valid {
+		conn.Close()
+		return nil, fmt.Errorf("failed to acquire lock %s", name)
+	}
+	if acquired.Int64 != 1 {
+		conn.Close()
+		holder, err := CurrentHolder(ctx, db, name)
+		if err != nil {
+			return nil, err
+		}
+		return nil, &HeldError{Name: name, Holder: holder}
+	}
+
+	// Record when the lock was taken, so that a run waiting for it can tell how long it is held
+	if _, err := conn.ExecContext(ctx, "SET @"+acquiredVariable+" = UTC_TIMESTAMP(6)"); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to record acquisition of lock %s: %w", name, err)
+	}
+
+	return &Lock{conn: conn, name: name}, nil
+}
+
+// Name returns the name of the lock
+func (l *Lock) Name() string {
+	return l.name
+}
+
+// Release releases the lock and closes its connection
+func (l *Lock) Release(ctx context.Context) error {
+	defer l.conn.Close()
+
+	var released sql.NullInt64
+	if err := l.conn.QueryRowContext(ctx, "SELECT RELEASE_LOCK(?)", l.name).Scan(&released); err != nil {
+		return fmt.Errorf("failed to release lock %s: %w", l.name, err)
+	}
+	if released.Int64 != 1 {
+		return fmt.Errorf("lock %s was no longer held", l.name)
+	}
+	return nil
+}
+
+// CurrentHolder returns the connection that holds the lock, or nil if the lock is free
+func CurrentHolder(ctx context.Context, db *sql.DB, name string) (*Holder, error) {
+	var connectionID sql.NullInt64
+	if err := db.QueryRowContext(ctx, "SELECT IS_USED_LOCK(?)", name).Scan(&connectionID); err != nil {
+		return nil, fmt.Errorf("failed to look up holder of lock %s: %w", name, err)
+	}
+	if !connectionID.Valid {
+		return nil, nil
+	}
+
+	holder := &Holder{ConnectionID: connectionID.Int64}
+
+	// The process list and performance_schema may be restricted; the connection ID alone still
+	// identifies the holder
+	var seconds sql.NullInt64
+	err := db.QueryRowContext(ctx, "SELECT USER, HOST, TIME FROM information_schema.PROCESSLIST WHERE ID = ?", holder.ConnectionID).
+		Scan(&holder.User, &holder.Host, &seconds)
+	if err == nil && seconds.Valid {
+		holder.Idle = time.Duration(seconds.Int64) * time.Second
+	}
+
+	var acquired sql.NullString
+	err = db.QueryRowContext(ctx, "SELECT v.VARIABLE_VALUE FROM performance_schema.user_variables_by_thread v "+
+		"JOIN performance_schema.threads t ON t.THREAD_ID = v.THREAD_ID WHERE t.PROCESSLIST_ID = ? AND v.VARIABLE_NAME = ?",
+		holder.ConnectionID, acquiredVariable).Scan(&acquired)
+	if err == nil && acquired.Valid {
+		if since, err := time.Parse(acquiredFormat, acquired.String); err == nil {
+			holder.Since = since
+		}
+	}
+
+	return holder, nil
+}
+
+// ForceUnlock kills the connection that holds the lock and returns it, or nil if the lock was free
+func ForceUnlock(ctx context.Context, db *sql.DB, name string) (*Holder, error) {
+	holder, err := CurrentHolder(ctx, db, name)
+	if err != nil || holder == nil {
+		return nil, err
+	}
+
+	if _, err := db.ExecContext(ctx, fmt.Sprintf("KILL CONNECTION %d", holder.ConnectionID)); err != nil {
+		return nil, fmt.Errorf("failed to kill connection %d holding lock %s: %w", holder.ConnectionID, name, err)
+	}
+	return holder, nil
+}
+
+// Unlock records a forced unlock
+type Unlock struct {
+	Time   time.Time `json:"time"`
+	User   string    `json:"user"`
+	Target string    `json:"target"`
+	Lock   string    `json:"lock"`
+	Reason string    `json:"reason"`
+	Holder *Holder   `json:"holder"`
+}
+
+// NewUnlock creates the record of a forced unlock
+func NewUnlock(reason, target, name string, holder *Holder) Unlock {
+	return Unlock{
+		Time:   time.Now().UTC(),
+		User:   currentUser(),
+		Target: target,
+		Lock:   name,
+		Reason: reason,
+		Holder: holder,
+	}
+}
+
+// AppendAudit appends the forced unlock to the audit log as a single JSON line
+func AppendAudit(path string, unlock Unlock) error {
+	data, err := json.Marshal(unlock)
+	if err != nil {
+		return fmt.Errorf("failed to encode forced unlock: %w", err)
+	}
+
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open lock audit log %s: %w", path, err)
+	}
+	defer file.Close()
+
+	if _, err := file.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("failed to write lock audit log %s: %w", path, err)
+	}
+
+	return nil
+}
+
+// currentUser returns the name of the user running the tool
+func currentUser() string {
+	if currentUser, err := user.Current(); err == nil {
+		return currentUser.Username
+	}
+	return "unknown"
+}
@@ -0,0 +1,184 @@
+package lock
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func TestName(t *testing.T) {
+	if name := Name("app"); name != "mysql-schema-sync:app" {
+		t.Errorf("Expected mysql-schema-sync:app, got %s", name)
+	}
+
+	long := strings.Repeat("d", 64)
+	name := Name(long)
+	if len(name) != maxNameLength || !strings.HasPrefix(name, namePrefix) {
+		t.Errorf("Expected a %d character lock name, got %s", maxNameLength, name)
+	}
+	if Name(long) != name || Name(strings.Repeat("e", 64)) == name {
+		t.Error("Expected shortened lock names to be stable and distinct")
+	}
+}
+
+func TestConfig(t *testing.T) {
+	if timeout := (Config{}).Timeout(); timeout != DefaultWaitTimeout {
+		t.Errorf("Expected the default wait timeout, got %s", timeout)
+	}
+	if err := (Config{WaitTimeout: -time.Second}).Validate(); err == nil {
+		t.Error("Expected a negative wait timeout to be rejected")
+	}
+}
+
+func TestConfig_AuditLogPath(t *testing.T) {
+	if path := (Config{AuditLog: "/var/log/unlocks.jsonl"}).AuditLogPath(); path != "/var/log/unlocks.jsonl" {
+		t.Errorf("Expected the configured audit log, got %s", path)
+	}
+
+	t.Setenv("HOME", "/home/ci")
+	if path := (Config{}).AuditLogPath(); path != filepath.Join("/home/ci", DefaultAuditLog) {
+		t.Errorf("Expected forced unlocks to be audited in the home directory by default, got %s", path)
+	}
+}
+
+func TestAcquireAndRelease(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create sqlmock: %v", err)
+	}
+	defer db.Close()
+
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT GET_LOCK(?, ?)")).
+		WithArgs("mysql-schema-sync:app", int64(10)).
+		WillReturnRows(sqlmock.NewRows([]string{"lock"}).AddRow(1))
+	mock.ExpectExec(regexp.QuoteMeta("SET @schema_sync_lock_acquired = UTC_TIMESTAMP(6)")).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT RELEASE_LOCK(?)")).
+		WithArgs("mysql-schema-sync:app").
+		WillReturnRows(sqlmock.NewRows([]string{"released"}).AddRow(1))
+
+	lock, err := Acquire(context.Background(), db, Name("app"), 10*time.Second)
+	if err != nil {
+		t.Fatalf("Acquire() error = %v", err)
+	}
+	if err := lock.Release(context.Background()); err != nil {
+		t.Errorf("Release() error = %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
+func TestAcquire_Held(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create sqlmock: %v", err)
+	}
+	defer db.Close()
+
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT GET_LOCK(?, ?)")).
+		WillReturnRows(sqlmock.NewRows([]string{"lock"}).AddRow(0))
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT IS_USED_LOCK(?)")).
+		WithArgs("mysql-schema-sync:app").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(42))
+	mock.ExpectQuery(regexp.QuoteMeta("FROM information_schema.PROCESSLIST WHERE ID = ?")).
+		WithArgs(int64(42)).
+		WillReturnRows(sqlmock.NewRows([]string{"USER", "HOST", "TIME"}).AddRow("ci", "10.0.0.7:51234", 120))
+	mock.ExpectQuery(regexp.QuoteMeta("FROM performance_schema.user_variables_by_thread")).
+		WithArgs(int64(42), "schema_sync_lock_acquired").
+		WillReturnRows(sqlmock.NewRows([]string{"VARIABLE_VALUE"}).AddRow("2026-10-18 07:45:12.250000"))
+
+	_, err = Acquire(context.Background(), db, Name("app"), time.Second)
+	held, ok := err.(*HeldError)
+	if !ok {
+		t.Fatalf("Expected a HeldError, got %v", err)
+	}
+	if held.Holder == nil || held.Holder.ConnectionID != 42 || held.Holder.Host != "10.0.0.7:51234" {
+		t.Fatalf("Unexpected holder %+v", held.Holder)
+	}
+	if !held.Holder.Since.Equal(time.Date(2026, 10, 18, 7, 45, 12, 250000000, time.UTC)) {
+		t.Errorf("Expected the lock to be held since it was acquired, got %s", held.Holder.Since)
+	}
+	if held.Holder.Idle != 2*time.Minute {
+		t.Errorf("Expected the holder to be idle for 2 minutes, got %s", held.Holder.Idle)
+	}
+	if !strings.Contains(err.Error(), "connection 42 (ci@10.0.0.7:51234)") || !strings.Contains(err.Error(), "idle for 2m0s") {
+		t.Errorf("Expected the error to name the holder, got %q", err.Error())
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
+func TestForceUnlock(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create sqlmock: %v", err)
+	}
+	defer db.Close()
+
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT IS_USED_LOCK(?)")).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(42))
+	mock.ExpectQuery(regexp.QuoteMeta("FROM information_schema.PROCESSLIST")).
+		WillReturnRows(sqlmock.NewRows([]string{"USER", "HOST", "TIME"}))
+	mock.ExpectQuery(regexp.QuoteMeta("FROM performance_schema.user_variables_by_thread")).
+		WillReturnRows(sqlmock.NewRows([]string{"VARIABLE_VALUE"}))
+	mock.ExpectExec(regexp.QuoteMeta("KILL CONNECTION 42")).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT IS_USED_LOCK(?)")).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(nil))
+
+	holder, err := ForceUnlock(context.Background(), db, Name("app"))
+	if err != nil {
+		t.Fatalf("ForceUnlock() error = %v", err)
+	}
+	if holder == nil || holder.ConnectionID != 42 {
+		t.Errorf("Expected connection 42 to be killed, got %+v", holder)
+	}
+
+	holder, err = ForceUnlock(context.Background(), db, Name("app"))
+	if err != nil || holder != nil {
+		t.Errorf("Expected nothing to unlock on a free lock, got %+v, %v", holder, err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
+func TestAppendAudit(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "lock-audit.jsonl")
+	holder := &Holder{ConnectionID: 42, Host: "ci-1"}
+
+	for i := 0; i < 2; i++ {
+		if err := AppendAudit(path, NewUnlock("CI job was cancelled", "app", Name("app"), holder)); err != nil {
+			t.Fatalf("AppendAudit() error = %v", err)
+		}
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read audit log: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("Expected 2 audit lines, got %d", len(lines))
+	}
+
+	var unlock Unlock
+	if err := json.Unmarshal([]byte(lines[0]), &unlock); err != nil {
+		t.Fatalf("Failed to decode audit line: %v", err)
+	}
+	if unlock.Reason != "CI job was cancelled" || unlock.Holder == nil || unlock.Holder.ConnectionID != 42 || unlock.User == "" {
+		t.Errorf("Unexpected audit record %+v", unlock)
+	}
+}
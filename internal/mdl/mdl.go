@@ -0,0 +1,259 @@
+package mdl
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Action is what the executor does when a statement's table is blocked
+type Action string
+
+const (
+	// ActionAbort stops the migration and reports the blocking sessions
+	ActionAbort Action = "abort"
+	// ActionWait waits for the blocking sessions to finish, up to the wait timeout
+	ActionWait Action = "wait"
+)
+
+// DefaultWaitTimeout is how long ActionWait waits for blocking sessions to finish
+const DefaultWaitTimeout = 5 * time.Minute
+
+// Config controls the metadata lock checks run before each DDL statement
+type Config struct {
+	// Enabled looks for sessions holding metadata locks on a table before altering it
+	Enabled bool `mapstructure:"enabled" yaml:"enabled"`
+	// LockWaitTimeout is set as the session lock_wait_timeout of every statement, so a statement
+	// stuck behind a metadata lock gives up instead of blocking all traffic; zero keeps the server setting
+	LockWaitTimeout time.Duration `mapstructure:"lock_wait_timeout" yaml:"lock_wait_timeout"`
+	// MaxTransactionAge ignores transactions younger than this; zero reports every open transaction
+	MaxTransactionAge time.Duration `mapstructure:"max_transaction_age" yaml:"max_transaction_age"`
+	// OnBlocked is abort or wait
+	OnBlocked Action `mapstructure:"on_blocked" yaml:"on_blocked"`
+	// WaitTimeout is how long to wait for blocking sessions when OnBlocked is wait
+	WaitTimeout time.Duration `mapstructure:"wait_timeout" yaml:"wait_timeout"`
+}
+
+// Action returns the configured action, abort by default
+func (c Config) Action() Action {
+	if c.OnBlocked == "" {
+		return ActionAbort
+	}
+	return c.OnBlocked
+}
+
+// Timeout returns the configured wait timeout, or the default one
+func (c Config) Timeout() time.Duration {
+	if c.WaitTimeout == 0 {
+		return DefaultWaitTimeout
+	}
+	return c.WaitTimeout
+}
+
+// Validate validates the metadata lock configuration
+func (c Config) Validate() error {
+	switch c.OnBlocked {
+	case "", ActionAbort, ActionWait:
+	default:
+		return fmt.Errorf("unsupported metadata lock action: %s (supported: abort, wait)", c.OnBlocked)
+	}
+	if c.LockWaitTimeout < 0 || c.MaxTransactionAge < 0 || c.WaitTimeout < 0 {
+		return fmt.Errorf("metadata lock timeouts cannot be negative")
+	}
+	if c.LockWaitTimeout > 0 && c.LockWaitTimeout < time.Second {
+		return fmt.Errorf("lock_wait_timeout must be at least 1s, got %s", c.LockWaitTimeout)
+	}
+	return nil
+}
+
+// SessionSQL returns the statement setting the session lock_wait_timeout, or an empty string if
+// the server setting is kept
+func (c Config) SessionSQL() string {
+	if c.LockWaitTimeout <= 0 {
+		return ""
+	}
+	return fmt.Sprintf("SET SESSION lock_wait_timeout = %d", int64(c.LockWaitTimeout.Round(time.Second)/time.Second))
+}
+
+// ResetSQL returns the statement that restores the session lock_wait_timeout set by SessionSQL
+func (c Config) ResetSQL() string {
+	if c.LockWaitTimeout <= 0 {
+		return ""
+	}
+	return "SET SESSION lock_wait_timeout = DEFAULT"
+}
+
+// Blocker is a session that holds a metadata lock or an open transaction a DDL statement would
+// wait for
+type Blocker struct {
+	ConnectionID int64
+	User         string
+	Host         string
+	// Table is empty if the session could not be tied to a table
+	Table string
+	// LockType is the metadata lock the session holds, if known
+	LockType string
+	// TransactionStarted is when the session's transaction started, if it has one
+	TransactionStarted *time.Time
+	State              string
+	Query              string
+}
+
+// String describes the blocker for reports
+func (b Blocker) String() string {
+	var parts []string
+	parts = append(parts, fmt.Sprintf("connection %d (%s@%s)", b.ConnectionID, b.User, b.Host))
+	if b.LockType != "" {
+		parts = append(parts, fmt.Sprintf("holds %s metadata lock", b.LockType))
+	}
+	if b.TransactionStarted != nil {
+		parts = append(parts, fmt.Sprintf("transaction open for %s", time.Since(*b.TransactionStarted).Round(time.Second)))
+	}
+	if b.State != "" {
+		parts = append(parts, b.State)
+	}
+	if b.Query != "" {
+		parts = append(parts, fmt.Sprintf("running %q", truncate(b.Query, 120)))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// Checker looks for sessions that would block DDL on a table
+type Checker struct {
+	db     *sql.DB
+	config Config
+}
+
+// NewChecker creates a Checker for the given database connection
+func NewChecker(db *sql.DB, config Config) *Checker {
+	return &Checker{db: db, config: config}
+}
+
+// Blockers returns the sessions holding metadata locks on the table, with their transactions. If
+// performance_schema metadata lock instrumentation is unavailable, it falls back to every long
+// running InnoDB transaction, since those may hold locks on the table.
+func (c *Checker) Blockers(ctx context.Context, database, table string) ([]Blocker, error) {
+	transactions, err := c.transactions(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	holders, err := c.metadataLockHolders(ctx, database, table)
+	if err != nil {
+		blockers := make([]Blocker, 0, len(transactions))
+		for _, trx := range transactions {
+			if c.oldEnough(trx.TransactionStarted) {
+				blockers = append(blockers, trx)
+			}
+		}
+		return blockers, nil
+	}
+
+	byConnection := make(map[int64]Blocker, len(transactions))
+	for _, trx := range transactions {
+		byConnection[trx.ConnectionID] = trx
+	}
+
+	blockers := make([]Blocker, 0, len(holders))
+	for _, holder := range holders {
+		if trx, ok := byConnection[holder.ConnectionID]; ok {
+			holder.TransactionStarted = trx.TransactionStarted
+			holder.State = trx.State
+			if holder.Query == "" {
+				holder.Query = trx.Query
+			}
+		}
+		if holder.TransactionStarted != nil && !c.oldEnough(holder.TransactionStarted) {
+			continue
+		}
+		blockers = append(blockers, holder)
+	}
+	return blockers, nil
+}
+
+// oldEnough reports whether a transaction is at least the configured age
+func (c *Checker) oldEnough(started *time.Time) bool {
+	return started == nil || time.Since(*started) >= c.config.MaxTransactionAge
+}
+
+// metadataLockHolders returns the other sessions holding granted metadata locks on the table
+func (c *Checker) metadataLockHolders(ctx context.Context, database, table string) ([]Blocker, error) {
+	query := `SELECT t.PROCESSLIST_ID, COALESCE(t.PROCESSLIST_USER, ''), COALESCE(t.PROCESSLIST_HOST, ''),
+		ml.LOCK_TYPE, COALESCE(t.PROCESSLIST_INFO, '')
+		FROM performance_schema.metadata_locks ml
+		JOIN performance_schema.threads t ON t.THREAD_ID = ml.OWNER_THREAD_ID
+		WHERE ml.OBJECT_TYPE = 'TABLE' AND ml.OBJECT_SCHEMA = ? AND ml.OBJECT_NAME = ?
+		AND ml.LOCK_STATUS = 'GRANTED' AND t.PROCESSLIST_ID IS NOT NULL AND t.PROCESSLIST_ID <> CONNECTION_ID()
+		ORDER BY t.PROCESSLIST_ID`
+
+	rows, err := c.db.QueryContext(ctx, query, database, table)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read metadata locks: %w", err)
+	}
+	defer rows.Close()
+
+	holders := make([]Blocker, 0)
+	seen := make(map[int64]bool)
+	for rows.Next() {
+		holder := Blocker{Table: table}
+		if err := rows.Scan(&holder.ConnectionID, &holder.User, &holder.Host, &holder.LockType, &holder.Query); err != nil {
+			return nil, fmt.Errorf("failed to scan metadata lock: %w", err)
+		}
+		// A session holds several locks on a table; report it once
+		if seen[holder.ConnectionID] {
+			continue
+		}
+		seen[holder.ConnectionID] = true
+		holders = append(holders, holder)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read metadata locks: %w", err)
+	}
+	return holders, nil
+}
+
+// transactions returns the open InnoDB transactions of other sessions
+func (c *Checker) transactions(ctx context.Context) ([]Blocker, error) {
+	query := `SELECT trx.trx_mysql_thread_id, COALESCE(p.USER, ''), COALESCE(p.HOST, ''),
+		TIMESTAMPDIFF(SECOND, trx.trx_started, NOW()), trx.trx_state, COALESCE(trx.trx_query, '')
+		FROM information_schema.innodb_trx trx
+		LEFT JOIN information_schema.PROCESSLIST p ON p.ID = trx.trx_mysql_thread_id
+		WHERE trx.trx_mysql_thread_id <> CONNECTION_ID()
+		ORDER BY trx.trx_started`
+
+	rows, err := c.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read open transactions: %w", err)
+	}
+	defer rows.Close()
+
+	transactions := make([]Blocker, 0)
+	for rows.Next() {
+		var trx Blocker
+		var age int64
+		if err := rows.Scan(&trx.ConnectionID, &trx.User, &trx.Host, &age, &trx.State, &trx.Query); err != nil {
+			return nil, fmt.Errorf("failed to scan open transaction: %w", err)
+		}
+		// The age is computed by the server, so the server time zone does not matter
+		started := time.Now().Add(-time.Duration(age) * time.Second)
+		trx.TransactionStarted = &started
+		transactions = append(transactions, trx)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read open transactions: %w", err)
+	}
+	return transactions, nil
+}
+
+// truncate shortens a string to at most n characters
+func truncate(s string, n int) string {
+	s = strings.Join(strings.Fields(s), " ")
+	if len(s) <= n {
+		return s
+	}
+	return s[:n-3] + "..."
+}
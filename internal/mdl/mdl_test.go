@@ -0,0 +1,113 @@
+package mdl
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+var (
+	transactionColumns = []string{"trx_mysql_thread_id", "USER", "HOST", "age", "trx_state", "trx_query"}
+	lockColumns        = []string{"PROCESSLIST_ID", "PROCESSLIST_USER", "PROCESSLIST_HOST", "LOCK_TYPE", "PROCESSLIST_INFO"}
+)
+
+func TestConfig(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  Config
+		wantErr bool
+	}{
+		{"defaults", Config{}, false},
+		{"wait", Config{OnBlocked: ActionWait, WaitTimeout: time.Minute}, false},
+		{"unknown action", Config{OnBlocked: "retry"}, true},
+		{"negative timeout", Config{MaxTransactionAge: -time.Second}, true},
+		{"sub-second lock wait", Config{LockWaitTimeout: 500 * time.Millisecond}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.config.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	if action := (Config{}).Action(); action != ActionAbort {
+		t.Errorf("Expected abort by default, got %s", action)
+	}
+	if sql := (Config{}).SessionSQL(); sql != "" {
+		t.Errorf("Expected no session setting by default, got %s", sql)
+	}
+	if sql := (Config{LockWaitTimeout: 10 * time.Second}).SessionSQL(); sql != "SET SESSION lock_wait_timeout = 10" {
+		t.Errorf("Unexpected session setting %s", sql)
+	}
+}
+
+func TestChecker_Blockers(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create sqlmock: %v", err)
+	}
+	defer db.Close()
+
+	mock.ExpectQuery(regexp.QuoteMeta("FROM information_schema.innodb_trx")).
+		WillReturnRows(sqlmock.NewRows(transactionColumns).
+			AddRow(11, "app", "10.0.0.5:4000", 600, "RUNNING", "").
+			AddRow(12, "app", "10.0.0.6:4000", 2, "RUNNING", "SELECT 1"))
+	mock.ExpectQuery(regexp.QuoteMeta("FROM performance_schema.metadata_locks")).
+		WithArgs("shop", "orders").
+		WillReturnRows(sqlmock.NewRows(lockColumns).
+			AddRow(11, "app", "10.0.0.5:4000", "SHARED_READ", "").
+			AddRow(11, "app", "10.0.0.5:4000", "SHARED_WRITE", "").
+			AddRow(12, "app", "10.0.0.6:4000", "SHARED_READ", "SELECT * FROM orders").
+			AddRow(13, "report", "10.0.0.9:4000", "SHARED_NO_READ_WRITE", "LOCK TABLES orders WRITE"))
+
+	blockers, err := NewChecker(db, Config{MaxTransactionAge: time.Minute}).Blockers(context.Background(), "shop", "orders")
+	if err != nil {
+		t.Fatalf("Blockers() error = %v", err)
+	}
+
+	// The young transaction of connection 12 is ignored; 13 holds a lock without a transaction
+	if len(blockers) != 2 || blockers[0].ConnectionID != 11 || blockers[1].ConnectionID != 13 {
+		t.Fatalf("Expected connections 11 and 13 to block, got %+v", blockers)
+	}
+	if blockers[0].TransactionStarted == nil || time.Since(*blockers[0].TransactionStarted) < 9*time.Minute {
+		t.Errorf("Expected the transaction age of connection 11, got %+v", blockers[0])
+	}
+	if description := blockers[0].String(); !strings.Contains(description, "connection 11 (app@10.0.0.5:4000)") ||
+		!strings.Contains(description, "SHARED_READ") || !strings.Contains(description, "transaction open for 10m") {
+		t.Errorf("Unexpected description %q", description)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
+func TestChecker_BlockersWithoutPerformanceSchema(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create sqlmock: %v", err)
+	}
+	defer db.Close()
+
+	mock.ExpectQuery(regexp.QuoteMeta("FROM information_schema.innodb_trx")).
+		WillReturnRows(sqlmock.NewRows(transactionColumns).
+			AddRow(11, "app", "10.0.0.5:4000", 600, "RUNNING", "").
+			AddRow(12, "app", "10.0.0.6:4000", 2, "RUNNING", ""))
+	mock.ExpectQuery(regexp.QuoteMeta("FROM performance_schema.metadata_locks")).
+		WillReturnError(errors.New("SELECT command denied"))
+
+	blockers, err := NewChecker(db, Config{MaxTransactionAge: time.Minute}).Blockers(context.Background(), "shop", "orders")
+	if err != nil {
+		t.Fatalf("Blockers() error = %v", err)
+	}
+	if len(blockers) != 1 || blockers[0].ConnectionID != 11 || blockers[0].Table != "" {
+		t.Errorf("Expected the long transaction to be reported without a table, got %+v", blockers)
+	}
+}
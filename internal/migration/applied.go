@@ -0,0 +1,48 @@
+package migration
+
+import (
+	"strings"
+
+	"mysql-schema-sync/internal/schema"
+)
+
+// StatementApplied reports whether the effect of a statement is visible in the target schema. The
+// second result is false if the schema cannot tell, as for column modifications, merged
+// alterations and constraints, which the schema extraction does not read.
+func StatementApplied(stmt MigrationStatement, target *schema.Schema) (applied bool, known bool) {
+	kind, name, _ := strings.Cut(stmt.Object, ":")
+	tableName, objectName, _ := strings.Cut(name, ".")
+	if target == nil || tableName == "" {
+		return false, false
+	}
+	table := target.Tables[tableName]
+
+	switch stmt.Type {
+	case StatementTypeCreateTable:
+		return table != nil, true
+	case StatementTypeDropTable:
+		return table == nil, true
+	case StatementTypeAddColumn, StatementTypeDropColumn:
+		if kind != "column" {
+			return false, false
+		}
+		exists := table != nil && table.Columns[objectName] != nil
+		return exists == (stmt.Type == StatementTypeAddColumn), true
+	case StatementTypeCreateIndex, StatementTypeRenameIndex, StatementTypeDropIndex:
+		if kind != "index" {
+			return false, false
+		}
+		exists := false
+		if table != nil {
+			for _, index := range table.Indexes {
+				if index.Name == objectName {
+					exists = true
+					break
+				}
+			}
+		}
+		return exists == (stmt.Type != StatementTypeDropIndex), true
+	default:
+		return false, false
+	}
+}
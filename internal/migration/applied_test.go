@@ -0,0 +1,51 @@
+package migration
+
+import (
+	"testing"
+
+	"mysql-schema-sync/internal/schema"
+)
+
+func TestStatementApplied(t *testing.T) {
+	target := &schema.Schema{
+		Tables: map[string]*schema.Table{
+			"orders": {
+				Name: "orders",
+				Columns: map[string]*schema.Column{
+					"id":   {Name: "id", DataType: "int"},
+					"note": {Name: "note", DataType: "text"},
+				},
+				Indexes: []*schema.Index{
+					{Name: "idx_note", TableName: "orders", Columns: []string{"note"}},
+				},
+			},
+		},
+	}
+
+	tests := []struct {
+		name    string
+		stmt    MigrationStatement
+		applied bool
+		known   bool
+	}{
+		{"created table", MigrationStatement{Type: StatementTypeCreateTable, Object: tableObject("orders")}, true, true},
+		{"missing table", MigrationStatement{Type: StatementTypeCreateTable, Object: tableObject("users")}, false, true},
+		{"dropped table", MigrationStatement{Type: StatementTypeDropTable, Object: tableObject("users")}, true, true},
+		{"added column", MigrationStatement{Type: StatementTypeAddColumn, Object: columnObject("orders", "note")}, true, true},
+		{"missing column", MigrationStatement{Type: StatementTypeAddColumn, Object: columnObject("orders", "total")}, false, true},
+		{"dropped column", MigrationStatement{Type: StatementTypeDropColumn, Object: columnObject("orders", "note")}, false, true},
+		{"created index", MigrationStatement{Type: StatementTypeCreateIndex, Object: indexObject("orders", "idx_note")}, true, true},
+		{"dropped index", MigrationStatement{Type: StatementTypeDropIndex, Object: indexObject("orders", "idx_total")}, true, true},
+		{"modified column", MigrationStatement{Type: StatementTypeModifyColumn, Object: columnObject("orders", "note")}, false, false},
+		{"no object", MigrationStatement{Type: StatementTypeCreateTable}, false, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			applied, known := StatementApplied(tt.stmt, target)
+			if applied != tt.applied || known != tt.known {
+				t.Errorf("StatementApplied() = (%v, %v), want (%v, %v)", applied, known, tt.applied, tt.known)
+			}
+		})
+	}
+}
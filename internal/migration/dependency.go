@@ -0,0 +1,439 @@
+package migration
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"mysql-schema-sync/internal/schema"
+)
+
+// Object keys identify the schema objects that statements create, drop or require.
+// Views and triggers are not part of the schema model, so the graph covers tables,
+// columns, indexes and constraints.
+
+// tableObject returns the object key of a table
+func tableObject(table string) string {
+	return "table:" + table
+}
+
+// columnObject returns the object key of a column
+func columnObject(table, column string) string {
+	return "column:" + table + "." + column
+}
+
+// indexObject returns the object key of an index
+func indexObject(table, index string) string {
+	return "index:" + table + "." + index
+}
+
+// constraintObject returns the object key of a constraint
+func constraintObject(table, constraint string) string {
+	return "constraint:" + table + "." + constraint
+}
+
+// annotateDependencies records the object a statement acts on and the objects it requires
+func annotateDependencies(stmt *MigrationStatement, object interface{}) {
+	deps := newDependencySet()
+
+	switch obj := object.(type) {
+	case *schema.Table:
+		stmt.Object = tableObject(obj.Name)
+		for _, constraint := range sortedForeignKeys(obj) {
+			if constraint.ReferencedTable != obj.Name {
+				deps.add(tableObject(constraint.ReferencedTable))
+			}
+		}
+	case *schema.Column:
+		stmt.Object = columnObject(stmt.TableName, obj.Name)
+		deps.add(tableObject(stmt.TableName))
+	case *schema.ColumnDiff:
+		stmt.Object = columnObject(stmt.TableName, obj.ColumnName)
+		deps.add(tableObject(stmt.TableName))
+	case *schema.Index:
+		stmt.Object = indexObject(obj.TableName, obj.Name)
+		deps.add(tableObject(obj.TableName))
+		for _, column := range obj.Columns {
+			deps.add(columnObject(obj.TableName, column))
+		}
+	case *schema.Constraint:
+		stmt.Object = constraintObject(obj.TableName, obj.Name)
+		deps.add(tableObject(obj.TableName))
+		for _, column := range obj.Columns {
+			deps.add(columnObject(obj.TableName, column))
+		}
+		if obj.Type == schema.ConstraintTypeForeignKey && obj.ReferencedTable != "" {
+			deps.add(tableObject(obj.ReferencedTable))
+			for _, column := range obj.ReferencedColumns {
+				deps.add(columnObject(obj.ReferencedTable, column))
+			}
+		}
+	}
+
+	stmt.Dependencies = deps.list()
+}
+
+// dependencySet collects object keys in insertion order without duplicates
+type dependencySet struct {
+	seen  map[string]bool
+	items []string
+}
+
+// newDependencySet creates an empty dependencySet
+func newDependencySet() *dependencySet {
+	return &dependencySet{seen: make(map[string]bool), items: make([]string, 0)}
+}
+
+// add adds a key if it is not already present
+func (ds *dependencySet) add(key string) {
+	if !ds.seen[key] {
+		ds.seen[key] = true
+		ds.items = append(ds.items, key)
+	}
+}
+
+// list returns the collected keys
+func (ds *dependencySet) list() []string {
+	return ds.items
+}
+
+// createsObject returns true if the statement type brings its object into existence
+func createsObject(stmtType StatementType) bool {
+	switch stmtType {
+	case StatementTypeCreateTable, StatementTypeAddColumn, StatementTypeCreateIndex, StatementTypeRenameIndex,
+		StatementTypeAddConstraint:
+		return true
+	default:
+		return false
+	}
+}
+
+// dropsObject returns true if the statement type removes its object
+func dropsObject(stmtType StatementType) bool {
+	switch stmtType {
+	case StatementTypeDropTable, StatementTypeDropColumn, StatementTypeDropIndex, StatementTypeDropConstraint:
+		return true
+	default:
+		return false
+	}
+}
+
+// isForeignKeyReference returns true if a table statement depends on another table only through
+// one of its foreign keys, which is the kind of dependency that may be broken to resolve a cycle
+func isForeignKeyReference(stmt MigrationStatement, dependency string) bool {
+	if stmt.Type != StatementTypeCreateTable && stmt.Type != StatementTypeDropTable {
+		return false
+	}
+	return strings.HasPrefix(dependency, "table:") && dependency != tableObject(stmt.TableName)
+}
+
+// DependencyGraph orders migration statements so that every object exists before it is used
+// and is only dropped once nothing that runs later still needs it
+type DependencyGraph struct {
+	statements []MigrationStatement
+	edges      [][]int
+	inDegree   []int
+}
+
+// NewDependencyGraph builds the dependency graph of the given statements
+func NewDependencyGraph(statements []MigrationStatement) *DependencyGraph {
+	g := &DependencyGraph{
+		statements: statements,
+		edges:      make([][]int, len(statements)),
+		inDegree:   make([]int, len(statements)),
+	}
+	g.build()
+	return g
+}
+
+// build derives the edges between statements from their objects and dependencies
+func (g *DependencyGraph) build() {
+	creators := make(map[string][]int)
+	droppers := make(map[string][]int)
+	for i, stmt := range g.statements {
+		if stmt.Object == "" {
+			continue
+		}
+		if createsObject(stmt.Type) {
+			creators[stmt.Object] = append(creators[stmt.Object], i)
+		}
+		if dropsObject(stmt.Type) {
+			droppers[stmt.Object] = append(droppers[stmt.Object], i)
+		}
+	}
+
+	seen := make(map[[2]int]bool)
+	addEdge := func(from, to int) {
+		if from == to || seen[[2]int{from, to}] {
+			return
+		}
+		seen[[2]int{from, to}] = true
+		g.edges[from] = append(g.edges[from], to)
+		g.inDegree[to]++
+	}
+
+	for i, stmt := range g.statements {
+		for _, dependency := range stmt.Dependencies {
+			// Objects are created before the statements that need them
+			if !dropsObject(stmt.Type) {
+				for _, creator := range creators[dependency] {
+					addEdge(creator, i)
+				}
+			}
+
+			// Objects are dropped only after the statements that still need them, unless the
+			// dropping statement runs with foreign key checks disabled
+			for _, dropper := range droppers[dependency] {
+				if g.statements[dropper].DisableForeignKeyChecks && isForeignKeyReference(stmt, dependency) {
+					continue
+				}
+				addEdge(i, dropper)
+			}
+		}
+
+		// An object that is dropped and recreated is dropped first
+		if createsObject(stmt.Type) {
+			for _, dropper := range droppers[stmt.Object] {
+				addEdge(dropper, i)
+			}
+		}
+	}
+}
+
+// precedes reports whether statement i should run before statement j when neither depends on the other
+func (g *DependencyGraph) precedes(i, j int) bool {
+	orderI := g.statements[i].Type.GetExecutionOrder()
+	orderJ := g.statements[j].Type.GetExecutionOrder()
+	if orderI != orderJ {
+		return orderI < orderJ
+	}
+	if g.statements[i].TableName != g.statements[j].TableName {
+		return g.statements[i].TableName < g.statements[j].TableName
+	}
+	return i < j
+}
+
+// Sort returns the statement indexes in a topological order, falling back to the execution order
+// of statement types and table names between independent statements. If the graph contains a
+// cycle, the indexes of the statements forming it are returned instead.
+func (g *DependencyGraph) Sort() (order []int, cycle []int) {
+	inDegree := make([]int, len(g.inDegree))
+	copy(inDegree, g.inDegree)
+
+	ready := make([]int, 0)
+	for i, degree := range inDegree {
+		if degree == 0 {
+			ready = append(ready, i)
+		}
+	}
+
+	order = make([]int, 0, len(g.statements))
+	for len(ready) > 0 {
+		sort.Slice(ready, func(a, b int) bool {
+			return g.precedes(ready[a], ready[b])
+		})
+		next := ready[0]
+		ready = ready[1:]
+		order = append(order, next)
+
+		for _, to := range g.edges[next] {
+			inDegree[to]--
+			if inDegree[to] == 0 {
+				ready = append(ready, to)
+			}
+		}
+	}
+
+	if len(order) < len(g.statements) {
+		return nil, g.findCycle(inDegree)
+	}
+	return order, nil
+}
+
+// findCycle returns one cycle among the statements left unsorted
+func (g *DependencyGraph) findCycle(inDegree []int) []int {
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+
+	state := make([]int, len(g.statements))
+	stack := make([]int, 0)
+
+	var visit func(node int) []int
+	visit = func(node int) []int {
+		state[node] = visiting
+		stack = append(stack, node)
+		for _, to := range g.edges[node] {
+			if inDegree[to] == 0 {
+				continue
+			}
+			switch state[to] {
+			case visiting:
+				for i, n := range stack {
+					if n == to {
+						return append([]int(nil), stack[i:]...)
+					}
+				}
+			case unvisited:
+				if cycle := visit(to); cycle != nil {
+					return cycle
+				}
+			}
+		}
+		stack = stack[:len(stack)-1]
+		state[node] = visited
+		return nil
+	}
+
+	for i := range g.statements {
+		if inDegree[i] > 0 && state[i] == unvisited {
+			if cycle := visit(i); cycle != nil {
+				return cycle
+			}
+		}
+	}
+	return nil
+}
+
+// describeCycle formats a cycle for error messages
+func (g *DependencyGraph) describeCycle(cycle []int) string {
+	parts := make([]string, 0, len(cycle)+1)
+	for _, idx := range cycle {
+		parts = append(parts, g.statements[idx].Description)
+	}
+	if len(cycle) > 0 {
+		parts = append(parts, g.statements[cycle[0]].Description)
+	}
+	return strings.Join(parts, " -> ")
+}
+
+// ExecutableSQL returns the SQL to run for the statement, including any session settings it needs
+func (ms *MigrationStatement) ExecutableSQL() []string {
+	session, reset := ms.SessionSQL()
+	statements := append(session, ms.SQL)
+	return append(statements, reset...)
+}
+
+// SessionSQL returns the session settings the statement needs and the statements that restore them
+func (ms *MigrationStatement) SessionSQL() (session, reset []string) {
+	if ms.DisableForeignKeyChecks {
+		return []string{"SET FOREIGN_KEY_CHECKS=0"}, []string{"SET FOREIGN_KEY_CHECKS=1"}
+	}
+	return nil, nil
+}
+
+// orderStatements sorts the plan topologically, resolving foreign key cycles between new tables by
+// deferring one of the foreign keys and between dropped tables by disabling foreign key checks
+func (mp *MigrationPlanner) orderStatements(plan *MigrationPlan, diff *schema.SchemaDiff) error {
+	addedTables := make(map[string]*schema.Table)
+	for _, table := range diff.AddedTables {
+		addedTables[table.Name] = table
+	}
+	deferred := make(map[string]map[string]bool)
+
+	// Every resolution removes at least one edge, so the loop is bounded by the statement count
+	for attempt := 0; attempt <= len(plan.Statements)+len(diff.AddedTables); attempt++ {
+		graph := NewDependencyGraph(plan.Statements)
+		order, cycle := graph.Sort()
+		if cycle == nil {
+			sorted := make([]MigrationStatement, len(order))
+			for i, idx := range order {
+				sorted[i] = plan.Statements[idx]
+			}
+			plan.Statements = sorted
+			return nil
+		}
+
+		resolved, err := mp.breakCycle(plan, graph, cycle, addedTables, deferred)
+		if err != nil {
+			return err
+		}
+		if !resolved {
+			return fmt.Errorf("circular dependency between statements: %s", graph.describeCycle(cycle))
+		}
+	}
+
+	return fmt.Errorf("unable to resolve circular dependencies between statements")
+}
+
+// breakCycle removes one foreign key edge from the cycle, returning false if the cycle has none
+func (mp *MigrationPlanner) breakCycle(plan *MigrationPlan, graph *DependencyGraph, cycle []int,
+	addedTables map[string]*schema.Table, deferred map[string]map[string]bool) (bool, error) {
+	for i, from := range cycle {
+		to := cycle[(i+1)%len(cycle)]
+		source := plan.Statements[from]
+		target := plan.Statements[to]
+
+		// A new table references another new table that needs it first: create the table without
+		// the foreign key and add the constraint once both tables exist
+		if source.Type == StatementTypeCreateTable && target.Type == StatementTypeCreateTable &&
+			isForeignKeyReference(target, source.Object) {
+			table, ok := addedTables[target.TableName]
+			if !ok {
+				continue
+			}
+			if err := mp.deferForeignKeys(plan, to, table, source.TableName, deferred); err != nil {
+				return false, err
+			}
+			return true, nil
+		}
+
+		// A dropped table is still referenced by another dropped table: drop it with foreign key
+		// checks disabled
+		if source.Type == StatementTypeDropTable && target.Type == StatementTypeDropTable &&
+			isForeignKeyReference(source, target.Object) {
+			plan.Statements[to].DisableForeignKeyChecks = true
+			plan.AddWarning(fmt.Sprintf("Table '%s' is dropped with foreign key checks disabled to break a circular foreign key dependency", target.TableName))
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// deferForeignKeys moves the foreign keys of a new table that reference the given table out of its
+// CREATE TABLE statement into separate ADD CONSTRAINT statements
+func (mp *MigrationPlanner) deferForeignKeys(plan *MigrationPlan, stmtIndex int, table *schema.Table,
+	referencedTable string, deferred map[string]map[string]bool) error {
+	if deferred[table.Name] == nil {
+		deferred[table.Name] = make(map[string]bool)
+	}
+
+	newlyDeferred := make([]*schema.Constraint, 0)
+	for _, constraint := range sortedForeignKeys(table) {
+		if constraint.ReferencedTable == referencedTable && !deferred[table.Name][constraint.Name] {
+			deferred[table.Name][constraint.Name] = true
+			newlyDeferred = append(newlyDeferred, constraint)
+		}
+	}
+
+	sql, err := mp.sqlGenerator.generateCreateTableSQL(table, deferred[table.Name])
+	if err != nil {
+		return fmt.Errorf("failed to regenerate create table SQL for %s: %w", table.Name, err)
+	}
+
+	stmt := &plan.Statements[stmtIndex]
+	stmt.SQL = sql
+	remaining := make([]string, 0, len(stmt.Dependencies))
+	for _, dependency := range stmt.Dependencies {
+		if dependency != tableObject(referencedTable) {
+			remaining = append(remaining, dependency)
+		}
+	}
+	stmt.Dependencies = remaining
+
+	for _, constraint := range newlyDeferred {
+		fk := *constraint
+		if fk.TableName == "" {
+			fk.TableName = table.Name
+		}
+		if err := mp.planConstraintAdditions(plan, []*schema.Constraint{&fk}); err != nil {
+			return err
+		}
+		plan.AddWarning(fmt.Sprintf("Foreign key '%s' on new table '%s' is added after table creation to break a circular dependency", fk.Name, table.Name))
+	}
+
+	return nil
+}
@@ -0,0 +1,266 @@
+package migration
+
+import (
+	"strings"
+	"testing"
+
+	"mysql-schema-sync/internal/schema"
+)
+
+// newFKTable builds a table with an id primary key and a foreign key to another table
+func newFKTable(name, fkName, column, referencedTable string) *schema.Table {
+	table := schema.NewTable(name)
+	table.AddColumn(&schema.Column{Name: "id", DataType: "INT", IsNullable: false})
+	if column != "" {
+		table.AddColumn(&schema.Column{Name: column, DataType: "INT", IsNullable: true})
+		table.AddConstraint(&schema.Constraint{
+			Name:              fkName,
+			TableName:         name,
+			Type:              schema.ConstraintTypeForeignKey,
+			Columns:           []string{column},
+			ReferencedTable:   referencedTable,
+			ReferencedColumns: []string{"id"},
+		})
+	}
+	return table
+}
+
+// statementIndex returns the position of the first statement matching the type and table
+func statementIndex(plan *MigrationPlan, stmtType StatementType, tableName string) int {
+	for i, stmt := range plan.Statements {
+		if stmt.Type == stmtType && stmt.TableName == tableName {
+			return i
+		}
+	}
+	return -1
+}
+
+func TestMigrationPlanner_OrdersNewTablesByForeignKey(t *testing.T) {
+	planner := NewMigrationPlanner()
+
+	diff := &schema.SchemaDiff{
+		AddedTables: []*schema.Table{
+			newFKTable("accounts", "fk_accounts_user", "user_id", "users"),
+			newFKTable("users", "", "", ""),
+		},
+	}
+
+	plan, err := planner.PlanMigration(diff)
+	if err != nil {
+		t.Fatalf("PlanMigration() error = %v", err)
+	}
+
+	if len(plan.Statements) != 2 {
+		t.Fatalf("Expected 2 statements, got %d", len(plan.Statements))
+	}
+
+	if plan.Statements[0].TableName != "users" || plan.Statements[1].TableName != "accounts" {
+		t.Errorf("Expected users to be created before accounts, got %s then %s",
+			plan.Statements[0].TableName, plan.Statements[1].TableName)
+	}
+
+	accounts := plan.Statements[1]
+	if !strings.Contains(accounts.SQL, "CONSTRAINT `fk_accounts_user` FOREIGN KEY (`user_id`) REFERENCES `users` (`id`)") {
+		t.Errorf("Expected foreign key in CREATE TABLE, got:\n%s", accounts.SQL)
+	}
+	if accounts.Object != "table:accounts" || len(accounts.Dependencies) != 1 || accounts.Dependencies[0] != "table:users" {
+		t.Errorf("Unexpected dependency annotation: object=%q dependencies=%v", accounts.Object, accounts.Dependencies)
+	}
+}
+
+func TestMigrationPlanner_DefersForeignKeyInCycle(t *testing.T) {
+	planner := NewMigrationPlanner()
+
+	diff := &schema.SchemaDiff{
+		AddedTables: []*schema.Table{
+			newFKTable("authors", "fk_authors_book", "favorite_book_id", "books"),
+			newFKTable("books", "fk_books_author", "author_id", "authors"),
+		},
+	}
+
+	plan, err := planner.PlanMigration(diff)
+	if err != nil {
+		t.Fatalf("PlanMigration() error = %v", err)
+	}
+
+	if len(plan.Statements) != 3 {
+		t.Fatalf("Expected 2 CREATE TABLE statements and 1 deferred foreign key, got %d", len(plan.Statements))
+	}
+
+	if plan.Statements[2].Type != StatementTypeAddConstraint {
+		t.Fatalf("Expected the deferred foreign key to run last, got %s", plan.Statements[2].Type)
+	}
+
+	// The table keeping its foreign key inline is created after the table it references
+	if strings.Contains(plan.Statements[0].SQL, "FOREIGN KEY") || !strings.Contains(plan.Statements[1].SQL, "FOREIGN KEY") {
+		t.Errorf("Expected only the second CREATE TABLE to define a foreign key:\n%s\n%s",
+			plan.Statements[0].SQL, plan.Statements[1].SQL)
+	}
+	if plan.Statements[2].TableName != plan.Statements[0].TableName {
+		t.Errorf("Expected the deferred foreign key to belong to %s, got %s",
+			plan.Statements[0].TableName, plan.Statements[2].TableName)
+	}
+
+	found := false
+	for _, warning := range plan.Warnings {
+		if strings.Contains(warning, "circular dependency") {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("Expected a warning about the deferred foreign key, got %v", plan.Warnings)
+	}
+}
+
+func TestMigrationPlanner_OrdersDroppedTablesByForeignKey(t *testing.T) {
+	planner := NewMigrationPlanner()
+
+	diff := &schema.SchemaDiff{
+		RemovedTables: []*schema.Table{
+			newFKTable("customers", "", "", ""),
+			newFKTable("orders", "fk_orders_customer", "customer_id", "customers"),
+		},
+	}
+
+	plan, err := planner.PlanMigration(diff)
+	if err != nil {
+		t.Fatalf("PlanMigration() error = %v", err)
+	}
+
+	if plan.Statements[0].TableName != "orders" || plan.Statements[1].TableName != "customers" {
+		t.Errorf("Expected orders to be dropped before customers, got %s then %s",
+			plan.Statements[0].TableName, plan.Statements[1].TableName)
+	}
+
+	for _, stmt := range plan.Statements {
+		if stmt.DisableForeignKeyChecks {
+			t.Errorf("Did not expect foreign key checks to be disabled for %s", stmt.TableName)
+		}
+	}
+}
+
+func TestMigrationPlanner_DisablesForeignKeyChecksForDropCycle(t *testing.T) {
+	planner := NewMigrationPlanner()
+
+	diff := &schema.SchemaDiff{
+		RemovedTables: []*schema.Table{
+			newFKTable("authors", "fk_authors_book", "favorite_book_id", "books"),
+			newFKTable("books", "fk_books_author", "author_id", "authors"),
+		},
+	}
+
+	plan, err := planner.PlanMigration(diff)
+	if err != nil {
+		t.Fatalf("PlanMigration() error = %v", err)
+	}
+
+	if len(plan.Statements) != 2 {
+		t.Fatalf("Expected 2 statements, got %d", len(plan.Statements))
+	}
+
+	first := plan.Statements[0]
+	if !first.DisableForeignKeyChecks {
+		t.Fatalf("Expected the first drop to run without foreign key checks")
+	}
+	if plan.Statements[1].DisableForeignKeyChecks {
+		t.Errorf("Expected only one statement to disable foreign key checks")
+	}
+
+	executable := first.ExecutableSQL()
+	if len(executable) != 3 || executable[0] != "SET FOREIGN_KEY_CHECKS=0" ||
+		executable[1] != first.SQL || executable[2] != "SET FOREIGN_KEY_CHECKS=1" {
+		t.Errorf("Unexpected executable SQL: %v", executable)
+	}
+}
+
+func TestMigrationPlanner_DropsForeignKeyBeforeColumn(t *testing.T) {
+	planner := NewMigrationPlanner()
+
+	diff := &schema.SchemaDiff{
+		ModifiedTables: []*schema.TableDiff{
+			{
+				TableName: "orders",
+				RemovedColumns: []*schema.Column{
+					{Name: "customer_id", DataType: "INT", IsNullable: true},
+				},
+			},
+		},
+		RemovedConstraints: []*schema.Constraint{
+			{
+				Name:              "fk_orders_customer",
+				TableName:         "orders",
+				Type:              schema.ConstraintTypeForeignKey,
+				Columns:           []string{"customer_id"},
+				ReferencedTable:   "customers",
+				ReferencedColumns: []string{"id"},
+			},
+		},
+		RemovedTables: []*schema.Table{
+			newFKTable("customers", "", "", ""),
+		},
+	}
+
+	plan, err := planner.PlanMigration(diff)
+	if err != nil {
+		t.Fatalf("PlanMigration() error = %v", err)
+	}
+
+	dropFK := statementIndex(plan, StatementTypeDropConstraint, "orders")
+	dropColumn := statementIndex(plan, StatementTypeDropColumn, "orders")
+	dropTable := statementIndex(plan, StatementTypeDropTable, "customers")
+
+	if dropFK == -1 || dropColumn == -1 || dropTable == -1 {
+		t.Fatalf("Missing statements in plan: %+v", plan.Statements)
+	}
+	if dropFK > dropColumn || dropFK > dropTable {
+		t.Errorf("Expected foreign key to be dropped first, got positions fk=%d column=%d table=%d",
+			dropFK, dropColumn, dropTable)
+	}
+}
+
+func TestDependencyGraph_ReportsUnresolvableCycle(t *testing.T) {
+	first := NewMigrationStatement("ALTER TABLE `t` ADD COLUMN `a` INT", StatementTypeAddColumn, "Add column a to table t")
+	first.TableName = "t"
+	first.Object = "column:t.a"
+	first.Dependencies = []string{"column:t.b"}
+
+	second := NewMigrationStatement("ALTER TABLE `t` ADD COLUMN `b` INT", StatementTypeAddColumn, "Add column b to table t")
+	second.TableName = "t"
+	second.Object = "column:t.b"
+	second.Dependencies = []string{"column:t.a"}
+
+	graph := NewDependencyGraph([]MigrationStatement{*first, *second})
+	order, cycle := graph.Sort()
+	if order != nil {
+		t.Errorf("Expected no order for a cyclic graph, got %v", order)
+	}
+	if len(cycle) != 2 {
+		t.Fatalf("Expected a cycle of 2 statements, got %v", cycle)
+	}
+
+	description := graph.describeCycle(cycle)
+	if !strings.Contains(description, "Add column a to table t") || !strings.Contains(description, " -> ") {
+		t.Errorf("Unexpected cycle description: %s", description)
+	}
+}
+
+func TestDependencyGraph_KeepsExecutionOrderWithoutDependencies(t *testing.T) {
+	statements := []MigrationStatement{
+		{SQL: "CREATE INDEX `idx` ON `b` (`x`)", Type: StatementTypeCreateIndex, TableName: "b"},
+		{SQL: "ALTER TABLE `b` ADD COLUMN `y` INT", Type: StatementTypeAddColumn, TableName: "b"},
+		{SQL: "ALTER TABLE `a` ADD COLUMN `y` INT", Type: StatementTypeAddColumn, TableName: "a"},
+		{SQL: "DROP TABLE `c`", Type: StatementTypeDropTable, TableName: "c"},
+	}
+
+	order, cycle := NewDependencyGraph(statements).Sort()
+	if cycle != nil {
+		t.Fatalf("Unexpected cycle: %v", cycle)
+	}
+
+	expected := []int{3, 2, 1, 0}
+	for i := range expected {
+		if order[i] != expected[i] {
+			t.Fatalf("Expected order %v, got %v", expected, order)
+		}
+	}
+}
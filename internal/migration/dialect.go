@@ -0,0 +1,142 @@
+package migration
+
+import (
+	"fmt"
+
+	"mysql-schema-sync/internal/schema"
+)
+
+// SetServerVersion makes the generator choose syntax supported by the given target server.
+// Without a version the generator emits syntax that works on MySQL 5.6 and later, and does not
+// reject changes that some versions cannot express.
+func (sg *SQLGenerator) SetServerVersion(version ServerVersion) {
+	sg.serverVersion = version
+}
+
+// ServerVersion returns the target server version, or the zero value if it is unknown
+func (sg *SQLGenerator) ServerVersion() ServerVersion {
+	return sg.serverVersion
+}
+
+// SetServerVersion makes the planner generate SQL for the given target server
+func (mp *MigrationPlanner) SetServerVersion(version ServerVersion) {
+	mp.sqlGenerator.SetServerVersion(version)
+}
+
+// supportsRenameColumn returns true if the server accepts ALTER TABLE ... RENAME COLUMN
+func (v ServerVersion) supportsRenameColumn() bool {
+	if v.MariaDB {
+		return v.AtLeast(10, 5, 2)
+	}
+	return v.AtLeast(8, 0, 3)
+}
+
+// supportsRenameIndex returns true if the server accepts ALTER TABLE ... RENAME INDEX
+func (v ServerVersion) supportsRenameIndex() bool {
+	if v.MariaDB {
+		return v.AtLeast(10, 5, 2)
+	}
+	return v.AtLeast(5, 7, 0)
+}
+
+// supportsCheckConstraints returns true if the server enforces CHECK constraints. Older MySQL
+// versions parse CHECK clauses and silently ignore them.
+func (v ServerVersion) supportsCheckConstraints() bool {
+	if v.MariaDB {
+		return v.AtLeast(10, 2, 1)
+	}
+	return v.AtLeast(8, 0, 16)
+}
+
+// supportsInstantAddColumnAnywhere returns true if columns added before the end of the table can
+// use ALGORITHM=INSTANT. Earlier versions only add trailing columns instantly.
+func (v ServerVersion) supportsInstantAddColumnAnywhere() bool {
+	if v.MariaDB {
+		return v.AtLeast(10, 4, 0)
+	}
+	return v.AtLeast(8, 0, 29)
+}
+
+// columnPosition places an added column before the end of the table. The zero value appends
+// the column.
+type columnPosition struct {
+	first bool
+	after string
+}
+
+// clause returns the FIRST or AFTER clause of the position
+func (p *columnPosition) clause() string {
+	switch {
+	case p == nil:
+		return ""
+	case p.first:
+		return " FIRST"
+	case p.after != "":
+		return fmt.Sprintf(" AFTER `%s`", p.after)
+	default:
+		return ""
+	}
+}
+
+// placedColumn is an added column together with its position, used to predict whether the
+// server can add it instantly
+type placedColumn struct {
+	column   *schema.Column
+	position *columnPosition
+}
+
+// columnPositions returns the positions of the added columns that do not go at the end of the
+// table, so that the target keeps the source column order. It returns nil if the diff does not
+// record the source column order.
+func columnPositions(tableDiff *schema.TableDiff) map[string]*columnPosition {
+	if len(tableDiff.ColumnOrder) == 0 {
+		return nil
+	}
+
+	added := make(map[string]bool, len(tableDiff.AddedColumns))
+	for _, column := range tableDiff.AddedColumns {
+		added[column.Name] = true
+	}
+
+	positions := make(map[string]*columnPosition)
+	trailing := true
+	for i := len(tableDiff.ColumnOrder) - 1; i >= 0; i-- {
+		name := tableDiff.ColumnOrder[i]
+		if !added[name] {
+			trailing = false
+			continue
+		}
+		if trailing {
+			continue
+		}
+		if i == 0 {
+			positions[name] = &columnPosition{first: true}
+		} else {
+			positions[name] = &columnPosition{after: tableDiff.ColumnOrder[i-1]}
+		}
+	}
+
+	return positions
+}
+
+// sameColumnDefinition returns true if two columns differ at most in their name
+func sameColumnDefinition(a, b *schema.Column) bool {
+	return a.DataType == b.DataType &&
+		a.IsNullable == b.IsNullable &&
+		a.Extra == b.Extra &&
+		a.Default().Equal(b.Default())
+}
+
+// sameIndexDefinition returns true if two indexes differ at most in their name
+func sameIndexDefinition(a, b *schema.Index) bool {
+	if a.TableName != b.TableName || a.IsUnique != b.IsUnique || a.IsPrimary != b.IsPrimary ||
+		a.IndexType != b.IndexType || len(a.Columns) != len(b.Columns) {
+		return false
+	}
+	for i := range a.Columns {
+		if a.Columns[i] != b.Columns[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,289 @@
+package migration
+
+import (
+	"strings"
+	"testing"
+
+	"mysql-schema-sync/internal/schema"
+)
+
+var (
+	mysql56    = ServerVersion{Major: 5, Minor: 6, Patch: 51}
+	mysql57    = ServerVersion{Major: 5, Minor: 7, Patch: 40}
+	mysql8020  = ServerVersion{Major: 8, Minor: 0, Patch: 20}
+	mysql8030  = ServerVersion{Major: 8, Minor: 0, Patch: 30}
+	mariadb104 = ServerVersion{Major: 10, Minor: 4, Patch: 30, MariaDB: true}
+	mariadb106 = ServerVersion{Major: 10, Minor: 6, Patch: 12, MariaDB: true}
+)
+
+func TestSQLGenerator_GenerateRenameColumnSQL(t *testing.T) {
+	oldColumn := &schema.Column{Name: "note", DataType: "varchar(50)", IsNullable: true}
+	renamed := &schema.Column{Name: "comment", DataType: "varchar(50)", IsNullable: true}
+	renamedNotNull := &schema.Column{Name: "comment", DataType: "varchar(50)"}
+
+	tests := []struct {
+		name      string
+		version   ServerVersion
+		newColumn *schema.Column
+		expected  string
+	}{
+		{
+			name:      "unknown version",
+			newColumn: renamed,
+			expected:  "ALTER TABLE `posts` CHANGE COLUMN `note` `comment` varchar(50) NULL",
+		},
+		{
+			name:      "MySQL 5.7",
+			version:   mysql57,
+			newColumn: renamed,
+			expected:  "ALTER TABLE `posts` CHANGE COLUMN `note` `comment` varchar(50) NULL",
+		},
+		{
+			name:      "MySQL 8.0",
+			version:   mysql8030,
+			newColumn: renamed,
+			expected:  "ALTER TABLE `posts` RENAME COLUMN `note` TO `comment`",
+		},
+		{
+			name:      "MySQL 8.0 with definition change",
+			version:   mysql8030,
+			newColumn: renamedNotNull,
+			expected:  "ALTER TABLE `posts` CHANGE COLUMN `note` `comment` varchar(50) NOT NULL",
+		},
+		{
+			name:      "MariaDB 10.4",
+			version:   mariadb104,
+			newColumn: renamed,
+			expected:  "ALTER TABLE `posts` CHANGE COLUMN `note` `comment` varchar(50) NULL",
+		},
+		{
+			name:      "MariaDB 10.6",
+			version:   mariadb106,
+			newColumn: renamed,
+			expected:  "ALTER TABLE `posts` RENAME COLUMN `note` TO `comment`",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			generator := NewSQLGenerator()
+			generator.SetServerVersion(tt.version)
+
+			sql, err := generator.GenerateRenameColumnSQL("posts", oldColumn, tt.newColumn)
+			if err != nil {
+				t.Fatalf("GenerateRenameColumnSQL() error = %v", err)
+			}
+			if sql != tt.expected {
+				t.Errorf("Expected %s, got %s", tt.expected, sql)
+			}
+		})
+	}
+}
+
+func TestSQLGenerator_CheckConstraintsByVersion(t *testing.T) {
+	check := &schema.Constraint{
+		Name:            "chk_price",
+		TableName:       "products",
+		Type:            schema.ConstraintTypeCheck,
+		Columns:         []string{"price"},
+		CheckExpression: "price >= 0",
+	}
+
+	tests := []struct {
+		name         string
+		version      ServerVersion
+		wantAddErr   bool
+		expectedDrop string
+	}{
+		{"unknown version", ServerVersion{}, false, "ALTER TABLE `products` DROP CHECK `chk_price`"},
+		{"MySQL 8.0.15", ServerVersion{Major: 8, Minor: 0, Patch: 15}, true, "ALTER TABLE `products` DROP CHECK `chk_price`"},
+		{"MySQL 8.0.16", ServerVersion{Major: 8, Minor: 0, Patch: 16}, false, "ALTER TABLE `products` DROP CHECK `chk_price`"},
+		{"MariaDB 10.6", mariadb106, false, "ALTER TABLE `products` DROP CONSTRAINT `chk_price`"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			generator := NewSQLGenerator()
+			generator.SetServerVersion(tt.version)
+
+			_, err := generator.GenerateAddConstraintSQL(check)
+			if (err != nil) != tt.wantAddErr {
+				t.Errorf("GenerateAddConstraintSQL() error = %v, wantErr %v", err, tt.wantAddErr)
+			}
+			if err != nil && !strings.Contains(err.Error(), "not enforced by server 8.0.15") {
+				t.Errorf("Expected error to name the server version, got %v", err)
+			}
+
+			sql, err := generator.GenerateDropConstraintSQL(check)
+			if err != nil {
+				t.Fatalf("GenerateDropConstraintSQL() error = %v", err)
+			}
+			if sql != tt.expectedDrop {
+				t.Errorf("Expected %s, got %s", tt.expectedDrop, sql)
+			}
+		})
+	}
+}
+
+func TestSQLGenerator_GenerateRenameIndexSQL(t *testing.T) {
+	oldIndex := &schema.Index{Name: "idx_old", TableName: "users", Columns: []string{"email"}}
+	newIndex := &schema.Index{Name: "idx_email", TableName: "users", Columns: []string{"email"}}
+
+	generator := NewSQLGenerator()
+	generator.SetServerVersion(mysql56)
+	if _, err := generator.GenerateRenameIndexSQL(oldIndex, newIndex); err == nil {
+		t.Error("Expected RENAME INDEX to be rejected on MySQL 5.6")
+	}
+
+	generator.SetServerVersion(mysql57)
+	sql, err := generator.GenerateRenameIndexSQL(oldIndex, newIndex)
+	if err != nil {
+		t.Fatalf("GenerateRenameIndexSQL() error = %v", err)
+	}
+	if expected := "ALTER TABLE `users` RENAME INDEX `idx_old` TO `idx_email`"; sql != expected {
+		t.Errorf("Expected %s, got %s", expected, sql)
+	}
+}
+
+func TestMigrationPlanner_RenamesIndexes(t *testing.T) {
+	diff := &schema.SchemaDiff{
+		RemovedIndexes: []*schema.Index{
+			{Name: "idx_old", TableName: "users", Columns: []string{"email"}, IndexType: "BTREE"},
+			{Name: "idx_gone", TableName: "users", Columns: []string{"name"}, IndexType: "BTREE"},
+		},
+		AddedIndexes: []*schema.Index{
+			{Name: "idx_email", TableName: "users", Columns: []string{"email"}, IndexType: "BTREE"},
+			{Name: "idx_unique_name", TableName: "users", Columns: []string{"name"}, IsUnique: true, IndexType: "BTREE"},
+		},
+	}
+
+	t.Run("supported", func(t *testing.T) {
+		planner := NewMigrationPlanner()
+		planner.SetServerVersion(mysql8030)
+
+		plan, err := planner.PlanMigration(diff)
+		if err != nil {
+			t.Fatalf("PlanMigration() error = %v", err)
+		}
+
+		types := make(map[StatementType][]string)
+		for _, stmt := range plan.Statements {
+			types[stmt.Type] = append(types[stmt.Type], stmt.SQL)
+		}
+
+		renames := types[StatementTypeRenameIndex]
+		if len(renames) != 1 || renames[0] != "ALTER TABLE `users` RENAME INDEX `idx_old` TO `idx_email`" {
+			t.Errorf("Expected one RENAME INDEX statement, got %v", renames)
+		}
+		// The unique index differs from the dropped one, so it is recreated
+		if len(types[StatementTypeDropIndex]) != 1 || len(types[StatementTypeCreateIndex]) != 1 {
+			t.Errorf("Expected idx_gone to be dropped and idx_unique_name created, got %v", types)
+		}
+
+		rollback, err := planner.PlanRollback(diff)
+		if err != nil {
+			t.Fatalf("PlanRollback() error = %v", err)
+		}
+		found := false
+		for _, stmt := range rollback.Statements {
+			if stmt.SQL == "ALTER TABLE `users` RENAME INDEX `idx_email` TO `idx_old`" {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf("Expected rollback to rename the index back, got %+v", rollback.Statements)
+		}
+	})
+
+	t.Run("unknown version", func(t *testing.T) {
+		plan, err := NewMigrationPlanner().PlanMigration(diff)
+		if err != nil {
+			t.Fatalf("PlanMigration() error = %v", err)
+		}
+		for _, stmt := range plan.Statements {
+			if stmt.Type == StatementTypeRenameIndex {
+				t.Errorf("Expected indexes to be dropped and recreated, got %s", stmt.SQL)
+			}
+		}
+	})
+}
+
+func TestMigrationPlanner_CheckConstraintUnsupported(t *testing.T) {
+	planner := NewMigrationPlanner()
+	planner.SetServerVersion(mysql57)
+
+	diff := &schema.SchemaDiff{
+		AddedConstraints: []*schema.Constraint{
+			{
+				Name:            "chk_price",
+				TableName:       "products",
+				Type:            schema.ConstraintTypeCheck,
+				Columns:         []string{"price"},
+				CheckExpression: "price >= 0",
+			},
+		},
+	}
+
+	_, err := planner.PlanMigration(diff)
+	if err == nil || !strings.Contains(err.Error(), "CHECK constraint chk_price on table products is not enforced by server 5.7.40") {
+		t.Errorf("Expected a clear error for CHECK constraints on MySQL 5.7, got %v", err)
+	}
+}
+
+func TestMigrationPlanner_ColumnPositions(t *testing.T) {
+	diff := &schema.SchemaDiff{
+		ModifiedTables: []*schema.TableDiff{
+			{
+				TableName: "users",
+				AddedColumns: []*schema.Column{
+					{Name: "nickname", DataType: "varchar(50)", IsNullable: true, Position: 5},
+					{Name: "uuid", DataType: "char(36)", IsNullable: true, Position: 1},
+					{Name: "email", DataType: "varchar(100)", IsNullable: true, Position: 3},
+				},
+				ColumnOrder: []string{"uuid", "id", "email", "name", "nickname"},
+			},
+		},
+	}
+
+	tests := []struct {
+		name       string
+		version    ServerVersion
+		algorithms []DDLAlgorithm
+	}{
+		{"MySQL 8.0.20 adds only trailing columns instantly", mysql8020, []DDLAlgorithm{AlgorithmInplace, AlgorithmInplace, AlgorithmInstant}},
+		{"MySQL 8.0.30 adds columns anywhere instantly", mysql8030, []DDLAlgorithm{AlgorithmInstant, AlgorithmInstant, AlgorithmInstant}},
+		{"MariaDB 10.4 adds columns anywhere instantly", mariadb104, []DDLAlgorithm{AlgorithmInstant, AlgorithmInstant, AlgorithmInstant}},
+	}
+
+	expectedSQL := []string{
+		"ALTER TABLE `users` ADD COLUMN `uuid` char(36) NULL FIRST",
+		"ALTER TABLE `users` ADD COLUMN `email` varchar(100) NULL AFTER `id`",
+		"ALTER TABLE `users` ADD COLUMN `nickname` varchar(50) NULL",
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			planner := NewMigrationPlanner()
+			if err := planner.EnableOnlineDDL(tt.version); err != nil {
+				t.Fatalf("EnableOnlineDDL() error = %v", err)
+			}
+
+			plan, err := planner.PlanMigration(diff)
+			if err != nil {
+				t.Fatalf("PlanMigration() error = %v", err)
+			}
+			if len(plan.Statements) != 3 {
+				t.Fatalf("Expected 3 statements, got %d", len(plan.Statements))
+			}
+
+			for i, stmt := range plan.Statements {
+				if !strings.HasPrefix(stmt.SQL, expectedSQL[i]) {
+					t.Errorf("Statement %d: expected %s, got %s", i, expectedSQL[i], stmt.SQL)
+				}
+				if stmt.Algorithm != tt.algorithms[i] {
+					t.Errorf("Statement %d: expected ALGORITHM=%s, got %s", i, tt.algorithms[i], stmt.Algorithm)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,233 @@
+package migration
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"mysql-schema-sync/internal/schema"
+)
+
+// Throughput assumptions used to turn table sizes into durations. They are deliberately
+// conservative figures for InnoDB on SSD storage under moderate load.
+const (
+	copyBytesPerSecond       = 25 * 1024 * 1024  // ALGORITHM=COPY re-inserts every row
+	rebuildBytesPerSecond    = 50 * 1024 * 1024  // in-place rebuilds sort and rewrite the table
+	indexBuildBytesPerSecond = 100 * 1024 * 1024 // index builds scan the data and write one index
+	metadataSeconds          = 0.1               // metadata-only changes and the final metadata lock
+)
+
+// StatementEstimate describes the expected impact of a statement on its table
+type StatementEstimate struct {
+	// Algorithm and Lock are the predicted online DDL behaviour, whether or not the clauses are
+	// added to the SQL. An empty lock means only a brief metadata lock is taken.
+	Algorithm DDLAlgorithm `json:"algorithm,omitempty"`
+	Lock      DDLLock      `json:"lock,omitempty"`
+	// Rebuild is true if the table is rewritten
+	Rebuild bool `json:"rebuild"`
+	// IndexBuild is true if a new index is built from the table data without a rebuild
+	IndexBuild bool `json:"index_build,omitempty"`
+	// SizeKnown is false if the table size could not be read, e.g. for tables created by the plan
+	SizeKnown   bool    `json:"size_known"`
+	Rows        int64   `json:"rows"`
+	BytesToCopy int64   `json:"bytes_to_copy"`
+	Seconds     float64 `json:"estimated_seconds"`
+}
+
+// Duration returns the estimated execution time
+func (se *StatementEstimate) Duration() time.Duration {
+	return time.Duration(se.Seconds * float64(time.Second))
+}
+
+// String returns a one-line description of the estimate
+func (se *StatementEstimate) String() string {
+	parts := make([]string, 0, 4)
+	switch {
+	case se.Rebuild:
+		parts = append(parts, "table rebuild")
+	case se.IndexBuild:
+		parts = append(parts, "index build")
+	default:
+		parts = append(parts, "metadata only")
+	}
+
+	if se.SizeKnown && se.BytesToCopy > 0 {
+		parts = append(parts, fmt.Sprintf("%s of %d rows", formatBytes(se.BytesToCopy), se.Rows))
+	}
+	parts = append(parts, "~"+formatEstimatedDuration(se.Duration()))
+
+	lock := "metadata lock only"
+	if se.Lock != "" {
+		lock = fmt.Sprintf("LOCK=%s", se.Lock)
+	}
+	parts = append(parts, lock)
+
+	return strings.Join(parts, ", ")
+}
+
+// predictImpact classifies the work the server does for a statement from its predicted algorithm
+func predictImpact(stmtType StatementType, object interface{}, algorithm DDLAlgorithm, lock DDLLock) *StatementEstimate {
+	estimate := &StatementEstimate{Algorithm: algorithm, Lock: lock}
+
+	switch algorithm {
+	case AlgorithmCopy:
+		estimate.Rebuild = true
+	case AlgorithmInplace:
+		switch stmtType {
+		case StatementTypeAddColumn:
+			estimate.Rebuild = true
+		case StatementTypeDropColumn:
+			estimate.Rebuild = true
+			// Archived columns are renamed rather than dropped
+			if columnDiff, ok := object.(*schema.ColumnDiff); ok {
+				estimate.Rebuild = changesNullability(columnDiff)
+			}
+		case StatementTypeModifyColumn:
+			columnDiff, ok := object.(*schema.ColumnDiff)
+			estimate.Rebuild = !ok || changesNullability(columnDiff)
+		case StatementTypeCreateIndex, StatementTypeAddConstraint:
+			estimate.IndexBuild = true
+		}
+	}
+
+	return estimate
+}
+
+// changesNullability returns true if a column change toggles NULL/NOT NULL, which rebuilds the
+// table even in place
+func changesNullability(columnDiff *schema.ColumnDiff) bool {
+	if columnDiff.OldColumn == nil || columnDiff.NewColumn == nil {
+		return true
+	}
+	return columnDiff.OldColumn.IsNullable != columnDiff.NewColumn.IsNullable
+}
+
+// combineEstimates returns the impact of running the statements as one merged ALTER TABLE
+func combineEstimates(statements []MigrationStatement) *StatementEstimate {
+	var combined *StatementEstimate
+	for _, stmt := range statements {
+		if stmt.Estimate == nil {
+			continue
+		}
+		if combined == nil {
+			combined = &StatementEstimate{}
+		}
+		if stmt.Estimate.Algorithm.rank() > combined.Algorithm.rank() {
+			combined.Algorithm = stmt.Estimate.Algorithm
+		}
+		if stmt.Estimate.Lock.rank() > combined.Lock.rank() {
+			combined.Lock = stmt.Estimate.Lock
+		}
+		combined.Rebuild = combined.Rebuild || stmt.Estimate.Rebuild
+		combined.IndexBuild = combined.IndexBuild || stmt.Estimate.IndexBuild
+	}
+
+	if combined != nil && combined.Rebuild {
+		// A rebuild creates the new indexes as part of the copy
+		combined.IndexBuild = false
+	}
+	return combined
+}
+
+// EstimatePlan fills in the rows, bytes and duration of every statement from the target table
+// sizes. Statements on tables without a known size are estimated as metadata-only changes.
+func EstimatePlan(plan *MigrationPlan, sizes map[string]*schema.TableSize) {
+	if plan == nil {
+		return
+	}
+
+	for i := range plan.Statements {
+		stmt := &plan.Statements[i]
+		if stmt.Estimate == nil {
+			stmt.Estimate = &StatementEstimate{}
+		}
+		stmt.Estimate.applySize(sizes[stmt.TableName])
+	}
+}
+
+// applySize computes the bytes to copy and the duration for a table of the given size
+func (se *StatementEstimate) applySize(size *schema.TableSize) {
+	se.SizeKnown = size != nil
+	se.Rows = 0
+	se.BytesToCopy = 0
+	se.Seconds = metadataSeconds
+
+	if size == nil {
+		return
+	}
+	se.Rows = size.Rows
+
+	switch {
+	case se.Rebuild:
+		rate := float64(rebuildBytesPerSecond)
+		if se.Algorithm == AlgorithmCopy {
+			rate = copyBytesPerSecond
+		}
+		se.BytesToCopy = size.TotalBytes()
+		se.Seconds += float64(se.BytesToCopy) / rate
+	case se.IndexBuild:
+		se.BytesToCopy = size.DataLength
+		se.Seconds += float64(se.BytesToCopy) / indexBuildBytesPerSecond
+	}
+}
+
+// PlanEstimate summarizes the estimates of all statements in a plan
+type PlanEstimate struct {
+	Duration    time.Duration
+	BytesToCopy int64
+	Rebuilds    int
+	// SizeKnown is true if at least one statement was estimated from a table size
+	SizeKnown bool
+}
+
+// Estimate returns the combined estimate of the plan statements
+func (mp *MigrationPlan) Estimate() PlanEstimate {
+	var total PlanEstimate
+	for _, stmt := range mp.Statements {
+		if stmt.Estimate == nil {
+			continue
+		}
+		total.Duration += stmt.Estimate.Duration()
+		total.BytesToCopy += stmt.Estimate.BytesToCopy
+		if stmt.Estimate.Rebuild {
+			total.Rebuilds++
+		}
+		total.SizeKnown = total.SizeKnown || stmt.Estimate.SizeKnown
+	}
+	return total
+}
+
+// String returns a one-line description of the plan estimate
+func (pe PlanEstimate) String() string {
+	text := "~" + formatEstimatedDuration(pe.Duration)
+	if pe.Rebuilds > 0 || pe.BytesToCopy > 0 {
+		text += fmt.Sprintf(" (%d table rebuilds, %s to copy)", pe.Rebuilds, formatBytes(pe.BytesToCopy))
+	}
+	return text
+}
+
+// formatEstimatedDuration rounds a duration to a precision that matches the estimate
+func formatEstimatedDuration(d time.Duration) string {
+	switch {
+	case d < time.Second:
+		return "< 1s"
+	case d < time.Minute:
+		return d.Round(time.Second).String()
+	default:
+		return d.Round(time.Minute).String()
+	}
+}
+
+// formatBytes formats a byte count in human-readable form
+func formatBytes(bytes int64) string {
+	const unit = 1024
+	if bytes < unit {
+		return fmt.Sprintf("%d B", bytes)
+	}
+	div, exp := int64(unit), 0
+	for n := bytes / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %cB", float64(bytes)/float64(div), "KMGTPE"[exp])
+}
@@ -0,0 +1,259 @@
+package migration
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"mysql-schema-sync/internal/schema"
+)
+
+func TestPredictImpact(t *testing.T) {
+	tests := []struct {
+		name           string
+		stmtType       StatementType
+		object         interface{}
+		algorithm      DDLAlgorithm
+		lock           DDLLock
+		wantRebuild    bool
+		wantIndexBuild bool
+	}{
+		{
+			name:      "instant add column",
+			stmtType:  StatementTypeAddColumn,
+			algorithm: AlgorithmInstant,
+		},
+		{
+			name:        "in-place add column rebuilds",
+			stmtType:    StatementTypeAddColumn,
+			algorithm:   AlgorithmInplace,
+			lock:        LockNone,
+			wantRebuild: true,
+		},
+		{
+			name:        "copy always rebuilds",
+			stmtType:    StatementTypeModifyColumn,
+			algorithm:   AlgorithmCopy,
+			lock:        LockShared,
+			wantRebuild: true,
+		},
+		{
+			name:     "in-place varchar extension is metadata only",
+			stmtType: StatementTypeModifyColumn,
+			object: &schema.ColumnDiff{
+				OldColumn: &schema.Column{Name: "name", DataType: "VARCHAR(50)"},
+				NewColumn: &schema.Column{Name: "name", DataType: "VARCHAR(100)"},
+			},
+			algorithm: AlgorithmInplace,
+			lock:      LockNone,
+		},
+		{
+			name:     "nullability change rebuilds in place",
+			stmtType: StatementTypeModifyColumn,
+			object: &schema.ColumnDiff{
+				OldColumn: &schema.Column{Name: "name", DataType: "VARCHAR(50)", IsNullable: true},
+				NewColumn: &schema.Column{Name: "name", DataType: "VARCHAR(50)"},
+			},
+			algorithm:   AlgorithmInplace,
+			lock:        LockNone,
+			wantRebuild: true,
+		},
+		{
+			name:           "index build",
+			stmtType:       StatementTypeCreateIndex,
+			algorithm:      AlgorithmInplace,
+			lock:           LockNone,
+			wantIndexBuild: true,
+		},
+		{
+			name:      "drop index is metadata only",
+			stmtType:  StatementTypeDropIndex,
+			algorithm: AlgorithmInplace,
+			lock:      LockNone,
+		},
+		{
+			name:     "create table",
+			stmtType: StatementTypeCreateTable,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			estimate := predictImpact(tt.stmtType, tt.object, tt.algorithm, tt.lock)
+			if estimate.Rebuild != tt.wantRebuild {
+				t.Errorf("Rebuild = %v, want %v", estimate.Rebuild, tt.wantRebuild)
+			}
+			if estimate.IndexBuild != tt.wantIndexBuild {
+				t.Errorf("IndexBuild = %v, want %v", estimate.IndexBuild, tt.wantIndexBuild)
+			}
+			if estimate.Algorithm != tt.algorithm || estimate.Lock != tt.lock {
+				t.Errorf("Expected %s/%s, got %s/%s", tt.algorithm, tt.lock, estimate.Algorithm, estimate.Lock)
+			}
+		})
+	}
+}
+
+func TestEstimatePlan(t *testing.T) {
+	planner := NewMigrationPlanner()
+	if err := planner.EnableOnlineDDL(ServerVersion{Major: 8, Minor: 0, Patch: 30}); err != nil {
+		t.Fatalf("EnableOnlineDDL() error = %v", err)
+	}
+
+	diff := &schema.SchemaDiff{
+		AddedTables: []*schema.Table{
+			{
+				Name:    "audit",
+				Columns: map[string]*schema.Column{"id": {Name: "id", DataType: "INT", Position: 1}},
+			},
+		},
+		ModifiedTables: []*schema.TableDiff{
+			{
+				TableName: "orders",
+				AddedColumns: []*schema.Column{
+					{Name: "note", DataType: "VARCHAR(255)", IsNullable: true},
+				},
+				ModifiedColumns: []*schema.ColumnDiff{
+					{
+						ColumnName: "total",
+						OldColumn:  &schema.Column{Name: "total", DataType: "FLOAT", IsNullable: true},
+						NewColumn:  &schema.Column{Name: "total", DataType: "DECIMAL(12,2)", IsNullable: true},
+					},
+				},
+			},
+		},
+		AddedIndexes: []*schema.Index{
+			{Name: "idx_note", TableName: "orders", Columns: []string{"note"}, IndexType: "BTREE"},
+		},
+	}
+
+	plan, err := planner.PlanMigration(diff)
+	if err != nil {
+		t.Fatalf("PlanMigration() error = %v", err)
+	}
+
+	const mib = 1024 * 1024
+	sizes := map[string]*schema.TableSize{
+		"orders": {TableName: "orders", Rows: 1000000, DataLength: 500 * mib, IndexLength: 100 * mib},
+	}
+	EstimatePlan(plan, sizes)
+
+	byType := make(map[StatementType]*StatementEstimate)
+	for _, stmt := range plan.Statements {
+		if stmt.Estimate == nil {
+			t.Fatalf("Expected an estimate for %q", stmt.Description)
+		}
+		byType[stmt.Type] = stmt.Estimate
+	}
+
+	created := byType[StatementTypeCreateTable]
+	if created.SizeKnown || created.Rebuild || created.BytesToCopy != 0 {
+		t.Errorf("Expected new table to be estimated without a size, got %+v", created)
+	}
+
+	added := byType[StatementTypeAddColumn]
+	if !added.SizeKnown || added.Rebuild || added.BytesToCopy != 0 || added.Rows != 1000000 {
+		t.Errorf("Expected instant add column to copy nothing, got %+v", added)
+	}
+
+	modified := byType[StatementTypeModifyColumn]
+	if !modified.Rebuild || modified.Algorithm != AlgorithmCopy || modified.BytesToCopy != 600*mib {
+		t.Errorf("Expected type change to copy the whole table, got %+v", modified)
+	}
+	if modified.Duration() != 24*time.Second+100*time.Millisecond {
+		t.Errorf("Expected copy to take 24.1s, got %s", modified.Duration())
+	}
+
+	index := byType[StatementTypeCreateIndex]
+	if !index.IndexBuild || index.Rebuild || index.BytesToCopy != 500*mib {
+		t.Errorf("Expected index build to scan the table data, got %+v", index)
+	}
+
+	total := plan.Estimate()
+	if !total.SizeKnown || total.Rebuilds != 1 || total.BytesToCopy != 1100*mib {
+		t.Errorf("Unexpected plan estimate: %+v", total)
+	}
+	if got, want := total.String(), "~29s (1 table rebuilds, 1.1 GB to copy)"; got != want {
+		t.Errorf("PlanEstimate.String() = %q, want %q", got, want)
+	}
+
+	if !strings.Contains(modified.String(), "table rebuild, 600.0 MB of 1000000 rows") ||
+		!strings.HasSuffix(modified.String(), "LOCK=SHARED") {
+		t.Errorf("Unexpected statement estimate text: %s", modified.String())
+	}
+
+	data, err := json.Marshal(plan.Statements[0])
+	if err != nil {
+		t.Fatalf("json.Marshal() error = %v", err)
+	}
+	if !strings.Contains(string(data), `"estimate":{`) || !strings.Contains(string(data), `"estimated_seconds":`) {
+		t.Errorf("Expected estimate in JSON output, got %s", data)
+	}
+}
+
+func TestEstimatePlan_MergedStatements(t *testing.T) {
+	planner := NewMigrationPlanner()
+	optimizer := NewPlanOptimizer()
+
+	diff := &schema.SchemaDiff{
+		ModifiedTables: []*schema.TableDiff{
+			{
+				TableName: "orders",
+				ModifiedColumns: []*schema.ColumnDiff{
+					{
+						ColumnName: "total",
+						OldColumn:  &schema.Column{Name: "total", DataType: "FLOAT", IsNullable: true},
+						NewColumn:  &schema.Column{Name: "total", DataType: "DECIMAL(12,2)", IsNullable: true},
+					},
+				},
+			},
+		},
+		AddedIndexes: []*schema.Index{
+			{Name: "idx_total", TableName: "orders", Columns: []string{"total"}, IndexType: "BTREE"},
+		},
+	}
+
+	plan, err := planner.PlanMigration(diff)
+	if err != nil {
+		t.Fatalf("PlanMigration() error = %v", err)
+	}
+	optimized, err := optimizer.MergeTableAlterations(plan)
+	if err != nil {
+		t.Fatalf("MergeTableAlterations() error = %v", err)
+	}
+	if len(optimized.Statements) != 1 {
+		t.Fatalf("Expected 1 merged statement, got %d", len(optimized.Statements))
+	}
+
+	EstimatePlan(optimized, map[string]*schema.TableSize{
+		"orders": {TableName: "orders", Rows: 10, DataLength: 2048, IndexLength: 1024},
+	})
+
+	estimate := optimized.Statements[0].Estimate
+	if estimate == nil {
+		t.Fatal("Expected merged statement to have an estimate")
+	}
+	if !estimate.Rebuild || estimate.IndexBuild || estimate.Algorithm != AlgorithmCopy || estimate.Lock != LockShared {
+		t.Errorf("Expected merged statement to take the strongest algorithm and lock, got %+v", estimate)
+	}
+	if estimate.BytesToCopy != 3072 {
+		t.Errorf("Expected the table to be copied once, got %d bytes", estimate.BytesToCopy)
+	}
+}
+
+func TestFormatEstimatedDuration(t *testing.T) {
+	tests := []struct {
+		duration time.Duration
+		want     string
+	}{
+		{100 * time.Millisecond, "< 1s"},
+		{12600 * time.Millisecond, "13s"},
+		{95 * time.Minute, "1h35m0s"},
+	}
+
+	for _, tt := range tests {
+		if got := formatEstimatedDuration(tt.duration); got != tt.want {
+			t.Errorf("formatEstimatedDuration(%s) = %q, want %q", tt.duration, got, tt.want)
+		}
+	}
+}
@@ -19,8 +19,10 @@ const (
 	StatementTypeModifyColumn   StatementType = "MODIFY_COLUMN"
 	StatementTypeCreateIndex    StatementType = "CREATE_INDEX"
 	StatementTypeDropIndex      StatementType = "DROP_INDEX"
+	StatementTypeRenameIndex    StatementType = "RENAME_INDEX"
 	StatementTypeAddConstraint  StatementType = "ADD_CONSTRAINT"
 	StatementTypeDropConstraint StatementType = "DROP_CONSTRAINT"
+	StatementTypeAlterTable     StatementType = "ALTER_TABLE"
 )
 
 // MigrationStatement represents a single SQL statement in a migration
@@ -30,7 +32,30 @@ type MigrationStatement struct {
 	Description   string        `json:"description"`
 	IsDestructive bool          `json:"is_destructive"`
 	TableName     string        `json:"table_name,omitempty"`
-	Dependencies  []string      `json:"dependencies,omitempty"`
+	// Object identifies the schema object the statement creates, drops or changes
+	Object string `json:"object,omitempty"`
+	// Dependencies lists the objects that must exist while the statement runs
+	Dependencies []string `json:"dependencies,omitempty"`
+	// DisableForeignKeyChecks runs the statement with FOREIGN_KEY_CHECKS=0 to break a dependency cycle
+	DisableForeignKeyChecks bool `json:"disable_foreign_key_checks,omitempty"`
+	// DataLoss marks rollback statements that restore structure but not the data the migration removed
+	DataLoss bool `json:"data_loss,omitempty"`
+	// Operations holds the original sub-operations of a merged ALTER_TABLE statement
+	Operations []AlterOperation `json:"operations,omitempty"`
+	// Algorithm and Lock are the expected online DDL behaviour when online DDL is enabled
+	Algorithm DDLAlgorithm `json:"algorithm,omitempty"`
+	Lock      DDLLock      `json:"lock,omitempty"`
+	// Estimate is the expected impact of the statement on its table
+	Estimate *StatementEstimate `json:"estimate,omitempty"`
+}
+
+// AlterOperation represents a single operation folded into a merged ALTER TABLE statement
+type AlterOperation struct {
+	Clause        string        `json:"clause"`
+	Type          StatementType `json:"type"`
+	Description   string        `json:"description"`
+	IsDestructive bool          `json:"is_destructive"`
+	Algorithm     DDLAlgorithm  `json:"algorithm,omitempty"`
 }
 
 // MigrationPlan represents a complete migration plan with ordered statements
@@ -38,6 +63,10 @@ type MigrationPlan struct {
 	Statements []MigrationStatement `json:"statements"`
 	Warnings   []string             `json:"warnings"`
 	Summary    MigrationSummary     `json:"summary"`
+	// Rollback is the plan that reverts this one, computed from the same schema diff
+	Rollback *MigrationPlan `json:"rollback,omitempty"`
+	// Archived lists the tables and columns that safe-drop mode archives instead of dropping
+	Archived []ArchivedObject `json:"archived,omitempty"`
 }
 
 // MigrationSummary provides a high-level overview of the migration
@@ -79,14 +108,20 @@ func (ms *MigrationStatement) Validate() error {
 		StatementTypeModifyColumn:   true,
 		StatementTypeCreateIndex:    true,
 		StatementTypeDropIndex:      true,
+		StatementTypeRenameIndex:    true,
 		StatementTypeAddConstraint:  true,
 		StatementTypeDropConstraint: true,
+		StatementTypeAlterTable:     true,
 	}
 
 	if !validTypes[ms.Type] {
 		return fmt.Errorf("invalid statement type: %s", ms.Type)
 	}
 
+	if ms.Type == StatementTypeAlterTable && len(ms.Operations) == 0 {
+		return fmt.Errorf("merged alter table statement must have at least one operation")
+	}
+
 	return nil
 }
 
@@ -124,8 +159,9 @@ func (st StatementType) GetExecutionOrder() int {
 	orderMap := map[StatementType]int{
 		// First: Drop foreign key constraints to avoid dependency issues
 		StatementTypeDropConstraint: 1,
-		// Second: Drop indexes (except primary keys handled with tables)
-		StatementTypeDropIndex: 2,
+		// Second: Drop and rename indexes (except primary keys handled with tables)
+		StatementTypeDropIndex:   2,
+		StatementTypeRenameIndex: 2,
 		// Third: Drop columns
 		StatementTypeDropColumn: 3,
 		// Fourth: Drop tables
@@ -134,8 +170,9 @@ func (st StatementType) GetExecutionOrder() int {
 		StatementTypeCreateTable: 5,
 		// Sixth: Add columns
 		StatementTypeAddColumn: 6,
-		// Seventh: Modify columns
+		// Seventh: Modify columns and merged table alterations
 		StatementTypeModifyColumn: 7,
+		StatementTypeAlterTable:   7,
 		// Eighth: Create indexes
 		StatementTypeCreateIndex: 8,
 		// Ninth: Add constraints (foreign keys last)
@@ -190,38 +227,21 @@ func (mp *MigrationPlan) updateSummary() {
 	summary.TotalStatements = len(mp.Statements)
 
 	for _, stmt := range mp.Statements {
-		if stmt.IsDestructive {
-			summary.DestructiveCount++
-		}
+		summary.DestructiveCount += stmt.destructiveCount()
 
-		switch stmt.Type {
-		case StatementTypeCreateTable:
-			summary.TablesAdded++
-		case StatementTypeDropTable:
-			summary.TablesRemoved++
-		case StatementTypeAddColumn:
-			summary.ColumnsAdded++
-		case StatementTypeDropColumn:
-			summary.ColumnsRemoved++
-		case StatementTypeModifyColumn:
-			summary.ColumnsModified++
-		case StatementTypeCreateIndex:
-			summary.IndexesAdded++
-		case StatementTypeDropIndex:
-			summary.IndexesRemoved++
-		case StatementTypeAddConstraint:
-			summary.ConstraintsAdded++
-		case StatementTypeDropConstraint:
-			summary.ConstraintsRemoved++
+		for _, stmtType := range stmt.operationTypes() {
+			summary.countOperation(stmtType)
 		}
 	}
 
 	// Count modified tables by checking which tables have column modifications
 	modifiedTables := make(map[string]bool)
 	for _, stmt := range mp.Statements {
-		if stmt.Type == StatementTypeAddColumn || stmt.Type == StatementTypeDropColumn || stmt.Type == StatementTypeModifyColumn {
-			if stmt.TableName != "" {
-				modifiedTables[stmt.TableName] = true
+		for _, stmtType := range stmt.operationTypes() {
+			if stmtType == StatementTypeAddColumn || stmtType == StatementTypeDropColumn || stmtType == StatementTypeModifyColumn {
+				if stmt.TableName != "" {
+					modifiedTables[stmt.TableName] = true
+				}
 			}
 		}
 	}
@@ -230,6 +250,61 @@ func (mp *MigrationPlan) updateSummary() {
 	mp.Summary = summary
 }
 
+// operationTypes returns the statement types this statement performs, expanding merged alterations
+func (ms *MigrationStatement) operationTypes() []StatementType {
+	if ms.Type != StatementTypeAlterTable {
+		return []StatementType{ms.Type}
+	}
+
+	types := make([]StatementType, len(ms.Operations))
+	for i, op := range ms.Operations {
+		types[i] = op.Type
+	}
+	return types
+}
+
+// destructiveCount returns the number of destructive operations performed by the statement
+func (ms *MigrationStatement) destructiveCount() int {
+	if ms.Type != StatementTypeAlterTable {
+		if ms.IsDestructive {
+			return 1
+		}
+		return 0
+	}
+
+	count := 0
+	for _, op := range ms.Operations {
+		if op.IsDestructive {
+			count++
+		}
+	}
+	return count
+}
+
+// countOperation increments the summary counter matching the statement type
+func (s *MigrationSummary) countOperation(stmtType StatementType) {
+	switch stmtType {
+	case StatementTypeCreateTable:
+		s.TablesAdded++
+	case StatementTypeDropTable:
+		s.TablesRemoved++
+	case StatementTypeAddColumn:
+		s.ColumnsAdded++
+	case StatementTypeDropColumn:
+		s.ColumnsRemoved++
+	case StatementTypeModifyColumn:
+		s.ColumnsModified++
+	case StatementTypeCreateIndex:
+		s.IndexesAdded++
+	case StatementTypeDropIndex:
+		s.IndexesRemoved++
+	case StatementTypeAddConstraint:
+		s.ConstraintsAdded++
+	case StatementTypeDropConstraint:
+		s.ConstraintsRemoved++
+	}
+}
+
 // HasDestructiveOperations returns true if the plan contains destructive operations
 func (mp *MigrationPlan) HasDestructiveOperations() bool {
 	return mp.Summary.DestructiveCount > 0
@@ -260,11 +335,15 @@ func (mp *MigrationPlan) GetStatementsByTable(tableName string) []MigrationState
 // Hash calculates a SHA-256 hash of the migration plan for identification
 func (mp *MigrationPlan) Hash() string {
 	// Create a deterministic representation of the plan
+	statements := make([]MigrationStatement, len(mp.Statements))
+	for i, stmt := range mp.Statements {
+		statements[i] = stmt.hashable()
+	}
 	data := struct {
 		Statements []MigrationStatement `json:"statements"`
 		Summary    MigrationSummary     `json:"summary"`
 	}{
-		Statements: mp.Statements,
+		Statements: statements,
 		Summary:    mp.Summary,
 	}
 
@@ -278,6 +357,24 @@ func (mp *MigrationPlan) Hash() string {
 	return hex.EncodeToString(hash[:])
 }
 
+// hashable returns the statement without the parts that depend on the state of the target or the
+// time of planning rather than on the schema diff, so that the same diff always hashes the same
+func (ms MigrationStatement) hashable() MigrationStatement {
+	ms.Estimate = nil
+	ms.SQL = stripArchiveTimes(ms.SQL)
+	ms.Description = stripArchiveTimes(ms.Description)
+	if ms.Operations != nil {
+		operations := make([]AlterOperation, len(ms.Operations))
+		for i, op := range ms.Operations {
+			op.Clause = stripArchiveTimes(op.Clause)
+			op.Description = stripArchiveTimes(op.Description)
+			operations[i] = op
+		}
+		ms.Operations = operations
+	}
+	return ms
+}
+
 // simpleHash creates a simple hash based on statement count and types
 func (mp *MigrationPlan) simpleHash() string {
 	var builder strings.Builder
@@ -258,6 +258,23 @@ func TestMigrationPlan_GetStatementsByTable(t *testing.T) {
 	}
 }
 
+func TestMigrationPlan_HashIgnoresEstimates(t *testing.T) {
+	plan := NewMigrationPlan()
+	stmt := *NewMigrationStatement(
+		"ALTER TABLE test ADD COLUMN name VARCHAR(255)",
+		StatementTypeAddColumn,
+		"Add name column to test",
+	)
+	stmt.TableName = "test"
+	plan.AddStatement(stmt)
+
+	hash := plan.Hash()
+	plan.Statements[0].Estimate = &StatementEstimate{Rebuild: true, SizeKnown: true, Rows: 1000}
+	if plan.Hash() != hash {
+		t.Error("Expected the hash not to depend on the table size estimate")
+	}
+}
+
 func TestNewMigrationStatement(t *testing.T) {
 	sql := "CREATE TABLE test (id INT)"
 	stmtType := StatementTypeCreateTable
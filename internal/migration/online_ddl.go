@@ -0,0 +1,389 @@
+package migration
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"mysql-schema-sync/internal/schema"
+)
+
+// DDLAlgorithm represents the ALGORITHM used by MySQL to execute an ALTER TABLE
+type DDLAlgorithm string
+
+const (
+	AlgorithmInstant DDLAlgorithm = "INSTANT"
+	AlgorithmInplace DDLAlgorithm = "INPLACE"
+	AlgorithmCopy    DDLAlgorithm = "COPY"
+)
+
+// DDLLock represents the LOCK level requested for an ALTER TABLE
+type DDLLock string
+
+const (
+	LockNone      DDLLock = "NONE"
+	LockShared    DDLLock = "SHARED"
+	LockExclusive DDLLock = "EXCLUSIVE"
+)
+
+// rank orders algorithms from least to most intrusive
+func (a DDLAlgorithm) rank() int {
+	switch a {
+	case AlgorithmInstant:
+		return 1
+	case AlgorithmInplace:
+		return 2
+	case AlgorithmCopy:
+		return 3
+	default:
+		return 0
+	}
+}
+
+// rank orders lock levels from least to most restrictive
+func (l DDLLock) rank() int {
+	switch l {
+	case LockNone:
+		return 1
+	case LockShared:
+		return 2
+	case LockExclusive:
+		return 3
+	default:
+		return 0
+	}
+}
+
+// ServerVersion identifies the MySQL or MariaDB server a plan targets
+type ServerVersion struct {
+	Major   int
+	Minor   int
+	Patch   int
+	MariaDB bool
+}
+
+var serverVersionPattern = regexp.MustCompile(`^(\d+)\.(\d+)(?:\.(\d+))?`)
+
+// ParseServerVersion parses a version string as returned by SELECT VERSION()
+func ParseServerVersion(version string) (ServerVersion, error) {
+	version = strings.TrimSpace(version)
+	matches := serverVersionPattern.FindStringSubmatch(version)
+	if matches == nil {
+		return ServerVersion{}, fmt.Errorf("unrecognized server version: %q", version)
+	}
+
+	parsed := ServerVersion{
+		MariaDB: strings.Contains(strings.ToLower(version), "mariadb"),
+	}
+	parsed.Major, _ = strconv.Atoi(matches[1])
+	parsed.Minor, _ = strconv.Atoi(matches[2])
+	if matches[3] != "" {
+		parsed.Patch, _ = strconv.Atoi(matches[3])
+	}
+
+	return parsed, nil
+}
+
+// AtLeast returns true if the version is greater than or equal to major.minor.patch
+func (v ServerVersion) AtLeast(major, minor, patch int) bool {
+	if v.Major != major {
+		return v.Major > major
+	}
+	if v.Minor != minor {
+		return v.Minor > minor
+	}
+	return v.Patch >= patch
+}
+
+// String returns the version in major.minor.patch form
+func (v ServerVersion) String() string {
+	version := fmt.Sprintf("%d.%d.%d", v.Major, v.Minor, v.Patch)
+	if v.MariaDB {
+		version += "-MariaDB"
+	}
+	return version
+}
+
+// known returns true if the version was detected, as opposed to the zero value used when the
+// target server is unknown
+func (v ServerVersion) known() bool {
+	return v != ServerVersion{}
+}
+
+// SupportsOnlineDDL returns true if the server accepts ALGORITHM and LOCK clauses
+func (v ServerVersion) SupportsOnlineDDL() bool {
+	if v.MariaDB {
+		return v.AtLeast(10, 0, 0)
+	}
+	return v.AtLeast(5, 6, 0)
+}
+
+// supportsInstantAddColumn returns true if trailing columns can be added with ALGORITHM=INSTANT
+func (v ServerVersion) supportsInstantAddColumn() bool {
+	if v.MariaDB {
+		return v.AtLeast(10, 3, 2)
+	}
+	return v.AtLeast(8, 0, 12)
+}
+
+// supportsInstantDropColumn returns true if columns can be dropped with ALGORITHM=INSTANT
+func (v ServerVersion) supportsInstantDropColumn() bool {
+	if v.MariaDB {
+		return v.AtLeast(10, 4, 0)
+	}
+	return v.AtLeast(8, 0, 29)
+}
+
+// supportsInstantMetadataChange returns true if default value changes are INSTANT
+func (v ServerVersion) supportsInstantMetadataChange() bool {
+	if v.MariaDB {
+		return v.AtLeast(10, 3, 2)
+	}
+	return v.AtLeast(8, 0, 12)
+}
+
+// supportsExpressionDefaults returns true if column defaults may be arbitrary expressions rather
+// than literals and CURRENT_TIMESTAMP
+func (v ServerVersion) supportsExpressionDefaults() bool {
+	if v.MariaDB {
+		return v.AtLeast(10, 2, 1)
+	}
+	return v.AtLeast(8, 0, 13)
+}
+
+// supportsInstantDropCheck returns true if CHECK constraints can be dropped with ALGORITHM=INSTANT
+func (v ServerVersion) supportsInstantDropCheck() bool {
+	return !v.MariaDB && v.AtLeast(8, 0, 16)
+}
+
+// EnableOnlineDDL makes the generator annotate statements with ALGORITHM and LOCK clauses
+// suitable for the given server version
+func (sg *SQLGenerator) EnableOnlineDDL(version ServerVersion) error {
+	if !version.SupportsOnlineDDL() {
+		return fmt.Errorf("online DDL requires MySQL 5.6 or MariaDB 10.0 or later, server is %s", version)
+	}
+
+	sg.onlineDDL = true
+	sg.serverVersion = version
+	return nil
+}
+
+// OnlineDDLEnabled returns true if online DDL clauses are generated
+func (sg *SQLGenerator) OnlineDDLEnabled() bool {
+	return sg.onlineDDL
+}
+
+// PredictOnlineDDL returns the algorithm and lock level the server is expected to use for an
+// operation. An empty algorithm means the operation does not take ALGORITHM/LOCK clauses.
+func (sg *SQLGenerator) PredictOnlineDDL(stmtType StatementType, object interface{}) (DDLAlgorithm, DDLLock) {
+	version := sg.serverVersion
+
+	switch stmtType {
+	case StatementTypeAddColumn:
+		var position *columnPosition
+		if placed, ok := object.(*placedColumn); ok {
+			object, position = placed.column, placed.position
+		}
+		column, ok := object.(*schema.Column)
+		if !ok {
+			return AlgorithmCopy, LockShared
+		}
+		extra := strings.ToUpper(column.Extra)
+		switch {
+		case strings.Contains(extra, "AUTO_INCREMENT"):
+			return AlgorithmInplace, LockShared
+		case strings.Contains(extra, "STORED GENERATED"):
+			return AlgorithmCopy, LockShared
+		case version.supportsInstantAddColumn() && (position == nil || version.supportsInstantAddColumnAnywhere()):
+			return AlgorithmInstant, ""
+		default:
+			return AlgorithmInplace, LockNone
+		}
+
+	case StatementTypeDropColumn:
+		// Archived columns are renamed rather than dropped
+		if columnDiff, ok := object.(*schema.ColumnDiff); ok {
+			return sg.predictColumnModification(columnDiff)
+		}
+		if version.supportsInstantDropColumn() {
+			return AlgorithmInstant, ""
+		}
+		return AlgorithmInplace, LockNone
+
+	case StatementTypeModifyColumn:
+		columnDiff, ok := object.(*schema.ColumnDiff)
+		if !ok {
+			return AlgorithmCopy, LockShared
+		}
+		return sg.predictColumnModification(columnDiff)
+
+	case StatementTypeCreateIndex:
+		index, ok := object.(*schema.Index)
+		if ok {
+			indexType := strings.ToUpper(index.IndexType)
+			if indexType == "FULLTEXT" || indexType == "SPATIAL" {
+				return AlgorithmInplace, LockShared
+			}
+		}
+		return AlgorithmInplace, LockNone
+
+	case StatementTypeDropIndex, StatementTypeRenameIndex:
+		return AlgorithmInplace, LockNone
+
+	case StatementTypeAddConstraint:
+		constraint, ok := object.(*schema.Constraint)
+		if ok && constraint.Type == schema.ConstraintTypeUnique {
+			return AlgorithmInplace, LockNone
+		}
+		// Foreign keys (with foreign_key_checks enabled) and CHECK constraints must
+		// validate existing rows through a table copy
+		return AlgorithmCopy, LockShared
+
+	case StatementTypeDropConstraint:
+		constraint, ok := object.(*schema.Constraint)
+		if ok && constraint.Type == schema.ConstraintTypeCheck && version.supportsInstantDropCheck() {
+			return AlgorithmInstant, ""
+		}
+		return AlgorithmInplace, LockNone
+
+	default:
+		return "", ""
+	}
+}
+
+// predictColumnModification classifies a column change as metadata-only, rebuild or copy
+func (sg *SQLGenerator) predictColumnModification(columnDiff *schema.ColumnDiff) (DDLAlgorithm, DDLLock) {
+	oldCol, newCol := columnDiff.OldColumn, columnDiff.NewColumn
+	if oldCol == nil || newCol == nil {
+		return AlgorithmCopy, LockShared
+	}
+
+	sameType := strings.EqualFold(oldCol.DataType, newCol.DataType)
+	sameExtra := strings.EqualFold(oldCol.Extra, newCol.Extra)
+
+	if sameType && sameExtra && oldCol.IsNullable == newCol.IsNullable {
+		// Only the default value differs
+		if sg.serverVersion.supportsInstantMetadataChange() {
+			return AlgorithmInstant, ""
+		}
+		return AlgorithmInplace, LockNone
+	}
+
+	if sameType && sameExtra {
+		// Changing nullability rebuilds the table in place
+		return AlgorithmInplace, LockNone
+	}
+
+	if sameExtra && oldCol.IsNullable == newCol.IsNullable && isInplaceVarcharExtension(oldCol.DataType, newCol.DataType) {
+		return AlgorithmInplace, LockNone
+	}
+
+	return AlgorithmCopy, LockShared
+}
+
+var varcharPattern = regexp.MustCompile(`(?i)^VARCHAR\((\d+)\)$`)
+
+// maxVarcharBytesPerChar is the worst-case character width (utf8mb4) used to
+// determine how many length bytes a VARCHAR column needs
+const maxVarcharBytesPerChar = 4
+
+// isInplaceVarcharExtension returns true if a VARCHAR is widened without crossing the
+// 255-byte boundary that changes the number of length bytes
+func isInplaceVarcharExtension(oldType, newType string) bool {
+	oldMatch := varcharPattern.FindStringSubmatch(strings.TrimSpace(oldType))
+	newMatch := varcharPattern.FindStringSubmatch(strings.TrimSpace(newType))
+	if oldMatch == nil || newMatch == nil {
+		return false
+	}
+
+	oldLen, _ := strconv.Atoi(oldMatch[1])
+	newLen, _ := strconv.Atoi(newMatch[1])
+	if newLen < oldLen {
+		return false
+	}
+
+	return (oldLen*maxVarcharBytesPerChar < 256) == (newLen*maxVarcharBytesPerChar < 256)
+}
+
+// ApplyOnlineDDL records the predicted impact of the statement and, when online DDL is enabled,
+// annotates it with its predicted algorithm and appends the matching ALGORITHM/LOCK clause so
+// that the server fails instead of falling back to COPY
+func (sg *SQLGenerator) ApplyOnlineDDL(stmt *MigrationStatement, object interface{}) {
+	if stmt == nil {
+		return
+	}
+
+	algorithm, lock := sg.PredictOnlineDDL(stmt.Type, object)
+	stmt.Estimate = predictImpact(stmt.Type, object, algorithm, lock)
+	if !sg.onlineDDL || algorithm == "" {
+		return
+	}
+
+	stmt.Algorithm = algorithm
+	stmt.Lock = lock
+	stmt.SQL += onlineDDLClause(stmt.SQL, algorithm, lock)
+}
+
+var onlineDDLClausePattern = regexp.MustCompile(`(?i)(,\s*|\s+)ALGORITHM\s*=\s*\w+(\s*,?\s*LOCK\s*=\s*\w+)?\s*$`)
+
+// onlineDDLClause builds the ALGORITHM/LOCK suffix for a statement.
+// ALTER TABLE separates the clauses with commas while CREATE/DROP INDEX takes them as options.
+// INSTANT operations only permit the default lock, so no LOCK clause is emitted for them.
+func onlineDDLClause(sql string, algorithm DDLAlgorithm, lock DDLLock) string {
+	separator := " "
+	if strings.HasPrefix(strings.ToUpper(strings.TrimSpace(sql)), "ALTER TABLE") {
+		separator = ", "
+	}
+
+	clause := separator + "ALGORITHM=" + string(algorithm)
+	if algorithm != AlgorithmInstant && lock != "" {
+		clause += separator + "LOCK=" + string(lock)
+	}
+	return clause
+}
+
+// stripOnlineDDLClause removes a trailing ALGORITHM/LOCK suffix from a statement
+func stripOnlineDDLClause(sql string) string {
+	return onlineDDLClausePattern.ReplaceAllString(sql, "")
+}
+
+// combineOnlineDDL returns the most intrusive algorithm and lock among the given statements
+func combineOnlineDDL(statements []MigrationStatement) (DDLAlgorithm, DDLLock) {
+	var algorithm DDLAlgorithm
+	var lock DDLLock
+
+	for _, stmt := range statements {
+		if stmt.Algorithm.rank() > algorithm.rank() {
+			algorithm = stmt.Algorithm
+		}
+		if stmt.Lock.rank() > lock.rank() {
+			lock = stmt.Lock
+		}
+	}
+
+	if algorithm != "" && algorithm != AlgorithmInstant && lock == "" {
+		lock = LockNone
+	}
+
+	return algorithm, lock
+}
+
+// BlocksWrites returns true if the statement is expected to block concurrent writes
+func (ms *MigrationStatement) BlocksWrites() bool {
+	if ms.Algorithm == "" {
+		return false
+	}
+	return ms.Algorithm == AlgorithmCopy || ms.Lock == LockShared || ms.Lock == LockExclusive
+}
+
+// GetBlockingStatements returns the statements expected to block writes on their table
+func (mp *MigrationPlan) GetBlockingStatements() []MigrationStatement {
+	var statements []MigrationStatement
+	for _, stmt := range mp.Statements {
+		if stmt.BlocksWrites() {
+			statements = append(statements, stmt)
+		}
+	}
+	return statements
+}
@@ -0,0 +1,304 @@
+package migration
+
+import (
+	"strings"
+	"testing"
+
+	"mysql-schema-sync/internal/schema"
+)
+
+func TestParseServerVersion(t *testing.T) {
+	tests := []struct {
+		name     string
+		version  string
+		expected ServerVersion
+		wantErr  bool
+	}{
+		{
+			name:     "mysql 8",
+			version:  "8.0.32",
+			expected: ServerVersion{Major: 8, Minor: 0, Patch: 32},
+		},
+		{
+			name:     "mysql with suffix",
+			version:  "5.7.40-log",
+			expected: ServerVersion{Major: 5, Minor: 7, Patch: 40},
+		},
+		{
+			name:     "mariadb",
+			version:  "10.6.12-MariaDB-1:10.6.12+maria~ubu2004",
+			expected: ServerVersion{Major: 10, Minor: 6, Patch: 12, MariaDB: true},
+		},
+		{
+			name:    "invalid",
+			version: "unknown",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseServerVersion(tt.version)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseServerVersion() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && got != tt.expected {
+				t.Errorf("ParseServerVersion() = %+v, want %+v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestSQLGenerator_EnableOnlineDDL(t *testing.T) {
+	generator := NewSQLGenerator()
+
+	if err := generator.EnableOnlineDDL(ServerVersion{Major: 5, Minor: 5, Patch: 62}); err == nil {
+		t.Error("Expected error for server without online DDL support")
+	}
+	if generator.OnlineDDLEnabled() {
+		t.Error("Expected online DDL to stay disabled after a rejected version")
+	}
+
+	if err := generator.EnableOnlineDDL(ServerVersion{Major: 8, Minor: 0, Patch: 32}); err != nil {
+		t.Fatalf("EnableOnlineDDL() error = %v", err)
+	}
+	if !generator.OnlineDDLEnabled() {
+		t.Error("Expected online DDL to be enabled")
+	}
+}
+
+func TestSQLGenerator_PredictOnlineDDL(t *testing.T) {
+	mysql57 := ServerVersion{Major: 5, Minor: 7, Patch: 40}
+	mysql8012 := ServerVersion{Major: 8, Minor: 0, Patch: 12}
+	mysql8032 := ServerVersion{Major: 8, Minor: 0, Patch: 32}
+
+	column := &schema.Column{Name: "note", DataType: "VARCHAR(50)", IsNullable: true}
+	defaultValue := "x"
+
+	tests := []struct {
+		name              string
+		version           ServerVersion
+		stmtType          StatementType
+		object            interface{}
+		expectedAlgorithm DDLAlgorithm
+		expectedLock      DDLLock
+	}{
+		{
+			name:              "add column on 5.7",
+			version:           mysql57,
+			stmtType:          StatementTypeAddColumn,
+			object:            column,
+			expectedAlgorithm: AlgorithmInplace,
+			expectedLock:      LockNone,
+		},
+		{
+			name:              "add column on 8.0",
+			version:           mysql8012,
+			stmtType:          StatementTypeAddColumn,
+			object:            column,
+			expectedAlgorithm: AlgorithmInstant,
+		},
+		{
+			name:              "add auto increment column",
+			version:           mysql8032,
+			stmtType:          StatementTypeAddColumn,
+			object:            &schema.Column{Name: "id", DataType: "INT", Extra: "auto_increment"},
+			expectedAlgorithm: AlgorithmInplace,
+			expectedLock:      LockShared,
+		},
+		{
+			name:              "drop column before 8.0.29",
+			version:           mysql8012,
+			stmtType:          StatementTypeDropColumn,
+			object:            column,
+			expectedAlgorithm: AlgorithmInplace,
+			expectedLock:      LockNone,
+		},
+		{
+			name:              "drop column on 8.0.32",
+			version:           mysql8032,
+			stmtType:          StatementTypeDropColumn,
+			object:            column,
+			expectedAlgorithm: AlgorithmInstant,
+		},
+		{
+			name:     "change default only",
+			version:  mysql8032,
+			stmtType: StatementTypeModifyColumn,
+			object: &schema.ColumnDiff{
+				ColumnName: "note",
+				OldColumn:  column,
+				NewColumn:  &schema.Column{Name: "note", DataType: "VARCHAR(50)", IsNullable: true, DefaultValue: &defaultValue},
+			},
+			expectedAlgorithm: AlgorithmInstant,
+		},
+		{
+			name:     "extend varchar within length byte boundary",
+			version:  mysql57,
+			stmtType: StatementTypeModifyColumn,
+			object: &schema.ColumnDiff{
+				ColumnName: "note",
+				OldColumn:  column,
+				NewColumn:  &schema.Column{Name: "note", DataType: "VARCHAR(60)", IsNullable: true},
+			},
+			expectedAlgorithm: AlgorithmInplace,
+			expectedLock:      LockNone,
+		},
+		{
+			name:     "extend varchar across length byte boundary",
+			version:  mysql8032,
+			stmtType: StatementTypeModifyColumn,
+			object: &schema.ColumnDiff{
+				ColumnName: "note",
+				OldColumn:  column,
+				NewColumn:  &schema.Column{Name: "note", DataType: "VARCHAR(255)", IsNullable: true},
+			},
+			expectedAlgorithm: AlgorithmCopy,
+			expectedLock:      LockShared,
+		},
+		{
+			name:     "change data type",
+			version:  mysql8032,
+			stmtType: StatementTypeModifyColumn,
+			object: &schema.ColumnDiff{
+				ColumnName: "note",
+				OldColumn:  column,
+				NewColumn:  &schema.Column{Name: "note", DataType: "TEXT", IsNullable: true},
+			},
+			expectedAlgorithm: AlgorithmCopy,
+			expectedLock:      LockShared,
+		},
+		{
+			name:              "create fulltext index",
+			version:           mysql8032,
+			stmtType:          StatementTypeCreateIndex,
+			object:            &schema.Index{Name: "ft_note", TableName: "t", Columns: []string{"note"}, IndexType: "FULLTEXT"},
+			expectedAlgorithm: AlgorithmInplace,
+			expectedLock:      LockShared,
+		},
+		{
+			name:              "add foreign key",
+			version:           mysql8032,
+			stmtType:          StatementTypeAddConstraint,
+			object:            &schema.Constraint{Name: "fk", Type: schema.ConstraintTypeForeignKey},
+			expectedAlgorithm: AlgorithmCopy,
+			expectedLock:      LockShared,
+		},
+		{
+			name:              "create table has no clause",
+			version:           mysql8032,
+			stmtType:          StatementTypeCreateTable,
+			object:            &schema.Table{Name: "t"},
+			expectedAlgorithm: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			generator := NewSQLGenerator()
+			if err := generator.EnableOnlineDDL(tt.version); err != nil {
+				t.Fatalf("EnableOnlineDDL() error = %v", err)
+			}
+
+			algorithm, lock := generator.PredictOnlineDDL(tt.stmtType, tt.object)
+			if algorithm != tt.expectedAlgorithm {
+				t.Errorf("Expected algorithm %q, got %q", tt.expectedAlgorithm, algorithm)
+			}
+			if lock != tt.expectedLock {
+				t.Errorf("Expected lock %q, got %q", tt.expectedLock, lock)
+			}
+		})
+	}
+}
+
+func TestMigrationPlanner_OnlineDDLClauses(t *testing.T) {
+	planner := NewMigrationPlanner()
+	if err := planner.EnableOnlineDDL(ServerVersion{Major: 5, Minor: 7, Patch: 40}); err != nil {
+		t.Fatalf("EnableOnlineDDL() error = %v", err)
+	}
+
+	diff := &schema.SchemaDiff{
+		ModifiedTables: []*schema.TableDiff{
+			{
+				TableName: "users",
+				AddedColumns: []*schema.Column{
+					{Name: "nickname", DataType: "VARCHAR(50)", IsNullable: true},
+				},
+				ModifiedColumns: []*schema.ColumnDiff{
+					{
+						ColumnName: "age",
+						OldColumn:  &schema.Column{Name: "age", DataType: "INT", IsNullable: true},
+						NewColumn:  &schema.Column{Name: "age", DataType: "BIGINT", IsNullable: true},
+					},
+				},
+			},
+		},
+		AddedIndexes: []*schema.Index{
+			{Name: "idx_nickname", TableName: "users", Columns: []string{"nickname"}, IndexType: "BTREE"},
+		},
+	}
+
+	plan, err := planner.PlanMigration(diff)
+	if err != nil {
+		t.Fatalf("PlanMigration() error = %v", err)
+	}
+
+	expected := map[StatementType]string{
+		StatementTypeAddColumn:    "ALTER TABLE `users` ADD COLUMN `nickname` VARCHAR(50) NULL, ALGORITHM=INPLACE, LOCK=NONE",
+		StatementTypeModifyColumn: "ALTER TABLE `users` MODIFY COLUMN `age` BIGINT NULL, ALGORITHM=COPY, LOCK=SHARED",
+		StatementTypeCreateIndex:  "CREATE INDEX `idx_nickname` ON `users` (`nickname`) ALGORITHM=INPLACE LOCK=NONE",
+	}
+
+	for _, stmt := range plan.Statements {
+		if stmt.SQL != expected[stmt.Type] {
+			t.Errorf("Unexpected SQL for %s:\n%s\nwant:\n%s", stmt.Type, stmt.SQL, expected[stmt.Type])
+		}
+	}
+
+	blocking := plan.GetBlockingStatements()
+	if len(blocking) != 1 || blocking[0].Type != StatementTypeModifyColumn {
+		t.Errorf("Expected only the column type change to block writes, got %+v", blocking)
+	}
+
+	// Merging strips the individual clauses and applies the most intrusive one to the whole statement
+	optimized, err := NewPlanOptimizer().MergeTableAlterations(plan)
+	if err != nil {
+		t.Fatalf("MergeTableAlterations() error = %v", err)
+	}
+
+	if len(optimized.Statements) != 1 {
+		t.Fatalf("Expected 1 merged statement, got %d", len(optimized.Statements))
+	}
+
+	merged := optimized.Statements[0]
+	if merged.Algorithm != AlgorithmCopy || merged.Lock != LockShared {
+		t.Errorf("Expected merged statement to use COPY/SHARED, got %s/%s", merged.Algorithm, merged.Lock)
+	}
+	if strings.Count(merged.SQL, "ALGORITHM=") != 1 || !strings.HasSuffix(merged.SQL, ",\n  ADD INDEX `idx_nickname` (`nickname`), ALGORITHM=COPY, LOCK=SHARED") {
+		t.Errorf("Unexpected merged SQL:\n%s", merged.SQL)
+	}
+}
+
+func TestMigrationPlanner_OnlineDDLDisabledByDefault(t *testing.T) {
+	planner := NewMigrationPlanner()
+
+	diff := &schema.SchemaDiff{
+		ModifiedTables: []*schema.TableDiff{
+			{
+				TableName: "users",
+				AddedColumns: []*schema.Column{
+					{Name: "nickname", DataType: "VARCHAR(50)", IsNullable: true},
+				},
+			},
+		},
+	}
+
+	plan, err := planner.PlanMigration(diff)
+	if err != nil {
+		t.Fatalf("PlanMigration() error = %v", err)
+	}
+
+	if plan.Statements[0].Algorithm != "" || strings.Contains(plan.Statements[0].SQL, "ALGORITHM") {
+		t.Errorf("Expected no online DDL annotations, got %+v", plan.Statements[0])
+	}
+}
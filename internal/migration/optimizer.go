@@ -0,0 +1,250 @@
+package migration
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// PlanOptimizer rewrites migration plans into equivalent plans with fewer table rebuilds
+type PlanOptimizer struct{}
+
+// NewPlanOptimizer creates a new PlanOptimizer instance
+func NewPlanOptimizer() *PlanOptimizer {
+	return &PlanOptimizer{}
+}
+
+var (
+	alterTablePattern  = regexp.MustCompile("(?s)^ALTER TABLE `([^`]+)`\\s+(.+)$")
+	createIndexPattern = regexp.MustCompile("^CREATE (UNIQUE )?INDEX `([^`]+)` ON `([^`]+)` (.+)$")
+	dropIndexPattern   = regexp.MustCompile("^DROP INDEX `([^`]+)` ON `([^`]+)`$")
+)
+
+// mergeableTypes lists the statement types that can be folded into a single ALTER TABLE
+var mergeableTypes = map[StatementType]bool{
+	StatementTypeAddColumn:      true,
+	StatementTypeDropColumn:     true,
+	StatementTypeModifyColumn:   true,
+	StatementTypeCreateIndex:    true,
+	StatementTypeDropIndex:      true,
+	StatementTypeAddConstraint:  true,
+	StatementTypeDropConstraint: true,
+}
+
+// MergeTableAlterations coalesces all compatible operations on the same table into one
+// ALTER TABLE statement so that each table is rebuilt at most once.
+//
+// Foreign key operations are left as standalone statements because their position in the
+// plan is dictated by other tables, and constraints that are dropped and re-added under the
+// same name are kept apart because MySQL rejects that combination in a single statement.
+func (po *PlanOptimizer) MergeTableAlterations(plan *MigrationPlan) (*MigrationPlan, error) {
+	if plan == nil {
+		return nil, fmt.Errorf("migration plan cannot be nil")
+	}
+
+	// Collect the mergeable operations of each table in plan order
+	groups := make(map[string][]int)
+	clauses := make(map[int]string)
+	for i, stmt := range plan.Statements {
+		clause, ok := po.alterClause(stmt)
+		if !ok {
+			continue
+		}
+		clauses[i] = clause
+		groups[stmt.TableName] = append(groups[stmt.TableName], i)
+	}
+
+	for tableName, indexes := range groups {
+		groups[tableName] = po.excludeRecreatedConstraints(plan, indexes)
+	}
+
+	// Decide where each merged statement goes and which statements it replaces
+	anchors := make(map[int]string)
+	merged := make(map[int]bool)
+	for tableName, indexes := range groups {
+		if len(indexes) < 2 {
+			continue
+		}
+		anchors[po.anchorIndex(plan, indexes)] = tableName
+		for _, idx := range indexes {
+			merged[idx] = true
+		}
+	}
+
+	optimized := NewMigrationPlan()
+	optimized.Warnings = append(optimized.Warnings, plan.Warnings...)
+	optimized.Rollback = plan.Rollback
+	optimized.Archived = plan.Archived
+
+	for i, stmt := range plan.Statements {
+		if tableName, ok := anchors[i]; ok {
+			mergedStmt := po.buildMergedStatement(plan, tableName, groups[tableName], clauses)
+			if err := optimized.AddStatement(*mergedStmt); err != nil {
+				return nil, fmt.Errorf("failed to add merged statement for table %s: %w", tableName, err)
+			}
+			continue
+		}
+
+		if merged[i] {
+			continue
+		}
+
+		if err := optimized.AddStatement(stmt); err != nil {
+			return nil, fmt.Errorf("failed to add statement: %w", err)
+		}
+	}
+
+	return optimized, nil
+}
+
+// alterClause converts a statement into the clause it would contribute to a merged ALTER TABLE
+func (po *PlanOptimizer) alterClause(stmt MigrationStatement) (string, bool) {
+	if !mergeableTypes[stmt.Type] || stmt.TableName == "" {
+		return "", false
+	}
+
+	tableName, clause, ok := stmt.AlterSpecification()
+	if !ok || tableName != stmt.TableName {
+		return "", false
+	}
+
+	if strings.Contains(clause, "FOREIGN KEY") {
+		return "", false
+	}
+
+	return clause, true
+}
+
+// AlterSpecification returns the table and the ALTER TABLE clause equivalent to the statement.
+// CREATE INDEX and DROP INDEX are rewritten as ALTER TABLE clauses and any online DDL suffix is
+// removed, so the clause can be combined with others or handed to an external tool.
+func (ms *MigrationStatement) AlterSpecification() (string, string, bool) {
+	sql := stripOnlineDDLClause(strings.TrimSpace(ms.SQL))
+
+	if matches := alterTablePattern.FindStringSubmatch(sql); matches != nil {
+		return matches[1], strings.TrimSpace(matches[2]), true
+	}
+
+	if matches := createIndexPattern.FindStringSubmatch(sql); matches != nil {
+		return matches[3], fmt.Sprintf("ADD %sINDEX `%s` %s", matches[1], matches[2], matches[4]), true
+	}
+
+	if matches := dropIndexPattern.FindStringSubmatch(sql); matches != nil {
+		return matches[2], fmt.Sprintf("DROP INDEX `%s`", matches[1]), true
+	}
+
+	return "", "", false
+}
+
+// excludeRecreatedConstraints removes constraint operations whose name is both dropped and added
+func (po *PlanOptimizer) excludeRecreatedConstraints(plan *MigrationPlan, indexes []int) []int {
+	dropped := make(map[string]bool)
+	added := make(map[string]bool)
+	for _, idx := range indexes {
+		stmt := plan.Statements[idx]
+		switch stmt.Type {
+		case StatementTypeDropConstraint:
+			dropped[po.objectName(stmt.SQL)] = true
+		case StatementTypeAddConstraint:
+			added[po.objectName(stmt.SQL)] = true
+		}
+	}
+
+	kept := make([]int, 0, len(indexes))
+	for _, idx := range indexes {
+		stmt := plan.Statements[idx]
+		if stmt.Type == StatementTypeDropConstraint || stmt.Type == StatementTypeAddConstraint {
+			name := po.objectName(stmt.SQL)
+			if dropped[name] && added[name] {
+				continue
+			}
+		}
+		kept = append(kept, idx)
+	}
+
+	return kept
+}
+
+// objectName returns the last backtick-quoted identifier before the first parenthesis
+func (po *PlanOptimizer) objectName(sql string) string {
+	if idx := strings.Index(sql, "("); idx != -1 {
+		sql = sql[:idx]
+	}
+
+	parts := strings.Split(sql, "`")
+	if len(parts) < 3 {
+		return ""
+	}
+
+	// Identifiers sit at the odd positions of the split
+	last := len(parts) - 2
+	if last%2 == 0 {
+		last--
+	}
+	return parts[last]
+}
+
+// anchorIndex chooses the plan position that the merged statement takes over.
+//
+// The first additive operation is used so that new columns and indexes exist before any
+// standalone foreign keys on other tables reference them, and after all removals that
+// other tables depend on. Groups that only remove objects take the last removal's place.
+func (po *PlanOptimizer) anchorIndex(plan *MigrationPlan, indexes []int) int {
+	for _, idx := range indexes {
+		if !plan.Statements[idx].Type.IsDestructive() {
+			return idx
+		}
+	}
+	return indexes[len(indexes)-1]
+}
+
+// buildMergedStatement creates the ALTER TABLE statement for a group of operations
+func (po *PlanOptimizer) buildMergedStatement(plan *MigrationPlan, tableName string, indexes []int, clauses map[int]string) *MigrationStatement {
+	operations := make([]AlterOperation, 0, len(indexes))
+	clauseList := make([]string, 0, len(indexes))
+	members := make([]MigrationStatement, 0, len(indexes))
+	isDestructive := false
+	dataLoss := false
+
+	for _, idx := range indexes {
+		stmt := plan.Statements[idx]
+		operations = append(operations, AlterOperation{
+			Clause:        clauses[idx],
+			Type:          stmt.Type,
+			Description:   stmt.Description,
+			IsDestructive: stmt.IsDestructive,
+			Algorithm:     stmt.Algorithm,
+		})
+		clauseList = append(clauseList, clauses[idx])
+		members = append(members, stmt)
+		if stmt.IsDestructive {
+			isDestructive = true
+		}
+		if stmt.DataLoss {
+			dataLoss = true
+		}
+	}
+
+	sql := fmt.Sprintf("ALTER TABLE `%s`\n  %s", tableName, strings.Join(clauseList, ",\n  "))
+
+	// The merged statement runs with the most intrusive algorithm of its operations
+	algorithm, lock := combineOnlineDDL(members)
+	if algorithm != "" {
+		sql += onlineDDLClause(sql, algorithm, lock)
+	}
+
+	stmt := NewMigrationStatement(
+		sql,
+		StatementTypeAlterTable,
+		fmt.Sprintf("Alter table %s (%d operations)", tableName, len(operations)),
+	)
+	stmt.TableName = tableName
+	stmt.IsDestructive = isDestructive
+	stmt.DataLoss = dataLoss
+	stmt.Operations = operations
+	stmt.Algorithm = algorithm
+	stmt.Lock = lock
+	stmt.Estimate = combineEstimates(members)
+
+	return stmt
+}
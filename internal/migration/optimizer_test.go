@@ -0,0 +1,205 @@
+package migration
+
+import (
+	"strings"
+	"testing"
+
+	"mysql-schema-sync/internal/schema"
+)
+
+func TestPlanOptimizer_MergeTableAlterations(t *testing.T) {
+	planner := NewMigrationPlanner()
+	optimizer := NewPlanOptimizer()
+
+	diff := &schema.SchemaDiff{
+		ModifiedTables: []*schema.TableDiff{
+			{
+				TableName: "orders",
+				AddedColumns: []*schema.Column{
+					{Name: "note", DataType: "VARCHAR(255)", IsNullable: true},
+				},
+				RemovedColumns: []*schema.Column{
+					{Name: "legacy", DataType: "INT", IsNullable: true},
+				},
+				ModifiedColumns: []*schema.ColumnDiff{
+					{
+						ColumnName: "total",
+						OldColumn:  &schema.Column{Name: "total", DataType: "DECIMAL(10,2)", IsNullable: true},
+						NewColumn:  &schema.Column{Name: "total", DataType: "DECIMAL(12,2)", IsNullable: true},
+					},
+				},
+			},
+		},
+		AddedIndexes: []*schema.Index{
+			{Name: "idx_note", TableName: "orders", Columns: []string{"note"}, IndexType: "BTREE"},
+		},
+	}
+
+	plan, err := planner.PlanMigration(diff)
+	if err != nil {
+		t.Fatalf("PlanMigration() error = %v", err)
+	}
+
+	optimized, err := optimizer.MergeTableAlterations(plan)
+	if err != nil {
+		t.Fatalf("MergeTableAlterations() error = %v", err)
+	}
+
+	if len(optimized.Statements) != 1 {
+		t.Fatalf("Expected 1 merged statement, got %d", len(optimized.Statements))
+	}
+
+	stmt := optimized.Statements[0]
+	if stmt.Type != StatementTypeAlterTable {
+		t.Errorf("Expected ALTER_TABLE statement, got %s", stmt.Type)
+	}
+
+	expectedSQL := "ALTER TABLE `orders`\n" +
+		"  DROP COLUMN `legacy`,\n" +
+		"  ADD COLUMN `note` VARCHAR(255) NULL,\n" +
+		"  MODIFY COLUMN `total` DECIMAL(12,2) NULL,\n" +
+		"  ADD INDEX `idx_note` (`note`)"
+	if stmt.SQL != expectedSQL {
+		t.Errorf("Unexpected merged SQL:\n%s\nwant:\n%s", stmt.SQL, expectedSQL)
+	}
+
+	if len(stmt.Operations) != 4 {
+		t.Fatalf("Expected 4 operations, got %d", len(stmt.Operations))
+	}
+
+	if !stmt.IsDestructive {
+		t.Error("Expected merged statement to be destructive because it drops a column")
+	}
+
+	if !stmt.Operations[0].IsDestructive || stmt.Operations[1].IsDestructive {
+		t.Error("Expected destructive flags to be preserved per operation")
+	}
+
+	if stmt.Operations[0].Description != "Drop column legacy from table orders" {
+		t.Errorf("Expected original description to be preserved, got %q", stmt.Operations[0].Description)
+	}
+
+	// Summary should still count the individual operations
+	if optimized.Summary.ColumnsAdded != 1 || optimized.Summary.ColumnsRemoved != 1 ||
+		optimized.Summary.ColumnsModified != 1 || optimized.Summary.IndexesAdded != 1 {
+		t.Errorf("Unexpected summary after merge: %+v", optimized.Summary)
+	}
+
+	if optimized.Summary.TotalStatements != 1 {
+		t.Errorf("Expected 1 total statement, got %d", optimized.Summary.TotalStatements)
+	}
+
+	if optimized.Summary.DestructiveCount != 1 {
+		t.Errorf("Expected 1 destructive operation, got %d", optimized.Summary.DestructiveCount)
+	}
+
+	if len(optimized.Warnings) != len(plan.Warnings) {
+		t.Errorf("Expected warnings to be preserved")
+	}
+}
+
+func TestPlanOptimizer_KeepsForeignKeysStandalone(t *testing.T) {
+	optimizer := NewPlanOptimizer()
+	plan := NewMigrationPlan()
+
+	statements := []*MigrationStatement{
+		NewMigrationStatement("ALTER TABLE `orders` DROP FOREIGN KEY `fk_old`", StatementTypeDropConstraint, "Drop foreign_key constraint fk_old on table orders"),
+		NewMigrationStatement("CREATE TABLE `customers` (\n  `id` INT NOT NULL\n)", StatementTypeCreateTable, "Create table customers"),
+		NewMigrationStatement("ALTER TABLE `orders` ADD COLUMN `customer_id` INT NULL", StatementTypeAddColumn, "Add column customer_id to table orders"),
+		NewMigrationStatement("CREATE INDEX `idx_customer` ON `orders` (`customer_id`)", StatementTypeCreateIndex, "Create index idx_customer on table orders"),
+		NewMigrationStatement("ALTER TABLE `orders` ADD CONSTRAINT `fk_customer` FOREIGN KEY (`customer_id`) REFERENCES `customers` (`id`)", StatementTypeAddConstraint, "Add foreign_key constraint fk_customer to table orders"),
+	}
+	tables := []string{"orders", "customers", "orders", "orders", "orders"}
+
+	for i, stmt := range statements {
+		stmt.TableName = tables[i]
+		if err := plan.AddStatement(*stmt); err != nil {
+			t.Fatalf("AddStatement() error = %v", err)
+		}
+	}
+
+	optimized, err := optimizer.MergeTableAlterations(plan)
+	if err != nil {
+		t.Fatalf("MergeTableAlterations() error = %v", err)
+	}
+
+	if len(optimized.Statements) != 4 {
+		t.Fatalf("Expected 4 statements, got %d", len(optimized.Statements))
+	}
+
+	expectedTypes := []StatementType{
+		StatementTypeDropConstraint,
+		StatementTypeCreateTable,
+		StatementTypeAlterTable,
+		StatementTypeAddConstraint,
+	}
+	for i, expected := range expectedTypes {
+		if optimized.Statements[i].Type != expected {
+			t.Errorf("Statement %d: expected %s, got %s", i, expected, optimized.Statements[i].Type)
+		}
+	}
+
+	merged := optimized.Statements[2]
+	if !strings.Contains(merged.SQL, "ADD INDEX `idx_customer` (`customer_id`)") {
+		t.Errorf("Expected CREATE INDEX to be converted to ADD INDEX clause, got %s", merged.SQL)
+	}
+}
+
+func TestPlanOptimizer_KeepsRecreatedConstraintsStandalone(t *testing.T) {
+	optimizer := NewPlanOptimizer()
+	plan := NewMigrationPlan()
+
+	statements := []*MigrationStatement{
+		NewMigrationStatement("ALTER TABLE `users` DROP CHECK `chk_age`", StatementTypeDropConstraint, "Drop check constraint chk_age on table users"),
+		NewMigrationStatement("ALTER TABLE `users` ADD COLUMN `nickname` VARCHAR(50) NULL", StatementTypeAddColumn, "Add column nickname to table users"),
+		NewMigrationStatement("ALTER TABLE `users` MODIFY COLUMN `age` INT NULL", StatementTypeModifyColumn, "Modify column age in table users"),
+		NewMigrationStatement("ALTER TABLE `users` ADD CONSTRAINT `chk_age` CHECK (age >= 0)", StatementTypeAddConstraint, "Add check constraint chk_age to table users"),
+	}
+
+	for _, stmt := range statements {
+		stmt.TableName = "users"
+		if err := plan.AddStatement(*stmt); err != nil {
+			t.Fatalf("AddStatement() error = %v", err)
+		}
+	}
+
+	optimized, err := optimizer.MergeTableAlterations(plan)
+	if err != nil {
+		t.Fatalf("MergeTableAlterations() error = %v", err)
+	}
+
+	if len(optimized.Statements) != 3 {
+		t.Fatalf("Expected 3 statements, got %d", len(optimized.Statements))
+	}
+
+	if optimized.Statements[0].Type != StatementTypeDropConstraint ||
+		optimized.Statements[1].Type != StatementTypeAlterTable ||
+		optimized.Statements[2].Type != StatementTypeAddConstraint {
+		t.Errorf("Unexpected statement order: %s, %s, %s",
+			optimized.Statements[0].Type, optimized.Statements[1].Type, optimized.Statements[2].Type)
+	}
+}
+
+func TestPlanOptimizer_SingleOperationUnchanged(t *testing.T) {
+	optimizer := NewPlanOptimizer()
+	plan := NewMigrationPlan()
+
+	stmt := NewMigrationStatement("ALTER TABLE `users` ADD COLUMN `nickname` VARCHAR(50) NULL", StatementTypeAddColumn, "Add column nickname to table users")
+	stmt.TableName = "users"
+	if err := plan.AddStatement(*stmt); err != nil {
+		t.Fatalf("AddStatement() error = %v", err)
+	}
+
+	optimized, err := optimizer.MergeTableAlterations(plan)
+	if err != nil {
+		t.Fatalf("MergeTableAlterations() error = %v", err)
+	}
+
+	if len(optimized.Statements) != 1 || optimized.Statements[0].SQL != stmt.SQL {
+		t.Errorf("Expected single operation to be left untouched, got %+v", optimized.Statements)
+	}
+
+	if _, err := optimizer.MergeTableAlterations(nil); err == nil {
+		t.Error("Expected error for nil plan")
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"sort"
 	"strings"
+	"time"
 
 	"mysql-schema-sync/internal/schema"
 )
@@ -11,22 +12,41 @@ import (
 // MigrationPlanner handles the creation of migration plans from schema differences
 type MigrationPlanner struct {
 	sqlGenerator *SQLGenerator
+	safeDrop     SafeDropConfig
+	now          func() time.Time
+	// archivedAt is the time embedded in the names of objects archived by the current plan
+	archivedAt time.Time
 }
 
 // NewMigrationPlanner creates a new MigrationPlanner instance
 func NewMigrationPlanner() *MigrationPlanner {
 	return &MigrationPlanner{
 		sqlGenerator: NewSQLGenerator(),
+		now:          time.Now,
 	}
 }
 
-// PlanMigration creates a migration plan from schema differences
+// EnableOnlineDDL makes the planner annotate statements with online DDL clauses for the given server
+func (mp *MigrationPlanner) EnableOnlineDDL(version ServerVersion) error {
+	return mp.sqlGenerator.EnableOnlineDDL(version)
+}
+
+// PlanMigration creates a migration plan from schema differences. Tables and columns archived by
+// safe-drop mode are left alone; only purge-archived removes them.
 func (mp *MigrationPlanner) PlanMigration(diff *schema.SchemaDiff) (*MigrationPlan, error) {
 	if diff == nil {
 		return nil, fmt.Errorf("schema diff cannot be nil")
 	}
 
+	return mp.planMigration(filterDiff(diff, func(stmtType StatementType, object string) bool {
+		return !isArchivedRemoval(stmtType, object)
+	}))
+}
+
+// planMigration creates a migration plan from schema differences, including archived objects
+func (mp *MigrationPlanner) planMigration(diff *schema.SchemaDiff) (*MigrationPlan, error) {
 	plan := NewMigrationPlan()
+	mp.archivedAt = mp.now().UTC().Truncate(time.Second)
 
 	// Generate statements for each type of change
 	if err := mp.planTableRemovals(plan, diff.RemovedTables); err != nil {
@@ -37,7 +57,12 @@ func (mp *MigrationPlanner) PlanMigration(diff *schema.SchemaDiff) (*MigrationPl
 		return nil, fmt.Errorf("failed to plan constraint removals: %w", err)
 	}
 
-	if err := mp.planIndexRemovals(plan, diff.RemovedIndexes); err != nil {
+	removedIndexes, addedIndexes, err := mp.planIndexRenames(plan, diff.RemovedIndexes, diff.AddedIndexes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to plan index renames: %w", err)
+	}
+
+	if err := mp.planIndexRemovals(plan, removedIndexes); err != nil {
 		return nil, fmt.Errorf("failed to plan index removals: %w", err)
 	}
 
@@ -49,7 +74,7 @@ func (mp *MigrationPlanner) PlanMigration(diff *schema.SchemaDiff) (*MigrationPl
 		return nil, fmt.Errorf("failed to plan table additions: %w", err)
 	}
 
-	if err := mp.planIndexAdditions(plan, diff.AddedIndexes); err != nil {
+	if err := mp.planIndexAdditions(plan, addedIndexes); err != nil {
 		return nil, fmt.Errorf("failed to plan index additions: %w", err)
 	}
 
@@ -57,8 +82,10 @@ func (mp *MigrationPlanner) PlanMigration(diff *schema.SchemaDiff) (*MigrationPl
 		return nil, fmt.Errorf("failed to plan constraint additions: %w", err)
 	}
 
-	// Sort statements by execution order
-	mp.sortStatements(plan)
+	// Order statements so that dependencies are satisfied
+	if err := mp.orderStatements(plan, diff); err != nil {
+		return nil, fmt.Errorf("failed to order statements: %w", err)
+	}
 
 	// Add warnings for destructive operations
 	mp.addDestructiveWarnings(plan)
@@ -69,6 +96,13 @@ func (mp *MigrationPlanner) PlanMigration(diff *schema.SchemaDiff) (*MigrationPl
 // planTableRemovals plans the removal of tables
 func (mp *MigrationPlanner) planTableRemovals(plan *MigrationPlan, tables []*schema.Table) error {
 	for _, table := range tables {
+		if mp.safeDrop.Enabled {
+			if err := mp.planTableArchive(plan, table); err != nil {
+				return err
+			}
+			continue
+		}
+
 		sql, err := mp.sqlGenerator.GenerateDropTableSQL(table)
 		if err != nil {
 			return fmt.Errorf("failed to generate drop table SQL for %s: %w", table.Name, err)
@@ -80,6 +114,7 @@ func (mp *MigrationPlanner) planTableRemovals(plan *MigrationPlan, tables []*sch
 			fmt.Sprintf("Drop table %s", table.Name),
 		)
 		stmt.TableName = table.Name
+		annotateDependencies(stmt, table)
 
 		if err := plan.AddStatement(*stmt); err != nil {
 			return fmt.Errorf("failed to add drop table statement: %w", err)
@@ -105,6 +140,7 @@ func (mp *MigrationPlanner) planTableAdditions(plan *MigrationPlan, tables []*sc
 			fmt.Sprintf("Create table %s", table.Name),
 		)
 		stmt.TableName = table.Name
+		annotateDependencies(stmt, table)
 
 		if err := plan.AddStatement(*stmt); err != nil {
 			return fmt.Errorf("failed to add create table statement: %w", err)
@@ -149,6 +185,13 @@ func (mp *MigrationPlanner) planTableModifications(plan *MigrationPlan, tableDif
 // planColumnRemovals plans the removal of columns
 func (mp *MigrationPlanner) planColumnRemovals(plan *MigrationPlan, tableDiff *schema.TableDiff) error {
 	for _, column := range tableDiff.RemovedColumns {
+		if mp.safeDrop.Enabled {
+			if err := mp.planColumnArchive(plan, tableDiff, column); err != nil {
+				return err
+			}
+			continue
+		}
+
 		sql, err := mp.sqlGenerator.GenerateDropColumnSQL(tableDiff.TableName, column)
 		if err != nil {
 			return fmt.Errorf("failed to generate drop column SQL: %w", err)
@@ -160,6 +203,8 @@ func (mp *MigrationPlanner) planColumnRemovals(plan *MigrationPlan, tableDiff *s
 			fmt.Sprintf("Drop column %s from table %s", column.Name, tableDiff.TableName),
 		)
 		stmt.TableName = tableDiff.TableName
+		mp.sqlGenerator.ApplyOnlineDDL(stmt, column)
+		annotateDependencies(stmt, column)
 
 		if err := plan.AddStatement(*stmt); err != nil {
 			return fmt.Errorf("failed to add drop column statement: %w", err)
@@ -174,8 +219,23 @@ func (mp *MigrationPlanner) planColumnRemovals(plan *MigrationPlan, tableDiff *s
 
 // planColumnAdditions plans the addition of new columns
 func (mp *MigrationPlanner) planColumnAdditions(plan *MigrationPlan, tableDiff *schema.TableDiff) error {
-	for _, column := range tableDiff.AddedColumns {
-		sql, err := mp.sqlGenerator.GenerateAddColumnSQL(tableDiff.TableName, column)
+	positions := columnPositions(tableDiff)
+	columns := tableDiff.AddedColumns
+	if positions != nil {
+		// Columns placed after other added columns must be added after them
+		columns = append([]*schema.Column(nil), columns...)
+		order := make(map[string]int, len(tableDiff.ColumnOrder))
+		for i, name := range tableDiff.ColumnOrder {
+			order[name] = i
+		}
+		sort.SliceStable(columns, func(i, j int) bool {
+			return order[columns[i].Name] < order[columns[j].Name]
+		})
+	}
+
+	for _, column := range columns {
+		position := positions[column.Name]
+		sql, err := mp.sqlGenerator.generateAddColumnSQL(tableDiff.TableName, column, position)
 		if err != nil {
 			return fmt.Errorf("failed to generate add column SQL: %w", err)
 		}
@@ -186,6 +246,11 @@ func (mp *MigrationPlanner) planColumnAdditions(plan *MigrationPlan, tableDiff *
 			fmt.Sprintf("Add column %s to table %s", column.Name, tableDiff.TableName),
 		)
 		stmt.TableName = tableDiff.TableName
+		mp.sqlGenerator.ApplyOnlineDDL(stmt, &placedColumn{column: column, position: position})
+		annotateDependencies(stmt, column)
+		if position != nil && position.after != "" {
+			stmt.Dependencies = append(stmt.Dependencies, columnObject(tableDiff.TableName, position.after))
+		}
 
 		if err := plan.AddStatement(*stmt); err != nil {
 			return fmt.Errorf("failed to add column statement: %w", err)
@@ -215,6 +280,8 @@ func (mp *MigrationPlanner) planColumnModifications(plan *MigrationPlan, tableDi
 			fmt.Sprintf("Modify column %s in table %s", columnDiff.ColumnName, tableDiff.TableName),
 		)
 		stmt.TableName = tableDiff.TableName
+		mp.sqlGenerator.ApplyOnlineDDL(stmt, columnDiff)
+		annotateDependencies(stmt, columnDiff)
 
 		if err := plan.AddStatement(*stmt); err != nil {
 			return fmt.Errorf("failed to add modify column statement: %w", err)
@@ -227,6 +294,60 @@ func (mp *MigrationPlanner) planColumnModifications(plan *MigrationPlan, tableDi
 	return nil
 }
 
+// planIndexRenames plans RENAME INDEX statements for indexes that were removed and added under a
+// different name with the same definition, if the target server supports renaming indexes. It
+// returns the removed and added indexes that still need to be dropped and created.
+func (mp *MigrationPlanner) planIndexRenames(plan *MigrationPlan, removed, added []*schema.Index) ([]*schema.Index, []*schema.Index, error) {
+	if !mp.sqlGenerator.serverVersion.supportsRenameIndex() {
+		return removed, added, nil
+	}
+
+	renamed := make(map[*schema.Index]bool)
+	remainingAdded := make([]*schema.Index, 0, len(added))
+	for _, newIndex := range added {
+		var oldIndex *schema.Index
+		for _, candidate := range removed {
+			if !candidate.IsPrimary && !renamed[candidate] && sameIndexDefinition(candidate, newIndex) {
+				oldIndex = candidate
+				break
+			}
+		}
+		if oldIndex == nil {
+			remainingAdded = append(remainingAdded, newIndex)
+			continue
+		}
+		renamed[oldIndex] = true
+
+		sql, err := mp.sqlGenerator.GenerateRenameIndexSQL(oldIndex, newIndex)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to generate rename index SQL for %s: %w", oldIndex.Name, err)
+		}
+
+		stmt := NewMigrationStatement(
+			sql,
+			StatementTypeRenameIndex,
+			fmt.Sprintf("Rename index %s to %s on table %s", oldIndex.Name, newIndex.Name, newIndex.TableName),
+		)
+		stmt.TableName = newIndex.TableName
+		mp.sqlGenerator.ApplyOnlineDDL(stmt, newIndex)
+		annotateDependencies(stmt, newIndex)
+		stmt.Dependencies = append(stmt.Dependencies, indexObject(oldIndex.TableName, oldIndex.Name))
+
+		if err := plan.AddStatement(*stmt); err != nil {
+			return nil, nil, fmt.Errorf("failed to add rename index statement: %w", err)
+		}
+	}
+
+	remainingRemoved := make([]*schema.Index, 0, len(removed))
+	for _, index := range removed {
+		if !renamed[index] {
+			remainingRemoved = append(remainingRemoved, index)
+		}
+	}
+
+	return remainingRemoved, remainingAdded, nil
+}
+
 // planIndexRemovals plans the removal of indexes
 func (mp *MigrationPlanner) planIndexRemovals(plan *MigrationPlan, indexes []*schema.Index) error {
 	for _, index := range indexes {
@@ -246,6 +367,8 @@ func (mp *MigrationPlanner) planIndexRemovals(plan *MigrationPlan, indexes []*sc
 			fmt.Sprintf("Drop index %s on table %s", index.Name, index.TableName),
 		)
 		stmt.TableName = index.TableName
+		mp.sqlGenerator.ApplyOnlineDDL(stmt, index)
+		annotateDependencies(stmt, index)
 
 		if err := plan.AddStatement(*stmt); err != nil {
 			return fmt.Errorf("failed to add drop index statement: %w", err)
@@ -274,6 +397,8 @@ func (mp *MigrationPlanner) planIndexAdditions(plan *MigrationPlan, indexes []*s
 			fmt.Sprintf("Create index %s on table %s", index.Name, index.TableName),
 		)
 		stmt.TableName = index.TableName
+		mp.sqlGenerator.ApplyOnlineDDL(stmt, index)
+		annotateDependencies(stmt, index)
 
 		if err := plan.AddStatement(*stmt); err != nil {
 			return fmt.Errorf("failed to add create index statement: %w", err)
@@ -298,6 +423,8 @@ func (mp *MigrationPlanner) planConstraintRemovals(plan *MigrationPlan, constrai
 				strings.ToLower(string(constraint.Type)), constraint.Name, constraint.TableName),
 		)
 		stmt.TableName = constraint.TableName
+		mp.sqlGenerator.ApplyOnlineDDL(stmt, constraint)
+		annotateDependencies(stmt, constraint)
 
 		if err := plan.AddStatement(*stmt); err != nil {
 			return fmt.Errorf("failed to add drop constraint statement: %w", err)
@@ -322,6 +449,8 @@ func (mp *MigrationPlanner) planConstraintAdditions(plan *MigrationPlan, constra
 				strings.ToLower(string(constraint.Type)), constraint.Name, constraint.TableName),
 		)
 		stmt.TableName = constraint.TableName
+		mp.sqlGenerator.ApplyOnlineDDL(stmt, constraint)
+		annotateDependencies(stmt, constraint)
 
 		if err := plan.AddStatement(*stmt); err != nil {
 			return fmt.Errorf("failed to add constraint statement: %w", err)
@@ -341,21 +470,6 @@ func (mp *MigrationPlanner) planTableConstraintAdditions(plan *MigrationPlan, ta
 	return mp.planConstraintAdditions(plan, tableDiff.AddedConstraints)
 }
 
-// sortStatements sorts migration statements by execution order
-func (mp *MigrationPlanner) sortStatements(plan *MigrationPlan) {
-	sort.Slice(plan.Statements, func(i, j int) bool {
-		orderI := plan.Statements[i].Type.GetExecutionOrder()
-		orderJ := plan.Statements[j].Type.GetExecutionOrder()
-
-		if orderI != orderJ {
-			return orderI < orderJ
-		}
-
-		// For statements of the same type, sort by table name for consistency
-		return plan.Statements[i].TableName < plan.Statements[j].TableName
-	})
-}
-
 // addDestructiveWarnings adds warnings for destructive operations
 func (mp *MigrationPlanner) addDestructiveWarnings(plan *MigrationPlan) {
 	if plan.HasDestructiveOperations() {
@@ -382,8 +496,7 @@ func (mp *MigrationPlanner) addColumnModificationWarnings(plan *MigrationPlan, t
 	}
 
 	// Check for default value changes
-	if (oldCol.DefaultValue == nil) != (newCol.DefaultValue == nil) ||
-		(oldCol.DefaultValue != nil && newCol.DefaultValue != nil && *oldCol.DefaultValue != *newCol.DefaultValue) {
+	if !oldCol.Default().Equal(newCol.Default()) {
 		plan.AddWarning(fmt.Sprintf("Default value change for column '%s.%s' will only affect new rows",
 			tableName, columnDiff.ColumnName))
 	}
@@ -0,0 +1,160 @@
+package migration
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"mysql-schema-sync/internal/schema"
+)
+
+// InvertSchemaDiff returns the diff that undoes the given diff: added objects become removed,
+// removed objects are re-added from their original definitions and modifications are reverted
+func InvertSchemaDiff(diff *schema.SchemaDiff) *schema.SchemaDiff {
+	if diff == nil {
+		return nil
+	}
+
+	inverted := &schema.SchemaDiff{
+		AddedTables:        diff.RemovedTables,
+		RemovedTables:      diff.AddedTables,
+		ModifiedTables:     make([]*schema.TableDiff, 0, len(diff.ModifiedTables)),
+		AddedIndexes:       diff.RemovedIndexes,
+		RemovedIndexes:     diff.AddedIndexes,
+		AddedConstraints:   diff.RemovedConstraints,
+		RemovedConstraints: diff.AddedConstraints,
+	}
+
+	for _, tableDiff := range diff.ModifiedTables {
+		modifiedColumns := make([]*schema.ColumnDiff, 0, len(tableDiff.ModifiedColumns))
+		for _, columnDiff := range tableDiff.ModifiedColumns {
+			modifiedColumns = append(modifiedColumns, &schema.ColumnDiff{
+				ColumnName: columnDiff.ColumnName,
+				OldColumn:  columnDiff.NewColumn,
+				NewColumn:  columnDiff.OldColumn,
+			})
+		}
+
+		inverted.ModifiedTables = append(inverted.ModifiedTables, &schema.TableDiff{
+			TableName:          tableDiff.TableName,
+			AddedColumns:       tableDiff.RemovedColumns,
+			RemovedColumns:     tableDiff.AddedColumns,
+			ModifiedColumns:    modifiedColumns,
+			AddedConstraints:   tableDiff.RemovedConstraints,
+			RemovedConstraints: tableDiff.AddedConstraints,
+			// Re-added columns go back to their place in the target
+			ColumnOrder:       tableDiff.TargetColumnOrder,
+			TargetColumnOrder: tableDiff.ColumnOrder,
+		})
+	}
+
+	return inverted
+}
+
+// PlanRollback creates the plan that reverts the migration planned from the same diff. Statements
+// that recreate dropped tables or columns, or revert lossy type changes, are flagged with DataLoss
+// because the structure can be restored but the data cannot. Objects the migration archived
+// instead of dropping are renamed back with their data.
+func (mp *MigrationPlanner) PlanRollback(diff *schema.SchemaDiff, archived ...ArchivedObject) (*MigrationPlan, error) {
+	if diff == nil {
+		return nil, fmt.Errorf("schema diff cannot be nil")
+	}
+
+	rollback, err := mp.PlanMigration(InvertSchemaDiff(diff))
+	if err != nil {
+		return nil, err
+	}
+
+	restored, err := mp.restoreArchived(rollback, archived)
+	if err != nil {
+		return nil, err
+	}
+
+	lost := make(map[string]string)
+	for _, table := range diff.RemovedTables {
+		lost[tableObject(table.Name)] = fmt.Sprintf("rows of table '%s'", table.Name)
+	}
+	for _, tableDiff := range diff.ModifiedTables {
+		for _, column := range tableDiff.RemovedColumns {
+			lost[columnObject(tableDiff.TableName, column.Name)] =
+				fmt.Sprintf("values of column '%s.%s'", tableDiff.TableName, column.Name)
+		}
+		for _, columnDiff := range tableDiff.ModifiedColumns {
+			if columnDiff.OldColumn != nil && columnDiff.NewColumn != nil &&
+				columnDiff.OldColumn.DataType != columnDiff.NewColumn.DataType {
+				lost[columnObject(tableDiff.TableName, columnDiff.ColumnName)] =
+					fmt.Sprintf("values of column '%s.%s' converted from %s to %s", tableDiff.TableName,
+						columnDiff.ColumnName, columnDiff.OldColumn.DataType, columnDiff.NewColumn.DataType)
+			}
+		}
+	}
+
+	for i := range rollback.Statements {
+		stmt := &rollback.Statements[i]
+		switch stmt.Type {
+		case StatementTypeCreateTable, StatementTypeAddColumn, StatementTypeModifyColumn:
+		default:
+			continue
+		}
+		if restored[stmt.Object] {
+			continue
+		}
+		if description, ok := lost[stmt.Object]; ok {
+			stmt.DataLoss = true
+			rollback.AddWarning(fmt.Sprintf("Rollback restores the structure but not the %s", description))
+		}
+	}
+
+	return rollback, nil
+}
+
+// RollbackSQL returns the SQL statements of the plan's rollback, or nil if none was planned
+func (mp *MigrationPlan) RollbackSQL() []string {
+	if mp.Rollback == nil {
+		return nil
+	}
+	return mp.Rollback.ExecutableSQL()
+}
+
+// ExecutableSQL returns the SQL of every statement in the plan, including the session settings
+// individual statements need
+func (mp *MigrationPlan) ExecutableSQL() []string {
+	statements := make([]string, 0, len(mp.Statements))
+	for _, stmt := range mp.Statements {
+		statements = append(statements, stmt.ExecutableSQL()...)
+	}
+	return statements
+}
+
+// SQLScript renders the plan as an executable SQL script with each statement's description and
+// warnings as comments
+func (mp *MigrationPlan) SQLScript(title string) string {
+	var builder strings.Builder
+
+	builder.WriteString(fmt.Sprintf("-- %s\n", title))
+	builder.WriteString(fmt.Sprintf("-- Generated: %s\n", time.Now().Format(time.RFC3339)))
+	builder.WriteString(fmt.Sprintf("-- Statements: %d\n", len(mp.Statements)))
+	for _, warning := range mp.Warnings {
+		builder.WriteString(fmt.Sprintf("-- WARNING: %s\n", warning))
+	}
+	builder.WriteString(mp.SQLBody())
+
+	return builder.String()
+}
+
+// SQLBody renders the statements of the plan, each preceded by its description as a comment
+func (mp *MigrationPlan) SQLBody() string {
+	var builder strings.Builder
+
+	for _, stmt := range mp.Statements {
+		builder.WriteString(fmt.Sprintf("\n-- %s\n", stmt.Description))
+		if stmt.DataLoss {
+			builder.WriteString("-- NOTE: data removed by the migration is not restored\n")
+		}
+		for _, sql := range stmt.ExecutableSQL() {
+			builder.WriteString(sql + ";\n")
+		}
+	}
+
+	return builder.String()
+}
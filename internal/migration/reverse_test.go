@@ -0,0 +1,187 @@
+package migration
+
+import (
+	"strings"
+	"testing"
+
+	"mysql-schema-sync/internal/schema"
+)
+
+func TestInvertSchemaDiff(t *testing.T) {
+	oldColumn := &schema.Column{Name: "age", DataType: "INT", IsNullable: true}
+	newColumn := &schema.Column{Name: "age", DataType: "BIGINT", IsNullable: true}
+
+	diff := &schema.SchemaDiff{
+		AddedTables:   []*schema.Table{schema.NewTable("added")},
+		RemovedTables: []*schema.Table{schema.NewTable("removed")},
+		ModifiedTables: []*schema.TableDiff{
+			{
+				TableName:       "users",
+				AddedColumns:    []*schema.Column{{Name: "nickname", DataType: "VARCHAR(50)"}},
+				ModifiedColumns: []*schema.ColumnDiff{{ColumnName: "age", OldColumn: oldColumn, NewColumn: newColumn}},
+			},
+		},
+		AddedIndexes: []*schema.Index{{Name: "idx_new", TableName: "users", Columns: []string{"nickname"}}},
+	}
+
+	inverted := InvertSchemaDiff(diff)
+
+	if len(inverted.AddedTables) != 1 || inverted.AddedTables[0].Name != "removed" {
+		t.Errorf("Expected removed table to be re-added, got %+v", inverted.AddedTables)
+	}
+	if len(inverted.RemovedTables) != 1 || inverted.RemovedTables[0].Name != "added" {
+		t.Errorf("Expected added table to be removed, got %+v", inverted.RemovedTables)
+	}
+	if len(inverted.RemovedIndexes) != 1 || len(inverted.AddedIndexes) != 0 {
+		t.Errorf("Expected added index to be removed")
+	}
+
+	tableDiff := inverted.ModifiedTables[0]
+	if len(tableDiff.RemovedColumns) != 1 || len(tableDiff.AddedColumns) != 0 {
+		t.Errorf("Expected added column to be removed")
+	}
+	if tableDiff.ModifiedColumns[0].OldColumn != newColumn || tableDiff.ModifiedColumns[0].NewColumn != oldColumn {
+		t.Errorf("Expected column modification to be reverted")
+	}
+
+	if InvertSchemaDiff(nil) != nil {
+		t.Error("Expected nil diff to stay nil")
+	}
+}
+
+func TestMigrationPlanner_PlanRollback(t *testing.T) {
+	planner := NewMigrationPlanner()
+
+	removed := schema.NewTable("audit_log")
+	removed.AddColumn(&schema.Column{Name: "id", DataType: "INT", IsNullable: false})
+
+	diff := &schema.SchemaDiff{
+		RemovedTables: []*schema.Table{removed},
+		ModifiedTables: []*schema.TableDiff{
+			{
+				TableName:      "users",
+				AddedColumns:   []*schema.Column{{Name: "nickname", DataType: "VARCHAR(50)", IsNullable: true}},
+				RemovedColumns: []*schema.Column{{Name: "legacy", DataType: "INT", IsNullable: true}},
+				ModifiedColumns: []*schema.ColumnDiff{
+					{
+						ColumnName: "bio",
+						OldColumn:  &schema.Column{Name: "bio", DataType: "TEXT", IsNullable: true},
+						NewColumn:  &schema.Column{Name: "bio", DataType: "VARCHAR(255)", IsNullable: true},
+					},
+				},
+			},
+		},
+	}
+
+	rollback, err := planner.PlanRollback(diff)
+	if err != nil {
+		t.Fatalf("PlanRollback() error = %v", err)
+	}
+
+	expected := map[string]struct {
+		sql      string
+		dataLoss bool
+	}{
+		"table:audit_log":     {sql: "CREATE TABLE `audit_log`", dataLoss: true},
+		"column:users.legacy": {sql: "ALTER TABLE `users` ADD COLUMN `legacy` INT NULL", dataLoss: true},
+		"column:users.nickname": {
+			sql: "ALTER TABLE `users` DROP COLUMN `nickname`",
+		},
+		"column:users.bio": {sql: "ALTER TABLE `users` MODIFY COLUMN `bio` TEXT NULL", dataLoss: true},
+	}
+
+	if len(rollback.Statements) != len(expected) {
+		t.Fatalf("Expected %d rollback statements, got %d", len(expected), len(rollback.Statements))
+	}
+
+	for _, stmt := range rollback.Statements {
+		want, ok := expected[stmt.Object]
+		if !ok {
+			t.Errorf("Unexpected rollback statement: %s", stmt.SQL)
+			continue
+		}
+		if !strings.HasPrefix(stmt.SQL, want.sql) {
+			t.Errorf("Unexpected SQL for %s: %s", stmt.Object, stmt.SQL)
+		}
+		if stmt.DataLoss != want.dataLoss {
+			t.Errorf("Expected DataLoss=%v for %s", want.dataLoss, stmt.Object)
+		}
+	}
+
+	dataLossWarnings := 0
+	for _, warning := range rollback.Warnings {
+		if strings.HasPrefix(warning, "Rollback restores the structure") {
+			dataLossWarnings++
+		}
+	}
+	if dataLossWarnings != 3 {
+		t.Errorf("Expected 3 data loss warnings, got %d: %v", dataLossWarnings, rollback.Warnings)
+	}
+}
+
+func TestMigrationPlanner_PlanRollbackColumnPosition(t *testing.T) {
+	planner := NewMigrationPlanner()
+
+	diff := &schema.SchemaDiff{
+		ModifiedTables: []*schema.TableDiff{
+			{
+				TableName:         "users",
+				RemovedColumns:    []*schema.Column{{Name: "legacy", DataType: "INT", IsNullable: true}},
+				TargetColumnOrder: []string{"id", "legacy", "email"},
+			},
+		},
+	}
+
+	rollback, err := planner.PlanRollback(diff)
+	if err != nil {
+		t.Fatalf("PlanRollback() error = %v", err)
+	}
+
+	expected := "ALTER TABLE `users` ADD COLUMN `legacy` INT NULL AFTER `id`"
+	if len(rollback.Statements) != 1 || rollback.Statements[0].SQL != expected {
+		t.Errorf("Expected the column to be re-added at its original position with %s, got %+v", expected, rollback.Statements)
+	}
+}
+
+func TestMigrationPlan_RollbackSQLAndScript(t *testing.T) {
+	planner := NewMigrationPlanner()
+
+	diff := &schema.SchemaDiff{
+		ModifiedTables: []*schema.TableDiff{
+			{
+				TableName:      "users",
+				RemovedColumns: []*schema.Column{{Name: "legacy", DataType: "INT", IsNullable: true}},
+			},
+		},
+	}
+
+	plan, err := planner.PlanMigration(diff)
+	if err != nil {
+		t.Fatalf("PlanMigration() error = %v", err)
+	}
+	if plan.RollbackSQL() != nil {
+		t.Error("Expected no rollback SQL before a rollback is attached")
+	}
+
+	plan.Rollback, err = planner.PlanRollback(diff)
+	if err != nil {
+		t.Fatalf("PlanRollback() error = %v", err)
+	}
+
+	rollbackSQL := plan.RollbackSQL()
+	if len(rollbackSQL) != 1 || rollbackSQL[0] != "ALTER TABLE `users` ADD COLUMN `legacy` INT NULL" {
+		t.Errorf("Unexpected rollback SQL: %v", rollbackSQL)
+	}
+
+	script := plan.Rollback.SQLScript("Rollback")
+	for _, fragment := range []string{
+		"-- Rollback\n",
+		"-- Add column legacy to table users\n",
+		"-- NOTE: data removed by the migration is not restored\n",
+		"ALTER TABLE `users` ADD COLUMN `legacy` INT NULL;\n",
+	} {
+		if !strings.Contains(script, fragment) {
+			t.Errorf("Expected script to contain %q, got:\n%s", fragment, script)
+		}
+	}
+}
@@ -0,0 +1,343 @@
+package migration
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"mysql-schema-sync/internal/schema"
+)
+
+// archiveTimePattern matches the archive time in archived names
+var archiveTimePattern = regexp.MustCompile(regexp.QuoteMeta(schema.ArchivePrefix) + `\d{14}_`)
+
+// stripArchiveTimes removes the archive time from the archived names in a text
+func stripArchiveTimes(text string) string {
+	return archiveTimePattern.ReplaceAllLiteralString(text, schema.ArchivePrefix)
+}
+
+// ColumnArchiveMode selects what safe-drop mode does with dropped columns
+type ColumnArchiveMode string
+
+const (
+	// ColumnArchiveRename renames dropped columns to an archived name and makes them nullable
+	ColumnArchiveRename ColumnArchiveMode = "rename"
+	// ColumnArchiveKeep leaves dropped columns untouched and only records them in the plan
+	ColumnArchiveKeep ColumnArchiveMode = "keep"
+)
+
+// ParseColumnArchiveMode converts a mode name into a ColumnArchiveMode. An empty name selects rename.
+func ParseColumnArchiveMode(name string) (ColumnArchiveMode, error) {
+	switch ColumnArchiveMode(strings.ToLower(strings.TrimSpace(name))) {
+	case "", ColumnArchiveRename:
+		return ColumnArchiveRename, nil
+	case ColumnArchiveKeep:
+		return ColumnArchiveKeep, nil
+	default:
+		return "", fmt.Errorf("unsupported column archive mode %q (supported: rename, keep)", name)
+	}
+}
+
+// SafeDropConfig configures archiving of dropped tables and columns instead of dropping them
+type SafeDropConfig struct {
+	Enabled bool              `mapstructure:"enabled" yaml:"enabled"`
+	Columns ColumnArchiveMode `mapstructure:"columns" yaml:"columns"`
+}
+
+// Validate validates the safe-drop configuration
+func (c SafeDropConfig) Validate() error {
+	if !c.Enabled {
+		return nil
+	}
+	_, err := ParseColumnArchiveMode(string(c.Columns))
+	return err
+}
+
+// ArchivedObject records a table or column that was archived instead of dropped
+type ArchivedObject struct {
+	TableName  string `json:"table_name"`
+	ColumnName string `json:"column_name,omitempty"`
+	// ArchivedName is the name the object was renamed to, or empty if a column was left in place
+	ArchivedName string    `json:"archived_name,omitempty"`
+	ArchivedAt   time.Time `json:"archived_at"`
+	// Column is the definition of an archived column, used to restore it on rollback
+	Column *schema.Column `json:"column,omitempty"`
+}
+
+// IsTable returns true if the archived object is a table
+func (ao ArchivedObject) IsTable() bool {
+	return ao.ColumnName == ""
+}
+
+// EnableSafeDrop makes the planner archive dropped tables and columns instead of dropping them
+func (mp *MigrationPlanner) EnableSafeDrop(config SafeDropConfig) error {
+	if err := config.Validate(); err != nil {
+		return err
+	}
+
+	mode, err := ParseColumnArchiveMode(string(config.Columns))
+	if err != nil {
+		return err
+	}
+	config.Columns = mode
+
+	mp.safeDrop = config
+	return nil
+}
+
+// planTableArchive renames a dropped table to its archived name
+func (mp *MigrationPlanner) planTableArchive(plan *MigrationPlan, table *schema.Table) error {
+	archivedName := schema.ArchivedName(table.Name, mp.archivedAt)
+
+	sql, err := mp.sqlGenerator.GenerateRenameTableSQL(table.Name, archivedName)
+	if err != nil {
+		return fmt.Errorf("failed to generate archive table SQL for %s: %w", table.Name, err)
+	}
+
+	stmt := NewMigrationStatement(
+		sql,
+		StatementTypeDropTable,
+		fmt.Sprintf("Archive table %s as %s", table.Name, archivedName),
+	)
+	stmt.TableName = table.Name
+	stmt.IsDestructive = false
+	annotateDependencies(stmt, table)
+
+	if err := plan.AddStatement(*stmt); err != nil {
+		return fmt.Errorf("failed to add archive table statement: %w", err)
+	}
+
+	plan.Archived = append(plan.Archived, ArchivedObject{
+		TableName:    table.Name,
+		ArchivedName: archivedName,
+		ArchivedAt:   mp.archivedAt,
+	})
+	plan.AddWarning(fmt.Sprintf("Table '%s' will be archived as '%s' instead of dropped; remove it later with purge-archived",
+		table.Name, archivedName))
+
+	return nil
+}
+
+// planColumnArchive renames a dropped column to its archived name, or leaves it in place,
+// depending on the configured column mode. Renamed columns become nullable so that inserts
+// which no longer mention them keep working, unless an index of the table requires them to be
+// NOT NULL.
+func (mp *MigrationPlanner) planColumnArchive(plan *MigrationPlan, tableDiff *schema.TableDiff, column *schema.Column) error {
+	tableName := tableDiff.TableName
+	archived := ArchivedObject{
+		TableName:  tableName,
+		ColumnName: column.Name,
+		ArchivedAt: mp.archivedAt,
+		Column:     column,
+	}
+
+	if mp.safeDrop.Columns == ColumnArchiveKeep {
+		plan.Archived = append(plan.Archived, archived)
+		plan.AddWarning(fmt.Sprintf("Column '%s.%s' is no longer in the source schema and is left in place", tableName, column.Name))
+		if !column.IsNullable && column.DefaultValue == nil {
+			plan.AddWarning(fmt.Sprintf("Column '%s.%s' is NOT NULL without a default; inserts that omit it will fail",
+				tableName, column.Name))
+		}
+		return nil
+	}
+
+	archived.ArchivedName = schema.ArchivedName(column.Name, mp.archivedAt)
+	archivedColumn := *column
+	archivedColumn.Name = archived.ArchivedName
+	archivedColumn.IsNullable = true
+	for _, name := range tableDiff.TargetNotNullColumns {
+		if name == column.Name {
+			archivedColumn.IsNullable = column.IsNullable
+			if column.DefaultValue == nil {
+				plan.AddWarning(fmt.Sprintf("Column '%s.%s' stays NOT NULL as part of the primary key or a spatial index; inserts that omit it will fail",
+					tableName, column.Name))
+			}
+			break
+		}
+	}
+
+	sql, err := mp.sqlGenerator.GenerateRenameColumnSQL(tableName, column, &archivedColumn)
+	if err != nil {
+		return fmt.Errorf("failed to generate archive column SQL: %w", err)
+	}
+
+	stmt := NewMigrationStatement(
+		sql,
+		StatementTypeDropColumn,
+		fmt.Sprintf("Archive column %s in table %s as %s", column.Name, tableName, archived.ArchivedName),
+	)
+	stmt.TableName = tableName
+	stmt.IsDestructive = false
+	mp.sqlGenerator.ApplyOnlineDDL(stmt, &schema.ColumnDiff{
+		ColumnName: column.Name,
+		OldColumn:  column,
+		NewColumn:  &archivedColumn,
+	})
+	annotateDependencies(stmt, column)
+
+	if err := plan.AddStatement(*stmt); err != nil {
+		return fmt.Errorf("failed to add archive column statement: %w", err)
+	}
+
+	plan.Archived = append(plan.Archived, archived)
+	plan.AddWarning(fmt.Sprintf("Column '%s.%s' will be archived as '%s' instead of dropped; remove it later with purge-archived",
+		tableName, column.Name, archived.ArchivedName))
+
+	return nil
+}
+
+// restoreArchived rewrites the statements of a rollback plan that recreate archived objects so
+// that they rename the archived objects back instead. Columns left in place need no statement.
+// It returns the objects that are restored with their data.
+func (mp *MigrationPlanner) restoreArchived(rollback *MigrationPlan, archived []ArchivedObject) (map[string]bool, error) {
+	restored := make(map[string]bool)
+	if len(archived) == 0 {
+		return restored, nil
+	}
+
+	byObject := make(map[string]ArchivedObject, len(archived))
+	restoredTables := make(map[string]bool)
+	for _, object := range archived {
+		if object.IsTable() {
+			byObject[tableObject(object.TableName)] = object
+			restoredTables[object.TableName] = true
+		} else {
+			byObject[columnObject(object.TableName, object.ColumnName)] = object
+		}
+	}
+
+	statements := make([]MigrationStatement, 0, len(rollback.Statements))
+	for _, stmt := range rollback.Statements {
+		// A renamed table keeps its foreign keys, so constraints deferred out of its CREATE TABLE are not re-added
+		if stmt.Type == StatementTypeAddConstraint && restoredTables[stmt.TableName] {
+			continue
+		}
+
+		object, ok := byObject[stmt.Object]
+		if !ok || (stmt.Type != StatementTypeCreateTable && stmt.Type != StatementTypeAddColumn) {
+			statements = append(statements, stmt)
+			continue
+		}
+		restored[stmt.Object] = true
+
+		if object.ArchivedName == "" {
+			continue
+		}
+
+		var sql string
+		var err error
+		if object.IsTable() {
+			sql, err = mp.sqlGenerator.GenerateRenameTableSQL(object.ArchivedName, object.TableName)
+			stmt.Description = fmt.Sprintf("Restore table %s from %s", object.TableName, object.ArchivedName)
+		} else {
+			archivedColumn := *object.Column
+			archivedColumn.Name = object.ArchivedName
+			archivedColumn.IsNullable = true
+			sql, err = mp.sqlGenerator.GenerateRenameColumnSQL(object.TableName, &archivedColumn, object.Column)
+			stmt.Description = fmt.Sprintf("Restore column %s in table %s from %s", object.ColumnName, object.TableName, object.ArchivedName)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate restore SQL for %s: %w", stmt.Object, err)
+		}
+
+		stmt.SQL = sql
+		stmt.Algorithm = ""
+		stmt.Lock = ""
+		statements = append(statements, stmt)
+	}
+
+	rollback.Statements = statements
+	rollback.updateSummary()
+	return restored, nil
+}
+
+// FindArchived returns the tables and columns of a schema that were archived before the cutoff.
+// Columns of archived tables are not listed separately because they go with their table.
+func FindArchived(target *schema.Schema, before time.Time) []ArchivedObject {
+	archived := make([]ArchivedObject, 0)
+	if target == nil {
+		return archived
+	}
+
+	for _, table := range target.Tables {
+		if archivedAt, name, ok := schema.ParseArchivedName(table.Name); ok {
+			if archivedAt.Before(before) {
+				archived = append(archived, ArchivedObject{TableName: name, ArchivedName: table.Name, ArchivedAt: archivedAt})
+			}
+			continue
+		}
+
+		for _, column := range table.Columns {
+			if archivedAt, name, ok := schema.ParseArchivedName(column.Name); ok && archivedAt.Before(before) {
+				archived = append(archived, ArchivedObject{
+					TableName:    table.Name,
+					ColumnName:   name,
+					ArchivedName: column.Name,
+					ArchivedAt:   archivedAt,
+					Column:       column,
+				})
+			}
+		}
+	}
+
+	sort.Slice(archived, func(i, j int) bool {
+		if archived[i].TableName != archived[j].TableName {
+			return archived[i].TableName < archived[j].TableName
+		}
+		return archived[i].ArchivedName < archived[j].ArchivedName
+	})
+
+	return archived
+}
+
+// PlanArchivePurge creates the plan that permanently drops the given archived objects of a schema.
+// The purge is planned as a regular removal so that foreign keys between archived tables are
+// dropped in dependency order; safe-drop mode never applies to it.
+func PlanArchivePurge(target *schema.Schema, archived []ArchivedObject) (*MigrationPlan, error) {
+	if target == nil {
+		return nil, fmt.Errorf("target schema cannot be nil")
+	}
+
+	diff := &schema.SchemaDiff{
+		AddedTables:        make([]*schema.Table, 0),
+		RemovedTables:      make([]*schema.Table, 0),
+		ModifiedTables:     make([]*schema.TableDiff, 0),
+		AddedIndexes:       make([]*schema.Index, 0),
+		RemovedIndexes:     make([]*schema.Index, 0),
+		AddedConstraints:   make([]*schema.Constraint, 0),
+		RemovedConstraints: make([]*schema.Constraint, 0),
+	}
+
+	tableDiffs := make(map[string]*schema.TableDiff)
+	for _, object := range archived {
+		if object.IsTable() {
+			table, exists := target.Tables[object.ArchivedName]
+			if !exists {
+				return nil, fmt.Errorf("archived table %s does not exist", object.ArchivedName)
+			}
+			diff.RemovedTables = append(diff.RemovedTables, table)
+			continue
+		}
+
+		table, exists := target.Tables[object.TableName]
+		if !exists {
+			return nil, fmt.Errorf("table %s of archived column %s does not exist", object.TableName, object.ArchivedName)
+		}
+		column, exists := table.Columns[object.ArchivedName]
+		if !exists {
+			return nil, fmt.Errorf("archived column %s.%s does not exist", object.TableName, object.ArchivedName)
+		}
+
+		tableDiff, exists := tableDiffs[object.TableName]
+		if !exists {
+			tableDiff = &schema.TableDiff{TableName: object.TableName}
+			tableDiffs[object.TableName] = tableDiff
+			diff.ModifiedTables = append(diff.ModifiedTables, tableDiff)
+		}
+		tableDiff.RemovedColumns = append(tableDiff.RemovedColumns, column)
+	}
+
+	return NewMigrationPlanner().planMigration(diff)
+}
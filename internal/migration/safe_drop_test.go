@@ -0,0 +1,261 @@
+package migration
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"mysql-schema-sync/internal/schema"
+)
+
+// newSafeDropPlanner returns a planner archiving drops at a fixed time
+func newSafeDropPlanner(t *testing.T, columns ColumnArchiveMode) *MigrationPlanner {
+	planner := NewMigrationPlanner()
+	planner.now = func() time.Time { return time.Date(2026, 3, 1, 12, 30, 0, 0, time.UTC) }
+	if err := planner.EnableSafeDrop(SafeDropConfig{Enabled: true, Columns: columns}); err != nil {
+		t.Fatalf("EnableSafeDrop() error = %v", err)
+	}
+	return planner
+}
+
+func TestParseColumnArchiveMode(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    ColumnArchiveMode
+		wantErr bool
+	}{
+		{"", ColumnArchiveRename, false},
+		{"rename", ColumnArchiveRename, false},
+		{"KEEP", ColumnArchiveKeep, false},
+		{"drop", "", true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseColumnArchiveMode(tt.input)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("ParseColumnArchiveMode(%q) = %q, %v", tt.input, got, err)
+		}
+	}
+}
+
+func TestMigrationPlanner_SafeDropArchivesTable(t *testing.T) {
+	planner := newSafeDropPlanner(t, ColumnArchiveRename)
+
+	diff := &schema.SchemaDiff{
+		RemovedTables: []*schema.Table{newFKTable("sessions", "", "", "")},
+	}
+
+	plan, err := planner.PlanMigration(diff)
+	if err != nil {
+		t.Fatalf("PlanMigration() error = %v", err)
+	}
+
+	if len(plan.Statements) != 1 {
+		t.Fatalf("Expected 1 statement, got %d", len(plan.Statements))
+	}
+	stmt := plan.Statements[0]
+	if stmt.SQL != "RENAME TABLE `sessions` TO `_archived_20260301123000_sessions`" {
+		t.Errorf("Unexpected SQL: %s", stmt.SQL)
+	}
+	if stmt.IsDestructive || plan.HasDestructiveOperations() {
+		t.Error("Expected archiving a table not to be destructive")
+	}
+	if len(plan.Archived) != 1 || plan.Archived[0].ArchivedName != "_archived_20260301123000_sessions" {
+		t.Errorf("Unexpected archived objects: %+v", plan.Archived)
+	}
+
+	rollback, err := planner.PlanRollback(diff, plan.Archived...)
+	if err != nil {
+		t.Fatalf("PlanRollback() error = %v", err)
+	}
+	if len(rollback.Statements) != 1 ||
+		rollback.Statements[0].SQL != "RENAME TABLE `_archived_20260301123000_sessions` TO `sessions`" {
+		t.Fatalf("Expected the rollback to rename the table back, got %+v", rollback.Statements)
+	}
+	if rollback.Statements[0].DataLoss {
+		t.Error("Expected restoring an archived table not to lose data")
+	}
+	for _, warning := range rollback.Warnings {
+		if strings.Contains(warning, "not the rows") {
+			t.Errorf("Unexpected data loss warning: %s", warning)
+		}
+	}
+}
+
+func TestMigrationPlanner_SafeDropRenamesColumn(t *testing.T) {
+	planner := newSafeDropPlanner(t, ColumnArchiveRename)
+
+	column := &schema.Column{Name: "legacy_code", DataType: "VARCHAR(20)", IsNullable: false}
+	diff := &schema.SchemaDiff{
+		ModifiedTables: []*schema.TableDiff{{TableName: "users", RemovedColumns: []*schema.Column{column}}},
+	}
+
+	plan, err := planner.PlanMigration(diff)
+	if err != nil {
+		t.Fatalf("PlanMigration() error = %v", err)
+	}
+
+	expected := "ALTER TABLE `users` CHANGE COLUMN `legacy_code` `_archived_20260301123000_legacy_code` VARCHAR(20) NULL"
+	if len(plan.Statements) != 1 || plan.Statements[0].SQL != expected {
+		t.Fatalf("Expected %s, got %+v", expected, plan.Statements)
+	}
+	if plan.Statements[0].Type != StatementTypeDropColumn || plan.Statements[0].IsDestructive {
+		t.Errorf("Unexpected statement: %+v", plan.Statements[0])
+	}
+
+	rollback, err := planner.PlanRollback(diff, plan.Archived...)
+	if err != nil {
+		t.Fatalf("PlanRollback() error = %v", err)
+	}
+	restore := "ALTER TABLE `users` CHANGE COLUMN `_archived_20260301123000_legacy_code` `legacy_code` VARCHAR(20) NOT NULL"
+	if len(rollback.Statements) != 1 || rollback.Statements[0].SQL != restore || rollback.Statements[0].DataLoss {
+		t.Errorf("Expected %s, got %+v", restore, rollback.Statements)
+	}
+}
+
+func TestMigrationPlanner_SafeDropKeepsKeyColumnNotNull(t *testing.T) {
+	planner := newSafeDropPlanner(t, ColumnArchiveRename)
+
+	column := &schema.Column{Name: "tenant_id", DataType: "INT", IsNullable: false}
+	diff := &schema.SchemaDiff{
+		ModifiedTables: []*schema.TableDiff{{
+			TableName:            "users",
+			RemovedColumns:       []*schema.Column{column},
+			TargetNotNullColumns: []string{"tenant_id"},
+		}},
+	}
+
+	plan, err := planner.PlanMigration(diff)
+	if err != nil {
+		t.Fatalf("PlanMigration() error = %v", err)
+	}
+
+	expected := "ALTER TABLE `users` CHANGE COLUMN `tenant_id` `_archived_20260301123000_tenant_id` INT NOT NULL"
+	if len(plan.Statements) != 1 || plan.Statements[0].SQL != expected {
+		t.Fatalf("Expected %s, got %+v", expected, plan.Statements)
+	}
+
+	warned := false
+	for _, warning := range plan.Warnings {
+		if strings.Contains(warning, "'users.tenant_id' stays NOT NULL") {
+			warned = true
+		}
+	}
+	if !warned {
+		t.Errorf("Expected a warning about the NOT NULL archived column, got %v", plan.Warnings)
+	}
+}
+
+func TestMigrationPlanner_SafeDropKeepsColumn(t *testing.T) {
+	planner := newSafeDropPlanner(t, ColumnArchiveKeep)
+
+	diff := &schema.SchemaDiff{
+		ModifiedTables: []*schema.TableDiff{{
+			TableName:      "users",
+			RemovedColumns: []*schema.Column{{Name: "nickname", DataType: "VARCHAR(50)", IsNullable: true}},
+		}},
+	}
+
+	plan, err := planner.PlanMigration(diff)
+	if err != nil {
+		t.Fatalf("PlanMigration() error = %v", err)
+	}
+
+	if len(plan.Statements) != 0 {
+		t.Errorf("Expected no statements, got %+v", plan.Statements)
+	}
+	if len(plan.Archived) != 1 || plan.Archived[0].ArchivedName != "" || plan.Archived[0].ColumnName != "nickname" {
+		t.Errorf("Expected the column to be recorded as left in place, got %+v", plan.Archived)
+	}
+
+	rollback, err := planner.PlanRollback(diff, plan.Archived...)
+	if err != nil {
+		t.Fatalf("PlanRollback() error = %v", err)
+	}
+	if len(rollback.Statements) != 0 {
+		t.Errorf("Expected nothing to restore, got %+v", rollback.Statements)
+	}
+}
+
+func TestMigrationPlanner_SafeDropHashIgnoresArchiveTime(t *testing.T) {
+	diff := &schema.SchemaDiff{
+		RemovedTables: []*schema.Table{newFKTable("sessions", "", "", "")},
+		ModifiedTables: []*schema.TableDiff{{
+			TableName:      "users",
+			RemovedColumns: []*schema.Column{{Name: "nickname", DataType: "VARCHAR(50)", IsNullable: true}},
+		}},
+	}
+
+	hashes := make([]string, 0, 2)
+	for _, at := range []time.Time{time.Date(2026, 3, 1, 12, 30, 0, 0, time.UTC), time.Date(2026, 3, 2, 8, 0, 0, 0, time.UTC)} {
+		planner := newSafeDropPlanner(t, ColumnArchiveRename)
+		planner.now = func() time.Time { return at }
+		plan, err := planner.PlanMigration(diff)
+		if err != nil {
+			t.Fatalf("PlanMigration() error = %v", err)
+		}
+		hashes = append(hashes, plan.Hash())
+	}
+
+	if hashes[0] != hashes[1] {
+		t.Error("Expected plans of the same diff archived at different times to hash the same")
+	}
+}
+
+func TestMigrationPlanner_SkipsArchivedObjects(t *testing.T) {
+	archivedAt := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	diff := &schema.SchemaDiff{
+		RemovedTables: []*schema.Table{newFKTable(schema.ArchivedName("sessions", archivedAt), "", "", "")},
+		ModifiedTables: []*schema.TableDiff{{
+			TableName:      "users",
+			RemovedColumns: []*schema.Column{{Name: schema.ArchivedName("nickname", archivedAt), DataType: "VARCHAR(50)", IsNullable: true}},
+		}},
+	}
+
+	for _, planner := range []*MigrationPlanner{NewMigrationPlanner(), newSafeDropPlanner(t, ColumnArchiveRename)} {
+		plan, err := planner.PlanMigration(diff)
+		if err != nil {
+			t.Fatalf("PlanMigration() error = %v", err)
+		}
+		if len(plan.Statements) != 0 || len(plan.Archived) != 0 {
+			t.Errorf("Expected archived objects to be left alone, got %+v and %+v", plan.Statements, plan.Archived)
+		}
+	}
+}
+
+func TestPlanArchivePurge(t *testing.T) {
+	target := schema.NewSchema("target_db")
+	target.AddTable(newFKTable("_archived_20260101000000_sessions", "", "", ""))
+	target.AddTable(newFKTable("_archived_20260301000000_tokens", "", "", ""))
+
+	users := newFKTable("users", "", "", "")
+	users.AddColumn(&schema.Column{Name: "_archived_20260101000000_legacy", DataType: "INT", IsNullable: true})
+	target.AddTable(users)
+
+	archived := FindArchived(target, time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC))
+	if len(archived) != 2 {
+		t.Fatalf("Expected 2 archived objects older than the cutoff, got %+v", archived)
+	}
+	if !archived[0].IsTable() || archived[0].TableName != "sessions" {
+		t.Errorf("Unexpected first archived object: %+v", archived[0])
+	}
+	if archived[1].IsTable() || archived[1].TableName != "users" || archived[1].ColumnName != "legacy" {
+		t.Errorf("Unexpected second archived object: %+v", archived[1])
+	}
+
+	plan, err := PlanArchivePurge(target, archived)
+	if err != nil {
+		t.Fatalf("PlanArchivePurge() error = %v", err)
+	}
+
+	sql := make([]string, 0, len(plan.Statements))
+	for _, stmt := range plan.Statements {
+		sql = append(sql, stmt.SQL)
+	}
+	joined := strings.Join(sql, "\n")
+	if !strings.Contains(joined, "DROP TABLE `_archived_20260101000000_sessions`") ||
+		!strings.Contains(joined, "ALTER TABLE `users` DROP COLUMN `_archived_20260101000000_legacy`") ||
+		strings.Contains(joined, "tokens") {
+		t.Errorf("Unexpected purge statements:\n%s", joined)
+	}
+}
@@ -0,0 +1,113 @@
+package migration
+
+// scheduleState tracks a statement through a Schedule
+type scheduleState int
+
+const (
+	statePending scheduleState = iota
+	stateRunning
+	stateDone
+)
+
+// Schedule hands the statements of an ordered plan to concurrent workers. A statement becomes
+// ready once every earlier statement it conflicts with has finished: statements on one of the same
+// tables, including the tables its dependencies live on. Statements without a table and statements
+// that run with foreign key checks disabled conflict with every other statement.
+type Schedule struct {
+	waiting  []int
+	unblocks [][]int
+	state    []scheduleState
+	pending  int
+}
+
+// NewSchedule creates a Schedule for the statements. Statements marked in done, e.g. because the
+// journal shows an earlier run applied them, count as finished and are never handed out.
+func NewSchedule(statements []MigrationStatement, done []bool) *Schedule {
+	s := &Schedule{
+		waiting:  make([]int, len(statements)),
+		unblocks: make([][]int, len(statements)),
+		state:    make([]scheduleState, len(statements)),
+	}
+
+	tables := make([]map[string]bool, len(statements))
+	for i, stmt := range statements {
+		tables[i] = statementTables(stmt)
+		if i < len(done) && done[i] {
+			s.state[i] = stateDone
+			continue
+		}
+		s.pending++
+	}
+
+	for j := range statements {
+		if s.state[j] == stateDone {
+			continue
+		}
+		for i := 0; i < j; i++ {
+			if s.state[i] != stateDone && conflicts(tables[i], tables[j]) {
+				s.waiting[j]++
+				s.unblocks[i] = append(s.unblocks[i], j)
+			}
+		}
+	}
+
+	return s
+}
+
+// Next returns the first statement in plan order that is ready to run and marks it running. It
+// returns false if no statement is ready until a running one finishes.
+func (s *Schedule) Next() (int, bool) {
+	for i, state := range s.state {
+		if state == statePending && s.waiting[i] == 0 {
+			s.state[i] = stateRunning
+			s.pending--
+			return i, true
+		}
+	}
+	return 0, false
+}
+
+// Finish marks a running statement as finished, releasing the statements waiting for it
+func (s *Schedule) Finish(index int) {
+	if s.state[index] != stateRunning {
+		return
+	}
+	s.state[index] = stateDone
+	for _, next := range s.unblocks[index] {
+		s.waiting[next]--
+	}
+}
+
+// Pending returns the number of statements that were not handed out yet
+func (s *Schedule) Pending() int {
+	return s.pending
+}
+
+// statementTables returns the tables a statement acts on or depends on. A nil result means the
+// statement has to run on its own.
+func statementTables(stmt MigrationStatement) map[string]bool {
+	if stmt.TableName == "" || stmt.DisableForeignKeyChecks {
+		return nil
+	}
+
+	tables := map[string]bool{stmt.TableName: true}
+	for _, key := range append([]string{stmt.Object}, stmt.Dependencies...) {
+		if table := objectTable(key); table != "" {
+			tables[table] = true
+		}
+	}
+	return tables
+}
+
+// conflicts returns true if two statements may not run at the same time
+func conflicts(a, b map[string]bool) bool {
+	if a == nil || b == nil {
+		return true
+	}
+	for table := range a {
+		if b[table] {
+			return true
+		}
+	}
+	return false
+}
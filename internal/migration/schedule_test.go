@@ -0,0 +1,98 @@
+package migration
+
+import (
+	"reflect"
+	"testing"
+)
+
+// drain hands out every ready statement, returning their indexes
+func drain(s *Schedule) []int {
+	ready := make([]int, 0)
+	for {
+		i, ok := s.Next()
+		if !ok {
+			return ready
+		}
+		ready = append(ready, i)
+	}
+}
+
+func TestSchedule(t *testing.T) {
+	statements := []MigrationStatement{
+		{Type: StatementTypeCreateTable, TableName: "customers", Object: tableObject("customers")},
+		{Type: StatementTypeAddColumn, TableName: "orders", Object: columnObject("orders", "note"),
+			Dependencies: []string{tableObject("orders")}},
+		{Type: StatementTypeAddColumn, TableName: "invoices", Object: columnObject("invoices", "note"),
+			Dependencies: []string{tableObject("invoices")}},
+		// Depends on customers through its foreign key
+		{Type: StatementTypeAddConstraint, TableName: "invoices", Object: constraintObject("invoices", "fk_customer"),
+			Dependencies: []string{tableObject("invoices"), tableObject("customers"), columnObject("customers", "id")}},
+		{Type: StatementTypeCreateIndex, TableName: "orders", Object: indexObject("orders", "idx_note"),
+			Dependencies: []string{tableObject("orders"), columnObject("orders", "note")}},
+	}
+
+	s := NewSchedule(statements, nil)
+	if s.Pending() != 5 {
+		t.Fatalf("Expected 5 pending statements, got %d", s.Pending())
+	}
+
+	// Statements on different tables are ready at once
+	if ready := drain(s); !reflect.DeepEqual(ready, []int{0, 1, 2}) {
+		t.Fatalf("Expected statements 0, 1 and 2 to be ready, got %v", ready)
+	}
+
+	// The foreign key waits for both the new table and the other change on its own table
+	s.Finish(0)
+	if ready := drain(s); len(ready) != 0 {
+		t.Fatalf("Expected nothing to be ready while invoices is altered, got %v", ready)
+	}
+	s.Finish(2)
+	if ready := drain(s); !reflect.DeepEqual(ready, []int{3}) {
+		t.Fatalf("Expected the foreign key to be ready, got %v", ready)
+	}
+
+	s.Finish(1)
+	if ready := drain(s); !reflect.DeepEqual(ready, []int{4}) {
+		t.Fatalf("Expected the index to be ready, got %v", ready)
+	}
+	if s.Pending() != 0 {
+		t.Errorf("Expected no pending statements, got %d", s.Pending())
+	}
+}
+
+func TestSchedule_Barriers(t *testing.T) {
+	statements := []MigrationStatement{
+		{Type: StatementTypeAddColumn, TableName: "orders", Object: columnObject("orders", "note")},
+		{Type: StatementTypeDropTable, TableName: "legacy", Object: tableObject("legacy"), DisableForeignKeyChecks: true},
+		{Type: StatementTypeAddColumn, TableName: "invoices", Object: columnObject("invoices", "note")},
+	}
+
+	s := NewSchedule(statements, nil)
+	if ready := drain(s); !reflect.DeepEqual(ready, []int{0}) {
+		t.Fatalf("Expected only statement 0 before the barrier, got %v", ready)
+	}
+	s.Finish(0)
+	if ready := drain(s); !reflect.DeepEqual(ready, []int{1}) {
+		t.Fatalf("Expected the barrier to run alone, got %v", ready)
+	}
+	s.Finish(1)
+	if ready := drain(s); !reflect.DeepEqual(ready, []int{2}) {
+		t.Fatalf("Expected statement 2 after the barrier, got %v", ready)
+	}
+}
+
+func TestSchedule_Done(t *testing.T) {
+	statements := []MigrationStatement{
+		{Type: StatementTypeAddColumn, TableName: "orders", Object: columnObject("orders", "note")},
+		{Type: StatementTypeCreateIndex, TableName: "orders", Object: indexObject("orders", "idx_note")},
+		{Type: StatementTypeAddColumn, TableName: "invoices", Object: columnObject("invoices", "note")},
+	}
+
+	s := NewSchedule(statements, []bool{true, false, true})
+	if s.Pending() != 1 {
+		t.Fatalf("Expected 1 pending statement, got %d", s.Pending())
+	}
+	if ready := drain(s); !reflect.DeepEqual(ready, []int{1}) {
+		t.Errorf("Expected only the statement that was not applied yet, got %v", ready)
+	}
+}
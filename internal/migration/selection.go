@@ -0,0 +1,428 @@
+package migration
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"mysql-schema-sync/internal/schema"
+)
+
+// selectableTypes are the statement types a selector can name. Merged ALTER_TABLE statements are
+// not selectable because changes are selected before alterations are merged.
+var selectableTypes = []StatementType{
+	StatementTypeCreateTable,
+	StatementTypeDropTable,
+	StatementTypeAddColumn,
+	StatementTypeDropColumn,
+	StatementTypeModifyColumn,
+	StatementTypeCreateIndex,
+	StatementTypeDropIndex,
+	StatementTypeRenameIndex,
+	StatementTypeAddConstraint,
+	StatementTypeDropConstraint,
+}
+
+// SelectionConfig chooses which changes of a migration plan are applied
+type SelectionConfig struct {
+	// Only applies just the statements matching one of these selectors
+	Only []string `mapstructure:"only" yaml:"only"`
+	// Skip leaves out the statements matching one of these selectors
+	Skip []string `mapstructure:"skip" yaml:"skip"`
+	// Review lets the user pick the changes to apply interactively
+	Review bool `mapstructure:"review" yaml:"review"`
+}
+
+// Enabled returns true if only part of the plan may be applied
+func (c SelectionConfig) Enabled() bool {
+	return len(c.Only) > 0 || len(c.Skip) > 0 || c.Review
+}
+
+// Validate checks that every selector can be parsed
+func (c SelectionConfig) Validate() error {
+	_, err := NewStatementFilter(c.Only, c.Skip)
+	return err
+}
+
+// Selector matches statements by table name and statement type. It is written as TABLE[:TYPE],
+// where both parts are glob patterns and the type is case-insensitive, e.g. "orders",
+// "audit_*:DROP_*" or ":create_index".
+type Selector struct {
+	Table string
+	Type  string
+}
+
+// ParseSelector parses a TABLE[:TYPE] selector expression
+func ParseSelector(expr string) (Selector, error) {
+	expr = strings.TrimSpace(expr)
+	if expr == "" {
+		return Selector{}, fmt.Errorf("selector cannot be empty")
+	}
+
+	table, stmtType, _ := strings.Cut(expr, ":")
+	selector := Selector{Table: strings.TrimSpace(table), Type: strings.ToUpper(strings.TrimSpace(stmtType))}
+	if selector.Table == "" {
+		selector.Table = "*"
+	}
+	if selector.Type == "" {
+		selector.Type = "*"
+	}
+
+	if _, err := path.Match(selector.Table, ""); err != nil {
+		return Selector{}, fmt.Errorf("invalid table pattern in selector %q: %w", expr, err)
+	}
+	if _, err := path.Match(selector.Type, ""); err != nil {
+		return Selector{}, fmt.Errorf("invalid statement type pattern in selector %q: %w", expr, err)
+	}
+
+	known := false
+	for _, candidate := range selectableTypes {
+		if matched, _ := path.Match(selector.Type, string(candidate)); matched {
+			known = true
+			break
+		}
+	}
+	if !known {
+		return Selector{}, fmt.Errorf("selector %q matches no statement type", expr)
+	}
+
+	return selector, nil
+}
+
+// Matches returns true if the statement is on a matching table and of a matching type
+func (s Selector) Matches(stmt MigrationStatement) bool {
+	tableMatched, _ := path.Match(s.Table, stmt.TableName)
+	typeMatched, _ := path.Match(s.Type, string(stmt.Type))
+	return tableMatched && typeMatched
+}
+
+// String returns the selector expression
+func (s Selector) String() string {
+	return s.Table + ":" + s.Type
+}
+
+// StatementFilter chooses statements with --only and --skip selectors
+type StatementFilter struct {
+	only []Selector
+	skip []Selector
+}
+
+// NewStatementFilter parses the only and skip selector expressions
+func NewStatementFilter(only, skip []string) (*StatementFilter, error) {
+	filter := &StatementFilter{}
+	for _, expr := range only {
+		selector, err := ParseSelector(expr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid --only selector: %w", err)
+		}
+		filter.only = append(filter.only, selector)
+	}
+	for _, expr := range skip {
+		selector, err := ParseSelector(expr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid --skip selector: %w", err)
+		}
+		filter.skip = append(filter.skip, selector)
+	}
+	return filter, nil
+}
+
+// Selects returns true if the statement matches an only selector, or there are none, and matches
+// no skip selector
+func (f *StatementFilter) Selects(stmt MigrationStatement) bool {
+	if f.Skips(stmt) {
+		return false
+	}
+	if len(f.only) == 0 {
+		return true
+	}
+	for _, selector := range f.only {
+		if selector.Matches(stmt) {
+			return true
+		}
+	}
+	return false
+}
+
+// Skips returns true if the statement matches a skip selector
+func (f *StatementFilter) Skips(stmt MigrationStatement) bool {
+	for _, selector := range f.skip {
+		if selector.Matches(stmt) {
+			return true
+		}
+	}
+	return false
+}
+
+// Selection is a consistent subset of the statements of a migration plan
+type Selection struct {
+	// Statements are the chosen statements and the statements they require, in plan order
+	Statements []MigrationStatement
+	// Required explains each statement that was added because a chosen statement needs it
+	Required []string
+	// Skipped are the statements left out
+	Skipped []MigrationStatement
+}
+
+// SelectStatements selects the chosen statements of a plan together with the statements they
+// require: the statements creating or changing the objects they use, the drop of an object they
+// recreate and the foreign keys deferred out of a table they create. Required statements that
+// are excluded make the selection fail, as does dropping an object that a foreign key of another
+// table still references.
+func SelectStatements(plan *MigrationPlan, chosen, excluded func(MigrationStatement) bool) (*Selection, error) {
+	statements := plan.Statements
+	creators := make(map[string][]int)
+	droppers := make(map[string][]int)
+	modifiers := make(map[string][]int)
+	dependents := make(map[string][]int)
+	for i, stmt := range statements {
+		switch {
+		case createsObject(stmt.Type):
+			creators[stmt.Object] = append(creators[stmt.Object], i)
+		case dropsObject(stmt.Type):
+			droppers[stmt.Object] = append(droppers[stmt.Object], i)
+			for _, dependency := range stmt.Dependencies {
+				dependents[dependency] = append(dependents[dependency], i)
+			}
+		case stmt.Type == StatementTypeModifyColumn:
+			modifiers[stmt.Object] = append(modifiers[stmt.Object], i)
+		}
+	}
+
+	selected := make([]bool, len(statements))
+	requiredBy := make(map[int]int)
+	queue := make([]int, 0, len(statements))
+	for i, stmt := range statements {
+		if chosen(stmt) {
+			selected[i] = true
+			queue = append(queue, i)
+		}
+	}
+
+	require := func(needed, by int) error {
+		if selected[needed] {
+			return nil
+		}
+		if excluded != nil && excluded(statements[needed]) {
+			return fmt.Errorf("'%s' is required by '%s' but is excluded",
+				statements[needed].Description, statements[by].Description)
+		}
+		selected[needed] = true
+		requiredBy[needed] = by
+		queue = append(queue, needed)
+		return nil
+	}
+
+	for len(queue) > 0 {
+		i := queue[0]
+		queue = queue[1:]
+		stmt := statements[i]
+
+		needed := make([]int, 0)
+		if !dropsObject(stmt.Type) {
+			for _, dependency := range stmt.Dependencies {
+				needed = append(needed, creators[dependency]...)
+				needed = append(needed, modifiers[dependency]...)
+			}
+		}
+		if createsObject(stmt.Type) {
+			needed = append(needed, droppers[stmt.Object]...)
+		}
+		if stmt.Type == StatementTypeCreateTable {
+			for j, other := range statements {
+				if other.Type == StatementTypeAddConstraint && other.TableName == stmt.TableName {
+					needed = append(needed, j)
+				}
+			}
+		}
+		if dropsObject(stmt.Type) {
+			for _, j := range dependents[stmt.Object] {
+				if statements[j].TableName == objectTable(stmt.Object) {
+					needed = append(needed, j)
+				}
+			}
+		}
+
+		for _, j := range needed {
+			if j == i {
+				continue
+			}
+			if err := require(j, i); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	// Foreign keys of other tables are never dropped implicitly
+	for i, stmt := range statements {
+		if !selected[i] || !dropsObject(stmt.Type) {
+			continue
+		}
+		for _, j := range dependents[stmt.Object] {
+			if !selected[j] && statements[j].TableName != objectTable(stmt.Object) {
+				return nil, fmt.Errorf("'%s' would break the foreign key that '%s' removes; select both or neither",
+					stmt.Description, statements[j].Description)
+			}
+		}
+	}
+
+	selection := &Selection{
+		Statements: make([]MigrationStatement, 0, len(statements)),
+		Required:   make([]string, 0, len(requiredBy)),
+		Skipped:    make([]MigrationStatement, 0),
+	}
+	for i, stmt := range statements {
+		if !selected[i] {
+			selection.Skipped = append(selection.Skipped, stmt)
+			continue
+		}
+		selection.Statements = append(selection.Statements, stmt)
+		if by, ok := requiredBy[i]; ok {
+			selection.Required = append(selection.Required,
+				fmt.Sprintf("'%s' is included because '%s' requires it", stmt.Description, statements[by].Description))
+		}
+	}
+
+	return selection, nil
+}
+
+// objectTable returns the table of an object key
+func objectTable(object string) string {
+	_, name, _ := strings.Cut(object, ":")
+	table, _, _ := strings.Cut(name, ".")
+	return table
+}
+
+// changeKeys returns the keys of the schema diff entries a statement was planned from. A renamed
+// index stands for both the removed and the added index.
+func changeKeys(stmt MigrationStatement) []string {
+	if stmt.Type != StatementTypeRenameIndex {
+		return []string{changeKey(stmt.Type, stmt.Object)}
+	}
+
+	keys := []string{changeKey(StatementTypeCreateIndex, stmt.Object)}
+	for _, dependency := range stmt.Dependencies {
+		if strings.HasPrefix(dependency, "index:") {
+			keys = append(keys, changeKey(StatementTypeDropIndex, dependency))
+		}
+	}
+	return keys
+}
+
+// changeKey identifies a schema diff entry by the statement type and object it is planned as
+func changeKey(stmtType StatementType, object string) string {
+	return string(stmtType) + " " + object
+}
+
+// FilterDiff returns the part of a schema diff that the selected statements of its plan were
+// planned from. Entries that produced no statement, such as columns left in place by safe-drop
+// mode, are kept.
+func FilterDiff(diff *schema.SchemaDiff, plan *MigrationPlan, selection *Selection) *schema.SchemaDiff {
+	planned := make(map[string]bool)
+	for _, stmt := range plan.Statements {
+		for _, key := range changeKeys(stmt) {
+			planned[key] = true
+		}
+	}
+	kept := make(map[string]bool)
+	for _, stmt := range selection.Statements {
+		for _, key := range changeKeys(stmt) {
+			kept[key] = true
+		}
+	}
+	return filterDiff(diff, func(stmtType StatementType, object string) bool {
+		key := changeKey(stmtType, object)
+		return kept[key] || !planned[key]
+	})
+}
+
+// filterDiff returns the entries of a schema diff that keep accepts
+func filterDiff(diff *schema.SchemaDiff, keep func(StatementType, string) bool) *schema.SchemaDiff {
+	filtered := &schema.SchemaDiff{}
+	for _, table := range diff.AddedTables {
+		if keep(StatementTypeCreateTable, tableObject(table.Name)) {
+			filtered.AddedTables = append(filtered.AddedTables, table)
+		}
+	}
+	for _, table := range diff.RemovedTables {
+		if keep(StatementTypeDropTable, tableObject(table.Name)) {
+			filtered.RemovedTables = append(filtered.RemovedTables, table)
+		}
+	}
+	for _, tableDiff := range diff.ModifiedTables {
+		if filteredTable := filterTableDiff(tableDiff, keep); filteredTable != nil {
+			filtered.ModifiedTables = append(filtered.ModifiedTables, filteredTable)
+		}
+	}
+	filtered.AddedIndexes = filterIndexes(diff.AddedIndexes, StatementTypeCreateIndex, keep)
+	filtered.RemovedIndexes = filterIndexes(diff.RemovedIndexes, StatementTypeDropIndex, keep)
+	filtered.AddedConstraints = filterConstraints(diff.AddedConstraints, StatementTypeAddConstraint, keep)
+	filtered.RemovedConstraints = filterConstraints(diff.RemovedConstraints, StatementTypeDropConstraint, keep)
+
+	return filtered
+}
+
+// filterTableDiff returns the kept changes of a table, or nil if none are left
+func filterTableDiff(tableDiff *schema.TableDiff, keep func(StatementType, string) bool) *schema.TableDiff {
+	filtered := &schema.TableDiff{
+		TableName:            tableDiff.TableName,
+		TargetColumnOrder:    tableDiff.TargetColumnOrder,
+		TargetNotNullColumns: tableDiff.TargetNotNullColumns,
+	}
+
+	skippedColumns := make(map[string]bool)
+	for _, column := range tableDiff.AddedColumns {
+		if keep(StatementTypeAddColumn, columnObject(tableDiff.TableName, column.Name)) {
+			filtered.AddedColumns = append(filtered.AddedColumns, column)
+		} else {
+			skippedColumns[column.Name] = true
+		}
+	}
+	for _, column := range tableDiff.RemovedColumns {
+		if keep(StatementTypeDropColumn, columnObject(tableDiff.TableName, column.Name)) {
+			filtered.RemovedColumns = append(filtered.RemovedColumns, column)
+		}
+	}
+	for _, columnDiff := range tableDiff.ModifiedColumns {
+		if keep(StatementTypeModifyColumn, columnObject(tableDiff.TableName, columnDiff.ColumnName)) {
+			filtered.ModifiedColumns = append(filtered.ModifiedColumns, columnDiff)
+		}
+	}
+	filtered.AddedConstraints = filterConstraints(tableDiff.AddedConstraints, StatementTypeAddConstraint, keep)
+	filtered.RemovedConstraints = filterConstraints(tableDiff.RemovedConstraints, StatementTypeDropConstraint, keep)
+
+	// Added columns that are not applied must not serve as positions for the others
+	for _, name := range tableDiff.ColumnOrder {
+		if !skippedColumns[name] {
+			filtered.ColumnOrder = append(filtered.ColumnOrder, name)
+		}
+	}
+
+	if len(filtered.AddedColumns) == 0 && len(filtered.RemovedColumns) == 0 && len(filtered.ModifiedColumns) == 0 &&
+		len(filtered.AddedConstraints) == 0 && len(filtered.RemovedConstraints) == 0 {
+		return nil
+	}
+	return filtered
+}
+
+// filterIndexes returns the kept indexes
+func filterIndexes(indexes []*schema.Index, stmtType StatementType, keep func(StatementType, string) bool) []*schema.Index {
+	var filtered []*schema.Index
+	for _, index := range indexes {
+		if keep(stmtType, indexObject(index.TableName, index.Name)) {
+			filtered = append(filtered, index)
+		}
+	}
+	return filtered
+}
+
+// filterConstraints returns the kept constraints
+func filterConstraints(constraints []*schema.Constraint, stmtType StatementType, keep func(StatementType, string) bool) []*schema.Constraint {
+	var filtered []*schema.Constraint
+	for _, constraint := range constraints {
+		if keep(stmtType, constraintObject(constraint.TableName, constraint.Name)) {
+			filtered = append(filtered, constraint)
+		}
+	}
+	return filtered
+}
@@ -0,0 +1,251 @@
+package migration
+
+import (
+	"strings"
+	"testing"
+
+	"mysql-schema-sync/internal/schema"
+)
+
+func TestParseSelector(t *testing.T) {
+	tests := []struct {
+		expr     string
+		expected Selector
+		wantErr  bool
+	}{
+		{expr: "orders", expected: Selector{Table: "orders", Type: "*"}},
+		{expr: "audit_*:drop_*", expected: Selector{Table: "audit_*", Type: "DROP_*"}},
+		{expr: ":CREATE_INDEX", expected: Selector{Table: "*", Type: "CREATE_INDEX"}},
+		{expr: " users : add_column ", expected: Selector{Table: "users", Type: "ADD_COLUMN"}},
+		{expr: "", wantErr: true},
+		{expr: "orders[", wantErr: true},
+		{expr: "orders:DROP_COLUM", wantErr: true},
+		{expr: "orders:ALTER_TABLE", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			selector, err := ParseSelector(tt.expr)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseSelector(%q) error = %v, wantErr %v", tt.expr, err, tt.wantErr)
+			}
+			if !tt.wantErr && selector != tt.expected {
+				t.Errorf("ParseSelector(%q) = %+v, want %+v", tt.expr, selector, tt.expected)
+			}
+		})
+	}
+}
+
+func TestStatementFilter(t *testing.T) {
+	addColumn := MigrationStatement{Type: StatementTypeAddColumn, TableName: "orders"}
+	dropColumn := MigrationStatement{Type: StatementTypeDropColumn, TableName: "orders"}
+	createTable := MigrationStatement{Type: StatementTypeCreateTable, TableName: "customers"}
+
+	tests := []struct {
+		name     string
+		only     []string
+		skip     []string
+		expected []bool
+	}{
+		{"no selectors", nil, nil, []bool{true, true, true}},
+		{"only table", []string{"orders"}, nil, []bool{true, true, false}},
+		{"skip type", nil, []string{"*:DROP_*"}, []bool{true, false, true}},
+		{"only and skip", []string{"orders", "customers"}, []string{"orders:drop_column"}, []bool{true, false, true}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filter, err := NewStatementFilter(tt.only, tt.skip)
+			if err != nil {
+				t.Fatalf("NewStatementFilter() error = %v", err)
+			}
+			for i, stmt := range []MigrationStatement{addColumn, dropColumn, createTable} {
+				if got := filter.Selects(stmt); got != tt.expected[i] {
+					t.Errorf("Selects(%s %s) = %v, want %v", stmt.Type, stmt.TableName, got, tt.expected[i])
+				}
+			}
+		})
+	}
+
+	if _, err := NewStatementFilter(nil, []string{"orders:NOPE"}); err == nil || !strings.Contains(err.Error(), "--skip") {
+		t.Errorf("Expected an invalid --skip selector to be reported, got %v", err)
+	}
+}
+
+// selectionDiff adds a customers table, links orders to it with a new column and foreign key, and
+// drops an unrelated orders column
+func selectionDiff() *schema.SchemaDiff {
+	return &schema.SchemaDiff{
+		AddedTables: []*schema.Table{
+			{
+				Name: "customers",
+				Columns: map[string]*schema.Column{
+					"id": {Name: "id", DataType: "int", Position: 1},
+				},
+				Indexes: []*schema.Index{
+					{Name: "PRIMARY", TableName: "customers", Columns: []string{"id"}, IsPrimary: true},
+				},
+				Constraints: map[string]*schema.Constraint{},
+			},
+		},
+		ModifiedTables: []*schema.TableDiff{
+			{
+				TableName: "orders",
+				AddedColumns: []*schema.Column{
+					{Name: "customer_id", DataType: "int", IsNullable: true, Position: 2},
+				},
+				RemovedColumns: []*schema.Column{
+					{Name: "legacy", DataType: "varchar(10)", IsNullable: true},
+				},
+				AddedConstraints: []*schema.Constraint{
+					{
+						Name:              "fk_orders_customer",
+						TableName:         "orders",
+						Type:              schema.ConstraintTypeForeignKey,
+						Columns:           []string{"customer_id"},
+						ReferencedTable:   "customers",
+						ReferencedColumns: []string{"id"},
+					},
+				},
+			},
+		},
+	}
+}
+
+func TestSelectStatements_PullsInDependencies(t *testing.T) {
+	plan, err := NewMigrationPlanner().PlanMigration(selectionDiff())
+	if err != nil {
+		t.Fatalf("PlanMigration() error = %v", err)
+	}
+
+	onlyForeignKey := func(stmt MigrationStatement) bool {
+		return stmt.Type == StatementTypeAddConstraint
+	}
+
+	selection, err := SelectStatements(plan, onlyForeignKey, nil)
+	if err != nil {
+		t.Fatalf("SelectStatements() error = %v", err)
+	}
+
+	types := make([]StatementType, 0)
+	for _, stmt := range selection.Statements {
+		types = append(types, stmt.Type)
+	}
+	if len(types) != 3 {
+		t.Fatalf("Expected the foreign key, the referenced table and the new column, got %v", types)
+	}
+	if len(selection.Required) != 2 {
+		t.Errorf("Expected two required statements to be explained, got %v", selection.Required)
+	}
+	if len(selection.Skipped) != 1 || selection.Skipped[0].Type != StatementTypeDropColumn {
+		t.Errorf("Expected only the column drop to be skipped, got %+v", selection.Skipped)
+	}
+
+	excludeColumns := func(stmt MigrationStatement) bool {
+		return stmt.Type == StatementTypeAddColumn
+	}
+	_, err = SelectStatements(plan, onlyForeignKey, excludeColumns)
+	if err == nil || !strings.Contains(err.Error(), "'Add column customer_id to table orders' is required by") {
+		t.Errorf("Expected an excluded dependency to be rejected, got %v", err)
+	}
+}
+
+func TestSelectStatements_RefusesBrokenForeignKeys(t *testing.T) {
+	diff := &schema.SchemaDiff{
+		ModifiedTables: []*schema.TableDiff{
+			{
+				TableName: "parents",
+				RemovedColumns: []*schema.Column{
+					{Name: "code", DataType: "varchar(10)"},
+				},
+			},
+			{
+				TableName: "children",
+				RemovedConstraints: []*schema.Constraint{
+					{
+						Name:              "fk_children_parent",
+						TableName:         "children",
+						Type:              schema.ConstraintTypeForeignKey,
+						Columns:           []string{"parent_code"},
+						ReferencedTable:   "parents",
+						ReferencedColumns: []string{"code"},
+					},
+				},
+			},
+		},
+	}
+
+	plan, err := NewMigrationPlanner().PlanMigration(diff)
+	if err != nil {
+		t.Fatalf("PlanMigration() error = %v", err)
+	}
+
+	_, err = SelectStatements(plan, func(stmt MigrationStatement) bool {
+		return stmt.Type == StatementTypeDropColumn
+	}, nil)
+	if err == nil || !strings.Contains(err.Error(), "would break the foreign key") {
+		t.Errorf("Expected dropping a referenced column alone to be refused, got %v", err)
+	}
+
+	selection, err := SelectStatements(plan, func(stmt MigrationStatement) bool { return true }, nil)
+	if err != nil {
+		t.Fatalf("SelectStatements() error = %v", err)
+	}
+	if len(selection.Statements) != 2 {
+		t.Errorf("Expected both statements to be selected, got %d", len(selection.Statements))
+	}
+
+	// Dropping only the foreign key leaves the column in place, which is consistent
+	if _, err := SelectStatements(plan, func(stmt MigrationStatement) bool {
+		return stmt.Type == StatementTypeDropConstraint
+	}, nil); err != nil {
+		t.Errorf("Expected dropping only the foreign key to be allowed, got %v", err)
+	}
+}
+
+func TestMigrationService_SelectChanges(t *testing.T) {
+	service := NewMigrationService()
+	diff := selectionDiff()
+	diff.ModifiedTables[0].ColumnOrder = []string{"id", "total", "note", "customer_id"}
+	diff.ModifiedTables[0].AddedColumns = append(diff.ModifiedTables[0].AddedColumns,
+		&schema.Column{Name: "note", DataType: "text", IsNullable: true, Position: 3})
+
+	plan, err := service.PlanMigration(diff)
+	if err != nil {
+		t.Fatalf("PlanMigration() error = %v", err)
+	}
+
+	filter, err := NewStatementFilter([]string{"orders:ADD_COLUMN"}, []string{"*:DROP_*"})
+	if err != nil {
+		t.Fatalf("NewStatementFilter() error = %v", err)
+	}
+	chosen := func(stmt MigrationStatement) bool {
+		return filter.Selects(stmt) && strings.Contains(stmt.Description, "note")
+	}
+
+	selected, selectedDiff, err := service.SelectChanges(diff, plan, chosen, filter.Skips)
+	if err != nil {
+		t.Fatalf("SelectChanges() error = %v", err)
+	}
+
+	if len(selected.Statements) != 1 {
+		t.Fatalf("Expected one statement, got %+v", selected.Statements)
+	}
+	// Without the skipped customer_id column, the note column goes at the end of the table
+	if expected := "ALTER TABLE `orders` ADD COLUMN `note` text NULL"; selected.Statements[0].SQL != expected {
+		t.Errorf("Expected %s, got %s", expected, selected.Statements[0].SQL)
+	}
+	if len(selectedDiff.AddedTables) != 0 || len(selectedDiff.ModifiedTables) != 1 ||
+		len(selectedDiff.ModifiedTables[0].RemovedColumns) != 0 {
+		t.Errorf("Expected the diff to hold only the note column, got %+v", selectedDiff)
+	}
+	if selected.Rollback == nil || len(selected.Rollback.Statements) != 1 ||
+		selected.Rollback.Statements[0].Type != StatementTypeDropColumn {
+		t.Errorf("Expected the rollback to revert only the selected change, got %+v", selected.Rollback)
+	}
+	for _, warning := range selected.Warnings {
+		if strings.Contains(warning, "legacy") {
+			t.Errorf("Expected no warnings about the skipped column drop, got %q", warning)
+		}
+	}
+}
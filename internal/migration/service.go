@@ -12,8 +12,14 @@ import (
 // MigrationService provides high-level migration operations
 type MigrationService interface {
 	PlanMigration(diff *schema.SchemaDiff) (*MigrationPlan, error)
+	PlanRollback(diff *schema.SchemaDiff) (*MigrationPlan, error)
 	GenerateSQL(diff *schema.SchemaDiff) ([]string, error)
 	ValidatePlan(plan *MigrationPlan) error
+	OptimizePlan(plan *MigrationPlan) (*MigrationPlan, error)
+	SelectChanges(diff *schema.SchemaDiff, plan *MigrationPlan, chosen, excluded func(MigrationStatement) bool) (*MigrationPlan, *schema.SchemaDiff, error)
+	EnableOnlineDDL(serverVersion string) error
+	SetServerVersion(serverVersion string) error
+	EnableSafeDrop(config SafeDropConfig) error
 
 	// SQL generation methods for specific operations
 	GenerateCreateTableSQL(table *schema.Table) (string, error)
@@ -32,6 +38,7 @@ type MigrationService interface {
 type migrationService struct {
 	planner   *MigrationPlanner
 	generator *SQLGenerator
+	optimizer *PlanOptimizer
 	logger    *logging.Logger
 }
 
@@ -40,6 +47,7 @@ func NewMigrationService() MigrationService {
 	return &migrationService{
 		planner:   NewMigrationPlanner(),
 		generator: NewSQLGenerator(),
+		optimizer: NewPlanOptimizer(),
 		logger:    logging.NewDefaultLogger(),
 	}
 }
@@ -49,6 +57,7 @@ func NewMigrationServiceWithLogger(logger *logging.Logger) MigrationService {
 	return &migrationService{
 		planner:   NewMigrationPlanner(),
 		generator: NewSQLGenerator(),
+		optimizer: NewPlanOptimizer(),
 		logger:    logger,
 	}
 }
@@ -74,16 +83,39 @@ func (ms *migrationService) PlanMigration(diff *schema.SchemaDiff) (*MigrationPl
 		return nil, errors.WrapError(err, "failed to create migration plan")
 	}
 
+	// Plan the reverse migration from the same diff so rollbacks never require re-planning
+	rollback, err := ms.planner.PlanRollback(diff, plan.Archived...)
+	if err != nil {
+		finishLog(err)
+		return nil, errors.WrapError(err, "failed to create rollback plan")
+	}
+	plan.Rollback = rollback
+
 	finishLog(nil)
 	ms.logger.WithFields(map[string]interface{}{
-		"statement_count": len(plan.Statements),
-		"warning_count":   len(plan.Warnings),
-		"duration":        duration.String(),
+		"statement_count":          len(plan.Statements),
+		"rollback_statement_count": len(rollback.Statements),
+		"warning_count":            len(plan.Warnings),
+		"duration":                 duration.String(),
 	}).Info("Migration plan created successfully")
 
 	return plan, nil
 }
 
+// PlanRollback creates the plan that reverts the migration planned from the same diff
+func (ms *migrationService) PlanRollback(diff *schema.SchemaDiff) (*MigrationPlan, error) {
+	if diff == nil {
+		return nil, errors.NewAppError(errors.ErrorTypeValidation, "schema diff cannot be nil", nil)
+	}
+
+	rollback, err := ms.planner.PlanRollback(diff)
+	if err != nil {
+		return nil, errors.WrapError(err, "failed to create rollback plan")
+	}
+
+	return rollback, nil
+}
+
 // GenerateSQL generates SQL statements from schema differences
 func (ms *migrationService) GenerateSQL(diff *schema.SchemaDiff) ([]string, error) {
 	if diff == nil {
@@ -97,10 +129,7 @@ func (ms *migrationService) GenerateSQL(diff *schema.SchemaDiff) ([]string, erro
 	}
 
 	// Extract SQL statements from the plan
-	sqlStatements := make([]string, len(plan.Statements))
-	for i, stmt := range plan.Statements {
-		sqlStatements[i] = stmt.SQL
-	}
+	sqlStatements := plan.ExecutableSQL()
 
 	ms.logger.WithField("statement_count", len(sqlStatements)).Debug("Generated SQL statements from migration plan")
 	return sqlStatements, nil
@@ -123,6 +152,110 @@ func (ms *migrationService) ValidatePlan(plan *MigrationPlan) error {
 	return nil
 }
 
+// OptimizePlan merges compatible per-table operations into single ALTER TABLE statements
+func (ms *migrationService) OptimizePlan(plan *MigrationPlan) (*MigrationPlan, error) {
+	if plan == nil {
+		return nil, errors.NewAppError(errors.ErrorTypeValidation, "migration plan cannot be nil", nil)
+	}
+
+	optimized, err := ms.optimizer.MergeTableAlterations(plan)
+	if err != nil {
+		return nil, errors.WrapError(err, "failed to optimize migration plan")
+	}
+
+	if plan.Rollback != nil {
+		optimized.Rollback, err = ms.optimizer.MergeTableAlterations(plan.Rollback)
+		if err != nil {
+			return nil, errors.WrapError(err, "failed to optimize rollback plan")
+		}
+	}
+
+	ms.logger.WithFields(map[string]interface{}{
+		"original_statements":  len(plan.Statements),
+		"optimized_statements": len(optimized.Statements),
+	}).Debug("Merged table alterations in migration plan")
+
+	return optimized, nil
+}
+
+// SelectChanges replans the migration for the chosen statements of a plan and the statements they
+// require, so that ordering, rollback and warnings only cover the changes that are applied. It
+// returns the new plan together with the part of the diff it was planned from.
+func (ms *migrationService) SelectChanges(diff *schema.SchemaDiff, plan *MigrationPlan, chosen, excluded func(MigrationStatement) bool) (*MigrationPlan, *schema.SchemaDiff, error) {
+	if diff == nil || plan == nil {
+		return nil, nil, errors.NewAppError(errors.ErrorTypeValidation, "schema diff and migration plan cannot be nil", nil)
+	}
+
+	selection, err := SelectStatements(plan, chosen, excluded)
+	if err != nil {
+		return nil, nil, errors.NewAppError(errors.ErrorTypeValidation, fmt.Sprintf("invalid change selection: %v", err), err)
+	}
+
+	selectedDiff := FilterDiff(diff, plan, selection)
+	selectedPlan, err := ms.PlanMigration(selectedDiff)
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, note := range selection.Required {
+		selectedPlan.AddWarning(note)
+	}
+	if len(selection.Skipped) > 0 {
+		selectedPlan.AddWarning(fmt.Sprintf("%d of %d planned statements are skipped by the change selection",
+			len(selection.Skipped), len(plan.Statements)))
+	}
+
+	ms.logger.WithFields(map[string]interface{}{
+		"selected_statements": len(selection.Statements),
+		"required_statements": len(selection.Required),
+		"skipped_statements":  len(selection.Skipped),
+	}).Info("Migration plan narrowed to the selected changes")
+
+	return selectedPlan, selectedDiff, nil
+}
+
+// EnableOnlineDDL enables ALGORITHM/LOCK prediction for the given target server version
+func (ms *migrationService) EnableOnlineDDL(serverVersion string) error {
+	version, err := ParseServerVersion(serverVersion)
+	if err != nil {
+		return errors.NewAppError(errors.ErrorTypeValidation, "failed to parse server version", err)
+	}
+
+	if err := ms.planner.EnableOnlineDDL(version); err != nil {
+		return errors.NewAppError(errors.ErrorTypeValidation, err.Error(), err)
+	}
+
+	if err := ms.generator.EnableOnlineDDL(version); err != nil {
+		return errors.NewAppError(errors.ErrorTypeValidation, err.Error(), err)
+	}
+
+	ms.logger.WithField("server_version", version.String()).Debug("Online DDL enabled")
+	return nil
+}
+
+// SetServerVersion makes planning and SQL generation use the syntax of the given target server
+func (ms *migrationService) SetServerVersion(serverVersion string) error {
+	version, err := ParseServerVersion(serverVersion)
+	if err != nil {
+		return errors.NewAppError(errors.ErrorTypeValidation, "failed to parse server version", err)
+	}
+
+	ms.planner.SetServerVersion(version)
+	ms.generator.SetServerVersion(version)
+
+	ms.logger.WithField("server_version", version.String()).Debug("SQL dialect set for target server")
+	return nil
+}
+
+// EnableSafeDrop makes planning archive dropped tables and columns instead of dropping them
+func (ms *migrationService) EnableSafeDrop(config SafeDropConfig) error {
+	if err := ms.planner.EnableSafeDrop(config); err != nil {
+		return errors.NewAppError(errors.ErrorTypeValidation, err.Error(), err)
+	}
+
+	ms.logger.WithField("columns", string(config.Columns)).Debug("Safe-drop mode enabled")
+	return nil
+}
+
 // Additional utility methods for specific SQL generation
 
 // GenerateCreateTableSQL generates SQL for creating a table
@@ -59,6 +59,14 @@ func TestMigrationService_PlanMigration(t *testing.T) {
 	if plan.Summary.TablesRemoved != 1 {
 		t.Errorf("Expected 1 table removed, got %d", plan.Summary.TablesRemoved)
 	}
+
+	// The rollback recreates the dropped table and drops the new one
+	if plan.Rollback == nil {
+		t.Fatal("Expected rollback plan to be attached")
+	}
+	if plan.Rollback.Summary.TablesAdded != 1 || plan.Rollback.Summary.TablesRemoved != 1 {
+		t.Errorf("Unexpected rollback summary: %+v", plan.Rollback.Summary)
+	}
 }
 
 func TestMigrationService_GenerateSQL(t *testing.T) {
@@ -2,13 +2,17 @@ package migration
 
 import (
 	"fmt"
+	"sort"
 	"strings"
 
 	"mysql-schema-sync/internal/schema"
 )
 
 // SQLGenerator handles the generation of SQL statements for schema changes
-type SQLGenerator struct{}
+type SQLGenerator struct {
+	onlineDDL     bool
+	serverVersion ServerVersion
+}
 
 // NewSQLGenerator creates a new SQLGenerator instance
 func NewSQLGenerator() *SQLGenerator {
@@ -17,6 +21,11 @@ func NewSQLGenerator() *SQLGenerator {
 
 // GenerateCreateTableSQL generates SQL for creating a table
 func (sg *SQLGenerator) GenerateCreateTableSQL(table *schema.Table) (string, error) {
+	return sg.generateCreateTableSQL(table, nil)
+}
+
+// generateCreateTableSQL generates SQL for creating a table, leaving out the named foreign keys
+func (sg *SQLGenerator) generateCreateTableSQL(table *schema.Table, skipForeignKeys map[string]bool) (string, error) {
 	if table == nil {
 		return "", fmt.Errorf("table cannot be nil")
 	}
@@ -53,6 +62,14 @@ func (sg *SQLGenerator) GenerateCreateTableSQL(table *schema.Table) (string, err
 		}
 	}
 
+	// Add foreign keys in name order so the output is deterministic
+	for _, constraint := range sortedForeignKeys(table) {
+		if skipForeignKeys[constraint.Name] {
+			continue
+		}
+		columnDefs = append(columnDefs, sg.generateForeignKeyDefinition(constraint))
+	}
+
 	builder.WriteString("  " + strings.Join(columnDefs, ",\n  "))
 	builder.WriteString("\n)")
 
@@ -68,8 +85,22 @@ func (sg *SQLGenerator) GenerateDropTableSQL(table *schema.Table) (string, error
 	return fmt.Sprintf("DROP TABLE `%s`", table.Name), nil
 }
 
+// GenerateRenameTableSQL generates SQL for renaming a table
+func (sg *SQLGenerator) GenerateRenameTableSQL(oldName, newName string) (string, error) {
+	if oldName == "" || newName == "" {
+		return "", fmt.Errorf("table names cannot be empty")
+	}
+
+	return fmt.Sprintf("RENAME TABLE `%s` TO `%s`", oldName, newName), nil
+}
+
 // GenerateAddColumnSQL generates SQL for adding a column
 func (sg *SQLGenerator) GenerateAddColumnSQL(tableName string, column *schema.Column) (string, error) {
+	return sg.generateAddColumnSQL(tableName, column, nil)
+}
+
+// generateAddColumnSQL generates SQL for adding a column at the given position
+func (sg *SQLGenerator) generateAddColumnSQL(tableName string, column *schema.Column, position *columnPosition) (string, error) {
 	if tableName == "" {
 		return "", fmt.Errorf("table name cannot be empty")
 	}
@@ -83,7 +114,7 @@ func (sg *SQLGenerator) GenerateAddColumnSQL(tableName string, column *schema.Co
 		return "", fmt.Errorf("failed to generate column definition: %w", err)
 	}
 
-	return fmt.Sprintf("ALTER TABLE `%s` ADD COLUMN %s", tableName, colDef), nil
+	return fmt.Sprintf("ALTER TABLE `%s` ADD COLUMN %s%s", tableName, colDef, position.clause()), nil
 }
 
 // GenerateDropColumnSQL generates SQL for dropping a column
@@ -99,6 +130,34 @@ func (sg *SQLGenerator) GenerateDropColumnSQL(tableName string, column *schema.C
 	return fmt.Sprintf("ALTER TABLE `%s` DROP COLUMN `%s`", tableName, column.Name), nil
 }
 
+// GenerateRenameColumnSQL generates SQL for renaming a column and changing it to the definition of
+// newColumn. RENAME COLUMN is used when the definition is unchanged and the server supports it,
+// CHANGE COLUMN otherwise.
+func (sg *SQLGenerator) GenerateRenameColumnSQL(tableName string, oldColumn, newColumn *schema.Column) (string, error) {
+	if tableName == "" {
+		return "", fmt.Errorf("table name cannot be empty")
+	}
+
+	if oldColumn == nil || newColumn == nil {
+		return "", fmt.Errorf("column cannot be nil")
+	}
+
+	if oldColumn.Name == "" {
+		return "", fmt.Errorf("column name cannot be empty")
+	}
+
+	if sg.serverVersion.supportsRenameColumn() && sameColumnDefinition(oldColumn, newColumn) {
+		return fmt.Sprintf("ALTER TABLE `%s` RENAME COLUMN `%s` TO `%s`", tableName, oldColumn.Name, newColumn.Name), nil
+	}
+
+	colDef, err := sg.generateColumnDefinition(newColumn)
+	if err != nil {
+		return "", fmt.Errorf("failed to generate column definition: %w", err)
+	}
+
+	return fmt.Sprintf("ALTER TABLE `%s` CHANGE COLUMN `%s` %s", tableName, oldColumn.Name, colDef), nil
+}
+
 // GenerateModifyColumnSQL generates SQL for modifying a column
 func (sg *SQLGenerator) GenerateModifyColumnSQL(tableName string, columnDiff *schema.ColumnDiff) (string, error) {
 	if tableName == "" {
@@ -167,6 +226,21 @@ func (sg *SQLGenerator) GenerateDropIndexSQL(index *schema.Index) (string, error
 	return fmt.Sprintf("DROP INDEX `%s` ON `%s`", index.Name, index.TableName), nil
 }
 
+// GenerateRenameIndexSQL generates SQL for renaming an index. Servers without RENAME INDEX
+// support must drop and recreate the index instead.
+func (sg *SQLGenerator) GenerateRenameIndexSQL(oldIndex, newIndex *schema.Index) (string, error) {
+	if oldIndex == nil || newIndex == nil {
+		return "", fmt.Errorf("index cannot be nil")
+	}
+
+	if sg.serverVersion.known() && !sg.serverVersion.supportsRenameIndex() {
+		return "", fmt.Errorf("renaming index %s to %s requires MySQL 5.7 or MariaDB 10.5.2, server is %s",
+			oldIndex.Name, newIndex.Name, sg.serverVersion)
+	}
+
+	return fmt.Sprintf("ALTER TABLE `%s` RENAME INDEX `%s` TO `%s`", newIndex.TableName, oldIndex.Name, newIndex.Name), nil
+}
+
 // GenerateAddConstraintSQL generates SQL for adding a constraint
 func (sg *SQLGenerator) GenerateAddConstraintSQL(constraint *schema.Constraint) (string, error) {
 	if constraint == nil {
@@ -187,6 +261,11 @@ func (sg *SQLGenerator) GenerateAddConstraintSQL(constraint *schema.Constraint)
 	case schema.ConstraintTypeUnique:
 		return sg.generateAddUniqueConstraintSQL(constraint)
 	case schema.ConstraintTypeCheck:
+		// Older servers accept the syntax but silently discard the constraint
+		if sg.serverVersion.known() && !sg.serverVersion.supportsCheckConstraints() {
+			return "", fmt.Errorf("CHECK constraint %s on table %s is not enforced by server %s (requires MySQL 8.0.16 or MariaDB 10.2.1)",
+				constraint.Name, constraint.TableName, sg.serverVersion)
+		}
 		return sg.generateAddCheckConstraintSQL(constraint)
 	default:
 		return "", fmt.Errorf("unsupported constraint type: %s", constraint.Type)
@@ -207,6 +286,11 @@ func (sg *SQLGenerator) GenerateDropConstraintSQL(constraint *schema.Constraint)
 		return fmt.Sprintf("ALTER TABLE `%s` DROP INDEX `%s`",
 			constraint.TableName, constraint.Name), nil
 	case schema.ConstraintTypeCheck:
+		// MariaDB has no DROP CHECK and drops CHECK constraints by name
+		if sg.serverVersion.MariaDB {
+			return fmt.Sprintf("ALTER TABLE `%s` DROP CONSTRAINT `%s`",
+				constraint.TableName, constraint.Name), nil
+		}
 		return fmt.Sprintf("ALTER TABLE `%s` DROP CHECK `%s`",
 			constraint.TableName, constraint.Name), nil
 	default:
@@ -233,17 +317,12 @@ func (sg *SQLGenerator) generateColumnDefinition(column *schema.Column) (string,
 	}
 
 	// Add default value
-	if column.DefaultValue != nil {
-		defaultVal := *column.DefaultValue
-		// Handle special MySQL default values
-		if strings.ToUpper(defaultVal) == "CURRENT_TIMESTAMP" ||
-			strings.ToUpper(defaultVal) == "NOW()" ||
-			strings.ToUpper(defaultVal) == "NULL" {
-			builder.WriteString(fmt.Sprintf(" DEFAULT %s", defaultVal))
-		} else {
-			// Quote string defaults
-			builder.WriteString(fmt.Sprintf(" DEFAULT '%s'", strings.ReplaceAll(defaultVal, "'", "''")))
+	if defaultValue := column.Default(); defaultValue != nil {
+		rendered, err := sg.renderDefault(column.Name, defaultValue)
+		if err != nil {
+			return "", err
 		}
+		builder.WriteString(" DEFAULT " + rendered)
 	}
 
 	// Add extra attributes (AUTO_INCREMENT, etc.)
@@ -254,6 +333,17 @@ func (sg *SQLGenerator) generateColumnDefinition(column *schema.Column) (string,
 	return builder.String(), nil
 }
 
+// renderDefault renders a default value for the target server. Expression defaults other than
+// CURRENT_TIMESTAMP cannot be expressed on servers that only accept literal defaults.
+func (sg *SQLGenerator) renderDefault(columnName string, defaultValue *schema.ColumnDefault) (string, error) {
+	if defaultValue.Kind == schema.DefaultKindExpression && !defaultValue.IsTimestampFunction() &&
+		sg.serverVersion.known() && !sg.serverVersion.supportsExpressionDefaults() {
+		return "", fmt.Errorf("expression default (%s) of column %s is not supported by server %s",
+			defaultValue.Value, columnName, sg.serverVersion)
+	}
+	return defaultValue.SQL(), nil
+}
+
 // generatePrimaryKeyDefinition generates the SQL definition for a primary key
 func (sg *SQLGenerator) generatePrimaryKeyDefinition(primaryKey *schema.Index) string {
 	quotedColumns := make([]string, len(primaryKey.Columns))
@@ -274,6 +364,13 @@ func (sg *SQLGenerator) generateUniqueConstraintDefinition(constraint *schema.Co
 
 // generateAddForeignKeySQL generates SQL for adding a foreign key constraint
 func (sg *SQLGenerator) generateAddForeignKeySQL(constraint *schema.Constraint) (string, error) {
+	return fmt.Sprintf("ALTER TABLE `%s` ADD %s",
+		constraint.TableName,
+		sg.generateForeignKeyDefinition(constraint)), nil
+}
+
+// generateForeignKeyDefinition generates the SQL definition for a foreign key constraint
+func (sg *SQLGenerator) generateForeignKeyDefinition(constraint *schema.Constraint) string {
 	quotedColumns := make([]string, len(constraint.Columns))
 	for i, col := range constraint.Columns {
 		quotedColumns[i] = fmt.Sprintf("`%s`", col)
@@ -285,8 +382,7 @@ func (sg *SQLGenerator) generateAddForeignKeySQL(constraint *schema.Constraint)
 	}
 
 	var builder strings.Builder
-	builder.WriteString(fmt.Sprintf("ALTER TABLE `%s` ADD CONSTRAINT `%s` FOREIGN KEY (%s) REFERENCES `%s` (%s)",
-		constraint.TableName,
+	builder.WriteString(fmt.Sprintf("CONSTRAINT `%s` FOREIGN KEY (%s) REFERENCES `%s` (%s)",
 		constraint.Name,
 		strings.Join(quotedColumns, ", "),
 		constraint.ReferencedTable,
@@ -301,7 +397,22 @@ func (sg *SQLGenerator) generateAddForeignKeySQL(constraint *schema.Constraint)
 		builder.WriteString(fmt.Sprintf(" ON DELETE %s", constraint.OnDelete))
 	}
 
-	return builder.String(), nil
+	return builder.String()
+}
+
+// sortedForeignKeys returns the foreign key constraints of a table ordered by name
+func sortedForeignKeys(table *schema.Table) []*schema.Constraint {
+	foreignKeys := make([]*schema.Constraint, 0)
+	for _, constraint := range table.Constraints {
+		if constraint.Type == schema.ConstraintTypeForeignKey {
+			foreignKeys = append(foreignKeys, constraint)
+		}
+	}
+
+	sort.Slice(foreignKeys, func(i, j int) bool {
+		return foreignKeys[i].Name < foreignKeys[j].Name
+	})
+	return foreignKeys
 }
 
 // generateAddUniqueConstraintSQL generates SQL for adding a unique constraint
@@ -388,6 +388,70 @@ func TestSQLGenerator_generateColumnDefinition(t *testing.T) {
 			},
 			expected: "`created_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP",
 		},
+		{
+			name: "column with fractional current timestamp default",
+			column: &schema.Column{
+				Name:         "updated_at",
+				DataType:     "datetime(6)",
+				IsNullable:   false,
+				DefaultValue: stringPtr("CURRENT_TIMESTAMP(6)"),
+				DefaultKind:  schema.DefaultKindExpression,
+				Extra:        "on update CURRENT_TIMESTAMP(6)",
+			},
+			expected: "`updated_at` datetime(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6) on update CURRENT_TIMESTAMP(6)",
+		},
+		{
+			name: "column with numeric default",
+			column: &schema.Column{
+				Name:         "quantity",
+				DataType:     "int",
+				IsNullable:   false,
+				DefaultValue: stringPtr("0"),
+			},
+			expected: "`quantity` int NOT NULL DEFAULT 0",
+		},
+		{
+			name: "column with bit default",
+			column: &schema.Column{
+				Name:         "flags",
+				DataType:     "bit(1)",
+				IsNullable:   false,
+				DefaultValue: stringPtr("b'1'"),
+			},
+			expected: "`flags` bit(1) NOT NULL DEFAULT b'1'",
+		},
+		{
+			name: "column with expression default",
+			column: &schema.Column{
+				Name:         "uuid",
+				DataType:     "char(36)",
+				IsNullable:   false,
+				DefaultValue: stringPtr("uuid()"),
+				DefaultKind:  schema.DefaultKindExpression,
+			},
+			expected: "`uuid` char(36) NOT NULL DEFAULT (uuid())",
+		},
+		{
+			name: "column with string default containing quotes and backslashes",
+			column: &schema.Column{
+				Name:         "path",
+				DataType:     "varchar(50)",
+				IsNullable:   true,
+				DefaultValue: stringPtr(`C:\it's`),
+			},
+			expected: "`path` varchar(50) NULL DEFAULT 'C:\\\\it''s'",
+		},
+		{
+			name: "column with explicit null default",
+			column: &schema.Column{
+				Name:         "note",
+				DataType:     "varchar(50)",
+				IsNullable:   true,
+				DefaultValue: stringPtr("NULL"),
+				DefaultKind:  schema.DefaultKindNull,
+			},
+			expected: "`note` varchar(50) NULL DEFAULT NULL",
+		},
 	}
 
 	for _, tt := range tests {
@@ -404,6 +468,51 @@ func TestSQLGenerator_generateColumnDefinition(t *testing.T) {
 	}
 }
 
+func TestSQLGenerator_ExpressionDefaultsByVersion(t *testing.T) {
+	uuidColumn := &schema.Column{
+		Name:         "uuid",
+		DataType:     "char(36)",
+		DefaultValue: stringPtr("uuid()"),
+		DefaultKind:  schema.DefaultKindExpression,
+	}
+	timestampColumn := &schema.Column{
+		Name:         "created_at",
+		DataType:     "timestamp",
+		DefaultValue: stringPtr("CURRENT_TIMESTAMP"),
+		DefaultKind:  schema.DefaultKindExpression,
+	}
+
+	tests := []struct {
+		name    string
+		version ServerVersion
+		column  *schema.Column
+		wantErr bool
+	}{
+		{"expression on MySQL 8.0.13", ServerVersion{Major: 8, Minor: 0, Patch: 13}, uuidColumn, false},
+		{"expression on MySQL 8.0.12", ServerVersion{Major: 8, Minor: 0, Patch: 12}, uuidColumn, true},
+		{"expression on MySQL 5.7", ServerVersion{Major: 5, Minor: 7, Patch: 40}, uuidColumn, true},
+		{"expression on MariaDB 10.3", ServerVersion{Major: 10, Minor: 3, Patch: 0, MariaDB: true}, uuidColumn, false},
+		{"current timestamp on MySQL 5.7", ServerVersion{Major: 5, Minor: 7, Patch: 40}, timestampColumn, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			generator := NewSQLGenerator()
+			if err := generator.EnableOnlineDDL(tt.version); err != nil {
+				t.Fatalf("EnableOnlineDDL() error = %v", err)
+			}
+
+			_, err := generator.generateColumnDefinition(tt.column)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("generateColumnDefinition() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && !strings.Contains(err.Error(), "expression default (uuid())") {
+				t.Errorf("Expected error to name the expression, got %v", err)
+			}
+		})
+	}
+}
+
 func TestSQLGenerator_NilInputs(t *testing.T) {
 	generator := NewSQLGenerator()
 
@@ -0,0 +1,127 @@
+package migration
+
+import (
+	"fmt"
+	"strings"
+
+	"mysql-schema-sync/internal/schema"
+)
+
+// ResidualDiff returns the differences a migration plan left behind that it was not meant to. diff
+// compares the schemas after the plan was executed, planned is the diff the plan was created from
+// and applied the part of it the plan was meant to resolve. Differences that were planned but not
+// applied, such as changes left out by --only or --skip, columns kept in place by safe-drop mode
+// and objects under an archived name are expected. Everything else is residual: changes the
+// server did not apply as planned, e.g. because it normalized a type or ignored a CHECK
+// constraint, and differences that appeared with the migration.
+func ResidualDiff(diff, planned, applied *schema.SchemaDiff, plan *MigrationPlan) *schema.SchemaDiff {
+	expected := diffKeys(planned)
+	for key := range diffKeys(applied) {
+		delete(expected, key)
+	}
+	for _, archived := range plan.Archived {
+		if !archived.IsTable() && archived.ArchivedName == "" {
+			expected[changeKey(StatementTypeDropColumn, columnObject(archived.TableName, archived.ColumnName))] = true
+		}
+	}
+
+	return filterDiff(diff, func(stmtType StatementType, object string) bool {
+		return !isArchivedRemoval(stmtType, object) && !expected[changeKey(stmtType, object)]
+	})
+}
+
+// diffKeys returns the keys of every entry of a schema diff
+func diffKeys(diff *schema.SchemaDiff) map[string]bool {
+	keys := make(map[string]bool)
+	if diff == nil {
+		return keys
+	}
+	filterDiff(diff, func(stmtType StatementType, object string) bool {
+		keys[changeKey(stmtType, object)] = true
+		return false
+	})
+	return keys
+}
+
+// isArchivedRemoval returns true if a diff entry removes a table or column archived by safe-drop mode
+func isArchivedRemoval(stmtType StatementType, object string) bool {
+	if stmtType != StatementTypeDropTable && stmtType != StatementTypeDropColumn {
+		return false
+	}
+	return schema.IsArchivedName(objectName(object))
+}
+
+// objectName returns the name of the table or column an object key refers to
+func objectName(object string) string {
+	_, name, _ := strings.Cut(object, ":")
+	if _, column, ok := strings.Cut(name, "."); ok {
+		return column
+	}
+	return name
+}
+
+// DescribeDiff lists the entries of a schema diff, one line each, from the point of view of the
+// target
+func DescribeDiff(diff *schema.SchemaDiff) []string {
+	var lines []string
+	for _, table := range diff.AddedTables {
+		lines = append(lines, fmt.Sprintf("table %s is missing on the target", table.Name))
+	}
+	for _, table := range diff.RemovedTables {
+		lines = append(lines, fmt.Sprintf("table %s exists only on the target", table.Name))
+	}
+	for _, tableDiff := range diff.ModifiedTables {
+		for _, column := range tableDiff.AddedColumns {
+			lines = append(lines, fmt.Sprintf("column %s.%s is missing on the target", tableDiff.TableName, column.Name))
+		}
+		for _, column := range tableDiff.RemovedColumns {
+			lines = append(lines, fmt.Sprintf("column %s.%s exists only on the target", tableDiff.TableName, column.Name))
+		}
+		for _, columnDiff := range tableDiff.ModifiedColumns {
+			lines = append(lines, fmt.Sprintf("column %s.%s is %s on the target but %s in the source",
+				tableDiff.TableName, columnDiff.ColumnName, describeColumn(columnDiff.OldColumn), describeColumn(columnDiff.NewColumn)))
+		}
+		lines = append(lines, describeConstraints(tableDiff.AddedConstraints, "is missing on the target")...)
+		lines = append(lines, describeConstraints(tableDiff.RemovedConstraints, "exists only on the target")...)
+	}
+	for _, index := range diff.AddedIndexes {
+		lines = append(lines, fmt.Sprintf("index %s.%s is missing on the target", index.TableName, index.Name))
+	}
+	for _, index := range diff.RemovedIndexes {
+		lines = append(lines, fmt.Sprintf("index %s.%s exists only on the target", index.TableName, index.Name))
+	}
+	lines = append(lines, describeConstraints(diff.AddedConstraints, "is missing on the target")...)
+	lines = append(lines, describeConstraints(diff.RemovedConstraints, "exists only on the target")...)
+	return lines
+}
+
+// describeColumn summarizes a column definition
+func describeColumn(column *schema.Column) string {
+	if column == nil {
+		return "absent"
+	}
+
+	parts := []string{column.DataType}
+	if column.IsNullable {
+		parts = append(parts, "NULL")
+	} else {
+		parts = append(parts, "NOT NULL")
+	}
+	if column.DefaultValue != nil {
+		parts = append(parts, "DEFAULT "+*column.DefaultValue)
+	}
+	if column.Extra != "" {
+		parts = append(parts, column.Extra)
+	}
+	return strings.Join(parts, " ")
+}
+
+// describeConstraints describes each constraint followed by what is wrong with it
+func describeConstraints(constraints []*schema.Constraint, problem string) []string {
+	lines := make([]string, 0, len(constraints))
+	for _, constraint := range constraints {
+		lines = append(lines, fmt.Sprintf("%s constraint %s.%s %s",
+			strings.ReplaceAll(strings.ToLower(string(constraint.Type)), "_", " "), constraint.TableName, constraint.Name, problem))
+	}
+	return lines
+}
@@ -0,0 +1,159 @@
+package migration
+
+import (
+	"testing"
+	"time"
+
+	"mysql-schema-sync/internal/schema"
+)
+
+func TestResidualDiff(t *testing.T) {
+	archivedAt := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	check := &schema.Constraint{
+		Name:            "chk_total",
+		TableName:       "orders",
+		Type:            schema.ConstraintTypeCheck,
+		CheckExpression: "total >= 0",
+	}
+	normalized := &schema.ColumnDiff{
+		ColumnName: "customer_id",
+		OldColumn:  &schema.Column{Name: "customer_id", DataType: "int(11)", IsNullable: true},
+		NewColumn:  &schema.Column{Name: "customer_id", DataType: "int", IsNullable: true},
+	}
+
+	// skipLegacy leaves the removal of the legacy column out of the applied diff
+	skipLegacy := func(diff *schema.SchemaDiff) *schema.SchemaDiff {
+		applied := *diff
+		tableDiff := *diff.ModifiedTables[0]
+		tableDiff.RemovedColumns = nil
+		applied.ModifiedTables = []*schema.TableDiff{&tableDiff}
+		return &applied
+	}
+
+	tests := []struct {
+		name     string
+		after    *schema.SchemaDiff
+		applied  func(*schema.SchemaDiff) *schema.SchemaDiff
+		archived []ArchivedObject
+		expected []string
+	}{
+		{
+			name:  "synchronized target",
+			after: &schema.SchemaDiff{},
+		},
+		{
+			name: "normalized column type",
+			after: &schema.SchemaDiff{ModifiedTables: []*schema.TableDiff{
+				{TableName: "orders", ModifiedColumns: []*schema.ColumnDiff{normalized}},
+			}},
+			expected: []string{"MODIFY_COLUMN column:orders.customer_id"},
+		},
+		{
+			name: "ignored check constraint",
+			after: &schema.SchemaDiff{ModifiedTables: []*schema.TableDiff{
+				{TableName: "orders", AddedConstraints: []*schema.Constraint{check}},
+			}},
+			expected: []string{"ADD_CONSTRAINT constraint:orders.chk_total"},
+		},
+		{
+			name: "skipped change is expected",
+			after: &schema.SchemaDiff{ModifiedTables: []*schema.TableDiff{
+				{TableName: "orders", RemovedColumns: []*schema.Column{{Name: "legacy", DataType: "varchar(10)"}}},
+			}},
+			applied: skipLegacy,
+		},
+		{
+			name: "applied change that did not happen",
+			after: &schema.SchemaDiff{ModifiedTables: []*schema.TableDiff{
+				{TableName: "orders", RemovedColumns: []*schema.Column{{Name: "legacy", DataType: "varchar(10)"}}},
+			}},
+			expected: []string{"DROP_COLUMN column:orders.legacy"},
+		},
+		{
+			name: "column kept by safe-drop mode",
+			after: &schema.SchemaDiff{ModifiedTables: []*schema.TableDiff{
+				{TableName: "orders", RemovedColumns: []*schema.Column{{Name: "legacy", DataType: "varchar(10)"}}},
+			}},
+			archived: []ArchivedObject{{TableName: "orders", ColumnName: "legacy", ArchivedAt: archivedAt}},
+		},
+		{
+			name: "archived objects",
+			after: &schema.SchemaDiff{
+				RemovedTables: []*schema.Table{{Name: schema.ArchivedName("audit", archivedAt)}},
+				ModifiedTables: []*schema.TableDiff{
+					{TableName: "orders", RemovedColumns: []*schema.Column{{Name: schema.ArchivedName("legacy", archivedAt)}}},
+				},
+			},
+		},
+		{
+			name:     "new difference",
+			after:    &schema.SchemaDiff{RemovedTables: []*schema.Table{{Name: "audit"}}},
+			expected: []string{"DROP_TABLE table:audit"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			planned := selectionDiff()
+			planned.ModifiedTables[0].AddedConstraints = append(planned.ModifiedTables[0].AddedConstraints, check)
+			applied := planned
+			if tt.applied != nil {
+				applied = tt.applied(planned)
+			}
+			plan := NewMigrationPlan()
+			plan.Archived = tt.archived
+
+			residual := ResidualDiff(tt.after, planned, applied, plan)
+
+			keys := diffKeys(residual)
+			if len(keys) != len(tt.expected) {
+				t.Fatalf("Expected residual differences %v, got %v", tt.expected, keys)
+			}
+			for _, key := range tt.expected {
+				if !keys[key] {
+					t.Errorf("Expected residual difference %s, got %v", key, keys)
+				}
+			}
+		})
+	}
+}
+
+func TestDescribeDiff(t *testing.T) {
+	defaultValue := "0"
+	diff := &schema.SchemaDiff{
+		AddedTables: []*schema.Table{{Name: "customers"}},
+		ModifiedTables: []*schema.TableDiff{
+			{
+				TableName: "orders",
+				ModifiedColumns: []*schema.ColumnDiff{
+					{
+						ColumnName: "total",
+						OldColumn:  &schema.Column{Name: "total", DataType: "int(11)", DefaultValue: &defaultValue},
+						NewColumn:  &schema.Column{Name: "total", DataType: "int", DefaultValue: &defaultValue},
+					},
+				},
+				AddedConstraints: []*schema.Constraint{
+					{Name: "chk_total", TableName: "orders", Type: schema.ConstraintTypeCheck},
+				},
+			},
+		},
+		RemovedIndexes: []*schema.Index{{Name: "idx_old", TableName: "orders"}},
+	}
+
+	expected := []string{
+		"table customers is missing on the target",
+		"column orders.total is int(11) NOT NULL DEFAULT 0 on the target but int NOT NULL DEFAULT 0 in the source",
+		"check constraint orders.chk_total is missing on the target",
+		"index orders.idx_old exists only on the target",
+	}
+
+	lines := DescribeDiff(diff)
+	if len(lines) != len(expected) {
+		t.Fatalf("Expected %d lines, got %q", len(expected), lines)
+	}
+	for i := range expected {
+		if lines[i] != expected[i] {
+			t.Errorf("Line %d: expected %q, got %q", i+1, expected[i], lines[i])
+		}
+	}
+}
@@ -0,0 +1,218 @@
+package migrationfiles
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"mysql-schema-sync/internal/migration"
+)
+
+// Format identifies a migration framework whose file layout is emitted
+type Format string
+
+const (
+	FormatGolangMigrate Format = "golang-migrate"
+	FormatFlyway        Format = "flyway"
+	FormatLiquibase     Format = "liquibase"
+	FormatAtlas         Format = "atlas"
+	FormatDbmate        Format = "dbmate"
+)
+
+// ParseFormat converts a user supplied format name into a Format
+func ParseFormat(name string) (Format, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "", "golang-migrate", "migrate":
+		return FormatGolangMigrate, nil
+	case "flyway":
+		return FormatFlyway, nil
+	case "liquibase":
+		return FormatLiquibase, nil
+	case "atlas":
+		return FormatAtlas, nil
+	case "dbmate":
+		return FormatDbmate, nil
+	default:
+		return "", fmt.Errorf("unsupported migration format: %s (supported: golang-migrate, flyway, liquibase, atlas, dbmate)", name)
+	}
+}
+
+// Config holds the configuration for emitting migration files instead of applying the plan
+type Config struct {
+	Dir    string `mapstructure:"dir" yaml:"dir"`
+	Format Format `mapstructure:"format" yaml:"format"`
+}
+
+// Enabled returns true if an output directory has been configured
+func (c Config) Enabled() bool {
+	return c.Dir != ""
+}
+
+// Validate validates the migration file configuration
+func (c Config) Validate() error {
+	if !c.Enabled() {
+		return nil
+	}
+
+	_, err := ParseFormat(string(c.Format))
+	return err
+}
+
+// Result describes the files produced for a plan
+type Result struct {
+	Files []string
+	// AlreadyEmitted is true when files for the same plan hash were found and nothing was written
+	AlreadyEmitted bool
+}
+
+// file is a migration file to be written
+type file struct {
+	name    string
+	content string
+}
+
+// Emitter writes migration plans as versioned files for a migration framework
+type Emitter struct {
+	config Config
+	now    func() time.Time
+}
+
+// NewEmitter creates a new Emitter
+func NewEmitter(config Config) *Emitter {
+	return &Emitter{
+		config: config,
+		now:    time.Now,
+	}
+}
+
+// hashLength is the number of plan hash characters used in file names
+const hashLength = 12
+
+// Emit writes the up and down files of the plan. File names contain the plan hash, so emitting
+// the same plan again returns the existing files instead of creating a new version.
+func (e *Emitter) Emit(plan *migration.MigrationPlan) (*Result, error) {
+	if plan == nil {
+		return nil, fmt.Errorf("migration plan cannot be nil")
+	}
+	if len(plan.Statements) == 0 {
+		return nil, fmt.Errorf("migration plan has no statements to emit")
+	}
+
+	format, err := ParseFormat(string(e.config.Format))
+	if err != nil {
+		return nil, err
+	}
+
+	hash := plan.Hash()
+	if len(hash) > hashLength {
+		hash = hash[:hashLength]
+	}
+	name := "schema_sync_" + hash
+
+	existing, err := e.existingFiles(name)
+	if err != nil {
+		return nil, err
+	}
+	if len(existing) > 0 {
+		return &Result{Files: existing, AlreadyEmitted: true}, nil
+	}
+
+	version := e.now().UTC().Format("20060102150405")
+	files := renderFiles(format, version, name, hash, plan)
+
+	if err := os.MkdirAll(e.config.Dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create migration directory %s: %w", e.config.Dir, err)
+	}
+
+	result := &Result{Files: make([]string, 0, len(files))}
+	for _, f := range files {
+		path := filepath.Join(e.config.Dir, f.name)
+		if err := os.WriteFile(path, []byte(f.content), 0644); err != nil {
+			return nil, fmt.Errorf("failed to write migration file %s: %w", path, err)
+		}
+		result.Files = append(result.Files, path)
+	}
+
+	return result, nil
+}
+
+// existingFiles returns the files in the output directory that were emitted for the named plan
+func (e *Emitter) existingFiles(name string) ([]string, error) {
+	entries, err := os.ReadDir(e.config.Dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read migration directory %s: %w", e.config.Dir, err)
+	}
+
+	files := make([]string, 0)
+	for _, entry := range entries {
+		if !entry.IsDir() && strings.Contains(entry.Name(), name) {
+			files = append(files, filepath.Join(e.config.Dir, entry.Name()))
+		}
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// renderFiles builds the files of a plan in the layout of the given framework
+func renderFiles(format Format, version, name, hash string, plan *migration.MigrationPlan) []file {
+	header := fmt.Sprintf("-- Generated by mysql-schema-sync from migration plan %s\n", hash)
+	up := header + plan.SQLBody()
+	down := ""
+	if plan.Rollback != nil && len(plan.Rollback.Statements) > 0 {
+		down = header + plan.Rollback.SQLBody()
+	}
+
+	switch format {
+	case FormatFlyway:
+		files := []file{{name: fmt.Sprintf("V%s__%s.sql", version, name), content: up}}
+		if down != "" {
+			files = append(files, file{name: fmt.Sprintf("U%s__%s.sql", version, name), content: down})
+		}
+		return files
+	case FormatLiquibase:
+		return []file{{name: fmt.Sprintf("%s_%s.sql", version, name), content: renderLiquibase(hash, plan)}}
+	case FormatAtlas:
+		// Atlas computes down migrations itself; run "atlas migrate hash" to update atlas.sum
+		return []file{{name: fmt.Sprintf("%s_%s.sql", version, name), content: up}}
+	case FormatDbmate:
+		content := "-- migrate:up\n" + up + "\n-- migrate:down\n" + down
+		return []file{{name: fmt.Sprintf("%s_%s.sql", version, name), content: content}}
+	default:
+		files := []file{{name: fmt.Sprintf("%s_%s.up.sql", version, name), content: up}}
+		if down != "" {
+			files = append(files, file{name: fmt.Sprintf("%s_%s.down.sql", version, name), content: down})
+		}
+		return files
+	}
+}
+
+// renderLiquibase renders the plan as a Liquibase formatted SQL changelog with a single changeset
+// whose rollback is the plan's rollback
+func renderLiquibase(hash string, plan *migration.MigrationPlan) string {
+	var builder strings.Builder
+	builder.WriteString("--liquibase formatted sql\n\n")
+	builder.WriteString(fmt.Sprintf("--changeset mysql-schema-sync:%s\n", hash))
+	builder.WriteString(fmt.Sprintf("--comment: Generated by mysql-schema-sync from migration plan %s\n", hash))
+
+	for _, sql := range plan.ExecutableSQL() {
+		builder.WriteString(sql + ";\n")
+	}
+
+	rollback := plan.RollbackSQL()
+	if len(rollback) == 0 {
+		builder.WriteString("--rollback empty\n")
+		return builder.String()
+	}
+	for _, sql := range rollback {
+		for _, line := range strings.Split(sql+";", "\n") {
+			builder.WriteString("--rollback " + line + "\n")
+		}
+	}
+	return builder.String()
+}
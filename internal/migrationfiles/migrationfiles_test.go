@@ -0,0 +1,201 @@
+package migrationfiles
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"mysql-schema-sync/internal/migration"
+	"mysql-schema-sync/internal/schema"
+)
+
+// newTestPlan plans adding a column together with its rollback
+func newTestPlan(t *testing.T) *migration.MigrationPlan {
+	t.Helper()
+
+	diff := &schema.SchemaDiff{
+		ModifiedTables: []*schema.TableDiff{
+			{
+				TableName:    "users",
+				AddedColumns: []*schema.Column{{Name: "nickname", DataType: "VARCHAR(50)", IsNullable: true}},
+			},
+		},
+	}
+
+	plan, err := migration.NewMigrationService().PlanMigration(diff)
+	if err != nil {
+		t.Fatalf("PlanMigration() error = %v", err)
+	}
+	return plan
+}
+
+// newTestEmitter creates an emitter with a fixed clock
+func newTestEmitter(dir string, format Format) *Emitter {
+	emitter := NewEmitter(Config{Dir: dir, Format: format})
+	emitter.now = func() time.Time {
+		return time.Date(2024, 3, 1, 12, 30, 0, 0, time.UTC)
+	}
+	return emitter
+}
+
+func TestParseFormat(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected Format
+		wantErr  bool
+	}{
+		{input: "", expected: FormatGolangMigrate},
+		{input: "golang-migrate", expected: FormatGolangMigrate},
+		{input: "Flyway", expected: FormatFlyway},
+		{input: "liquibase", expected: FormatLiquibase},
+		{input: "atlas", expected: FormatAtlas},
+		{input: "dbmate", expected: FormatDbmate},
+		{input: "rails", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := ParseFormat(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseFormat() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.expected {
+				t.Errorf("ParseFormat() = %q, want %q", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestEmitter_FileNames(t *testing.T) {
+	plan := newTestPlan(t)
+	name := "schema_sync_" + plan.Hash()[:hashLength]
+
+	tests := []struct {
+		format   Format
+		expected []string
+	}{
+		{format: FormatGolangMigrate, expected: []string{"20240301123000_" + name + ".up.sql", "20240301123000_" + name + ".down.sql"}},
+		{format: FormatFlyway, expected: []string{"V20240301123000__" + name + ".sql", "U20240301123000__" + name + ".sql"}},
+		{format: FormatLiquibase, expected: []string{"20240301123000_" + name + ".sql"}},
+		{format: FormatAtlas, expected: []string{"20240301123000_" + name + ".sql"}},
+		{format: FormatDbmate, expected: []string{"20240301123000_" + name + ".sql"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.format), func(t *testing.T) {
+			dir := t.TempDir()
+			result, err := newTestEmitter(dir, tt.format).Emit(plan)
+			if err != nil {
+				t.Fatalf("Emit() error = %v", err)
+			}
+
+			if len(result.Files) != len(tt.expected) {
+				t.Fatalf("Expected %d files, got %v", len(tt.expected), result.Files)
+			}
+			for i, expected := range tt.expected {
+				if result.Files[i] != filepath.Join(dir, expected) {
+					t.Errorf("Expected file %s, got %s", expected, result.Files[i])
+				}
+			}
+		})
+	}
+}
+
+func TestEmitter_Content(t *testing.T) {
+	plan := newTestPlan(t)
+
+	tests := []struct {
+		format    Format
+		fragments []string
+	}{
+		{
+			format: FormatDbmate,
+			fragments: []string{
+				"-- migrate:up\n",
+				"ALTER TABLE `users` ADD COLUMN `nickname` VARCHAR(50) NULL;\n",
+				"-- migrate:down\n",
+				"ALTER TABLE `users` DROP COLUMN `nickname`;\n",
+			},
+		},
+		{
+			format: FormatLiquibase,
+			fragments: []string{
+				"--liquibase formatted sql\n",
+				"--changeset mysql-schema-sync:" + plan.Hash()[:hashLength] + "\n",
+				"ALTER TABLE `users` ADD COLUMN `nickname` VARCHAR(50) NULL;\n",
+				"--rollback ALTER TABLE `users` DROP COLUMN `nickname`;\n",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.format), func(t *testing.T) {
+			result, err := newTestEmitter(t.TempDir(), tt.format).Emit(plan)
+			if err != nil {
+				t.Fatalf("Emit() error = %v", err)
+			}
+
+			content, err := os.ReadFile(result.Files[0])
+			if err != nil {
+				t.Fatalf("Failed to read emitted file: %v", err)
+			}
+			for _, fragment := range tt.fragments {
+				if !strings.Contains(string(content), fragment) {
+					t.Errorf("Expected %q in:\n%s", fragment, content)
+				}
+			}
+		})
+	}
+}
+
+func TestEmitter_Idempotent(t *testing.T) {
+	plan := newTestPlan(t)
+	dir := t.TempDir()
+
+	first, err := newTestEmitter(dir, FormatGolangMigrate).Emit(plan)
+	if err != nil {
+		t.Fatalf("Emit() error = %v", err)
+	}
+	if first.AlreadyEmitted {
+		t.Error("Expected first emit to write files")
+	}
+
+	// A later run of the same plan must not create a new version
+	emitter := newTestEmitter(dir, FormatGolangMigrate)
+	emitter.now = func() time.Time { return time.Date(2024, 3, 2, 0, 0, 0, 0, time.UTC) }
+	second, err := emitter.Emit(plan)
+	if err != nil {
+		t.Fatalf("Emit() error = %v", err)
+	}
+	if !second.AlreadyEmitted {
+		t.Error("Expected second emit to find the existing files")
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("ReadDir() error = %v", err)
+	}
+	if len(entries) != 2 {
+		t.Errorf("Expected 2 files in directory, got %d", len(entries))
+	}
+}
+
+func TestEmitter_Errors(t *testing.T) {
+	emitter := newTestEmitter(t.TempDir(), FormatGolangMigrate)
+
+	if _, err := emitter.Emit(nil); err == nil {
+		t.Error("Expected error for nil plan")
+	}
+	if _, err := emitter.Emit(migration.NewMigrationPlan()); err == nil {
+		t.Error("Expected error for empty plan")
+	}
+
+	if err := (Config{Dir: "out", Format: "rails"}).Validate(); err == nil {
+		t.Error("Expected validation error for unsupported format")
+	}
+	if err := (Config{}).Validate(); err != nil {
+		t.Errorf("Expected disabled config to be valid, got %v", err)
+	}
+}
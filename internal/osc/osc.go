@@ -0,0 +1,244 @@
+package osc
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"mysql-schema-sync/internal/database"
+	"mysql-schema-sync/internal/migration"
+	"mysql-schema-sync/internal/schema"
+)
+
+// Tool identifies an external online schema change tool
+type Tool string
+
+const (
+	ToolNone  Tool = ""
+	ToolGhost Tool = "gh-ost"
+	ToolPTOSC Tool = "pt-osc"
+)
+
+// ParseTool converts a user supplied tool name into a Tool
+func ParseTool(name string) (Tool, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "", "none":
+		return ToolNone, nil
+	case "gh-ost", "ghost":
+		return ToolGhost, nil
+	case "pt-osc", "pt-online-schema-change":
+		return ToolPTOSC, nil
+	default:
+		return ToolNone, fmt.Errorf("unsupported online schema change tool: %s (supported: gh-ost, pt-osc)", name)
+	}
+}
+
+// DefaultBinary returns the executable name used when no binary path is configured
+func (t Tool) DefaultBinary() string {
+	switch t {
+	case ToolGhost:
+		return "gh-ost"
+	case ToolPTOSC:
+		return "pt-online-schema-change"
+	default:
+		return ""
+	}
+}
+
+// Config holds the configuration for delegating large-table changes to an external tool
+type Config struct {
+	Tool        Tool     `mapstructure:"tool" yaml:"tool"`
+	ThresholdMB int64    `mapstructure:"threshold_mb" yaml:"threshold_mb"`
+	BinaryPath  string   `mapstructure:"binary_path" yaml:"binary_path"`
+	ExtraArgs   []string `mapstructure:"extra_args" yaml:"extra_args"`
+	PrintOnly   bool     `mapstructure:"print_only" yaml:"print_only"`
+	// AllowOnMaster lets gh-ost run against the primary instead of a replica
+	AllowOnMaster bool `mapstructure:"allow_on_master" yaml:"allow_on_master"`
+}
+
+// Enabled returns true if an external tool has been configured
+func (c Config) Enabled() bool {
+	return c.Tool != ToolNone
+}
+
+// Validate validates the online schema change configuration
+func (c Config) Validate() error {
+	if !c.Enabled() {
+		return nil
+	}
+
+	if _, err := ParseTool(string(c.Tool)); err != nil {
+		return err
+	}
+
+	if c.ThresholdMB < 0 {
+		return fmt.Errorf("online schema change threshold cannot be negative")
+	}
+
+	return nil
+}
+
+// thresholdBytes returns the delegation threshold in bytes
+func (c Config) thresholdBytes() int64 {
+	return c.ThresholdMB * 1024 * 1024
+}
+
+// Command represents an external tool invocation. The credentials are not part of the arguments,
+// where other users could read them; the runner passes them in a private option file.
+type Command struct {
+	Tool  Tool
+	Path  string
+	Args  []string
+	Table string
+
+	// user and password are written to the option file
+	user     string
+	password string
+}
+
+// optionFileFlag returns the flag that points the tool at an option file with the credentials
+func (t Tool) optionFileFlag() string {
+	switch t {
+	case ToolGhost:
+		return "--conf"
+	case ToolPTOSC:
+		// pt-online-schema-change only accepts it as the first option
+		return "--defaults-file"
+	default:
+		return ""
+	}
+}
+
+// hasCredentials returns true if the command needs an option file
+func (c *Command) hasCredentials() bool {
+	return c.Tool.optionFileFlag() != "" && (c.user != "" || c.password != "")
+}
+
+// optionFile returns the contents of the option file with the credentials
+func (c *Command) optionFile() string {
+	quote := func(value string) string {
+		return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(value) + `"`
+	}
+	return fmt.Sprintf("[client]\nuser=%s\npassword=%s\n", quote(c.user), quote(c.password))
+}
+
+// commandArgs returns the arguments with the option file flag for the given path
+func (c *Command) commandArgs(optionFile string) []string {
+	if !c.hasCredentials() {
+		return c.Args
+	}
+	return append([]string{c.Tool.optionFileFlag() + "=" + optionFile}, c.Args...)
+}
+
+// String returns a shell-quoted representation of the command. The option file is shown as a
+// placeholder because it only exists while the runner executes the command.
+func (c *Command) String() string {
+	args := c.commandArgs("<option file with user and password>")
+	parts := make([]string, 0, len(args)+1)
+	parts = append(parts, shellQuote(c.Path))
+	for _, arg := range args {
+		parts = append(parts, shellQuote(arg))
+	}
+	return strings.Join(parts, " ")
+}
+
+// shellQuote quotes an argument for display if it contains shell metacharacters
+func shellQuote(arg string) string {
+	if arg != "" && !strings.ContainsAny(arg, " \t\n'\"`$\\|&;<>()*?[]#~!{}") {
+		return arg
+	}
+	return "'" + strings.ReplaceAll(arg, "'", `'"'"'`) + "'"
+}
+
+// Delegator decides which statements are run through the external tool and builds their commands
+type Delegator struct {
+	config Config
+	sizes  map[string]*schema.TableSize
+}
+
+// NewDelegator creates a new Delegator using the given table sizes
+func NewDelegator(config Config, sizes map[string]*schema.TableSize) *Delegator {
+	if sizes == nil {
+		sizes = make(map[string]*schema.TableSize)
+	}
+	return &Delegator{
+		config: config,
+		sizes:  sizes,
+	}
+}
+
+// ShouldDelegate returns true if the statement alters a table at or above the size threshold
+func (d *Delegator) ShouldDelegate(stmt migration.MigrationStatement) bool {
+	if !d.config.Enabled() || stmt.TableName == "" {
+		return false
+	}
+
+	tableName, _, ok := stmt.AlterSpecification()
+	if !ok || tableName != stmt.TableName {
+		return false
+	}
+
+	size, exists := d.sizes[stmt.TableName]
+	if !exists {
+		return false
+	}
+
+	return size.TotalBytes() >= d.config.thresholdBytes()
+}
+
+// BuildCommand builds the external tool command line that applies the statement
+func (d *Delegator) BuildCommand(stmt migration.MigrationStatement, db database.DatabaseConfig) (*Command, error) {
+	tableName, clause, ok := stmt.AlterSpecification()
+	if !ok {
+		return nil, fmt.Errorf("statement cannot be expressed as an ALTER TABLE: %s", stmt.Description)
+	}
+
+	// Tools take the alteration on a single line
+	clause = strings.ReplaceAll(clause, ",\n  ", ", ")
+
+	path := d.config.BinaryPath
+	if path == "" {
+		path = d.config.Tool.DefaultBinary()
+	}
+
+	command := &Command{
+		Tool:     d.config.Tool,
+		Path:     path,
+		Table:    tableName,
+		user:     db.Username,
+		password: db.Password,
+	}
+
+	switch d.config.Tool {
+	case ToolGhost:
+		if strings.Contains(strings.ToUpper(clause), "FOREIGN KEY") {
+			return nil, fmt.Errorf("gh-ost does not support foreign key changes on table %s", tableName)
+		}
+		command.Args = []string{
+			"--host=" + db.Host,
+			"--port=" + strconv.Itoa(db.Port),
+			"--database=" + db.Database,
+			"--table=" + tableName,
+			"--alter=" + clause,
+		}
+		if d.config.AllowOnMaster {
+			command.Args = append(command.Args, "--allow-on-master")
+		}
+		command.Args = append(command.Args, "--execute")
+		command.Args = append(command.Args, d.config.ExtraArgs...)
+	case ToolPTOSC:
+		dsn := fmt.Sprintf("h=%s,P=%d,D=%s,t=%s", db.Host, db.Port, db.Database, tableName)
+		command.Args = []string{
+			"--alter", clause,
+			"--progress", "percentage,1",
+			"--execute",
+		}
+		// The DSN must come after all options
+		command.Args = append(command.Args, d.config.ExtraArgs...)
+		command.Args = append(command.Args, dsn)
+	default:
+		return nil, fmt.Errorf("no online schema change tool configured")
+	}
+
+	return command, nil
+}
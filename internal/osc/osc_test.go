@@ -0,0 +1,197 @@
+package osc
+
+import (
+	"strings"
+	"testing"
+
+	"mysql-schema-sync/internal/database"
+	"mysql-schema-sync/internal/migration"
+	"mysql-schema-sync/internal/schema"
+)
+
+func TestParseTool(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected Tool
+		wantErr  bool
+	}{
+		{input: "", expected: ToolNone},
+		{input: "gh-ost", expected: ToolGhost},
+		{input: "GHOST", expected: ToolGhost},
+		{input: "pt-osc", expected: ToolPTOSC},
+		{input: "pt-online-schema-change", expected: ToolPTOSC},
+		{input: "osc-tool", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			tool, err := ParseTool(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseTool() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tool != tt.expected {
+				t.Errorf("ParseTool() = %q, want %q", tool, tt.expected)
+			}
+		})
+	}
+}
+
+func TestDelegator_ShouldDelegate(t *testing.T) {
+	sizes := map[string]*schema.TableSize{
+		"events": {TableName: "events", DataLength: 2 * 1024 * 1024 * 1024, IndexLength: 0},
+		"users":  {TableName: "users", DataLength: 64 * 1024, IndexLength: 16 * 1024},
+	}
+	delegator := NewDelegator(Config{Tool: ToolGhost, ThresholdMB: 1024}, sizes)
+
+	tests := []struct {
+		name     string
+		stmt     migration.MigrationStatement
+		expected bool
+	}{
+		{
+			name: "large table alter",
+			stmt: migration.MigrationStatement{
+				SQL: "ALTER TABLE `events` ADD COLUMN `note` TEXT NULL", Type: migration.StatementTypeAddColumn, TableName: "events",
+			},
+			expected: true,
+		},
+		{
+			name: "large table index",
+			stmt: migration.MigrationStatement{
+				SQL: "CREATE INDEX `idx_note` ON `events` (`note`)", Type: migration.StatementTypeCreateIndex, TableName: "events",
+			},
+			expected: true,
+		},
+		{
+			name: "small table alter",
+			stmt: migration.MigrationStatement{
+				SQL: "ALTER TABLE `users` ADD COLUMN `note` TEXT NULL", Type: migration.StatementTypeAddColumn, TableName: "users",
+			},
+			expected: false,
+		},
+		{
+			name: "drop table is never delegated",
+			stmt: migration.MigrationStatement{
+				SQL: "DROP TABLE `events`", Type: migration.StatementTypeDropTable, TableName: "events",
+			},
+			expected: false,
+		},
+		{
+			name: "unknown table size",
+			stmt: migration.MigrationStatement{
+				SQL: "ALTER TABLE `logs` ADD COLUMN `note` TEXT NULL", Type: migration.StatementTypeAddColumn, TableName: "logs",
+			},
+			expected: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := delegator.ShouldDelegate(tt.stmt); got != tt.expected {
+				t.Errorf("ShouldDelegate() = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+
+	disabled := NewDelegator(Config{}, sizes)
+	if disabled.ShouldDelegate(tests[0].stmt) {
+		t.Error("Expected no delegation when no tool is configured")
+	}
+}
+
+func TestDelegator_BuildCommand(t *testing.T) {
+	db := database.DatabaseConfig{
+		Host:     "db.internal",
+		Port:     3306,
+		Username: "migrator",
+		Password: "s3cret",
+		Database: "app",
+	}
+
+	stmt := migration.MigrationStatement{
+		SQL:       "ALTER TABLE `events`\n  ADD COLUMN `note` TEXT NULL,\n  ADD INDEX `idx_type` (`type`), ALGORITHM=INPLACE, LOCK=NONE",
+		Type:      migration.StatementTypeAlterTable,
+		TableName: "events",
+	}
+
+	t.Run("gh-ost", func(t *testing.T) {
+		delegator := NewDelegator(Config{Tool: ToolGhost, AllowOnMaster: true, ExtraArgs: []string{"--max-load=Threads_running=25"}}, nil)
+		command, err := delegator.BuildCommand(stmt, db)
+		if err != nil {
+			t.Fatalf("BuildCommand() error = %v", err)
+		}
+
+		if command.Path != "gh-ost" {
+			t.Errorf("Expected gh-ost binary, got %s", command.Path)
+		}
+
+		expected := []string{
+			"--host=db.internal",
+			"--port=3306",
+			"--database=app",
+			"--table=events",
+			"--alter=ADD COLUMN `note` TEXT NULL, ADD INDEX `idx_type` (`type`)",
+			"--allow-on-master",
+			"--execute",
+			"--max-load=Threads_running=25",
+		}
+		if strings.Join(command.Args, "\n") != strings.Join(expected, "\n") {
+			t.Errorf("Unexpected args:\n%v\nwant:\n%v", command.Args, expected)
+		}
+
+		printed := command.String()
+		if strings.Contains(printed, "s3cret") || !strings.HasPrefix(printed, "gh-ost '--conf=<option file") {
+			t.Errorf("Expected credentials to be passed in an option file, got %s", printed)
+		}
+		if command.optionFile() != "[client]\nuser=\"migrator\"\npassword=\"s3cret\"\n" {
+			t.Errorf("Unexpected option file:\n%s", command.optionFile())
+		}
+	})
+
+	t.Run("gh-ost on a replica", func(t *testing.T) {
+		delegator := NewDelegator(Config{Tool: ToolGhost}, nil)
+		command, err := delegator.BuildCommand(stmt, db)
+		if err != nil {
+			t.Fatalf("BuildCommand() error = %v", err)
+		}
+		for _, arg := range command.Args {
+			if arg == "--allow-on-master" {
+				t.Error("Expected --allow-on-master only when allow_on_master is set")
+			}
+		}
+	})
+
+	t.Run("pt-osc", func(t *testing.T) {
+		delegator := NewDelegator(Config{Tool: ToolPTOSC, BinaryPath: "/usr/local/bin/pt-online-schema-change"}, nil)
+		command, err := delegator.BuildCommand(stmt, db)
+		if err != nil {
+			t.Fatalf("BuildCommand() error = %v", err)
+		}
+
+		if command.Path != "/usr/local/bin/pt-online-schema-change" {
+			t.Errorf("Expected configured binary path, got %s", command.Path)
+		}
+
+		last := command.Args[len(command.Args)-1]
+		if last != "h=db.internal,P=3306,D=app,t=events" {
+			t.Errorf("Expected DSN as last argument, got %s", last)
+		}
+
+		args := command.commandArgs("/tmp/osc.cnf")
+		if args[0] != "--defaults-file=/tmp/osc.cnf" || strings.Contains(strings.Join(args, " "), "s3cret") {
+			t.Errorf("Expected credentials to be passed in an option file first, got %v", args)
+		}
+	})
+
+	t.Run("gh-ost rejects foreign keys", func(t *testing.T) {
+		delegator := NewDelegator(Config{Tool: ToolGhost}, nil)
+		fkStmt := migration.MigrationStatement{
+			SQL:       "ALTER TABLE `events` ADD CONSTRAINT `fk_user` FOREIGN KEY (`user_id`) REFERENCES `users` (`id`)",
+			Type:      migration.StatementTypeAddConstraint,
+			TableName: "events",
+		}
+		if _, err := delegator.BuildCommand(fkStmt, db); err == nil {
+			t.Error("Expected error for foreign key change with gh-ost")
+		}
+	})
+}
@@ -0,0 +1,155 @@
+package osc
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"mysql-schema-sync/internal/logging"
+)
+
+// ProgressFunc receives the completion percentage parsed from the tool output
+type ProgressFunc func(percent float64, line string)
+
+// Runner executes external online schema change commands
+type Runner struct {
+	logger *logging.Logger
+}
+
+// NewRunner creates a new Runner
+func NewRunner(logger *logging.Logger) *Runner {
+	if logger == nil {
+		logger = logging.NewDefaultLogger()
+	}
+	return &Runner{logger: logger}
+}
+
+// progressPattern matches the percentage reported by gh-ost ("Copy: 100/1000 10.0%")
+// and pt-online-schema-change ("Copying `db`.`t`:  45% 00:30 remain")
+var progressPattern = regexp.MustCompile(`(\d+(?:\.\d+)?)%`)
+
+// maxErrorLines is the number of trailing output lines included in a failure message
+const maxErrorLines = 5
+
+// Run executes the command, streaming progress to the callback, and returns an error if the
+// tool exits with a non-zero status
+func (r *Runner) Run(ctx context.Context, command *Command, progress ProgressFunc) error {
+	if command == nil {
+		return fmt.Errorf("command cannot be nil")
+	}
+
+	r.logger.WithFields(map[string]interface{}{
+		"tool":    string(command.Tool),
+		"table":   command.Table,
+		"command": command.String(),
+	}).Info("Running online schema change tool")
+
+	args := command.Args
+	if command.hasCredentials() {
+		optionFile, err := writeOptionFile(command)
+		if err != nil {
+			return err
+		}
+		defer os.Remove(optionFile)
+		args = command.commandArgs(optionFile)
+	}
+
+	cmd := exec.CommandContext(ctx, command.Path, args...)
+
+	reader, writer := io.Pipe()
+	cmd.Stdout = writer
+	cmd.Stderr = writer
+
+	if err := cmd.Start(); err != nil {
+		writer.Close()
+		return fmt.Errorf("failed to start %s: %w", command.Tool, err)
+	}
+
+	// Consume output while the tool runs
+	lastLines := make([]string, 0, maxErrorLines)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		scanner := bufio.NewScanner(reader)
+		for scanner.Scan() {
+			line := strings.TrimSpace(scanner.Text())
+			if line == "" {
+				continue
+			}
+
+			r.logger.WithField("tool", string(command.Tool)).Debug(line)
+
+			if len(lastLines) == maxErrorLines {
+				lastLines = lastLines[1:]
+			}
+			lastLines = append(lastLines, line)
+
+			if percent, ok := parseProgress(line); ok && progress != nil {
+				progress(percent, line)
+			}
+		}
+		// Drain anything left so the tool never blocks on a full pipe
+		io.Copy(io.Discard, reader)
+	}()
+
+	waitErr := cmd.Wait()
+	writer.Close()
+	<-done
+
+	if waitErr != nil {
+		var exitErr *exec.ExitError
+		if errors.As(waitErr, &exitErr) {
+			return fmt.Errorf("%s exited with code %d: %s",
+				command.Tool, exitErr.ExitCode(), strings.Join(lastLines, "; "))
+		}
+		return fmt.Errorf("%s failed: %w", command.Tool, waitErr)
+	}
+
+	if progress != nil {
+		progress(100, "completed")
+	}
+
+	return nil
+}
+
+// writeOptionFile writes the credentials of the command to a temporary option file that only the
+// current user can read and returns its path
+func writeOptionFile(command *Command) (string, error) {
+	file, err := os.CreateTemp("", "mysql-schema-sync-osc-*.cnf")
+	if err != nil {
+		return "", fmt.Errorf("failed to create option file for %s: %w", command.Tool, err)
+	}
+
+	// CreateTemp creates the file with mode 0600
+	_, err = file.WriteString(command.optionFile())
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(file.Name())
+		return "", fmt.Errorf("failed to write option file for %s: %w", command.Tool, err)
+	}
+
+	return file.Name(), nil
+}
+
+// parseProgress extracts a completion percentage from a line of tool output
+func parseProgress(line string) (float64, bool) {
+	matches := progressPattern.FindStringSubmatch(line)
+	if matches == nil {
+		return 0, false
+	}
+
+	percent, err := strconv.ParseFloat(matches[1], 64)
+	if err != nil || percent > 100 {
+		return 0, false
+	}
+	return percent, true
+}
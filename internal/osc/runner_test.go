@@ -0,0 +1,95 @@
+package osc
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRunner_Run(t *testing.T) {
+	runner := NewRunner(nil)
+
+	command := &Command{
+		Tool: ToolGhost,
+		Path: "sh",
+		Args: []string{"-c", "echo 'Copy: 10/100 10.0%; Applied: 0'; echo 'Copying `app`.`events`:  45% 00:30 remain' >&2"},
+	}
+
+	var percents []float64
+	err := runner.Run(context.Background(), command, func(percent float64, line string) {
+		percents = append(percents, percent)
+	})
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	if len(percents) != 3 || percents[0] != 10 || percents[1] != 45 || percents[2] != 100 {
+		t.Errorf("Unexpected progress updates: %v", percents)
+	}
+}
+
+func TestRunner_RunFailure(t *testing.T) {
+	runner := NewRunner(nil)
+
+	command := &Command{
+		Tool: ToolPTOSC,
+		Path: "sh",
+		Args: []string{"-c", "echo 'Error altering new table' >&2; exit 3"},
+	}
+
+	err := runner.Run(context.Background(), command, nil)
+	if err == nil {
+		t.Fatal("Expected error for non-zero exit code")
+	}
+
+	if !strings.Contains(err.Error(), "exited with code 3") || !strings.Contains(err.Error(), "Error altering new table") {
+		t.Errorf("Unexpected error message: %v", err)
+	}
+}
+
+func TestRunner_RunOptionFile(t *testing.T) {
+	runner := NewRunner(nil)
+
+	// The tool prints the option file it was given and fails so that the output is reported
+	script := filepath.Join(t.TempDir(), "tool.sh")
+	content := "#!/bin/sh\npath=${1#--conf=}\nstat -c %a \"$path\"\ncat \"$path\"\necho \"$path\"\nexit 1\n"
+	if err := os.WriteFile(script, []byte(content), 0700); err != nil {
+		t.Fatalf("Failed to write tool script: %v", err)
+	}
+
+	command := &Command{Tool: ToolGhost, Path: script, user: "migrator", password: `p"w#d`}
+	err := runner.Run(context.Background(), command, nil)
+	if err == nil {
+		t.Fatal("Expected error for non-zero exit code")
+	}
+
+	lines := strings.Split(strings.TrimPrefix(err.Error(), "gh-ost exited with code 1: "), "; ")
+	expected := []string{"600", "[client]", `user="migrator"`, `password="p\"w#d"`}
+	if len(lines) != len(expected)+1 || strings.Join(lines[:len(expected)], "\n") != strings.Join(expected, "\n") {
+		t.Fatalf("Unexpected option file: %q", lines)
+	}
+	if _, err := os.Stat(lines[len(expected)]); !os.IsNotExist(err) {
+		t.Errorf("Expected the option file to be removed, got %v", err)
+	}
+}
+
+func TestParseProgress(t *testing.T) {
+	tests := []struct {
+		line     string
+		expected float64
+		ok       bool
+	}{
+		{line: "Copy: 500/1000 50.0%; Applied: 12; Backlog: 0/1000", expected: 50, ok: true},
+		{line: "Copying `app`.`events`:  99% 00:01 remain", expected: 99, ok: true},
+		{line: "Migrating `app`.`events`; Ghost table is `app`.`_events_gho`", ok: false},
+	}
+
+	for _, tt := range tests {
+		percent, ok := parseProgress(tt.line)
+		if ok != tt.ok || percent != tt.expected {
+			t.Errorf("parseProgress(%q) = %v, %v; want %v, %v", tt.line, percent, ok, tt.expected, tt.ok)
+		}
+	}
+}
@@ -0,0 +1,117 @@
+package planfile
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"mysql-schema-sync/internal/migration"
+	"mysql-schema-sync/internal/schema"
+)
+
+// FormatVersion is the version of the saved plan file layout
+const FormatVersion = 1
+
+// PlanFile is a reviewed migration plan saved together with the fingerprints of the schemas it
+// was computed from, so that it is only ever applied to the target it was planned against
+type PlanFile struct {
+	Version           int                      `json:"version"`
+	CreatedAt         time.Time                `json:"created_at"`
+	SourceDatabase    string                   `json:"source_database"`
+	TargetDatabase    string                   `json:"target_database"`
+	SourceFingerprint string                   `json:"source_fingerprint"`
+	TargetFingerprint string                   `json:"target_fingerprint"`
+	PlanHash          string                   `json:"plan_hash"`
+	Plan              *migration.MigrationPlan `json:"plan"`
+}
+
+// New creates a PlanFile for a plan computed from the given source and target schemas
+func New(plan *migration.MigrationPlan, source, target *schema.Schema) *PlanFile {
+	return &PlanFile{
+		Version:           FormatVersion,
+		CreatedAt:         time.Now().UTC(),
+		SourceDatabase:    source.Name,
+		TargetDatabase:    target.Name,
+		SourceFingerprint: source.Fingerprint(),
+		TargetFingerprint: target.Fingerprint(),
+		PlanHash:          plan.Hash(),
+		Plan:              plan,
+	}
+}
+
+// Save writes the plan file as indented JSON
+func (pf *PlanFile) Save(path string) error {
+	data, err := json.MarshalIndent(pf, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode plan file: %w", err)
+	}
+
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write plan file %s: %w", path, err)
+	}
+
+	return nil
+}
+
+// Load reads a plan file and verifies that the plan has not been edited since it was saved
+func Load(path string) (*PlanFile, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read plan file %s: %w", path, err)
+	}
+
+	var pf PlanFile
+	if err := json.Unmarshal(data, &pf); err != nil {
+		return nil, fmt.Errorf("failed to decode plan file %s: %w", path, err)
+	}
+
+	if err := pf.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid plan file %s: %w", path, err)
+	}
+
+	return &pf, nil
+}
+
+// Validate checks the plan file version and that the plan still matches its recorded hash
+func (pf *PlanFile) Validate() error {
+	if pf.Version != FormatVersion {
+		return fmt.Errorf("unsupported plan file version %d (expected %d)", pf.Version, FormatVersion)
+	}
+
+	if pf.Plan == nil {
+		return fmt.Errorf("plan file contains no migration plan")
+	}
+
+	if pf.TargetFingerprint == "" {
+		return fmt.Errorf("plan file has no target schema fingerprint")
+	}
+
+	if hash := pf.Plan.Hash(); hash != pf.PlanHash {
+		return fmt.Errorf("migration plan does not match its recorded hash (recorded %s, computed %s)", pf.PlanHash, hash)
+	}
+
+	return pf.Plan.Validate()
+}
+
+// CheckTarget returns an error if the target schema changed since the plan was created
+func (pf *PlanFile) CheckTarget(current *schema.Schema) error {
+	if current == nil {
+		return fmt.Errorf("current target schema cannot be nil")
+	}
+
+	if fingerprint := current.Fingerprint(); fingerprint != pf.TargetFingerprint {
+		return fmt.Errorf("target schema %s changed since the plan was created at %s (fingerprint %s, now %s); create a new plan",
+			current.Name, pf.CreatedAt.Format(time.RFC3339), shortFingerprint(pf.TargetFingerprint), shortFingerprint(fingerprint))
+	}
+
+	return nil
+}
+
+// shortFingerprint abbreviates a fingerprint for messages
+func shortFingerprint(fingerprint string) string {
+	if len(fingerprint) > 12 {
+		return fingerprint[:12]
+	}
+	return fingerprint
+}
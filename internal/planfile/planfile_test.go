@@ -0,0 +1,154 @@
+package planfile
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"mysql-schema-sync/internal/migration"
+	"mysql-schema-sync/internal/schema"
+)
+
+// newTestSchemas builds a source schema with an extra column and the target it was planned against
+func newTestSchemas() (*schema.Schema, *schema.Schema) {
+	source := schema.NewSchema("source_db")
+	users := schema.NewTable("users")
+	users.AddColumn(&schema.Column{Name: "id", DataType: "INT", IsNullable: false})
+	users.AddColumn(&schema.Column{Name: "email", DataType: "VARCHAR(255)", IsNullable: true})
+	source.AddTable(users)
+
+	target := schema.NewSchema("target_db")
+	targetUsers := schema.NewTable("users")
+	targetUsers.AddColumn(&schema.Column{Name: "id", DataType: "INT", IsNullable: false})
+	target.AddTable(targetUsers)
+
+	return source, target
+}
+
+// newTestPlan builds a plan with a single statement
+func newTestPlan() *migration.MigrationPlan {
+	plan := migration.NewMigrationPlan()
+	stmt := migration.NewMigrationStatement("ALTER TABLE `users` ADD COLUMN `email` VARCHAR(255) NULL",
+		migration.StatementTypeAddColumn, "Add column email to table users")
+	stmt.TableName = "users"
+	plan.AddStatement(*stmt)
+	return plan
+}
+
+func TestPlanFile_SaveAndLoad(t *testing.T) {
+	source, target := newTestSchemas()
+	plan := newTestPlan()
+	path := filepath.Join(t.TempDir(), "plan.json")
+
+	if err := New(plan, source, target).Save(path); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	loaded, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	if loaded.SourceDatabase != "source_db" || loaded.TargetDatabase != "target_db" {
+		t.Errorf("Unexpected databases: %s, %s", loaded.SourceDatabase, loaded.TargetDatabase)
+	}
+	if loaded.SourceFingerprint != source.Fingerprint() || loaded.TargetFingerprint != target.Fingerprint() {
+		t.Errorf("Fingerprints were not preserved")
+	}
+	if loaded.PlanHash != plan.Hash() {
+		t.Errorf("Expected plan hash %s, got %s", plan.Hash(), loaded.PlanHash)
+	}
+	if len(loaded.Plan.Statements) != 1 || loaded.Plan.Statements[0].SQL != plan.Statements[0].SQL {
+		t.Errorf("Statements were not preserved: %+v", loaded.Plan.Statements)
+	}
+}
+
+func TestLoad_RejectsEditedPlan(t *testing.T) {
+	source, target := newTestSchemas()
+	path := filepath.Join(t.TempDir(), "plan.json")
+
+	if err := New(newTestPlan(), source, target).Save(path); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	edited := strings.Replace(string(data), "VARCHAR(255)", "TEXT", 1)
+	if err := os.WriteFile(path, []byte(edited), 0644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	if _, err := Load(path); err == nil || !strings.Contains(err.Error(), "does not match its recorded hash") {
+		t.Errorf("Expected a hash mismatch error, got %v", err)
+	}
+}
+
+func TestPlanFile_Validate(t *testing.T) {
+	source, target := newTestSchemas()
+
+	tests := []struct {
+		name    string
+		modify  func(pf *PlanFile)
+		wantErr string
+	}{
+		{
+			name:   "valid",
+			modify: func(pf *PlanFile) {},
+		},
+		{
+			name:    "unsupported version",
+			modify:  func(pf *PlanFile) { pf.Version = FormatVersion + 1 },
+			wantErr: "unsupported plan file version",
+		},
+		{
+			name:    "missing plan",
+			modify:  func(pf *PlanFile) { pf.Plan = nil },
+			wantErr: "no migration plan",
+		},
+		{
+			name:    "missing target fingerprint",
+			modify:  func(pf *PlanFile) { pf.TargetFingerprint = "" },
+			wantErr: "no target schema fingerprint",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pf := New(newTestPlan(), source, target)
+			tt.modify(pf)
+
+			err := pf.Validate()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("Validate() error = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Validate() error = %v, want error containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestPlanFile_CheckTarget(t *testing.T) {
+	source, target := newTestSchemas()
+	pf := New(newTestPlan(), source, target)
+
+	if err := pf.CheckTarget(target); err != nil {
+		t.Errorf("CheckTarget() on unchanged target error = %v", err)
+	}
+
+	changed := schema.NewSchema("target_db")
+	users := schema.NewTable("users")
+	users.AddColumn(&schema.Column{Name: "id", DataType: "BIGINT", IsNullable: false})
+	changed.AddTable(users)
+
+	err := pf.CheckTarget(changed)
+	if err == nil || !strings.Contains(err.Error(), "changed since the plan was created") {
+		t.Errorf("Expected a drift error, got %v", err)
+	}
+}
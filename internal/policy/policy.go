@@ -0,0 +1,477 @@
+package policy
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"mysql-schema-sync/internal/migration"
+	"mysql-schema-sync/internal/schema"
+)
+
+// Severity decides whether a rule violation blocks the migration or is only reported
+type Severity string
+
+const (
+	SeverityDeny Severity = "deny"
+	SeverityWarn Severity = "warn"
+)
+
+// RuleType identifies the check a rule performs
+type RuleType string
+
+const (
+	// RuleNoDropTable rejects plans that drop tables
+	RuleNoDropTable RuleType = "no_drop_table"
+	// RuleNoDropColumn rejects plans that drop columns
+	RuleNoDropColumn RuleType = "no_drop_column"
+	// RuleRequirePrimaryKey rejects new tables without a primary key
+	RuleRequirePrimaryKey RuleType = "require_primary_key"
+	// RuleNoModifyColumn rejects column modifications on tables at least MinTableSizeMB large
+	RuleNoModifyColumn RuleType = "no_modify_column"
+	// RuleForeignKeyName rejects new foreign keys whose name does not match Pattern
+	RuleForeignKeyName RuleType = "foreign_key_name"
+)
+
+// DefaultForeignKeyPattern is the naming convention used by foreign_key_name rules without a pattern
+const DefaultForeignKeyPattern = "^fk_{table}_{column}$"
+
+// Config holds the configuration for enforcing a policy file
+type Config struct {
+	// File is the YAML policy file; no policy is enforced when it is empty
+	File string `mapstructure:"file" yaml:"file"`
+	// TargetTags describe the target database, e.g. prod, and select the rules that apply to it
+	TargetTags []string `mapstructure:"target_tags" yaml:"target_tags"`
+	// AuditLog is the file every policy override is appended to as a JSON line
+	AuditLog string `mapstructure:"audit_log" yaml:"audit_log"`
+	// Override is the reason given for running a plan that violates deny rules. It is only taken
+	// from the command line so that overrides are always explicit.
+	Override string `mapstructure:"-" yaml:"-"`
+}
+
+// Enabled returns true if a policy file has been configured
+func (c Config) Enabled() bool {
+	return c.File != ""
+}
+
+// Validate validates the policy configuration
+func (c Config) Validate() error {
+	if c.Override != "" && strings.TrimSpace(c.Override) == "" {
+		return fmt.Errorf("policy override requires a reason")
+	}
+	if c.Override != "" && !c.Enabled() {
+		return fmt.Errorf("policy override given but no policy file is configured")
+	}
+	return nil
+}
+
+// Rule is a single guardrail of a policy
+type Rule struct {
+	Name     string   `yaml:"name"`
+	Type     RuleType `yaml:"type"`
+	Severity Severity `yaml:"severity"`
+	// TargetTags limits the rule to targets carrying at least one of these tags
+	TargetTags []string `yaml:"target_tags,omitempty"`
+	// MinTableSizeMB is the table size from which no_modify_column applies
+	MinTableSizeMB int64 `yaml:"min_table_size_mb,omitempty"`
+	// Pattern is the regular expression foreign key names must match. {table} and {column} are
+	// replaced by the table name and the underscore-joined column names.
+	Pattern string `yaml:"pattern,omitempty"`
+	// Message explains the rule and is prepended to every violation it reports
+	Message string `yaml:"message,omitempty"`
+}
+
+// Policy is a set of rules evaluated against every schema diff and migration plan
+type Policy struct {
+	Rules []Rule `yaml:"rules"`
+}
+
+// Load reads and validates a YAML policy file
+func Load(path string) (*Policy, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read policy file %s: %w", path, err)
+	}
+
+	p, err := Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid policy file %s: %w", path, err)
+	}
+
+	return p, nil
+}
+
+// Parse decodes and validates a YAML policy
+func Parse(data []byte) (*Policy, error) {
+	var p Policy
+	decoder := yaml.NewDecoder(strings.NewReader(string(data)))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&p); err != nil {
+		return nil, fmt.Errorf("failed to decode policy: %w", err)
+	}
+
+	if err := p.Validate(); err != nil {
+		return nil, err
+	}
+
+	return &p, nil
+}
+
+// Validate checks that every rule is complete and that rule names are unique
+func (p *Policy) Validate() error {
+	names := make(map[string]bool)
+	for i, rule := range p.Rules {
+		if rule.Name == "" {
+			return fmt.Errorf("rule %d has no name", i+1)
+		}
+		if names[rule.Name] {
+			return fmt.Errorf("duplicate rule name: %s", rule.Name)
+		}
+		names[rule.Name] = true
+
+		switch rule.Type {
+		case RuleNoDropTable, RuleNoDropColumn, RuleRequirePrimaryKey, RuleNoModifyColumn, RuleForeignKeyName:
+		default:
+			return fmt.Errorf("rule %s has unsupported type %q", rule.Name, rule.Type)
+		}
+
+		switch rule.Severity {
+		case SeverityDeny, SeverityWarn:
+		default:
+			return fmt.Errorf("rule %s has unsupported severity %q (supported: deny, warn)", rule.Name, rule.Severity)
+		}
+
+		if rule.MinTableSizeMB < 0 {
+			return fmt.Errorf("rule %s has a negative min_table_size_mb", rule.Name)
+		}
+
+		if rule.Type == RuleForeignKeyName {
+			if _, err := rule.foreignKeyPattern("t", "c"); err != nil {
+				return fmt.Errorf("rule %s has an invalid pattern: %w", rule.Name, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// NeedsTableSizes returns true if any rule needs the target table sizes to be evaluated
+func (p *Policy) NeedsTableSizes() bool {
+	for _, rule := range p.Rules {
+		if rule.Type == RuleNoModifyColumn && rule.MinTableSizeMB > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// Target describes the database a plan is evaluated for
+type Target struct {
+	Tags       []string
+	TableSizes map[string]*schema.TableSize
+}
+
+// Evaluate checks the schema diff and the migration plan against every rule that applies to the
+// target. Deny rules produce validation errors and warn rules produce warnings.
+func (p *Policy) Evaluate(diff *schema.SchemaDiff, plan *migration.MigrationPlan, target Target) *schema.ValidationResult {
+	result := &schema.ValidationResult{
+		IsValid:  true,
+		Warnings: make([]schema.Warning, 0),
+		Errors:   make([]schema.ValidationError, 0),
+	}
+
+	for _, rule := range p.Rules {
+		if !rule.appliesTo(target.Tags) {
+			continue
+		}
+
+		for _, v := range rule.check(diff, plan, target) {
+			rule.report(v, result)
+		}
+	}
+
+	result.IsValid = len(result.Errors) == 0
+	return result
+}
+
+// violation is a single object breaking a rule
+type violation struct {
+	table   string
+	column  string
+	message string
+}
+
+// appliesTo returns true if the rule has no tag filter or the target carries one of its tags
+func (r Rule) appliesTo(tags []string) bool {
+	if len(r.TargetTags) == 0 {
+		return true
+	}
+	for _, want := range r.TargetTags {
+		for _, tag := range tags {
+			if strings.EqualFold(want, tag) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// check returns the violations of the rule
+func (r Rule) check(diff *schema.SchemaDiff, plan *migration.MigrationPlan, target Target) []violation {
+	switch r.Type {
+	case RuleNoDropTable:
+		return r.checkStatements(plan, migration.StatementTypeDropTable, true, nil, "drops table '%s'")
+	case RuleNoDropColumn:
+		return r.checkStatements(plan, migration.StatementTypeDropColumn, true, nil, "drops a column of table '%s'")
+	case RuleNoModifyColumn:
+		large := func(table string) bool {
+			if r.MinTableSizeMB == 0 {
+				return true
+			}
+			size, ok := target.TableSizes[table]
+			return ok && size.TotalBytes() >= r.MinTableSizeMB*1024*1024
+		}
+		return r.checkStatements(plan, migration.StatementTypeModifyColumn, false, large, "modifies a column of table '%s'")
+	case RuleRequirePrimaryKey:
+		return r.checkPrimaryKeys(diff)
+	case RuleForeignKeyName:
+		return r.checkForeignKeyNames(diff)
+	}
+	return nil
+}
+
+// checkStatements reports the tables touched by plan statements, or merged ALTER TABLE
+// operations, of the given type. Non-destructive statements such as safe-drop renames are
+// ignored when destructiveOnly is set.
+func (r Rule) checkStatements(plan *migration.MigrationPlan, stmtType migration.StatementType, destructiveOnly bool, match func(table string) bool, format string) []violation {
+	if plan == nil {
+		return nil
+	}
+
+	seen := make(map[string]bool)
+	var violations []violation
+	for _, stmt := range plan.Statements {
+		matched := stmt.Type == stmtType && (stmt.IsDestructive || !destructiveOnly)
+		for _, op := range stmt.Operations {
+			if op.Type == stmtType && (op.IsDestructive || !destructiveOnly) {
+				matched = true
+			}
+		}
+		if !matched || seen[stmt.TableName] {
+			continue
+		}
+		if match != nil && !match(stmt.TableName) {
+			continue
+		}
+
+		seen[stmt.TableName] = true
+		violations = append(violations, violation{
+			table:   stmt.TableName,
+			message: fmt.Sprintf("Plan "+format, stmt.TableName),
+		})
+	}
+	return violations
+}
+
+// checkPrimaryKeys reports new tables without a primary key
+func (r Rule) checkPrimaryKeys(diff *schema.SchemaDiff) []violation {
+	if diff == nil {
+		return nil
+	}
+
+	var violations []violation
+	for _, table := range diff.AddedTables {
+		if hasPrimaryKey(table) {
+			continue
+		}
+		violations = append(violations, violation{
+			table:   table.Name,
+			message: fmt.Sprintf("New table '%s' has no primary key", table.Name),
+		})
+	}
+	return violations
+}
+
+// hasPrimaryKey returns true if the table has a primary index
+func hasPrimaryKey(table *schema.Table) bool {
+	for _, index := range table.Indexes {
+		if index.IsPrimary {
+			return true
+		}
+	}
+	return false
+}
+
+// checkForeignKeyNames reports new foreign keys whose name does not follow the pattern
+func (r Rule) checkForeignKeyNames(diff *schema.SchemaDiff) []violation {
+	if diff == nil {
+		return nil
+	}
+
+	constraints := append([]*schema.Constraint{}, diff.AddedConstraints...)
+	for _, table := range diff.AddedTables {
+		names := make([]string, 0, len(table.Constraints))
+		for name := range table.Constraints {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			constraint := table.Constraints[name]
+			if constraint.TableName == "" {
+				constraint = copyWithTable(constraint, table.Name)
+			}
+			constraints = append(constraints, constraint)
+		}
+	}
+	for _, tableDiff := range diff.ModifiedTables {
+		for _, constraint := range tableDiff.AddedConstraints {
+			if constraint.TableName == "" {
+				constraint = copyWithTable(constraint, tableDiff.TableName)
+			}
+			constraints = append(constraints, constraint)
+		}
+	}
+
+	seen := make(map[string]bool)
+	var violations []violation
+	for _, constraint := range constraints {
+		if constraint.Type != schema.ConstraintTypeForeignKey {
+			continue
+		}
+		key := constraint.TableName + "." + constraint.Name
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		column := strings.Join(constraint.Columns, "_")
+		pattern, err := r.foreignKeyPattern(constraint.TableName, column)
+		if err != nil || pattern.MatchString(constraint.Name) {
+			continue
+		}
+		violations = append(violations, violation{
+			table:  constraint.TableName,
+			column: column,
+			message: fmt.Sprintf("Foreign key '%s' on table '%s' does not match naming pattern %s",
+				constraint.Name, constraint.TableName, r.pattern()),
+		})
+	}
+	return violations
+}
+
+// copyWithTable returns a copy of the constraint attributed to the given table
+func copyWithTable(constraint *schema.Constraint, table string) *schema.Constraint {
+	c := *constraint
+	c.TableName = table
+	return &c
+}
+
+// pattern returns the configured foreign key pattern or the default one
+func (r Rule) pattern() string {
+	if r.Pattern == "" {
+		return DefaultForeignKeyPattern
+	}
+	return r.Pattern
+}
+
+// foreignKeyPattern compiles the foreign key pattern for a table and its columns
+func (r Rule) foreignKeyPattern(table, column string) (*regexp.Regexp, error) {
+	expr := strings.NewReplacer(
+		"{table}", regexp.QuoteMeta(table),
+		"{column}", regexp.QuoteMeta(column),
+	).Replace(r.pattern())
+	return regexp.Compile(expr)
+}
+
+// report adds a violation to the result as an error or a warning depending on the rule severity
+func (r Rule) report(v violation, result *schema.ValidationResult) {
+	message := v.message
+	if r.Message != "" {
+		message = fmt.Sprintf("%s: %s", r.Message, v.message)
+	}
+	message = fmt.Sprintf("[%s] %s", r.Name, message)
+
+	if r.Severity == SeverityDeny {
+		result.Errors = append(result.Errors, schema.ValidationError{
+			Type:       schema.ErrorTypePolicy,
+			Message:    message,
+			TableName:  v.table,
+			ColumnName: v.column,
+			Details:    fmt.Sprintf("denied by policy rule %s (%s)", r.Name, r.Type),
+		})
+		return
+	}
+
+	result.Warnings = append(result.Warnings, schema.Warning{
+		Type:       schema.WarningTypePolicy,
+		Severity:   schema.SeverityHigh,
+		Message:    message,
+		TableName:  v.table,
+		ColumnName: v.column,
+		Suggestion: fmt.Sprintf("Review the change against policy rule %s", r.Name),
+	})
+}
+
+// Override records a deliberate decision to run a plan that violates deny rules
+type Override struct {
+	Time       time.Time `json:"time"`
+	User       string    `json:"user"`
+	Target     string    `json:"target"`
+	Reason     string    `json:"reason"`
+	PlanHash   string    `json:"plan_hash"`
+	Violations []string  `json:"violations"`
+}
+
+// NewOverride creates an override record for the denied violations of a validation result
+func NewOverride(reason, target string, plan *migration.MigrationPlan, result *schema.ValidationResult) Override {
+	override := Override{
+		Time:       time.Now().UTC(),
+		User:       currentUser(),
+		Target:     target,
+		Reason:     reason,
+		Violations: make([]string, 0, len(result.Errors)),
+	}
+	if plan != nil {
+		override.PlanHash = plan.Hash()
+	}
+	for _, err := range result.Errors {
+		override.Violations = append(override.Violations, err.Message)
+	}
+	return override
+}
+
+// AppendAudit appends the override to the audit log as a single JSON line
+func AppendAudit(path string, override Override) error {
+	data, err := json.Marshal(override)
+	if err != nil {
+		return fmt.Errorf("failed to encode policy override: %w", err)
+	}
+
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open policy audit log %s: %w", path, err)
+	}
+	defer file.Close()
+
+	if _, err := file.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("failed to write policy audit log %s: %w", path, err)
+	}
+
+	return nil
+}
+
+// currentUser returns the name of the user running the tool
+func currentUser() string {
+	for _, key := range []string{"USER", "USERNAME", "LOGNAME"} {
+		if user := os.Getenv(key); user != "" {
+			return user
+		}
+	}
+	return "unknown"
+}
@@ -0,0 +1,227 @@
+package policy
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"mysql-schema-sync/internal/migration"
+	"mysql-schema-sync/internal/schema"
+)
+
+const testPolicy = `
+rules:
+  - name: no-prod-table-drops
+    type: no_drop_table
+    severity: deny
+    target_tags: [prod]
+  - name: primary-keys
+    type: require_primary_key
+    severity: deny
+  - name: large-table-modifications
+    type: no_modify_column
+    severity: warn
+    min_table_size_mb: 10240
+  - name: fk-names
+    type: foreign_key_name
+    severity: warn
+`
+
+func TestParse(t *testing.T) {
+	p, err := Parse([]byte(testPolicy))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if len(p.Rules) != 4 {
+		t.Fatalf("Expected 4 rules, got %d", len(p.Rules))
+	}
+	if !p.NeedsTableSizes() {
+		t.Error("Expected a size-limited no_modify_column rule to need table sizes")
+	}
+
+	invalid := []struct {
+		name   string
+		policy string
+	}{
+		{"missing name", "rules:\n  - type: no_drop_table\n    severity: deny\n"},
+		{"duplicate name", "rules:\n  - {name: a, type: no_drop_table, severity: deny}\n  - {name: a, type: no_drop_column, severity: deny}\n"},
+		{"unknown type", "rules:\n  - {name: a, type: no_truncate, severity: deny}\n"},
+		{"unknown severity", "rules:\n  - {name: a, type: no_drop_table, severity: block}\n"},
+		{"unknown field", "rules:\n  - {name: a, type: no_drop_table, severity: deny, tables: [x]}\n"},
+		{"bad pattern", "rules:\n  - {name: a, type: foreign_key_name, severity: warn, pattern: \"fk_(\"}\n"},
+	}
+	for _, tt := range invalid {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Parse([]byte(tt.policy)); err == nil {
+				t.Error("Expected Parse() to fail")
+			}
+		})
+	}
+}
+
+func TestLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "policy.yaml")
+	if err := os.WriteFile(path, []byte(testPolicy), 0644); err != nil {
+		t.Fatalf("Failed to write policy: %v", err)
+	}
+
+	if _, err := Load(path); err != nil {
+		t.Errorf("Load() error = %v", err)
+	}
+	if _, err := Load(filepath.Join(t.TempDir(), "missing.yaml")); err == nil {
+		t.Error("Expected Load() to fail for a missing file")
+	}
+}
+
+func TestPolicy_Evaluate(t *testing.T) {
+	p, err := Parse([]byte(testPolicy))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	noKey := schema.NewTable("events")
+	noKey.AddColumn(&schema.Column{Name: "payload", DataType: "JSON"})
+
+	orders := schema.NewTable("orders")
+	orders.AddIndex(&schema.Index{Name: "PRIMARY", TableName: "orders", Columns: []string{"id"}, IsPrimary: true})
+	orders.AddConstraint(&schema.Constraint{Name: "orders_ibfk_1", TableName: "orders", Type: schema.ConstraintTypeForeignKey,
+		Columns: []string{"user_id"}, ReferencedTable: "users", ReferencedColumns: []string{"id"}})
+	orders.AddConstraint(&schema.Constraint{Name: "fk_orders_shop_id", TableName: "orders", Type: schema.ConstraintTypeForeignKey,
+		Columns: []string{"shop_id"}, ReferencedTable: "shops", ReferencedColumns: []string{"id"}})
+
+	diff := &schema.SchemaDiff{AddedTables: []*schema.Table{noKey, orders}}
+	plan := &migration.MigrationPlan{Statements: []migration.MigrationStatement{
+		{SQL: "DROP TABLE `sessions`", Type: migration.StatementTypeDropTable, TableName: "sessions", IsDestructive: true},
+		{SQL: "RENAME TABLE `tokens` TO `_archived_20260101000000_tokens`", Type: migration.StatementTypeDropTable, TableName: "tokens"},
+		{SQL: "ALTER TABLE `logs` MODIFY COLUMN `msg` TEXT", Type: migration.StatementTypeModifyColumn, TableName: "logs"},
+		{SQL: "ALTER TABLE `tags` MODIFY COLUMN `name` TEXT", Type: migration.StatementTypeModifyColumn, TableName: "tags"},
+	}}
+	sizes := map[string]*schema.TableSize{
+		"logs": {TableName: "logs", DataLength: 11 * 1024 * 1024 * 1024},
+		"tags": {TableName: "tags", DataLength: 1024},
+	}
+
+	t.Run("prod target", func(t *testing.T) {
+		result := p.Evaluate(diff, plan, Target{Tags: []string{"PROD"}, TableSizes: sizes})
+		if result.IsValid {
+			t.Fatal("Expected deny rules to make the result invalid")
+		}
+
+		messages := collectMessages(result)
+		for _, want := range []string{
+			"error:[no-prod-table-drops] Plan drops table 'sessions'",
+			"error:[primary-keys] New table 'events' has no primary key",
+			"warning:[large-table-modifications] Plan modifies a column of table 'logs'",
+			"warning:[fk-names] Foreign key 'orders_ibfk_1' on table 'orders' does not match naming pattern ^fk_{table}_{column}$",
+		} {
+			if !strings.Contains(messages, want) {
+				t.Errorf("Expected %q in:\n%s", want, messages)
+			}
+		}
+		for _, unwanted := range []string{"tokens", "'tags'", "fk_orders_shop_id"} {
+			if strings.Contains(messages, unwanted) {
+				t.Errorf("Did not expect %q in:\n%s", unwanted, messages)
+			}
+		}
+		if result.Errors[0].Type != schema.ErrorTypePolicy || result.Warnings[0].Type != schema.WarningTypePolicy {
+			t.Errorf("Expected policy error and warning types, got %+v", result)
+		}
+	})
+
+	t.Run("untagged target", func(t *testing.T) {
+		result := p.Evaluate(diff, plan, Target{TableSizes: sizes})
+		if strings.Contains(collectMessages(result), "no-prod-table-drops") {
+			t.Error("Expected tagged rules not to apply to untagged targets")
+		}
+		if len(result.Errors) != 1 {
+			t.Errorf("Expected only the primary key violation, got %+v", result.Errors)
+		}
+	})
+}
+
+func TestRule_CheckMergedStatements(t *testing.T) {
+	rule := Rule{Name: "no-column-drops", Type: RuleNoDropColumn, Severity: SeverityDeny}
+	plan := &migration.MigrationPlan{Statements: []migration.MigrationStatement{{
+		SQL:       "ALTER TABLE `users` ADD COLUMN `a` INT, DROP COLUMN `b`",
+		Type:      migration.StatementTypeAlterTable,
+		TableName: "users",
+		Operations: []migration.AlterOperation{
+			{Clause: "ADD COLUMN `a` INT", Type: migration.StatementTypeAddColumn},
+			{Clause: "DROP COLUMN `b`", Type: migration.StatementTypeDropColumn, IsDestructive: true},
+		},
+	}}}
+
+	p := &Policy{Rules: []Rule{rule}}
+	result := p.Evaluate(nil, plan, Target{})
+	if len(result.Errors) != 1 || result.Errors[0].TableName != "users" {
+		t.Errorf("Expected the merged drop to be denied, got %+v", result.Errors)
+	}
+}
+
+func TestConfig_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  Config
+		wantErr bool
+	}{
+		{"disabled", Config{}, false},
+		{"enabled", Config{File: "policy.yaml"}, false},
+		{"override", Config{File: "policy.yaml", Override: "hotfix approved by DBA"}, false},
+		{"blank override", Config{File: "policy.yaml", Override: "  "}, true},
+		{"override without policy", Config{Override: "because"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.config.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestAppendAudit(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.log")
+	result := &schema.ValidationResult{Errors: []schema.ValidationError{{Message: "[no-drops] Plan drops table 'sessions'"}}}
+	plan := &migration.MigrationPlan{Statements: []migration.MigrationStatement{{SQL: "DROP TABLE `sessions`"}}}
+
+	override := NewOverride("approved in change 42", "target_db", plan, result)
+	if err := AppendAudit(path, override); err != nil {
+		t.Fatalf("AppendAudit() error = %v", err)
+	}
+	if err := AppendAudit(path, override); err != nil {
+		t.Fatalf("AppendAudit() error = %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read audit log: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("Expected 2 audit lines, got %d", len(lines))
+	}
+
+	var decoded Override
+	if err := json.Unmarshal([]byte(lines[0]), &decoded); err != nil {
+		t.Fatalf("Failed to decode audit line: %v", err)
+	}
+	if decoded.Reason != "approved in change 42" || decoded.Target != "target_db" ||
+		decoded.PlanHash != plan.Hash() || len(decoded.Violations) != 1 {
+		t.Errorf("Unexpected audit record: %+v", decoded)
+	}
+}
+
+// collectMessages flattens the errors and warnings of a result for matching
+func collectMessages(result *schema.ValidationResult) string {
+	var lines []string
+	for _, err := range result.Errors {
+		lines = append(lines, "error:"+err.Message)
+	}
+	for _, warning := range result.Warnings {
+		lines = append(lines, "warning:"+warning.Message)
+	}
+	return strings.Join(lines, "\n")
+}
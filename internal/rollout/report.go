@@ -0,0 +1,136 @@
+package rollout
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// Format is an output format of the rollout report
+type Format string
+
+const (
+	FormatText Format = "text"
+	FormatJSON Format = "json"
+)
+
+// ParseFormat converts a user supplied format name into a Format
+func ParseFormat(name string) (Format, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "", "text":
+		return FormatText, nil
+	case "json":
+		return FormatJSON, nil
+	default:
+		return "", fmt.Errorf("unsupported rollout report format: %s (supported: text, json)", name)
+	}
+}
+
+// Report is the consolidated outcome of a rollout
+type Report struct {
+	PlanHash  string        `json:"plan_hash"`
+	StartedAt time.Time     `json:"started_at"`
+	Duration  time.Duration `json:"duration_ns"`
+	// Reference is the schema fingerprint the migrated targets converged on
+	Reference string `json:"reference_fingerprint,omitempty"`
+	// HaltReason explains why the rollout stopped before reaching every target
+	HaltReason string        `json:"halt_reason,omitempty"`
+	Groups     []GroupReport `json:"groups"`
+}
+
+// GroupReport is the outcome of the rollout on one group
+type GroupReport struct {
+	Name    string         `json:"name"`
+	Targets []TargetResult `json:"targets"`
+}
+
+// TargetResult is the outcome of the rollout on one target
+type TargetResult struct {
+	Target      string        `json:"target"`
+	Address     string        `json:"address"`
+	Status      Status        `json:"status"`
+	Statements  int           `json:"statements"`
+	Duration    time.Duration `json:"duration_ns"`
+	Fingerprint string        `json:"fingerprint,omitempty"`
+	Error       string        `json:"error,omitempty"`
+}
+
+// Succeeded returns true if the plan reached every target and they all match
+func (r *Report) Succeeded() bool {
+	return r.HaltReason == "" && r.count(StatusSucceeded) == r.targetCount()
+}
+
+// Summary counts the targets by status, e.g. "3 succeeded, 1 failed, 4 skipped"
+func (r *Report) Summary() string {
+	var parts []string
+	for _, status := range []Status{StatusSucceeded, StatusFailed, StatusMismatch, StatusSkipped} {
+		if count := r.count(status); count > 0 {
+			parts = append(parts, fmt.Sprintf("%d %s", count, status))
+		}
+	}
+	if len(parts) == 0 {
+		return "no targets"
+	}
+	return strings.Join(parts, ", ")
+}
+
+// count returns the number of targets with the given status
+func (r *Report) count(status Status) int {
+	count := 0
+	for _, group := range r.Groups {
+		for _, target := range group.Targets {
+			if target.Status == status {
+				count++
+			}
+		}
+	}
+	return count
+}
+
+// targetCount returns the number of targets in the report
+func (r *Report) targetCount() int {
+	count := 0
+	for _, group := range r.Groups {
+		count += len(group.Targets)
+	}
+	return count
+}
+
+// WriteReport writes the report as a table per group, or as JSON
+func WriteReport(w io.Writer, report *Report, format Format) error {
+	if format == FormatJSON {
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(report)
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tw, "Plan hash:\t%s\n", report.PlanHash)
+	fmt.Fprintf(tw, "Started:\t%s\n", report.StartedAt.Local().Format(time.RFC3339))
+	fmt.Fprintf(tw, "Duration:\t%s\n", report.Duration.Round(time.Millisecond))
+	fmt.Fprintf(tw, "Targets:\t%s\n", report.Summary())
+	if report.HaltReason != "" {
+		fmt.Fprintf(tw, "Halted:\t%s\n", report.HaltReason)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	for _, group := range report.Groups {
+		fmt.Fprintf(w, "\nGroup %s:\n", group.Name)
+		tw = tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, "TARGET\tADDRESS\tSTATUS\tSTATEMENTS\tDURATION\tERROR")
+		for _, target := range group.Targets {
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%d\t%s\t%s\n", target.Target, target.Address, target.Status,
+				target.Statements, target.Duration.Round(time.Millisecond), target.Error)
+		}
+		if err := tw.Flush(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
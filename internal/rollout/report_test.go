@@ -0,0 +1,70 @@
+package rollout
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+func testReport() *Report {
+	return &Report{
+		PlanHash:   "abc123",
+		StartedAt:  time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC),
+		Duration:   90 * time.Second,
+		HaltReason: "target shard-1 in group prod failed: lock wait timeout",
+		Groups: []GroupReport{
+			{Name: "canary", Targets: []TargetResult{
+				{Target: "canary-1", Address: "canary.db:3306/app", Status: StatusSucceeded, Statements: 3, Duration: time.Second},
+			}},
+			{Name: "prod", Targets: []TargetResult{
+				{Target: "shard-1", Address: "shard-1.db:3306/app", Status: StatusFailed, Statements: 1, Error: "lock wait timeout"},
+				{Target: "shard-2", Address: "shard-2.db:3306/app", Status: StatusSkipped},
+			}},
+		},
+	}
+}
+
+func TestParseFormat(t *testing.T) {
+	if format, err := ParseFormat("JSON"); err != nil || format != FormatJSON {
+		t.Errorf("ParseFormat(JSON) = %v, %v", format, err)
+	}
+	if _, err := ParseFormat("sarif"); err == nil {
+		t.Error("Expected an unsupported format error")
+	}
+}
+
+func TestWriteReport_Text(t *testing.T) {
+	var out bytes.Buffer
+	if err := WriteReport(&out, testReport(), FormatText); err != nil {
+		t.Fatalf("WriteReport() error = %v", err)
+	}
+
+	for _, expected := range []string{
+		"Targets:    1 succeeded, 1 failed, 1 skipped",
+		"Halted:     target shard-1 in group prod failed",
+		"Group canary:",
+		"canary-1  canary.db:3306/app  succeeded  3",
+		"shard-1  shard-1.db:3306/app  failed   1           0s        lock wait timeout",
+	} {
+		if !strings.Contains(out.String(), expected) {
+			t.Errorf("Expected %q in report:\n%s", expected, out.String())
+		}
+	}
+}
+
+func TestWriteReport_JSON(t *testing.T) {
+	var out bytes.Buffer
+	if err := WriteReport(&out, testReport(), FormatJSON); err != nil {
+		t.Fatalf("WriteReport() error = %v", err)
+	}
+
+	var decoded Report
+	if err := json.Unmarshal(out.Bytes(), &decoded); err != nil {
+		t.Fatalf("Failed to decode report: %v", err)
+	}
+	if decoded.Succeeded() || len(decoded.Groups) != 2 || decoded.Groups[1].Targets[0].Error != "lock wait timeout" {
+		t.Errorf("Unexpected decoded report %+v", decoded)
+	}
+}
@@ -0,0 +1,215 @@
+package rollout
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"mysql-schema-sync/internal/database"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Spec is an ordered list of target groups that one plan is rolled out to, e.g.
+// canary, then staging, then the production shards
+type Spec struct {
+	// Soak is how long to wait after a group succeeded before starting the next one, for groups
+	// that do not set their own
+	Soak time.Duration `yaml:"soak"`
+	// Groups are rolled out one after the other, in order
+	Groups []Group `yaml:"groups"`
+}
+
+// Group is a set of targets that are migrated together
+type Group struct {
+	Name string `yaml:"name"`
+	// Concurrency is how many targets of the group are migrated at the same time
+	Concurrency int `yaml:"concurrency"`
+	// Soak overrides the rollout's soak time after this group
+	Soak    time.Duration `yaml:"soak"`
+	Targets []Target      `yaml:"targets"`
+}
+
+// Target is one database of a group. It is either read from the target section of another
+// configuration file, or given inline.
+type Target struct {
+	Name string `yaml:"name"`
+	// Config is a configuration file whose target section describes the database
+	Config                  string `yaml:"config"`
+	database.DatabaseConfig `yaml:",inline"`
+}
+
+// UnmarshalYAML accepts a plain configuration file path as a target
+func (t *Target) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		t.Config = node.Value
+		return nil
+	}
+
+	type plain Target
+	return node.Decode((*plain)(t))
+}
+
+// Address identifies the target database in reports
+func (t Target) Address() string {
+	return fmt.Sprintf("%s:%d/%s", t.Host, t.Port, t.Database)
+}
+
+// ConcurrencyLimit returns how many targets of the group run at the same time
+func (g Group) ConcurrencyLimit() int {
+	if g.Concurrency <= 0 {
+		return 1
+	}
+	return g.Concurrency
+}
+
+// SoakTime returns how long to wait after the group before starting the next one
+func (s *Spec) SoakTime(group Group) time.Duration {
+	if group.Soak > 0 {
+		return group.Soak
+	}
+	return s.Soak
+}
+
+// Load reads a rollout file and resolves its targets. Configuration files of targets are
+// resolved relative to the rollout file and read with loadTarget. Inline targets take the port,
+// credentials and timeout they leave out from defaults.
+func Load(path string, defaults database.DatabaseConfig, loadTarget func(path string) (database.DatabaseConfig, error)) (*Spec, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read rollout file %s: %w", path, err)
+	}
+
+	spec, err := Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid rollout file %s: %w", path, err)
+	}
+
+	if err := spec.resolve(filepath.Dir(path), defaults, loadTarget); err != nil {
+		return nil, fmt.Errorf("invalid rollout file %s: %w", path, err)
+	}
+
+	if err := spec.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid rollout file %s: %w", path, err)
+	}
+
+	return spec, nil
+}
+
+// Parse decodes a YAML rollout without resolving its targets
+func Parse(data []byte) (*Spec, error) {
+	var spec Spec
+	decoder := yaml.NewDecoder(strings.NewReader(string(data)))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&spec); err != nil {
+		return nil, fmt.Errorf("failed to decode rollout: %w", err)
+	}
+	return &spec, nil
+}
+
+// resolve reads the targets given as configuration files, completes inline targets from defaults
+// and names every target
+func (s *Spec) resolve(dir string, defaults database.DatabaseConfig, loadTarget func(path string) (database.DatabaseConfig, error)) error {
+	for i := range s.Groups {
+		for j := range s.Groups[i].Targets {
+			target := &s.Groups[i].Targets[j]
+			if target.Config != "" {
+				path := target.Config
+				if !filepath.IsAbs(path) {
+					path = filepath.Join(dir, path)
+				}
+				db, err := loadTarget(path)
+				if err != nil {
+					return err
+				}
+				target.DatabaseConfig = db
+				if target.Name == "" {
+					target.Name = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+				}
+			} else {
+				target.inherit(defaults)
+			}
+
+			if target.Port == 0 {
+				target.Port = 3306
+			}
+			if target.Name == "" {
+				target.Name = target.Address()
+			}
+		}
+	}
+	return nil
+}
+
+// inherit fills in the connection settings the target leaves out
+func (t *Target) inherit(defaults database.DatabaseConfig) {
+	if t.Port == 0 {
+		t.Port = defaults.Port
+	}
+	if t.Username == "" {
+		t.Username = defaults.Username
+		if t.Password == "" {
+			t.Password = defaults.Password
+		}
+	}
+	if t.Timeout == 0 {
+		t.Timeout = defaults.Timeout
+	}
+}
+
+// Validate checks that the rollout has groups, that every group has targets and that group and
+// target names are unique
+func (s *Spec) Validate() error {
+	if len(s.Groups) == 0 {
+		return fmt.Errorf("rollout has no groups")
+	}
+	if s.Soak < 0 {
+		return fmt.Errorf("soak time cannot be negative")
+	}
+
+	groups := make(map[string]bool)
+	targets := make(map[string]string)
+	for i, group := range s.Groups {
+		if group.Name == "" {
+			return fmt.Errorf("group %d has no name", i+1)
+		}
+		if groups[group.Name] {
+			return fmt.Errorf("duplicate group %s", group.Name)
+		}
+		groups[group.Name] = true
+
+		if group.Concurrency < 0 {
+			return fmt.Errorf("group %s: concurrency cannot be negative", group.Name)
+		}
+		if group.Soak < 0 {
+			return fmt.Errorf("group %s: soak time cannot be negative", group.Name)
+		}
+		if len(group.Targets) == 0 {
+			return fmt.Errorf("group %s has no targets", group.Name)
+		}
+
+		for _, target := range group.Targets {
+			if other, ok := targets[target.Name]; ok {
+				return fmt.Errorf("target %s appears in groups %s and %s", target.Name, other, group.Name)
+			}
+			targets[target.Name] = group.Name
+
+			if err := target.DatabaseConfig.Validate(); err != nil {
+				return fmt.Errorf("group %s: target %s: %w", group.Name, target.Name, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// TargetCount returns the number of targets across all groups
+func (s *Spec) TargetCount() int {
+	count := 0
+	for _, group := range s.Groups {
+		count += len(group.Targets)
+	}
+	return count
+}
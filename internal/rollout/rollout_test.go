@@ -0,0 +1,149 @@
+package rollout
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"mysql-schema-sync/internal/database"
+)
+
+func TestLoad(t *testing.T) {
+	dir := t.TempDir()
+	fleet := `soak: 10m
+groups:
+  - name: canary
+    targets: [canary.yaml]
+    soak: 30m
+  - name: prod
+    concurrency: 2
+    targets:
+      - {name: shard-1, host: shard-1.db, database: app}
+      - {host: shard-2.db, port: 3307, database: app, username: admin, password: other}
+`
+	path := filepath.Join(dir, "fleet.yaml")
+	if err := os.WriteFile(path, []byte(fleet), 0o644); err != nil {
+		t.Fatalf("Failed to write fleet file: %v", err)
+	}
+
+	var loaded []string
+	loadTarget := func(path string) (database.DatabaseConfig, error) {
+		loaded = append(loaded, path)
+		return database.DatabaseConfig{Host: "canary.db", Port: 3306, Username: "deploy", Database: "app"}, nil
+	}
+	defaults := database.DatabaseConfig{Host: "canary.db", Port: 3306, Username: "deploy", Password: "secret", Database: "app"}
+
+	spec, err := Load(path, defaults, loadTarget)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	if len(loaded) != 1 || loaded[0] != filepath.Join(dir, "canary.yaml") {
+		t.Errorf("Expected the canary config to be read relative to the fleet file, got %v", loaded)
+	}
+	if spec.TargetCount() != 3 {
+		t.Fatalf("Expected 3 targets, got %d", spec.TargetCount())
+	}
+	if spec.SoakTime(spec.Groups[0]) != 30*time.Minute || spec.SoakTime(spec.Groups[1]) != 10*time.Minute {
+		t.Errorf("Unexpected soak times %s and %s", spec.SoakTime(spec.Groups[0]), spec.SoakTime(spec.Groups[1]))
+	}
+	if spec.Groups[0].ConcurrencyLimit() != 1 || spec.Groups[1].ConcurrencyLimit() != 2 {
+		t.Errorf("Unexpected concurrency %d and %d", spec.Groups[0].ConcurrencyLimit(), spec.Groups[1].ConcurrencyLimit())
+	}
+
+	canary := spec.Groups[0].Targets[0]
+	if canary.Name != "canary" || canary.Host != "canary.db" {
+		t.Errorf("Unexpected canary target %+v", canary)
+	}
+
+	shard1 := spec.Groups[1].Targets[0]
+	if shard1.Username != "deploy" || shard1.Password != "secret" || shard1.Port != 3306 {
+		t.Errorf("Expected shard-1 to inherit the connection settings, got %+v", shard1.DatabaseConfig)
+	}
+
+	shard2 := spec.Groups[1].Targets[1]
+	if shard2.Name != "shard-2.db:3307/app" || shard2.Username != "admin" || shard2.Password != "other" {
+		t.Errorf("Expected shard-2 to keep its own settings, got %+v", shard2)
+	}
+}
+
+func TestSpec_Validate(t *testing.T) {
+	target := func(name string) Target {
+		return Target{Name: name, DatabaseConfig: database.DatabaseConfig{Host: name, Port: 3306, Username: "user", Database: "app"}}
+	}
+
+	tests := []struct {
+		name    string
+		spec    Spec
+		wantErr string
+	}{
+		{name: "valid", spec: Spec{Groups: []Group{{Name: "canary", Targets: []Target{target("a")}}}}},
+		{name: "no groups", spec: Spec{}, wantErr: "no groups"},
+		{name: "unnamed group", spec: Spec{Groups: []Group{{Targets: []Target{target("a")}}}}, wantErr: "has no name"},
+		{name: "empty group", spec: Spec{Groups: []Group{{Name: "canary"}}}, wantErr: "has no targets"},
+		{
+			name: "duplicate group",
+			spec: Spec{Groups: []Group{
+				{Name: "prod", Targets: []Target{target("a")}},
+				{Name: "prod", Targets: []Target{target("b")}},
+			}},
+			wantErr: "duplicate group",
+		},
+		{
+			name: "target in two groups",
+			spec: Spec{Groups: []Group{
+				{Name: "canary", Targets: []Target{target("a")}},
+				{Name: "prod", Targets: []Target{target("a")}},
+			}},
+			wantErr: "appears in groups canary and prod",
+		},
+		{
+			name:    "negative soak",
+			spec:    Spec{Groups: []Group{{Name: "canary", Soak: -time.Second, Targets: []Target{target("a")}}}},
+			wantErr: "soak time cannot be negative",
+		},
+		{
+			name:    "incomplete target",
+			spec:    Spec{Groups: []Group{{Name: "canary", Targets: []Target{{Name: "a"}}}}},
+			wantErr: "host is required",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.spec.Validate()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("Validate() error = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Validate() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestParse_UnknownField(t *testing.T) {
+	if _, err := Parse([]byte("groups:\n  - name: canary\n    parallelism: 2\n")); err == nil {
+		t.Error("Expected unknown fields to be rejected")
+	}
+}
+
+func TestLoad_TargetConfigError(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "fleet.yaml")
+	if err := os.WriteFile(path, []byte("groups:\n  - name: canary\n    targets: [missing.yaml]\n"), 0o644); err != nil {
+		t.Fatalf("Failed to write fleet file: %v", err)
+	}
+
+	_, err := Load(path, database.DatabaseConfig{}, func(path string) (database.DatabaseConfig, error) {
+		return database.DatabaseConfig{}, fmt.Errorf("failed to read target config %s", path)
+	})
+	if err == nil || !strings.Contains(err.Error(), "missing.yaml") {
+		t.Errorf("Expected the target config error, got %v", err)
+	}
+}
@@ -0,0 +1,196 @@
+package rollout
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Status is the outcome of the rollout on one target
+type Status string
+
+const (
+	// StatusSucceeded means the plan was applied and the target matches the other targets
+	StatusSucceeded Status = "succeeded"
+	// StatusFailed means applying the plan failed
+	StatusFailed Status = "failed"
+	// StatusMismatch means the plan was applied but the resulting schema differs from the
+	// schema of the targets migrated before it
+	StatusMismatch Status = "mismatch"
+	// StatusSkipped means the rollout halted before the target was migrated
+	StatusSkipped Status = "skipped"
+)
+
+// Outcome is what applying the plan to one target produced
+type Outcome struct {
+	// Statements is the number of statements executed on the target
+	Statements int
+	// Fingerprint is the target schema fingerprint after the plan was applied
+	Fingerprint string
+	Err         error
+}
+
+// ApplyFunc applies the plan to one target
+type ApplyFunc func(ctx context.Context, target Target) Outcome
+
+// Observer is notified as the rollout progresses. Target notifications of a group may arrive
+// concurrently.
+type Observer interface {
+	GroupStarted(group Group)
+	TargetFinished(group Group, result TargetResult)
+	Soaking(group Group, soak time.Duration)
+}
+
+// Runner rolls a plan out to the groups of a rollout, one group at a time. It stops starting
+// targets as soon as one fails or ends up with a different schema than the targets before it.
+type Runner struct {
+	spec     *Spec
+	observer Observer
+
+	mu        sync.Mutex
+	reference string
+	halt      string
+}
+
+// NewRunner creates a Runner for the rollout. The observer may be nil.
+func NewRunner(spec *Spec, observer Observer) *Runner {
+	return &Runner{spec: spec, observer: observer}
+}
+
+// Run applies the plan with the given hash to every target and reports the outcome of each one
+func (r *Runner) Run(ctx context.Context, planHash string, apply ApplyFunc) *Report {
+	report := &Report{PlanHash: planHash, StartedAt: time.Now()}
+
+	for i, group := range r.spec.Groups {
+		if r.halted(ctx) {
+			report.Groups = append(report.Groups, skippedGroup(group))
+			continue
+		}
+
+		if r.observer != nil {
+			r.observer.GroupStarted(group)
+		}
+		report.Groups = append(report.Groups, r.runGroup(ctx, group, apply))
+
+		if i == len(r.spec.Groups)-1 || r.halted(ctx) {
+			continue
+		}
+
+		if soak := r.spec.SoakTime(group); soak > 0 {
+			if r.observer != nil {
+				r.observer.Soaking(group, soak)
+			}
+			select {
+			case <-ctx.Done():
+			case <-time.After(soak):
+			}
+		}
+	}
+
+	report.Duration = time.Since(report.StartedAt)
+	report.Reference = r.reference
+	report.HaltReason = r.halt
+	if report.HaltReason == "" && ctx.Err() != nil && report.count(StatusSkipped) > 0 {
+		report.HaltReason = fmt.Sprintf("rollout interrupted: %v", ctx.Err())
+	}
+	return report
+}
+
+// runGroup migrates the targets of a group with the group's concurrency
+func (r *Runner) runGroup(ctx context.Context, group Group, apply ApplyFunc) GroupReport {
+	results := make([]TargetResult, len(group.Targets))
+	slots := make(chan struct{}, group.ConcurrencyLimit())
+
+	var wg sync.WaitGroup
+	for i, target := range group.Targets {
+		slots <- struct{}{}
+		if r.halted(ctx) {
+			<-slots
+			results[i] = TargetResult{Target: target.Name, Address: target.Address(), Status: StatusSkipped}
+			continue
+		}
+
+		wg.Add(1)
+		go func(i int, target Target) {
+			defer wg.Done()
+			defer func() { <-slots }()
+
+			results[i] = r.runTarget(ctx, group, target, apply)
+			if r.observer != nil {
+				r.observer.TargetFinished(group, results[i])
+			}
+		}(i, target)
+	}
+	wg.Wait()
+
+	return GroupReport{Name: group.Name, Targets: results}
+}
+
+// runTarget applies the plan to one target and checks its schema against the targets before it
+func (r *Runner) runTarget(ctx context.Context, group Group, target Target, apply ApplyFunc) TargetResult {
+	start := time.Now()
+	outcome := apply(ctx, target)
+
+	result := TargetResult{
+		Target:      target.Name,
+		Address:     target.Address(),
+		Status:      StatusSucceeded,
+		Statements:  outcome.Statements,
+		Fingerprint: outcome.Fingerprint,
+		Duration:    time.Since(start),
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	switch {
+	case outcome.Err != nil:
+		result.Status = StatusFailed
+		result.Error = outcome.Err.Error()
+		r.stop(fmt.Sprintf("target %s in group %s failed: %v", target.Name, group.Name, outcome.Err))
+	case outcome.Fingerprint == "":
+		// Nothing to compare, e.g. when the schema could not be read back
+	case r.reference == "":
+		// The first migrated target defines the schema every other target has to end up with
+		r.reference = outcome.Fingerprint
+	case outcome.Fingerprint != r.reference:
+		result.Status = StatusMismatch
+		result.Error = fmt.Sprintf("schema fingerprint %s differs from %s of the targets migrated before it",
+			shortFingerprint(outcome.Fingerprint), shortFingerprint(r.reference))
+		r.stop(fmt.Sprintf("target %s in group %s does not match the other targets after the migration", target.Name, group.Name))
+	}
+
+	return result
+}
+
+// stop records the first reason to halt the rollout. The caller holds the lock.
+func (r *Runner) stop(reason string) {
+	if r.halt == "" {
+		r.halt = reason
+	}
+}
+
+// halted returns true once a target failed or the context was cancelled
+func (r *Runner) halted(ctx context.Context) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.halt != "" || ctx.Err() != nil
+}
+
+// skippedGroup reports every target of a group that was never started
+func skippedGroup(group Group) GroupReport {
+	results := make([]TargetResult, len(group.Targets))
+	for i, target := range group.Targets {
+		results[i] = TargetResult{Target: target.Name, Address: target.Address(), Status: StatusSkipped}
+	}
+	return GroupReport{Name: group.Name, Targets: results}
+}
+
+// shortFingerprint abbreviates a fingerprint for messages
+func shortFingerprint(fingerprint string) string {
+	if len(fingerprint) > 12 {
+		return fingerprint[:12]
+	}
+	return fingerprint
+}
@@ -0,0 +1,158 @@
+package rollout
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"mysql-schema-sync/internal/database"
+)
+
+// recordingObserver records the rollout progress
+type recordingObserver struct {
+	mu     sync.Mutex
+	events []string
+}
+
+func (o *recordingObserver) GroupStarted(group Group) {
+	o.record("start " + group.Name)
+}
+
+func (o *recordingObserver) TargetFinished(group Group, result TargetResult) {
+	o.record("finish " + result.Target)
+}
+
+func (o *recordingObserver) Soaking(group Group, soak time.Duration) {
+	o.record("soak " + group.Name)
+}
+
+func (o *recordingObserver) record(event string) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.events = append(o.events, event)
+}
+
+func testSpec(groups ...Group) *Spec {
+	for i := range groups {
+		for j := range groups[i].Targets {
+			groups[i].Targets[j].DatabaseConfig = database.DatabaseConfig{Host: groups[i].Targets[j].Name, Port: 3306, Username: "user", Database: "app"}
+		}
+	}
+	return &Spec{Groups: groups}
+}
+
+func TestRunner_Run(t *testing.T) {
+	spec := testSpec(
+		Group{Name: "canary", Soak: time.Millisecond, Targets: []Target{{Name: "canary-1"}}},
+		Group{Name: "prod", Concurrency: 2, Targets: []Target{{Name: "shard-1"}, {Name: "shard-2"}, {Name: "shard-3"}}},
+	)
+
+	var running, maxRunning int32
+	observer := &recordingObserver{}
+	report := NewRunner(spec, observer).Run(context.Background(), "abc", func(ctx context.Context, target Target) Outcome {
+		current := atomic.AddInt32(&running, 1)
+		for {
+			seen := atomic.LoadInt32(&maxRunning)
+			if current <= seen || atomic.CompareAndSwapInt32(&maxRunning, seen, current) {
+				break
+			}
+		}
+		time.Sleep(5 * time.Millisecond)
+		atomic.AddInt32(&running, -1)
+		return Outcome{Statements: 2, Fingerprint: "fp"}
+	})
+
+	if !report.Succeeded() {
+		t.Fatalf("Expected the rollout to succeed, halted: %s", report.HaltReason)
+	}
+	if report.Summary() != "4 succeeded" || report.Reference != "fp" {
+		t.Errorf("Unexpected summary %q, reference %q", report.Summary(), report.Reference)
+	}
+	if maxRunning != 2 {
+		t.Errorf("Expected at most 2 targets at a time, got %d", maxRunning)
+	}
+	if observer.events[0] != "start canary" || observer.events[1] != "finish canary-1" ||
+		observer.events[2] != "soak canary" || observer.events[3] != "start prod" {
+		t.Errorf("Unexpected events %v", observer.events)
+	}
+	if len(observer.events) != 7 {
+		t.Errorf("Expected 7 events, got %v", observer.events)
+	}
+}
+
+func TestRunner_HaltsOnFailure(t *testing.T) {
+	spec := testSpec(
+		Group{Name: "canary", Targets: []Target{{Name: "canary-1"}, {Name: "canary-2"}, {Name: "canary-3"}}},
+		Group{Name: "prod", Targets: []Target{{Name: "shard-1"}}},
+	)
+
+	var applied []string
+	report := NewRunner(spec, nil).Run(context.Background(), "abc", func(ctx context.Context, target Target) Outcome {
+		applied = append(applied, target.Name)
+		if target.Name == "canary-2" {
+			return Outcome{Statements: 1, Err: errors.New("lock wait timeout")}
+		}
+		return Outcome{Statements: 2, Fingerprint: "fp"}
+	})
+
+	if report.Succeeded() {
+		t.Fatal("Expected the rollout to halt")
+	}
+	if len(applied) != 2 {
+		t.Errorf("Expected the rollout to stop after canary-2, applied %v", applied)
+	}
+	if report.HaltReason != "target canary-2 in group canary failed: lock wait timeout" {
+		t.Errorf("Unexpected halt reason %q", report.HaltReason)
+	}
+	if report.Summary() != "1 succeeded, 1 failed, 2 skipped" {
+		t.Errorf("Unexpected summary %q", report.Summary())
+	}
+	if report.Groups[1].Targets[0].Status != StatusSkipped {
+		t.Errorf("Expected the prod group to be skipped, got %+v", report.Groups[1])
+	}
+}
+
+func TestRunner_HaltsOnMismatch(t *testing.T) {
+	spec := testSpec(
+		Group{Name: "canary", Targets: []Target{{Name: "canary-1"}}},
+		Group{Name: "prod", Targets: []Target{{Name: "shard-1"}, {Name: "shard-2"}}},
+	)
+
+	report := NewRunner(spec, nil).Run(context.Background(), "abc", func(ctx context.Context, target Target) Outcome {
+		if target.Name == "shard-1" {
+			return Outcome{Statements: 2, Fingerprint: "0123456789abcdef-drifted"}
+		}
+		return Outcome{Statements: 2, Fingerprint: "fedcba9876543210-expected"}
+	})
+
+	result := report.Groups[1].Targets[0]
+	if result.Status != StatusMismatch || result.Error != "schema fingerprint 0123456789ab differs from fedcba987654 of the targets migrated before it" {
+		t.Errorf("Expected a mismatch, got %+v", result)
+	}
+	if report.Groups[1].Targets[1].Status != StatusSkipped {
+		t.Errorf("Expected shard-2 to be skipped after the mismatch, got %+v", report.Groups[1].Targets[1])
+	}
+}
+
+func TestRunner_InterruptedDuringSoak(t *testing.T) {
+	spec := testSpec(
+		Group{Name: "canary", Soak: time.Hour, Targets: []Target{{Name: "canary-1"}}},
+		Group{Name: "prod", Targets: []Target{{Name: "shard-1"}}},
+	)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	report := NewRunner(spec, nil).Run(ctx, "abc", func(ctx context.Context, target Target) Outcome {
+		cancel()
+		return Outcome{Fingerprint: "fp"}
+	})
+
+	if report.Succeeded() || report.HaltReason != "rollout interrupted: context canceled" {
+		t.Errorf("Expected an interrupted rollout, got %q", report.HaltReason)
+	}
+	if report.Groups[1].Targets[0].Status != StatusSkipped {
+		t.Errorf("Expected the prod group to be skipped, got %+v", report.Groups[1])
+	}
+}
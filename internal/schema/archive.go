@@ -0,0 +1,51 @@
+package schema
+
+import (
+	"strings"
+	"time"
+)
+
+// ArchivePrefix starts the name of every table and column archived by safe-drop mode
+const ArchivePrefix = "_archived_"
+
+// archiveTimestampFormat is the UTC timestamp embedded in archived names
+const archiveTimestampFormat = "20060102150405"
+
+// maxIdentifierLength is the longest table or column name MySQL accepts
+const maxIdentifierLength = 64
+
+// ArchivedName returns the name a table or column is renamed to when it is archived at the given
+// time. Long names are truncated to fit MySQL's identifier limit; the timestamp is always kept.
+func ArchivedName(name string, at time.Time) string {
+	prefix := ArchivePrefix + at.UTC().Format(archiveTimestampFormat) + "_"
+	if len(prefix)+len(name) > maxIdentifierLength {
+		name = name[:maxIdentifierLength-len(prefix)]
+	}
+	return prefix + name
+}
+
+// ParseArchivedName extracts the archive time and the original (possibly truncated) name
+// from an archived name. It returns false if the name was not produced by ArchivedName.
+func ParseArchivedName(name string) (time.Time, string, bool) {
+	if !strings.HasPrefix(name, ArchivePrefix) {
+		return time.Time{}, "", false
+	}
+
+	rest := name[len(ArchivePrefix):]
+	if len(rest) < len(archiveTimestampFormat)+2 || rest[len(archiveTimestampFormat)] != '_' {
+		return time.Time{}, "", false
+	}
+
+	archivedAt, err := time.Parse(archiveTimestampFormat, rest[:len(archiveTimestampFormat)])
+	if err != nil {
+		return time.Time{}, "", false
+	}
+
+	return archivedAt, rest[len(archiveTimestampFormat)+1:], true
+}
+
+// IsArchivedName returns true if a table or column name was produced by ArchivedName
+func IsArchivedName(name string) bool {
+	_, _, ok := ParseArchivedName(name)
+	return ok
+}
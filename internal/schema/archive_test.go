@@ -0,0 +1,32 @@
+package schema
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestArchivedName(t *testing.T) {
+	at := time.Date(2026, 3, 1, 12, 30, 0, 0, time.UTC)
+
+	name := ArchivedName("users", at)
+	if name != "_archived_20260301123000_users" {
+		t.Errorf("Unexpected archived name: %s", name)
+	}
+
+	archivedAt, original, ok := ParseArchivedName(name)
+	if !ok || !archivedAt.Equal(at) || original != "users" {
+		t.Errorf("ParseArchivedName() = %v, %q, %v", archivedAt, original, ok)
+	}
+
+	long := ArchivedName(strings.Repeat("x", 60), at)
+	if len(long) != maxIdentifierLength || !strings.HasPrefix(long, "_archived_20260301123000_x") {
+		t.Errorf("Expected long names to be truncated to %d characters, got %q", maxIdentifierLength, long)
+	}
+
+	for _, invalid := range []string{"users", "_archived_users", "_archived_2026030112300x_users", "_archived_20260301123000"} {
+		if _, _, ok := ParseArchivedName(invalid); ok {
+			t.Errorf("Expected %q not to parse as an archived name", invalid)
+		}
+	}
+}
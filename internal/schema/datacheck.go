@@ -0,0 +1,433 @@
+package schema
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// DataChecker queries the target data for rows that would make a schema change fail or
+// silently corrupt data, and reports them as blocking validation errors
+type DataChecker struct {
+	queryTimeout time.Duration
+	sampleLimit  int
+}
+
+// integerRange is the range of values an integer column type can store
+type integerRange struct {
+	min int64
+	max uint64
+}
+
+// integerRanges maps integer base types to their signed and unsigned ranges
+var integerRanges = map[string][2]integerRange{
+	"tinyint":   {{-128, 127}, {0, 255}},
+	"smallint":  {{-32768, 32767}, {0, 65535}},
+	"mediumint": {{-8388608, 8388607}, {0, 16777215}},
+	"int":       {{-2147483648, 2147483647}, {0, 4294967295}},
+	"integer":   {{-2147483648, 2147483647}, {0, 4294967295}},
+	"bigint":    {{-9223372036854775808, 9223372036854775807}, {0, 18446744073709551615}},
+}
+
+// textTypes are the string types whose values may exceed a CHAR/VARCHAR length
+var textTypes = map[string]bool{
+	"char":       true,
+	"varchar":    true,
+	"tinytext":   true,
+	"text":       true,
+	"mediumtext": true,
+	"longtext":   true,
+}
+
+// NewDataChecker creates a new DataChecker with the default query timeout
+func NewDataChecker() *DataChecker {
+	return NewDataCheckerWithTimeout(30 * time.Second)
+}
+
+// NewDataCheckerWithTimeout creates a new DataChecker with a custom query timeout
+func NewDataCheckerWithTimeout(timeout time.Duration) *DataChecker {
+	return &DataChecker{
+		queryTimeout: timeout,
+		sampleLimit:  5,
+	}
+}
+
+// CheckChanges inspects the rows of the target tables affected by the diff. Tables and columns that
+// do not exist in the target yet are skipped because they hold no data.
+func (dc *DataChecker) CheckChanges(db *sql.DB, target *Schema, diff *SchemaDiff) (*ValidationResult, error) {
+	result := &ValidationResult{
+		IsValid:  true,
+		Warnings: make([]Warning, 0),
+		Errors:   make([]ValidationError, 0),
+	}
+
+	if db == nil || target == nil || diff == nil {
+		return nil, fmt.Errorf("database, target schema and diff are required for data checks")
+	}
+
+	for _, tableDiff := range diff.ModifiedTables {
+		table, exists := target.Tables[tableDiff.TableName]
+		if !exists {
+			continue
+		}
+
+		for _, columnDiff := range tableDiff.ModifiedColumns {
+			if err := dc.checkColumnModification(db, table, columnDiff, result); err != nil {
+				return nil, err
+			}
+		}
+
+		for _, constraint := range tableDiff.AddedConstraints {
+			if err := dc.checkAddedConstraint(db, target, constraint, result); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	for _, index := range diff.AddedIndexes {
+		if !index.IsUnique && !index.IsPrimary {
+			continue
+		}
+		table, exists := target.Tables[index.TableName]
+		if !exists || !hasColumns(table, index.Columns) {
+			continue
+		}
+		kind := "Unique index"
+		if index.IsPrimary {
+			kind = "Primary key"
+		}
+		if err := dc.checkDuplicates(db, table, kind, index.Name, index.Columns, result); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, constraint := range diff.AddedConstraints {
+		if err := dc.checkAddedConstraint(db, target, constraint, result); err != nil {
+			return nil, err
+		}
+	}
+
+	result.IsValid = len(result.Errors) == 0
+	return result, nil
+}
+
+// checkColumnModification checks that existing values fit the new definition of a column
+func (dc *DataChecker) checkColumnModification(db *sql.DB, table *Table, columnDiff *ColumnDiff, result *ValidationResult) error {
+	old, new := columnDiff.OldColumn, columnDiff.NewColumn
+	if old == nil || new == nil {
+		return nil
+	}
+	column := quoteIdentifier(columnDiff.ColumnName)
+	from := quoteIdentifier(table.Name)
+
+	// NULL -> NOT NULL fails in strict mode, or silently rewrites NULLs to zero values otherwise
+	if old.IsNullable && !new.IsNullable {
+		where := fmt.Sprintf("%s IS NULL", column)
+		count, err := dc.countRows(db, from, where)
+		if err != nil {
+			return err
+		}
+		if count > 0 {
+			samples, err := dc.sampleKeys(db, table, from, where)
+			if err != nil {
+				return err
+			}
+			result.Errors = append(result.Errors, newDataViolation(table.Name, columnDiff.ColumnName, count, samples,
+				fmt.Sprintf("Column '%s.%s' cannot be made NOT NULL: %d rows contain NULL", table.Name, columnDiff.ColumnName, count)))
+		}
+	}
+
+	oldBase, oldUnsigned := parseColumnType(old.DataType)
+	newBase, newUnsigned := parseColumnType(new.DataType)
+
+	// Shrinking CHAR/VARCHAR, or converting TEXT to one, truncates longer values
+	if length := columnTypeSize(new.DataType); (newBase == "varchar" || newBase == "char") && length > 0 && textTypes[oldBase] {
+		oldLength := columnTypeSize(old.DataType)
+		if (oldBase == "varchar" || oldBase == "char") && oldLength > 0 && oldLength <= length {
+			return nil
+		}
+
+		where := fmt.Sprintf("CHAR_LENGTH(%s) > %d", column, length)
+		var count, longest int64
+		query := fmt.Sprintf("SELECT COUNT(*), COALESCE(MAX(CHAR_LENGTH(%s)), 0) FROM %s WHERE %s", column, from, where)
+		if err := dc.queryRow(db, query, &count, &longest); err != nil {
+			return fmt.Errorf("failed to check lengths of column %s.%s: %w", table.Name, columnDiff.ColumnName, err)
+		}
+		if count > 0 {
+			samples, err := dc.sampleKeys(db, table, from, where)
+			if err != nil {
+				return err
+			}
+			result.Errors = append(result.Errors, newDataViolation(table.Name, columnDiff.ColumnName, count, samples,
+				fmt.Sprintf("Column '%s.%s' cannot be changed to %s: %d rows are longer than %d characters (longest is %d)",
+					table.Name, columnDiff.ColumnName, new.DataType, count, length, longest)))
+		}
+		return nil
+	}
+
+	// Narrowing an integer type rejects or clamps values outside the new range
+	oldRanges, oldIsInteger := integerRanges[oldBase]
+	newRanges, newIsInteger := integerRanges[newBase]
+	if oldIsInteger && newIsInteger {
+		oldRange, newRange := oldRanges[boolIndex(oldUnsigned)], newRanges[boolIndex(newUnsigned)]
+		if newRange.min <= oldRange.min && newRange.max >= oldRange.max {
+			return nil
+		}
+
+		where := fmt.Sprintf("(%s < %d OR %s > %d)", column, newRange.min, column, newRange.max)
+		count, err := dc.countRows(db, from, where)
+		if err != nil {
+			return err
+		}
+		if count > 0 {
+			samples, err := dc.sampleKeys(db, table, from, where)
+			if err != nil {
+				return err
+			}
+			result.Errors = append(result.Errors, newDataViolation(table.Name, columnDiff.ColumnName, count, samples,
+				fmt.Sprintf("Column '%s.%s' cannot be changed to %s: %d rows are outside the range %d to %d",
+					table.Name, columnDiff.ColumnName, new.DataType, count, newRange.min, newRange.max)))
+		}
+	}
+
+	return nil
+}
+
+// checkAddedConstraint checks existing rows against a new UNIQUE or FOREIGN KEY constraint
+func (dc *DataChecker) checkAddedConstraint(db *sql.DB, target *Schema, constraint *Constraint, result *ValidationResult) error {
+	table, exists := target.Tables[constraint.TableName]
+	if !exists || !hasColumns(table, constraint.Columns) {
+		return nil
+	}
+
+	switch constraint.Type {
+	case ConstraintTypeUnique:
+		return dc.checkDuplicates(db, table, "Unique constraint", constraint.Name, constraint.Columns, result)
+	case ConstraintTypeForeignKey:
+		return dc.checkOrphans(db, target, table, constraint, result)
+	}
+	return nil
+}
+
+// checkDuplicates counts the values that appear more than once in the columns of a new unique key.
+// Rows with a NULL in any key column are ignored because they never conflict.
+func (dc *DataChecker) checkDuplicates(db *sql.DB, table *Table, kind, name string, columns []string, result *ValidationResult) error {
+	keys := quoteIdentifiers(columns)
+	where := notNullCondition("", columns)
+	grouped := fmt.Sprintf("FROM %s WHERE %s GROUP BY %s HAVING COUNT(*) > 1",
+		quoteIdentifier(table.Name), where, strings.Join(keys, ", "))
+
+	var values, rows int64
+	query := fmt.Sprintf("SELECT COUNT(*), COALESCE(SUM(duplicates), 0) FROM (SELECT COUNT(*) AS duplicates %s) AS duplicated_keys", grouped)
+	if err := dc.queryRow(db, query, &values, &rows); err != nil {
+		return fmt.Errorf("failed to check duplicates for %s on table %s: %w", name, table.Name, err)
+	}
+	if values == 0 {
+		return nil
+	}
+
+	samples, err := dc.queryStrings(db, fmt.Sprintf("SELECT CONCAT_WS(',', %s) %s LIMIT %d",
+		strings.Join(keys, ", "), grouped, dc.sampleLimit))
+	if err != nil {
+		return fmt.Errorf("failed to sample duplicates for %s on table %s: %w", name, table.Name, err)
+	}
+
+	result.Errors = append(result.Errors, newDataViolation(table.Name, strings.Join(columns, ","), values, samples,
+		fmt.Sprintf("%s '%s' on table '%s' cannot be added: %d values of (%s) are duplicated across %d rows",
+			kind, name, table.Name, values, strings.Join(columns, ", "), rows)))
+	return nil
+}
+
+// checkOrphans counts the rows whose foreign key columns reference no row of the parent table.
+// A parent table created by the migration is empty, so every non-NULL reference is orphaned.
+func (dc *DataChecker) checkOrphans(db *sql.DB, target *Schema, table *Table, constraint *Constraint, result *ValidationResult) error {
+	if len(constraint.ReferencedColumns) != len(constraint.Columns) {
+		return nil
+	}
+
+	from := fmt.Sprintf("%s AS child", quoteIdentifier(table.Name))
+	where := notNullCondition("child.", constraint.Columns)
+	if _, exists := target.Tables[constraint.ReferencedTable]; exists {
+		conditions := make([]string, len(constraint.Columns))
+		for i, column := range constraint.Columns {
+			conditions[i] = fmt.Sprintf("child.%s = parent.%s",
+				quoteIdentifier(column), quoteIdentifier(constraint.ReferencedColumns[i]))
+		}
+		from = fmt.Sprintf("%s LEFT JOIN %s AS parent ON %s", from,
+			quoteIdentifier(constraint.ReferencedTable), strings.Join(conditions, " AND "))
+		where = fmt.Sprintf("%s AND parent.%s IS NULL", where, quoteIdentifier(constraint.ReferencedColumns[0]))
+	}
+
+	count, err := dc.countRows(db, from, where)
+	if err != nil {
+		return err
+	}
+	if count == 0 {
+		return nil
+	}
+
+	samples, err := dc.sampleKeysWithPrefix(db, table, "child.", from, where)
+	if err != nil {
+		return err
+	}
+
+	result.Errors = append(result.Errors, newDataViolation(table.Name, strings.Join(constraint.Columns, ","), count, samples,
+		fmt.Sprintf("Foreign key '%s' on table '%s' cannot be added: %d rows reference missing rows in '%s'",
+			constraint.Name, table.Name, count, constraint.ReferencedTable)))
+	return nil
+}
+
+// countRows counts the rows matching a condition
+func (dc *DataChecker) countRows(db *sql.DB, from, where string) (int64, error) {
+	var count int64
+	query := fmt.Sprintf("SELECT COUNT(*) FROM %s WHERE %s", from, where)
+	if err := dc.queryRow(db, query, &count); err != nil {
+		return 0, fmt.Errorf("failed to count rows in %s: %w", from, err)
+	}
+	return count, nil
+}
+
+// sampleKeys returns the primary keys of a few rows matching a condition
+func (dc *DataChecker) sampleKeys(db *sql.DB, table *Table, from, where string) ([]string, error) {
+	return dc.sampleKeysWithPrefix(db, table, "", from, where)
+}
+
+// sampleKeysWithPrefix returns the primary keys of a few rows matching a condition, qualifying
+// the key columns with the given table alias prefix. Tables without a primary key have no samples.
+func (dc *DataChecker) sampleKeysWithPrefix(db *sql.DB, table *Table, prefix, from, where string) ([]string, error) {
+	primaryKey := table.GetPrimaryKey()
+	if primaryKey == nil || len(primaryKey.Columns) == 0 {
+		return nil, nil
+	}
+
+	keys := make([]string, len(primaryKey.Columns))
+	for i, column := range primaryKey.Columns {
+		keys[i] = prefix + quoteIdentifier(column)
+	}
+
+	query := fmt.Sprintf("SELECT CONCAT_WS(',', %s) FROM %s WHERE %s LIMIT %d",
+		strings.Join(keys, ", "), from, where, dc.sampleLimit)
+	samples, err := dc.queryStrings(db, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sample rows in %s: %w", table.Name, err)
+	}
+	return samples, nil
+}
+
+// queryRow runs a single-row query with the checker's timeout
+func (dc *DataChecker) queryRow(db *sql.DB, query string, dest ...interface{}) error {
+	ctx, cancel := context.WithTimeout(context.Background(), dc.queryTimeout)
+	defer cancel()
+
+	return db.QueryRowContext(ctx, query).Scan(dest...)
+}
+
+// queryStrings runs a single-column query with the checker's timeout
+func (dc *DataChecker) queryStrings(db *sql.DB, query string) ([]string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), dc.queryTimeout)
+	defer cancel()
+
+	rows, err := db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	values := make([]string, 0)
+	for rows.Next() {
+		var value sql.NullString
+		if err := rows.Scan(&value); err != nil {
+			return nil, err
+		}
+		values = append(values, value.String)
+	}
+
+	return values, rows.Err()
+}
+
+// newDataViolation creates a blocking validation error for rows that violate a change
+func newDataViolation(tableName, columnName string, count int64, samples []string, message string) ValidationError {
+	details := fmt.Sprintf("%d violating rows", count)
+	if len(samples) > 0 {
+		details = fmt.Sprintf("%s, for example: %s", details, strings.Join(samples, "; "))
+	}
+
+	return ValidationError{
+		Type:       ErrorTypeDataViolation,
+		Message:    message,
+		TableName:  tableName,
+		ColumnName: columnName,
+		Details:    details,
+		Count:      count,
+		SampleKeys: samples,
+	}
+}
+
+// parseColumnType returns the lowercase base type of a column type and whether it is unsigned
+func parseColumnType(dataType string) (string, bool) {
+	dt := strings.ToLower(strings.TrimSpace(dataType))
+	unsigned := strings.Contains(dt, "unsigned")
+	if idx := strings.IndexAny(dt, "( "); idx != -1 {
+		dt = dt[:idx]
+	}
+	return dt, unsigned
+}
+
+// columnTypeSize returns the length of a column type such as VARCHAR(255), or 0 if it has none
+func columnTypeSize(dataType string) int {
+	start := strings.Index(dataType, "(")
+	end := strings.Index(dataType, ")")
+	if start == -1 || end <= start {
+		return 0
+	}
+
+	var size int
+	fmt.Sscanf(dataType[start+1:end], "%d", &size)
+	return size
+}
+
+// boolIndex returns 1 for true and 0 for false
+func boolIndex(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// hasColumns reports whether all columns already exist in the table
+func hasColumns(table *Table, columns []string) bool {
+	if len(columns) == 0 {
+		return false
+	}
+	for _, column := range columns {
+		if _, exists := table.Columns[column]; !exists {
+			return false
+		}
+	}
+	return true
+}
+
+// notNullCondition requires every column, qualified with the prefix, to be NOT NULL
+func notNullCondition(prefix string, columns []string) string {
+	conditions := make([]string, len(columns))
+	for i, column := range columns {
+		conditions[i] = fmt.Sprintf("%s%s IS NOT NULL", prefix, quoteIdentifier(column))
+	}
+	return strings.Join(conditions, " AND ")
+}
+
+// quoteIdentifiers quotes each identifier with backticks
+func quoteIdentifiers(names []string) []string {
+	quoted := make([]string, len(names))
+	for i, name := range names {
+		quoted[i] = quoteIdentifier(name)
+	}
+	return quoted
+}
+
+// quoteIdentifier quotes a MySQL identifier with backticks
+func quoteIdentifier(name string) string {
+	return "`" + strings.ReplaceAll(name, "`", "``") + "`"
+}
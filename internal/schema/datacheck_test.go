@@ -0,0 +1,290 @@
+package schema
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+// newDataCheckSchema builds a target schema with users and orders tables keyed by id
+func newDataCheckSchema() *Schema {
+	target := NewSchema("target_db")
+
+	users := NewTable("users")
+	users.AddColumn(&Column{Name: "id", DataType: "int", IsNullable: false})
+	users.AddColumn(&Column{Name: "email", DataType: "varchar(255)", IsNullable: true})
+	users.AddColumn(&Column{Name: "age", DataType: "int", IsNullable: true})
+	users.AddIndex(&Index{Name: "PRIMARY", TableName: "users", Columns: []string{"id"}, IsPrimary: true, IsUnique: true})
+	target.AddTable(users)
+
+	orders := NewTable("orders")
+	orders.AddColumn(&Column{Name: "id", DataType: "int", IsNullable: false})
+	orders.AddColumn(&Column{Name: "user_id", DataType: "int", IsNullable: true})
+	orders.AddIndex(&Index{Name: "PRIMARY", TableName: "orders", Columns: []string{"id"}, IsPrimary: true, IsUnique: true})
+	target.AddTable(orders)
+
+	return target
+}
+
+func TestDataChecker_NullToNotNull(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock database: %v", err)
+	}
+	defer db.Close()
+
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT COUNT(*) FROM `users` WHERE `email` IS NULL")).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(3))
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT CONCAT_WS(',', `id`) FROM `users` WHERE `email` IS NULL LIMIT 5")).
+		WillReturnRows(sqlmock.NewRows([]string{"key"}).AddRow("4").AddRow("8").AddRow("15"))
+
+	diff := &SchemaDiff{
+		ModifiedTables: []*TableDiff{{
+			TableName: "users",
+			ModifiedColumns: []*ColumnDiff{{
+				ColumnName: "email",
+				OldColumn:  &Column{Name: "email", DataType: "varchar(255)", IsNullable: true},
+				NewColumn:  &Column{Name: "email", DataType: "varchar(255)", IsNullable: false},
+			}},
+		}},
+	}
+
+	result, err := NewDataChecker().CheckChanges(db, newDataCheckSchema(), diff)
+	if err != nil {
+		t.Fatalf("CheckChanges() error = %v", err)
+	}
+
+	if result.IsValid || len(result.Errors) != 1 {
+		t.Fatalf("Expected one blocking error, got %+v", result.Errors)
+	}
+	violation := result.Errors[0]
+	if violation.Type != ErrorTypeDataViolation || violation.Count != 3 || violation.ColumnName != "email" {
+		t.Errorf("Unexpected violation: %+v", violation)
+	}
+	if strings.Join(violation.SampleKeys, ",") != "4,8,15" || !strings.Contains(violation.Details, "4; 8; 15") {
+		t.Errorf("Unexpected samples: %v (%s)", violation.SampleKeys, violation.Details)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
+func TestDataChecker_VarcharShrink(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock database: %v", err)
+	}
+	defer db.Close()
+
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT COUNT(*), COALESCE(MAX(CHAR_LENGTH(`email`)), 0) FROM `users` WHERE CHAR_LENGTH(`email`) > 100")).
+		WillReturnRows(sqlmock.NewRows([]string{"count", "longest"}).AddRow(2, 180))
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT CONCAT_WS(',', `id`) FROM `users` WHERE CHAR_LENGTH(`email`) > 100 LIMIT 5")).
+		WillReturnRows(sqlmock.NewRows([]string{"key"}).AddRow("1").AddRow("2"))
+
+	diff := &SchemaDiff{
+		ModifiedTables: []*TableDiff{{
+			TableName: "users",
+			ModifiedColumns: []*ColumnDiff{{
+				ColumnName: "email",
+				OldColumn:  &Column{Name: "email", DataType: "varchar(255)", IsNullable: true},
+				NewColumn:  &Column{Name: "email", DataType: "varchar(100)", IsNullable: true},
+			}},
+		}},
+	}
+
+	result, err := NewDataChecker().CheckChanges(db, newDataCheckSchema(), diff)
+	if err != nil {
+		t.Fatalf("CheckChanges() error = %v", err)
+	}
+
+	if len(result.Errors) != 1 || !strings.Contains(result.Errors[0].Message, "longest is 180") {
+		t.Fatalf("Expected a length violation, got %+v", result.Errors)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
+func TestDataChecker_IntegerNarrowing(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock database: %v", err)
+	}
+	defer db.Close()
+
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT COUNT(*) FROM `users` WHERE (`age` < 0 OR `age` > 255)")).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+
+	diff := &SchemaDiff{
+		ModifiedTables: []*TableDiff{{
+			TableName: "users",
+			ModifiedColumns: []*ColumnDiff{{
+				ColumnName: "age",
+				OldColumn:  &Column{Name: "age", DataType: "int", IsNullable: true},
+				NewColumn:  &Column{Name: "age", DataType: "tinyint unsigned", IsNullable: true},
+			}},
+		}},
+	}
+
+	result, err := NewDataChecker().CheckChanges(db, newDataCheckSchema(), diff)
+	if err != nil {
+		t.Fatalf("CheckChanges() error = %v", err)
+	}
+
+	if !result.IsValid {
+		t.Errorf("Expected no violations, got %+v", result.Errors)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
+func TestDataChecker_SkipsSafeChanges(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock database: %v", err)
+	}
+	defer db.Close()
+
+	diff := &SchemaDiff{
+		ModifiedTables: []*TableDiff{{
+			TableName: "users",
+			ModifiedColumns: []*ColumnDiff{
+				{
+					ColumnName: "email",
+					OldColumn:  &Column{Name: "email", DataType: "varchar(100)", IsNullable: false},
+					NewColumn:  &Column{Name: "email", DataType: "varchar(255)", IsNullable: true},
+				},
+				{
+					ColumnName: "age",
+					OldColumn:  &Column{Name: "age", DataType: "smallint", IsNullable: true},
+					NewColumn:  &Column{Name: "age", DataType: "bigint", IsNullable: true},
+				},
+			},
+		}},
+		AddedIndexes: []*Index{
+			{Name: "idx_age", TableName: "users", Columns: []string{"age"}},
+			{Name: "uniq_code", TableName: "users", Columns: []string{"code"}, IsUnique: true},
+		},
+	}
+
+	result, err := NewDataChecker().CheckChanges(db, newDataCheckSchema(), diff)
+	if err != nil {
+		t.Fatalf("CheckChanges() error = %v", err)
+	}
+
+	if !result.IsValid {
+		t.Errorf("Expected no violations, got %+v", result.Errors)
+	}
+
+	// Widening changes, non-unique indexes and columns that do not exist yet need no queries
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
+func TestDataChecker_UniqueIndexDuplicates(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock database: %v", err)
+	}
+	defer db.Close()
+
+	grouped := "FROM `users` WHERE `email` IS NOT NULL GROUP BY `email` HAVING COUNT(*) > 1"
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT COUNT(*), COALESCE(SUM(duplicates), 0) FROM (SELECT COUNT(*) AS duplicates " + grouped + ") AS duplicated_keys")).
+		WillReturnRows(sqlmock.NewRows([]string{"values", "rows"}).AddRow(2, 5))
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT CONCAT_WS(',', `email`) " + grouped + " LIMIT 5")).
+		WillReturnRows(sqlmock.NewRows([]string{"key"}).AddRow("a@example.com").AddRow("b@example.com"))
+
+	diff := &SchemaDiff{
+		AddedIndexes: []*Index{
+			{Name: "uniq_email", TableName: "users", Columns: []string{"email"}, IsUnique: true},
+		},
+	}
+
+	result, err := NewDataChecker().CheckChanges(db, newDataCheckSchema(), diff)
+	if err != nil {
+		t.Fatalf("CheckChanges() error = %v", err)
+	}
+
+	if len(result.Errors) != 1 {
+		t.Fatalf("Expected one duplicate violation, got %+v", result.Errors)
+	}
+	violation := result.Errors[0]
+	if violation.Count != 2 || !strings.Contains(violation.Message, "duplicated across 5 rows") {
+		t.Errorf("Unexpected violation: %+v", violation)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
+func TestDataChecker_ForeignKeyOrphans(t *testing.T) {
+	tests := []struct {
+		name        string
+		referenced  string
+		expectQuery string
+		sampleQuery string
+	}{
+		{
+			name:        "existing parent table",
+			referenced:  "users",
+			expectQuery: "SELECT COUNT(*) FROM `orders` AS child LEFT JOIN `users` AS parent ON child.`user_id` = parent.`id` WHERE child.`user_id` IS NOT NULL AND parent.`id` IS NULL",
+			sampleQuery: "SELECT CONCAT_WS(',', child.`id`) FROM `orders` AS child LEFT JOIN `users` AS parent ON child.`user_id` = parent.`id` WHERE child.`user_id` IS NOT NULL AND parent.`id` IS NULL LIMIT 5",
+		},
+		{
+			name:        "parent table created by the migration",
+			referenced:  "customers",
+			expectQuery: "SELECT COUNT(*) FROM `orders` AS child WHERE child.`user_id` IS NOT NULL",
+			sampleQuery: "SELECT CONCAT_WS(',', child.`id`) FROM `orders` AS child WHERE child.`user_id` IS NOT NULL LIMIT 5",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			if err != nil {
+				t.Fatalf("Failed to create mock database: %v", err)
+			}
+			defer db.Close()
+
+			mock.ExpectQuery(regexp.QuoteMeta(tt.expectQuery)).
+				WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(7))
+			mock.ExpectQuery(regexp.QuoteMeta(tt.sampleQuery)).
+				WillReturnRows(sqlmock.NewRows([]string{"key"}).AddRow("42"))
+
+			diff := &SchemaDiff{
+				ModifiedTables: []*TableDiff{{
+					TableName: "orders",
+					AddedConstraints: []*Constraint{{
+						Name:              "fk_orders_user",
+						TableName:         "orders",
+						Type:              ConstraintTypeForeignKey,
+						Columns:           []string{"user_id"},
+						ReferencedTable:   tt.referenced,
+						ReferencedColumns: []string{"id"},
+					}},
+				}},
+			}
+
+			result, err := NewDataChecker().CheckChanges(db, newDataCheckSchema(), diff)
+			if err != nil {
+				t.Fatalf("CheckChanges() error = %v", err)
+			}
+
+			if len(result.Errors) != 1 || result.Errors[0].Count != 7 || result.Errors[0].SampleKeys[0] != "42" {
+				t.Fatalf("Expected an orphaned rows violation, got %+v", result.Errors)
+			}
+
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("Unfulfilled expectations: %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,142 @@
+package schema
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// DefaultKind classifies a column default value by how it is written in SQL
+type DefaultKind string
+
+const (
+	// DefaultKindLiteral is a string or temporal literal that is quoted in SQL
+	DefaultKindLiteral DefaultKind = "literal"
+	// DefaultKindNumeric is a number written without quotes
+	DefaultKindNumeric DefaultKind = "numeric"
+	// DefaultKindBit is a bit-value literal such as b'1'
+	DefaultKindBit DefaultKind = "bit"
+	// DefaultKindExpression is an expression evaluated by the server, such as CURRENT_TIMESTAMP or (uuid())
+	DefaultKindExpression DefaultKind = "expression"
+	// DefaultKindNull is an explicit DEFAULT NULL
+	DefaultKindNull DefaultKind = "null"
+)
+
+var (
+	// defaultGeneratedPattern matches the EXTRA flag marking expression defaults on MySQL 8.0.13+
+	defaultGeneratedPattern  = regexp.MustCompile(`(?i)\bDEFAULT_GENERATED\b`)
+	timestampFunctionPattern = regexp.MustCompile(`(?i)^(CURRENT_TIMESTAMP|NOW|LOCALTIME|LOCALTIMESTAMP)(\(\d*\))?$`)
+	bitLiteralPattern        = regexp.MustCompile(`^[bB]'[01]*'$`)
+	numericTypePattern       = regexp.MustCompile(`(?i)^(TINYINT|SMALLINT|MEDIUMINT|INT|INTEGER|BIGINT|DECIMAL|NUMERIC|DEC|FIXED|FLOAT|DOUBLE|REAL)\b`)
+)
+
+// ColumnDefault is the typed default value of a column
+type ColumnDefault struct {
+	Kind DefaultKind `json:"kind"`
+	// Value is the literal value without quotes, or the expression text without enclosing parentheses
+	Value string `json:"value"`
+}
+
+// IsTimestampFunction returns true if the default is CURRENT_TIMESTAMP or one of its synonyms,
+// which every server version accepts without parentheses
+func (d *ColumnDefault) IsTimestampFunction() bool {
+	return d.Kind == DefaultKindExpression && timestampFunctionPattern.MatchString(d.Value)
+}
+
+// SQL returns the default as it is written after DEFAULT in a column definition, using the MySQL
+// 8.0.13+ syntax for expression defaults
+func (d *ColumnDefault) SQL() string {
+	switch d.Kind {
+	case DefaultKindNumeric, DefaultKindBit:
+		return d.Value
+	case DefaultKindNull:
+		return "NULL"
+	case DefaultKindExpression:
+		if d.IsTimestampFunction() {
+			return d.Value
+		}
+		return "(" + d.Value + ")"
+	default:
+		return QuoteLiteral(d.Value)
+	}
+}
+
+// Equal returns true if both defaults have the same kind and value
+func (d *ColumnDefault) Equal(other *ColumnDefault) bool {
+	if d == nil || other == nil {
+		return d == other
+	}
+	if d.Kind != other.Kind {
+		return false
+	}
+	if d.Kind == DefaultKindExpression {
+		// Servers differ in the case they report functions in
+		return strings.EqualFold(d.Value, other.Value)
+	}
+	return d.Value == other.Value
+}
+
+// QuoteLiteral quotes a string as a SQL string literal
+func QuoteLiteral(value string) string {
+	value = strings.ReplaceAll(value, `\`, `\\`)
+	value = strings.ReplaceAll(value, "'", "''")
+	return "'" + value + "'"
+}
+
+// Default returns the typed default value of the column, or nil if it has none. Columns without a
+// recorded DefaultKind, such as those loaded from older snapshots, are classified from their type.
+func (c *Column) Default() *ColumnDefault {
+	if c.DefaultValue == nil {
+		return nil
+	}
+	kind := c.DefaultKind
+	if kind == "" {
+		kind = ClassifyDefault(c.DataType, *c.DefaultValue, false)
+	}
+	return &ColumnDefault{Kind: kind, Value: *c.DefaultValue}
+}
+
+// ClassifyDefault determines the kind of a COLUMN_DEFAULT value from INFORMATION_SCHEMA.COLUMNS.
+// defaultGenerated is true if EXTRA contains DEFAULT_GENERATED.
+func ClassifyDefault(columnType, value string, defaultGenerated bool) DefaultKind {
+	switch {
+	case defaultGenerated, timestampFunctionPattern.MatchString(value):
+		return DefaultKindExpression
+	case strings.EqualFold(value, "NULL"):
+		return DefaultKindNull
+	case bitLiteralPattern.MatchString(value):
+		return DefaultKindBit
+	case numericTypePattern.MatchString(columnType) && isNumber(value):
+		return DefaultKindNumeric
+	default:
+		return DefaultKindLiteral
+	}
+}
+
+// isNumber returns true if the value is a plain decimal number
+func isNumber(value string) bool {
+	_, err := strconv.ParseFloat(value, 64)
+	return err == nil && !strings.ContainsAny(value, "xXnN")
+}
+
+// parseColumnDefault splits the EXTRA and COLUMN_DEFAULT values reported by the server into the
+// column's extra attributes and typed default. DEFAULT_GENERATED is removed from EXTRA because
+// it is not valid in a column definition.
+func parseColumnDefault(column *Column, defaultValue *string) {
+	defaultGenerated := defaultGeneratedPattern.MatchString(column.Extra)
+	if defaultGenerated {
+		column.Extra = strings.TrimSpace(defaultGeneratedPattern.ReplaceAllString(column.Extra, ""))
+	}
+
+	if defaultValue == nil {
+		return
+	}
+
+	value := *defaultValue
+	if defaultGenerated {
+		// MySQL escapes quotes inside expression defaults, e.g. _utf8mb4\'abc\'
+		value = strings.ReplaceAll(value, `\'`, "'")
+	}
+	column.DefaultValue = &value
+	column.DefaultKind = ClassifyDefault(column.DataType, value, defaultGenerated)
+}
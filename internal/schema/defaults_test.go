@@ -0,0 +1,83 @@
+package schema
+
+import "testing"
+
+func TestClassifyDefault(t *testing.T) {
+	tests := []struct {
+		name             string
+		columnType       string
+		value            string
+		defaultGenerated bool
+		want             DefaultKind
+	}{
+		{"string literal", "varchar(20)", "active", false, DefaultKindLiteral},
+		{"number in string column", "varchar(20)", "42", false, DefaultKindLiteral},
+		{"integer", "int unsigned", "42", false, DefaultKindNumeric},
+		{"negative decimal", "decimal(10,2)", "-1.50", false, DefaultKindNumeric},
+		{"non-numeric text in numeric column", "int", "0x10", false, DefaultKindLiteral},
+		{"bit literal", "bit(1)", "b'1'", false, DefaultKindBit},
+		{"current timestamp", "timestamp", "CURRENT_TIMESTAMP", false, DefaultKindExpression},
+		{"current timestamp with precision", "datetime(6)", "CURRENT_TIMESTAMP(6)", false, DefaultKindExpression},
+		{"mariadb current timestamp", "timestamp", "current_timestamp()", false, DefaultKindExpression},
+		{"generated expression", "char(36)", "uuid()", true, DefaultKindExpression},
+		{"null", "varchar(20)", "NULL", false, DefaultKindNull},
+		{"date literal", "date", "2000-01-01", false, DefaultKindLiteral},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ClassifyDefault(tt.columnType, tt.value, tt.defaultGenerated); got != tt.want {
+				t.Errorf("ClassifyDefault(%q, %q, %v) = %s, want %s", tt.columnType, tt.value, tt.defaultGenerated, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestColumnDefault_SQL(t *testing.T) {
+	tests := []struct {
+		defaultValue ColumnDefault
+		want         string
+	}{
+		{ColumnDefault{Kind: DefaultKindLiteral, Value: "it's"}, "'it''s'"},
+		{ColumnDefault{Kind: DefaultKindLiteral, Value: `a\b`}, `'a\\b'`},
+		{ColumnDefault{Kind: DefaultKindNumeric, Value: "1.5"}, "1.5"},
+		{ColumnDefault{Kind: DefaultKindBit, Value: "b'101'"}, "b'101'"},
+		{ColumnDefault{Kind: DefaultKindNull, Value: "NULL"}, "NULL"},
+		{ColumnDefault{Kind: DefaultKindExpression, Value: "CURRENT_TIMESTAMP(3)"}, "CURRENT_TIMESTAMP(3)"},
+		{ColumnDefault{Kind: DefaultKindExpression, Value: "uuid_to_bin(uuid())"}, "(uuid_to_bin(uuid()))"},
+	}
+
+	for _, tt := range tests {
+		if got := tt.defaultValue.SQL(); got != tt.want {
+			t.Errorf("SQL() of %+v = %s, want %s", tt.defaultValue, got, tt.want)
+		}
+	}
+}
+
+func TestColumnDefault_Equal(t *testing.T) {
+	uuid := "uuid()"
+	literal := &Column{Name: "id", DataType: "char(36)", DefaultValue: &uuid}
+	expression := &Column{Name: "id", DataType: "char(36)", DefaultValue: &uuid, DefaultKind: DefaultKindExpression}
+	none := &Column{Name: "id", DataType: "char(36)"}
+
+	if literal.Default().Equal(expression.Default()) {
+		t.Error("Expected a literal default to differ from an expression with the same text")
+	}
+	if !expression.Default().Equal(expression.Default()) {
+		t.Error("Expected identical defaults to be equal")
+	}
+	if none.Default().Equal(literal.Default()) || !none.Default().Equal(none.Default()) {
+		t.Error("Expected a missing default to only equal another missing default")
+	}
+
+	upper := "UUID()"
+	expressionUpper := &Column{Name: "id", DataType: "char(36)", DefaultValue: &upper, DefaultKind: DefaultKindExpression}
+	if !expression.Default().Equal(expressionUpper.Default()) {
+		t.Error("Expected expression defaults to compare case-insensitively")
+	}
+
+	service := NewService()
+	if service.areColumnsEqual(literal, expression) {
+		t.Error("Expected columns with literal and expression defaults to differ")
+	}
+}
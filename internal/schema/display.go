@@ -140,7 +140,7 @@ func (df *DisplayFormatter) formatTableDetails(table *Table, indent string) stri
 			output.WriteString(" NOT NULL")
 		}
 		if col.DefaultValue != nil {
-			output.WriteString(fmt.Sprintf(" DEFAULT %s", *col.DefaultValue))
+			output.WriteString(" DEFAULT " + col.Default().SQL())
 		}
 		if col.Extra != "" {
 			output.WriteString(fmt.Sprintf(" %s", col.Extra))
@@ -164,7 +164,7 @@ func (df *DisplayFormatter) formatTableDiff(tableDiff *TableDiff, indent string)
 				output.WriteString(" NOT NULL")
 			}
 			if col.DefaultValue != nil {
-				output.WriteString(fmt.Sprintf(" DEFAULT %s", *col.DefaultValue))
+				output.WriteString(" DEFAULT " + col.Default().SQL())
 			}
 			if col.Extra != "" {
 				output.WriteString(fmt.Sprintf(" %s", col.Extra))
@@ -182,7 +182,7 @@ func (df *DisplayFormatter) formatTableDiff(tableDiff *TableDiff, indent string)
 				output.WriteString(" NOT NULL")
 			}
 			if col.DefaultValue != nil {
-				output.WriteString(fmt.Sprintf(" DEFAULT %s", *col.DefaultValue))
+				output.WriteString(" DEFAULT " + col.Default().SQL())
 			}
 			if col.Extra != "" {
 				output.WriteString(fmt.Sprintf(" %s", col.Extra))
@@ -250,15 +250,15 @@ func (df *DisplayFormatter) formatColumnDiff(colDiff *ColumnDiff, indent string)
 	}
 
 	// Default value change
-	oldDefault := "NULL"
-	newDefault := "NULL"
-	if old.DefaultValue != nil {
-		oldDefault = *old.DefaultValue
-	}
-	if new.DefaultValue != nil {
-		newDefault = *new.DefaultValue
-	}
-	if oldDefault != newDefault {
+	if !old.Default().Equal(new.Default()) {
+		oldDefault := "NULL"
+		newDefault := "NULL"
+		if old.DefaultValue != nil {
+			oldDefault = old.Default().SQL()
+		}
+		if new.DefaultValue != nil {
+			newDefault = new.Default().SQL()
+		}
 		output.WriteString(fmt.Sprintf("%sDefault: %s → %s\n",
 			indent,
 			df.colorize(oldDefault, "red"),
@@ -224,9 +224,11 @@ func (e *Extractor) extractColumns(db *sql.DB, schemaName, tableName string) (ma
 		}
 
 		// Handle default value
+		var value *string
 		if defaultValue.Valid {
-			column.DefaultValue = &defaultValue.String
+			value = &defaultValue.String
 		}
+		parseColumnDefault(column, value)
 
 		columns[columnName] = column
 	}
@@ -326,6 +328,148 @@ func (e *Extractor) extractGlobalIndexes(db *sql.DB, schemaName string) (map[str
 	return make(map[string]*Index), nil
 }
 
+// ExtractTableSizes retrieves row counts and storage sizes for all base tables in a schema.
+// The values are the storage engine's estimates and are not exact for InnoDB.
+func (e *Extractor) ExtractTableSizes(db *sql.DB, schemaName string) (map[string]*TableSize, error) {
+	if db == nil {
+		return nil, fmt.Errorf("database connection is nil")
+	}
+
+	query := `
+		SELECT TABLE_NAME, COALESCE(TABLE_ROWS, 0), COALESCE(DATA_LENGTH, 0), COALESCE(INDEX_LENGTH, 0)
+		FROM INFORMATION_SCHEMA.TABLES
+		WHERE TABLE_SCHEMA = ? AND TABLE_TYPE = 'BASE TABLE'
+	`
+
+	ctx, cancel := context.WithTimeout(context.Background(), e.queryTimeout)
+	defer cancel()
+
+	rows, err := db.QueryContext(ctx, query, schemaName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query table sizes: %w", err)
+	}
+	defer rows.Close()
+
+	sizes := make(map[string]*TableSize)
+
+	for rows.Next() {
+		size := &TableSize{}
+		if err := rows.Scan(&size.TableName, &size.Rows, &size.DataLength, &size.IndexLength); err != nil {
+			return nil, fmt.Errorf("failed to scan table size: %w", err)
+		}
+		sizes[size.TableName] = size
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating table size rows: %w", err)
+	}
+
+	return sizes, nil
+}
+
+// ExtractForeignKeys retrieves the foreign keys of all tables in a schema, with their columns in
+// key order
+func (e *Extractor) ExtractForeignKeys(db *sql.DB, schemaName string) ([]*Constraint, error) {
+	if db == nil {
+		return nil, fmt.Errorf("database connection is nil")
+	}
+
+	query := `
+		SELECT k.TABLE_NAME, k.CONSTRAINT_NAME, k.COLUMN_NAME, k.REFERENCED_TABLE_NAME, k.REFERENCED_COLUMN_NAME,
+			r.UPDATE_RULE, r.DELETE_RULE
+		FROM INFORMATION_SCHEMA.KEY_COLUMN_USAGE k
+		JOIN INFORMATION_SCHEMA.REFERENTIAL_CONSTRAINTS r
+			ON r.CONSTRAINT_SCHEMA = k.CONSTRAINT_SCHEMA AND r.CONSTRAINT_NAME = k.CONSTRAINT_NAME
+			AND r.TABLE_NAME = k.TABLE_NAME
+		WHERE k.TABLE_SCHEMA = ? AND k.REFERENCED_TABLE_NAME IS NOT NULL
+		ORDER BY k.TABLE_NAME, k.CONSTRAINT_NAME, k.ORDINAL_POSITION
+	`
+
+	ctx, cancel := context.WithTimeout(context.Background(), e.queryTimeout)
+	defer cancel()
+
+	rows, err := db.QueryContext(ctx, query, schemaName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query foreign keys: %w", err)
+	}
+	defer rows.Close()
+
+	var constraints []*Constraint
+	byKey := make(map[string]*Constraint)
+
+	for rows.Next() {
+		var tableName, name, column, refTable, refColumn, onUpdate, onDelete string
+		if err := rows.Scan(&tableName, &name, &column, &refTable, &refColumn, &onUpdate, &onDelete); err != nil {
+			return nil, fmt.Errorf("failed to scan foreign key: %w", err)
+		}
+
+		key := tableName + "." + name
+		constraint, exists := byKey[key]
+		if !exists {
+			constraint = &Constraint{
+				Name:            name,
+				TableName:       tableName,
+				Type:            ConstraintTypeForeignKey,
+				ReferencedTable: refTable,
+				OnUpdate:        onUpdate,
+				OnDelete:        onDelete,
+			}
+			byKey[key] = constraint
+			constraints = append(constraints, constraint)
+		}
+		constraint.Columns = append(constraint.Columns, column)
+		constraint.ReferencedColumns = append(constraint.ReferencedColumns, refColumn)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating foreign key rows: %w", err)
+	}
+
+	return constraints, nil
+}
+
+// ExtractColumnCharsets retrieves the character set and collation of every string column in a
+// schema, keyed by table and column name
+func (e *Extractor) ExtractColumnCharsets(db *sql.DB, schemaName string) (map[string]map[string]*ColumnCharset, error) {
+	if db == nil {
+		return nil, fmt.Errorf("database connection is nil")
+	}
+
+	query := `
+		SELECT TABLE_NAME, COLUMN_NAME, CHARACTER_SET_NAME, COLLATION_NAME
+		FROM INFORMATION_SCHEMA.COLUMNS
+		WHERE TABLE_SCHEMA = ? AND CHARACTER_SET_NAME IS NOT NULL
+	`
+
+	ctx, cancel := context.WithTimeout(context.Background(), e.queryTimeout)
+	defer cancel()
+
+	rows, err := db.QueryContext(ctx, query, schemaName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query column character sets: %w", err)
+	}
+	defer rows.Close()
+
+	charsets := make(map[string]map[string]*ColumnCharset)
+
+	for rows.Next() {
+		charset := &ColumnCharset{}
+		if err := rows.Scan(&charset.TableName, &charset.ColumnName, &charset.CharacterSet, &charset.Collation); err != nil {
+			return nil, fmt.Errorf("failed to scan column character set: %w", err)
+		}
+		if charsets[charset.TableName] == nil {
+			charsets[charset.TableName] = make(map[string]*ColumnCharset)
+		}
+		charsets[charset.TableName][charset.ColumnName] = charset
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating column character set rows: %w", err)
+	}
+
+	return charsets, nil
+}
+
 // indexBuilder is a helper struct for building indexes from multiple rows
 type indexBuilder struct {
 	name      string
@@ -86,6 +86,41 @@ func TestExtractTables(t *testing.T) {
 	}
 }
 
+func TestExtractTableSizes(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock database: %v", err)
+	}
+	defer db.Close()
+
+	rows := sqlmock.NewRows([]string{"TABLE_NAME", "TABLE_ROWS", "DATA_LENGTH", "INDEX_LENGTH"}).
+		AddRow("users", 1500, 1048576, 262144).
+		AddRow("posts", 0, 16384, 0)
+
+	mock.ExpectQuery("SELECT TABLE_NAME, COALESCE\\(TABLE_ROWS, 0\\)").
+		WithArgs("test_db").
+		WillReturnRows(rows)
+
+	extractor := NewExtractor()
+	sizes, err := extractor.ExtractTableSizes(db, "test_db")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if len(sizes) != 2 {
+		t.Fatalf("Expected 2 table sizes, got %d", len(sizes))
+	}
+
+	users := sizes["users"]
+	if users == nil || users.Rows != 1500 || users.TotalBytes() != 1310720 {
+		t.Errorf("Unexpected size for users: %+v", users)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
 func TestExtractColumns(t *testing.T) {
 	db, mock, err := sqlmock.New()
 	if err != nil {
@@ -153,6 +188,67 @@ func TestExtractColumns(t *testing.T) {
 	}
 }
 
+func TestExtractColumns_ExpressionDefaults(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock database: %v", err)
+	}
+	defer db.Close()
+
+	rows := sqlmock.NewRows([]string{
+		"COLUMN_NAME", "DATA_TYPE", "IS_NULLABLE", "COLUMN_DEFAULT",
+		"EXTRA", "ORDINAL_POSITION", "COLUMN_TYPE",
+	}).
+		AddRow("uuid", "char", "NO", "uuid()", "DEFAULT_GENERATED", 1, "char(36)").
+		AddRow("label", "varchar", "NO", `_utf8mb4\'none\'`, "DEFAULT_GENERATED", 2, "varchar(20)").
+		AddRow("updated_at", "datetime", "NO", "CURRENT_TIMESTAMP(6)", "DEFAULT_GENERATED on update CURRENT_TIMESTAMP(6)", 3, "datetime(6)").
+		AddRow("quantity", "int", "NO", "0", "", 4, "int").
+		AddRow("code", "varchar", "NO", "0", "", 5, "varchar(10)")
+
+	mock.ExpectQuery("SELECT COLUMN_NAME, DATA_TYPE, IS_NULLABLE").
+		WithArgs("test_db", "items").
+		WillReturnRows(rows)
+
+	extractor := NewExtractor()
+	columns, err := extractor.extractColumns(db, "test_db", "items")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		column    string
+		wantKind  DefaultKind
+		wantSQL   string
+		wantExtra string
+	}{
+		{"uuid", DefaultKindExpression, "(uuid())", ""},
+		{"label", DefaultKindExpression, "(_utf8mb4'none')", ""},
+		{"updated_at", DefaultKindExpression, "CURRENT_TIMESTAMP(6)", "on update CURRENT_TIMESTAMP(6)"},
+		{"quantity", DefaultKindNumeric, "0", ""},
+		{"code", DefaultKindLiteral, "'0'", ""},
+	}
+
+	for _, tt := range tests {
+		column := columns[tt.column]
+		if column == nil {
+			t.Fatalf("Expected column %s to exist", tt.column)
+		}
+		if column.DefaultKind != tt.wantKind {
+			t.Errorf("%s: expected default kind %s, got %s", tt.column, tt.wantKind, column.DefaultKind)
+		}
+		if got := column.Default().SQL(); got != tt.wantSQL {
+			t.Errorf("%s: expected default %s, got %s", tt.column, tt.wantSQL, got)
+		}
+		if column.Extra != tt.wantExtra {
+			t.Errorf("%s: expected extra %q, got %q", tt.column, tt.wantExtra, column.Extra)
+		}
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
 func TestExtractIndexes(t *testing.T) {
 	db, mock, err := sqlmock.New()
 	if err != nil {
@@ -310,3 +406,73 @@ func TestValidateSchemaExists_NotFound(t *testing.T) {
 		t.Errorf("Unfulfilled expectations: %v", err)
 	}
 }
+
+func TestExtractForeignKeys(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock database: %v", err)
+	}
+	defer db.Close()
+
+	rows := sqlmock.NewRows([]string{
+		"TABLE_NAME", "CONSTRAINT_NAME", "COLUMN_NAME", "REFERENCED_TABLE_NAME", "REFERENCED_COLUMN_NAME",
+		"UPDATE_RULE", "DELETE_RULE",
+	}).
+		AddRow("order_items", "fk_items_order", "order_id", "orders", "id", "RESTRICT", "CASCADE").
+		AddRow("order_items", "fk_items_variant", "product_id", "variants", "product_id", "RESTRICT", "RESTRICT").
+		AddRow("order_items", "fk_items_variant", "variant_no", "variants", "variant_no", "RESTRICT", "RESTRICT")
+
+	mock.ExpectQuery("SELECT k.TABLE_NAME, k.CONSTRAINT_NAME, k.COLUMN_NAME").
+		WithArgs("test_db").
+		WillReturnRows(rows)
+
+	constraints, err := NewExtractor().ExtractForeignKeys(db, "test_db")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if len(constraints) != 2 {
+		t.Fatalf("Expected 2 foreign keys, got %d", len(constraints))
+	}
+	if constraints[0].OnDelete != "CASCADE" || constraints[0].Type != ConstraintTypeForeignKey {
+		t.Errorf("Unexpected first foreign key: %+v", constraints[0])
+	}
+	composite := constraints[1]
+	if len(composite.Columns) != 2 || composite.Columns[1] != "variant_no" || composite.ReferencedColumns[0] != "product_id" {
+		t.Errorf("Expected a composite foreign key, got %+v", composite)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
+func TestExtractColumnCharsets(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock database: %v", err)
+	}
+	defer db.Close()
+
+	rows := sqlmock.NewRows([]string{"TABLE_NAME", "COLUMN_NAME", "CHARACTER_SET_NAME", "COLLATION_NAME"}).
+		AddRow("users", "email", "utf8mb4", "utf8mb4_0900_ai_ci").
+		AddRow("users", "name", "utf8mb3", "utf8mb3_general_ci")
+
+	mock.ExpectQuery("SELECT TABLE_NAME, COLUMN_NAME, CHARACTER_SET_NAME, COLLATION_NAME").
+		WithArgs("test_db").
+		WillReturnRows(rows)
+
+	charsets, err := NewExtractor().ExtractColumnCharsets(db, "test_db")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	name := charsets["users"]["name"]
+	if len(charsets["users"]) != 2 || name == nil || name.CharacterSet != "utf8mb3" || name.Collation != "utf8mb3_general_ci" {
+		t.Errorf("Unexpected column character sets: %+v", charsets)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
@@ -1,7 +1,11 @@
 package schema
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
 	"fmt"
+	"sort"
 	"strings"
 )
 
@@ -10,6 +14,30 @@ type Schema struct {
 	Name    string            `json:"name"`
 	Tables  map[string]*Table `json:"tables"`
 	Indexes map[string]*Index `json:"indexes"`
+	// TableSizes holds the row counts and storage sizes read during extraction, when available
+	TableSizes map[string]*TableSize `json:"table_sizes,omitempty"`
+}
+
+// TableSize holds the storage statistics of a table as reported by INFORMATION_SCHEMA.TABLES
+type TableSize struct {
+	TableName   string `json:"table_name"`
+	Rows        int64  `json:"rows"`
+	DataLength  int64  `json:"data_length"`
+	IndexLength int64  `json:"index_length"`
+}
+
+// TotalBytes returns the combined size of the table data and indexes
+func (ts *TableSize) TotalBytes() int64 {
+	return ts.DataLength + ts.IndexLength
+}
+
+// ColumnCharset holds the character set and collation of a string column as reported by
+// INFORMATION_SCHEMA.COLUMNS
+type ColumnCharset struct {
+	TableName    string `json:"table_name"`
+	ColumnName   string `json:"column_name"`
+	CharacterSet string `json:"character_set"`
+	Collation    string `json:"collation"`
 }
 
 // Table represents a database table
@@ -26,8 +54,10 @@ type Column struct {
 	DataType     string  `json:"data_type"`
 	IsNullable   bool    `json:"is_nullable"`
 	DefaultValue *string `json:"default_value"`
-	Extra        string  `json:"extra"`
-	Position     int     `json:"position"`
+	// DefaultKind records how DefaultValue is written in SQL; see Default
+	DefaultKind DefaultKind `json:"default_kind,omitempty"`
+	Extra       string      `json:"extra"`
+	Position    int         `json:"position"`
 }
 
 // Index represents a database index
@@ -81,6 +111,14 @@ type TableDiff struct {
 	ModifiedColumns    []*ColumnDiff `json:"modified_columns"`
 	AddedConstraints   []*Constraint `json:"added_constraints"`
 	RemovedConstraints []*Constraint `json:"removed_constraints"`
+	// ColumnOrder lists the source table columns by position, so added columns can be placed
+	ColumnOrder []string `json:"column_order,omitempty"`
+	// TargetColumnOrder lists the target table columns by position, so removed columns can be
+	// put back in place on rollback
+	TargetColumnOrder []string `json:"target_column_order,omitempty"`
+	// TargetNotNullColumns lists the removed columns that an index of the target table requires
+	// to be NOT NULL, so that archiving them keeps them NOT NULL
+	TargetNotNullColumns []string `json:"target_not_null_columns,omitempty"`
 }
 
 // ColumnDiff represents differences between two columns
@@ -448,6 +486,26 @@ func (t *Table) GetColumn(name string) (*Column, bool) {
 	return column, exists
 }
 
+// ColumnNames returns the names of the table columns in position order
+func (t *Table) ColumnNames() []string {
+	columns := make([]*Column, 0, len(t.Columns))
+	for _, column := range t.Columns {
+		columns = append(columns, column)
+	}
+	sort.Slice(columns, func(i, j int) bool {
+		if columns[i].Position != columns[j].Position {
+			return columns[i].Position < columns[j].Position
+		}
+		return columns[i].Name < columns[j].Name
+	})
+
+	names := make([]string, len(columns))
+	for i, column := range columns {
+		names[i] = column.Name
+	}
+	return names
+}
+
 // HasPrimaryKey checks if the table has a primary key
 func (t *Table) HasPrimaryKey() bool {
 	for _, index := range t.Indexes {
@@ -467,3 +525,73 @@ func (t *Table) GetPrimaryKey() *Index {
 	}
 	return nil
 }
+
+// RequiresNotNull returns true if the column is part of the primary key or a spatial index,
+// which MySQL only allows on NOT NULL columns
+func (t *Table) RequiresNotNull(columnName string) bool {
+	for _, index := range t.Indexes {
+		if !index.IsPrimary && index.IndexType != "SPATIAL" && index.IndexType != "RTREE" {
+			continue
+		}
+		for _, column := range index.Columns {
+			if column == columnName {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Fingerprint returns a hash of the schema structure that changes whenever a table, column,
+// index or constraint changes. The schema name is not included so that the same structure
+// has the same fingerprint in every environment.
+func (s *Schema) Fingerprint() string {
+	type tableFingerprint struct {
+		Name        string
+		Columns     []*Column
+		Indexes     []*Index
+		Constraints []*Constraint
+	}
+
+	tableNames := make([]string, 0, len(s.Tables))
+	for name := range s.Tables {
+		tableNames = append(tableNames, name)
+	}
+	sort.Strings(tableNames)
+
+	tables := make([]tableFingerprint, 0, len(tableNames))
+	for _, name := range tableNames {
+		table := s.Tables[name]
+
+		columns := make([]*Column, 0, len(table.Columns))
+		for _, column := range table.Columns {
+			columns = append(columns, column)
+		}
+		sort.Slice(columns, func(i, j int) bool { return columns[i].Name < columns[j].Name })
+
+		indexes := append([]*Index(nil), table.Indexes...)
+		sort.Slice(indexes, func(i, j int) bool { return indexes[i].Name < indexes[j].Name })
+
+		constraints := make([]*Constraint, 0, len(table.Constraints))
+		for _, constraint := range table.Constraints {
+			constraints = append(constraints, constraint)
+		}
+		sort.Slice(constraints, func(i, j int) bool { return constraints[i].Name < constraints[j].Name })
+
+		tables = append(tables, tableFingerprint{
+			Name:        name,
+			Columns:     columns,
+			Indexes:     indexes,
+			Constraints: constraints,
+		})
+	}
+
+	data, err := json.Marshal(tables)
+	if err != nil {
+		// All fields are plain values, so marshaling cannot fail in practice
+		data = []byte(fmt.Sprintf("%v", tables))
+	}
+
+	hash := sha256.Sum256(data)
+	return hex.EncodeToString(hash[:])
+}
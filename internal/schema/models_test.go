@@ -289,3 +289,38 @@ func TestUtilityMethods(t *testing.T) {
 		t.Errorf("Expected table name 'users', got %s", retrievedTable.Name)
 	}
 }
+
+func TestSchemaFingerprint(t *testing.T) {
+	build := func(name string, columnType string) *Schema {
+		s := NewSchema(name)
+		table := NewTable("users")
+		table.AddColumn(&Column{Name: "id", DataType: "INT", Position: 1})
+		table.AddColumn(&Column{Name: "email", DataType: columnType, IsNullable: true, Position: 2})
+		table.AddIndex(&Index{Name: "PRIMARY", TableName: "users", Columns: []string{"id"}, IsPrimary: true, IsUnique: true})
+		table.AddIndex(&Index{Name: "idx_email", TableName: "users", Columns: []string{"email"}})
+		s.AddTable(table)
+		return s
+	}
+
+	base := build("prod", "VARCHAR(255)")
+
+	if base.Fingerprint() != base.Fingerprint() {
+		t.Error("Expected fingerprint to be stable")
+	}
+
+	if base.Fingerprint() != build("staging", "VARCHAR(255)").Fingerprint() {
+		t.Error("Expected fingerprint to ignore the schema name")
+	}
+
+	if base.Fingerprint() == build("prod", "VARCHAR(100)").Fingerprint() {
+		t.Error("Expected fingerprint to change when a column changes")
+	}
+
+	// Index order within a table does not matter
+	reordered := build("prod", "VARCHAR(255)")
+	indexes := reordered.Tables["users"].Indexes
+	indexes[0], indexes[1] = indexes[1], indexes[0]
+	if base.Fingerprint() != reordered.Fingerprint() {
+		t.Error("Expected fingerprint to ignore index order")
+	}
+}
@@ -207,6 +207,84 @@ func (s *Service) ExtractSchemaFromDB(db *sql.DB, schemaName string) (*Schema, e
 	return schema, nil
 }
 
+// GetTableSizes retrieves the row counts and storage sizes of the tables in a schema
+func (s *Service) GetTableSizes(db *sql.DB, schemaName string) (map[string]*TableSize, error) {
+	if db == nil {
+		return nil, errors.NewAppError(errors.ErrorTypeValidation, "database connection is nil", nil)
+	}
+
+	sizes, err := s.extractor.ExtractTableSizes(db, schemaName)
+	if err != nil {
+		return nil, errors.WrapError(err, "failed to retrieve table sizes")
+	}
+
+	s.logger.WithFields(map[string]interface{}{
+		"schema":      schemaName,
+		"table_count": len(sizes),
+	}).Debug("Retrieved table sizes")
+
+	return sizes, nil
+}
+
+// GetForeignKeys retrieves the foreign keys of the tables in a schema
+func (s *Service) GetForeignKeys(db *sql.DB, schemaName string) ([]*Constraint, error) {
+	if db == nil {
+		return nil, errors.NewAppError(errors.ErrorTypeValidation, "database connection is nil", nil)
+	}
+
+	constraints, err := s.extractor.ExtractForeignKeys(db, schemaName)
+	if err != nil {
+		return nil, errors.WrapError(err, "failed to retrieve foreign keys")
+	}
+
+	s.logger.WithFields(map[string]interface{}{
+		"schema":            schemaName,
+		"foreign_key_count": len(constraints),
+	}).Debug("Retrieved foreign keys")
+
+	return constraints, nil
+}
+
+// GetColumnCharsets retrieves the character sets and collations of the string columns in a schema
+func (s *Service) GetColumnCharsets(db *sql.DB, schemaName string) (map[string]map[string]*ColumnCharset, error) {
+	if db == nil {
+		return nil, errors.NewAppError(errors.ErrorTypeValidation, "database connection is nil", nil)
+	}
+
+	charsets, err := s.extractor.ExtractColumnCharsets(db, schemaName)
+	if err != nil {
+		return nil, errors.WrapError(err, "failed to retrieve column character sets")
+	}
+
+	s.logger.WithFields(map[string]interface{}{
+		"schema":      schemaName,
+		"table_count": len(charsets),
+	}).Debug("Retrieved column character sets")
+
+	return charsets, nil
+}
+
+// CheckData queries the target data for rows that the changes in the diff would reject or corrupt.
+// Violations are returned as blocking errors in the validation result.
+func (s *Service) CheckData(db *sql.DB, target *Schema, diff *SchemaDiff) (*ValidationResult, error) {
+	if db == nil {
+		return nil, errors.NewAppError(errors.ErrorTypeValidation, "database connection is nil", nil)
+	}
+
+	checker := NewDataCheckerWithTimeout(s.extractor.queryTimeout)
+	result, err := checker.CheckChanges(db, target, diff)
+	if err != nil {
+		return nil, errors.WrapError(err, "failed to check target data")
+	}
+
+	s.logger.WithFields(map[string]interface{}{
+		"schema":      target.Name,
+		"error_count": len(result.Errors),
+	}).Debug("Checked target data against schema changes")
+
+	return result, nil
+}
+
 // CompareSchemas compares two schemas and returns the differences
 func (s *Service) CompareSchemas(source, target *Schema) (*SchemaDiff, error) {
 	if source == nil {
@@ -255,9 +333,10 @@ func (s *Service) CompareSchemas(source, target *Schema) (*SchemaDiff, error) {
 
 	processed := 0
 
-	// Find added and removed tables
+	// Find added and removed tables. Tables archived by safe-drop mode are not part of the
+	// schema, so they are never reported as removed.
 	for tableName, sourceTable := range source.Tables {
-		if _, exists := target.Tables[tableName]; !exists {
+		if _, exists := target.Tables[tableName]; !exists && !IsArchivedName(tableName) {
 			diff.AddedTables = append(diff.AddedTables, sourceTable)
 		}
 		processed++
@@ -267,7 +346,7 @@ func (s *Service) CompareSchemas(source, target *Schema) (*SchemaDiff, error) {
 	}
 
 	for tableName, targetTable := range target.Tables {
-		if _, exists := source.Tables[tableName]; !exists {
+		if _, exists := source.Tables[tableName]; !exists && !IsArchivedName(tableName) {
 			diff.RemovedTables = append(diff.RemovedTables, targetTable)
 		}
 		processed++
@@ -480,15 +559,15 @@ func (s *Service) compareTable(source, target *Table) *TableDiff {
 		RemovedConstraints: make([]*Constraint, 0),
 	}
 
-	// Find added and removed columns
+	// Find added and removed columns, leaving out columns archived by safe-drop mode
 	for columnName, sourceColumn := range source.Columns {
-		if _, exists := target.Columns[columnName]; !exists {
+		if _, exists := target.Columns[columnName]; !exists && !IsArchivedName(columnName) {
 			diff.AddedColumns = append(diff.AddedColumns, sourceColumn)
 		}
 	}
 
 	for columnName, targetColumn := range target.Columns {
-		if _, exists := source.Columns[columnName]; !exists {
+		if _, exists := source.Columns[columnName]; !exists && !IsArchivedName(columnName) {
 			diff.RemovedColumns = append(diff.RemovedColumns, targetColumn)
 		}
 	}
@@ -507,6 +586,19 @@ func (s *Service) compareTable(source, target *Table) *TableDiff {
 		}
 	}
 
+	// Record the source column order so that added columns can be placed
+	if len(diff.AddedColumns) > 0 {
+		diff.ColumnOrder = source.ColumnNames()
+	}
+	if len(diff.RemovedColumns) > 0 {
+		diff.TargetColumnOrder = target.ColumnNames()
+		for _, columnName := range diff.TargetColumnOrder {
+			if _, exists := source.Columns[columnName]; !exists && target.RequiresNotNull(columnName) {
+				diff.TargetNotNullColumns = append(diff.TargetNotNullColumns, columnName)
+			}
+		}
+	}
+
 	// Compare constraints
 	s.compareConstraintsForTable(source, target, diff)
 
@@ -528,20 +620,9 @@ func (s *Service) areColumnsEqual(col1, col2 *Column) bool {
 		return false
 	}
 
-	// Compare default values
-	if col1.DefaultValue == nil && col2.DefaultValue != nil {
-		return false
-	}
-	if col1.DefaultValue != nil && col2.DefaultValue == nil {
-		return false
-	}
-	if col1.DefaultValue != nil && col2.DefaultValue != nil {
-		if *col1.DefaultValue != *col2.DefaultValue {
-			return false
-		}
-	}
-
-	return true
+	// Compare default values by kind as well as text, so a literal is not confused with an
+	// expression of the same text
+	return col1.Default().Equal(col2.Default())
 }
 
 // compareConstraintsForTable compares constraints between two tables
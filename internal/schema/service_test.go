@@ -142,6 +142,38 @@ func TestCompareSchemas_DifferentSchemas(t *testing.T) {
 	}
 }
 
+func TestCompareSchemas_ArchivedObjects(t *testing.T) {
+	service := NewService()
+	archivedAt := time.Date(2026, 3, 1, 12, 30, 0, 0, time.UTC)
+
+	source := NewSchema("source_db")
+	target := NewSchema("target_db")
+
+	sourceUsers := NewTable("users")
+	sourceUsers.AddColumn(NewColumn("id", "int", false))
+	source.AddTable(sourceUsers)
+
+	// An archived table and an archived column left behind by safe-drop mode
+	targetUsers := NewTable("users")
+	targetUsers.AddColumn(NewColumn("id", "int", false))
+	targetUsers.AddColumn(NewColumn(ArchivedName("nickname", archivedAt), "varchar(50)", true))
+	target.AddTable(targetUsers)
+
+	archivedTable := NewTable(ArchivedName("sessions", archivedAt))
+	archivedTable.AddColumn(NewColumn("id", "int", false))
+	target.AddTable(archivedTable)
+
+	diff, err := service.CompareSchemas(source, target)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if !service.IsSchemaDiffEmpty(diff) {
+		t.Errorf("Expected archived tables and columns to be ignored, got %d removed tables and %d modified tables",
+			len(diff.RemovedTables), len(diff.ModifiedTables))
+	}
+}
+
 func TestAreColumnsEqual(t *testing.T) {
 	service := NewService()
 
@@ -1301,3 +1333,94 @@ func BenchmarkDetectRenamedTables(b *testing.B) {
 		_ = service.DetectRenamedTables(source, target)
 	}
 }
+
+func TestCompareSchemas_RecordsNotNullKeyColumns(t *testing.T) {
+	service := NewService()
+
+	source := NewSchema("source_db")
+	sourceTable := NewTable("users")
+	sourceTable.AddColumn(NewColumn("id", "int", false))
+	sourceTable.AddColumn(NewColumn("email", "varchar(255)", true))
+	source.AddTable(sourceTable)
+
+	target := NewSchema("target_db")
+	targetTable := NewTable("users")
+	for i, column := range []*Column{
+		NewColumn("tenant_id", "int", false),
+		NewColumn("id", "int", false),
+		NewColumn("email", "varchar(255)", true),
+		NewColumn("legacy_code", "varchar(20)", false),
+	} {
+		column.Position = i + 1
+		targetTable.AddColumn(column)
+	}
+	targetTable.Indexes = append(targetTable.Indexes,
+		&Index{Name: "PRIMARY", TableName: "users", Columns: []string{"tenant_id", "id"}, IsUnique: true, IsPrimary: true, IndexType: "BTREE"},
+		&Index{Name: "idx_legacy", TableName: "users", Columns: []string{"legacy_code"}, IndexType: "BTREE"})
+	target.AddTable(targetTable)
+
+	diff, err := service.CompareSchemas(source, target)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	var tableDiff *TableDiff
+	for _, modified := range diff.ModifiedTables {
+		if modified.TableName == "users" {
+			tableDiff = modified
+		}
+	}
+	if tableDiff == nil {
+		t.Fatalf("Expected users to be modified, got %+v", diff.ModifiedTables)
+	}
+	if strings.Join(tableDiff.TargetNotNullColumns, ",") != "tenant_id" {
+		t.Errorf("Expected only the removed primary key column to be recorded, got %v", tableDiff.TargetNotNullColumns)
+	}
+}
+
+func TestCompareSchemas_RecordsColumnOrder(t *testing.T) {
+	service := NewService()
+
+	source := NewSchema("source_db")
+	target := NewSchema("target_db")
+
+	sourceTable := NewTable("users")
+	for i, name := range []string{"id", "email", "name"} {
+		column := NewColumn(name, "varchar(50)", true)
+		column.Position = i + 1
+		sourceTable.AddColumn(column)
+	}
+	source.AddTable(sourceTable)
+
+	targetTable := NewTable("users")
+	for i, name := range []string{"id", "name"} {
+		column := NewColumn(name, "varchar(50)", true)
+		column.Position = i + 1
+		targetTable.AddColumn(column)
+	}
+	target.AddTable(targetTable)
+
+	diff, err := service.CompareSchemas(source, target)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if len(diff.ModifiedTables) != 1 {
+		t.Fatalf("Expected 1 modified table, got %d", len(diff.ModifiedTables))
+	}
+
+	order := diff.ModifiedTables[0].ColumnOrder
+	if len(order) != 3 || order[0] != "id" || order[1] != "email" || order[2] != "name" {
+		t.Errorf("Expected source column order [id email name], got %v", order)
+	}
+
+	// Removing a column records the target order instead
+	diff, err = service.CompareSchemas(target, source)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	tableDiff := diff.ModifiedTables[0]
+	if len(tableDiff.ColumnOrder) != 0 || strings.Join(tableDiff.TargetColumnOrder, ",") != "id,email,name" {
+		t.Errorf("Expected target column order [id email name], got %v and %v", tableDiff.ColumnOrder, tableDiff.TargetColumnOrder)
+	}
+}
@@ -24,10 +24,15 @@ type Warning struct {
 
 // ValidationError represents a validation error that prevents execution
 type ValidationError struct {
-	Type      ErrorType
-	Message   string
-	TableName string
-	Details   string
+	Type       ErrorType
+	Message    string
+	TableName  string
+	ColumnName string
+	Details    string
+	// Count is the number of rows violating the change, for data violations
+	Count int64
+	// SampleKeys holds the primary keys or duplicated values of a few violating rows
+	SampleKeys []string
 }
 
 // WarningType represents the type of warning
@@ -39,6 +44,7 @@ const (
 	WarningTypeCompatibility WarningType = "COMPATIBILITY"
 	WarningTypeDestructive   WarningType = "DESTRUCTIVE"
 	WarningTypeDependency    WarningType = "DEPENDENCY"
+	WarningTypePolicy        WarningType = "POLICY"
 )
 
 // WarningSeverity represents the severity level of a warning
@@ -55,9 +61,11 @@ const (
 type ErrorType string
 
 const (
-	ErrorTypeDependency   ErrorType = "DEPENDENCY"
-	ErrorTypeIncompatible ErrorType = "INCOMPATIBLE"
-	ErrorTypeConstraint   ErrorType = "CONSTRAINT"
+	ErrorTypeDependency    ErrorType = "DEPENDENCY"
+	ErrorTypeIncompatible  ErrorType = "INCOMPATIBLE"
+	ErrorTypeConstraint    ErrorType = "CONSTRAINT"
+	ErrorTypeDataViolation ErrorType = "DATA_VIOLATION"
+	ErrorTypePolicy        ErrorType = "POLICY"
 )
 
 // SchemaValidator validates schema changes and generates warnings
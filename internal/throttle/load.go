@@ -0,0 +1,287 @@
+package throttle
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Thresholds maps global status variables to the value they must stay below, in the
+// Threads_running=25,Innodb_row_lock_current_waits=10 format of gh-ost's --max-load
+type Thresholds map[string]int64
+
+// ParseThresholds parses a comma separated list of status=value thresholds
+func ParseThresholds(value string) (Thresholds, error) {
+	thresholds := make(Thresholds)
+	for _, part := range strings.Split(value, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		name, limit, found := strings.Cut(part, "=")
+		name = strings.TrimSpace(name)
+		if !found || name == "" {
+			return nil, fmt.Errorf("invalid threshold %q, expected status=value", part)
+		}
+		parsed, err := strconv.ParseInt(strings.TrimSpace(limit), 10, 64)
+		if err != nil || parsed <= 0 {
+			return nil, fmt.Errorf("invalid value in threshold %q, expected a positive integer", part)
+		}
+		thresholds[name] = parsed
+	}
+	return thresholds, nil
+}
+
+// String formats the thresholds in their parsed format
+func (t Thresholds) String() string {
+	names := t.names()
+	parts := make([]string, len(names))
+	for i, name := range names {
+		parts[i] = fmt.Sprintf("%s=%d", name, t[name])
+	}
+	return strings.Join(parts, ",")
+}
+
+// names returns the status variables in name order
+func (t Thresholds) names() []string {
+	names := make([]string, 0, len(t))
+	for name := range t {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// LoadConfig controls the target load checks between statements
+type LoadConfig struct {
+	// MaxLoad pauses execution while a status variable reaches its threshold
+	MaxLoad string `mapstructure:"max_load" yaml:"max_load"`
+	// CriticalLoad aborts the migration when a status variable reaches its threshold
+	CriticalLoad string `mapstructure:"critical_load" yaml:"critical_load"`
+	// Query pauses execution while it returns a value greater than zero
+	Query string `mapstructure:"query" yaml:"query"`
+	// PauseFile pauses execution while the file exists, so operators can pause a running sync
+	PauseFile string `mapstructure:"pause_file" yaml:"pause_file"`
+	// CheckInterval is how often load is checked again while paused
+	CheckInterval time.Duration `mapstructure:"check_interval" yaml:"check_interval"`
+}
+
+// Enabled returns true if any load check is configured
+func (c LoadConfig) Enabled() bool {
+	return c.MaxLoad != "" || c.CriticalLoad != "" || c.Query != "" || c.PauseFile != ""
+}
+
+// Interval returns the configured check interval, or the default one
+func (c LoadConfig) Interval() time.Duration {
+	if c.CheckInterval == 0 {
+		return DefaultCheckInterval
+	}
+	return c.CheckInterval
+}
+
+// Validate validates the load configuration
+func (c LoadConfig) Validate() error {
+	if _, err := ParseThresholds(c.MaxLoad); err != nil {
+		return fmt.Errorf("invalid max load: %w", err)
+	}
+	if _, err := ParseThresholds(c.CriticalLoad); err != nil {
+		return fmt.Errorf("invalid critical load: %w", err)
+	}
+	if c.CheckInterval < 0 {
+		return fmt.Errorf("load check interval cannot be negative")
+	}
+	return nil
+}
+
+// LoadStatus is the result of one load check
+type LoadStatus struct {
+	// Values are the status variables the thresholds refer to
+	Values map[string]int64
+	// Exceeded lists the max load thresholds that were reached
+	Exceeded []string
+	// Critical lists the critical load thresholds that were reached
+	Critical []string
+	// QueryValue is the result of the throttle query, if one is configured
+	QueryValue *int64
+	// PauseFile is true if the pause file exists
+	PauseFile bool
+}
+
+// Paused returns true if execution has to wait
+func (s LoadStatus) Paused() bool {
+	return len(s.Exceeded) > 0 || (s.QueryValue != nil && *s.QueryValue > 0) || s.PauseFile
+}
+
+// Reason describes why execution is paused
+func (s LoadStatus) Reason() string {
+	reasons := append([]string(nil), s.Exceeded...)
+	if s.QueryValue != nil && *s.QueryValue > 0 {
+		reasons = append(reasons, fmt.Sprintf("throttle query returned %d", *s.QueryValue))
+	}
+	if s.PauseFile {
+		reasons = append(reasons, "pause file exists")
+	}
+	return strings.Join(reasons, ", ")
+}
+
+// CriticalLoadError reports that the target reached a critical load threshold
+type CriticalLoadError struct {
+	Status LoadStatus
+}
+
+func (e *CriticalLoadError) Error() string {
+	return fmt.Sprintf("critical load reached: %s", strings.Join(e.Status.Critical, ", "))
+}
+
+// LoadMonitor checks the load of the target database
+type LoadMonitor struct {
+	db       *sql.DB
+	config   LoadConfig
+	max      Thresholds
+	critical Thresholds
+}
+
+// NewLoadMonitor creates a LoadMonitor for the target database
+func NewLoadMonitor(db *sql.DB, config LoadConfig) (*LoadMonitor, error) {
+	max, err := ParseThresholds(config.MaxLoad)
+	if err != nil {
+		return nil, fmt.Errorf("invalid max load: %w", err)
+	}
+	critical, err := ParseThresholds(config.CriticalLoad)
+	if err != nil {
+		return nil, fmt.Errorf("invalid critical load: %w", err)
+	}
+	return &LoadMonitor{db: db, config: config, max: max, critical: critical}, nil
+}
+
+// Check reads the status variables, runs the throttle query and looks for the pause file
+func (m *LoadMonitor) Check(ctx context.Context) (LoadStatus, error) {
+	status := LoadStatus{Values: make(map[string]int64)}
+
+	names := make(map[string]bool)
+	for name := range m.max {
+		names[name] = true
+	}
+	for name := range m.critical {
+		names[name] = true
+	}
+	if len(names) > 0 {
+		values, err := m.globalStatus(ctx, names)
+		if err != nil {
+			return status, err
+		}
+		status.Values = values
+	}
+
+	status.Exceeded = reached(m.max, status.Values)
+	status.Critical = reached(m.critical, status.Values)
+
+	if m.config.Query != "" {
+		var value sql.NullInt64
+		if err := m.db.QueryRowContext(ctx, m.config.Query).Scan(&value); err != nil {
+			return status, fmt.Errorf("failed to run throttle query: %w", err)
+		}
+		status.QueryValue = &value.Int64
+	}
+
+	if m.config.PauseFile != "" {
+		if _, err := os.Stat(m.config.PauseFile); err == nil {
+			status.PauseFile = true
+		}
+	}
+
+	return status, nil
+}
+
+// Wait blocks while the target is too busy or the pause file exists. It reports each paused check
+// to progress and fails as soon as a critical load threshold is reached.
+func (m *LoadMonitor) Wait(ctx context.Context, progress func(status LoadStatus)) error {
+	for {
+		status, err := m.Check(ctx)
+		if err != nil {
+			return err
+		}
+		if len(status.Critical) > 0 {
+			return &CriticalLoadError{Status: status}
+		}
+		if !status.Paused() {
+			return nil
+		}
+
+		if progress != nil {
+			progress(status)
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(m.config.Interval()):
+		}
+	}
+}
+
+// globalStatus reads the named global status variables
+func (m *LoadMonitor) globalStatus(ctx context.Context, names map[string]bool) (map[string]int64, error) {
+	placeholders := make([]string, 0, len(names))
+	args := make([]interface{}, 0, len(names))
+	for name := range names {
+		placeholders = append(placeholders, "?")
+		args = append(args, name)
+	}
+
+	query := fmt.Sprintf("SELECT VARIABLE_NAME, VARIABLE_VALUE FROM performance_schema.global_status WHERE VARIABLE_NAME IN (%s)",
+		strings.Join(placeholders, ", "))
+	rows, err := m.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read global status: %w", err)
+	}
+	defer rows.Close()
+
+	// Variable names are case-insensitive, so report them as the thresholds spell them
+	spelling := make(map[string]string, len(names))
+	for name := range names {
+		spelling[strings.ToLower(name)] = name
+	}
+
+	values := make(map[string]int64)
+	for rows.Next() {
+		var name, value string
+		if err := rows.Scan(&name, &value); err != nil {
+			return nil, fmt.Errorf("failed to scan global status: %w", err)
+		}
+		parsed, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("status variable %s is not numeric: %s", name, value)
+		}
+		if original, ok := spelling[strings.ToLower(name)]; ok {
+			values[original] = parsed
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read global status: %w", err)
+	}
+
+	for name := range names {
+		if _, ok := values[name]; !ok {
+			return nil, fmt.Errorf("unknown status variable %s", name)
+		}
+	}
+	return values, nil
+}
+
+// reached lists the thresholds that the values reach
+func reached(thresholds Thresholds, values map[string]int64) []string {
+	var result []string
+	for _, name := range thresholds.names() {
+		if values[name] >= thresholds[name] {
+			result = append(result, fmt.Sprintf("%s=%d (threshold %d)", name, values[name], thresholds[name]))
+		}
+	}
+	return result
+}
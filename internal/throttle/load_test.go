@@ -0,0 +1,147 @@
+package throttle
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func TestParseThresholds(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected string
+		wantErr  bool
+	}{
+		{value: "", expected: ""},
+		{value: "Threads_running=25", expected: "Threads_running=25"},
+		{value: " Threads_running=25, Innodb_row_lock_current_waits=10 ", expected: "Innodb_row_lock_current_waits=10,Threads_running=25"},
+		{value: "Threads_running", wantErr: true},
+		{value: "=25", wantErr: true},
+		{value: "Threads_running=0", wantErr: true},
+		{value: "Threads_running=many", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			thresholds, err := ParseThresholds(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseThresholds(%q) error = %v, wantErr %v", tt.value, err, tt.wantErr)
+			}
+			if !tt.wantErr && thresholds.String() != tt.expected {
+				t.Errorf("ParseThresholds(%q) = %q, want %q", tt.value, thresholds.String(), tt.expected)
+			}
+		})
+	}
+}
+
+func TestLoadConfig(t *testing.T) {
+	if (LoadConfig{}).Enabled() {
+		t.Error("Expected an empty load config to be disabled")
+	}
+	if !(LoadConfig{PauseFile: "/tmp/pause"}).Enabled() {
+		t.Error("Expected a pause file to enable load checks")
+	}
+	if (LoadConfig{}).Interval() != DefaultCheckInterval {
+		t.Error("Expected the default check interval")
+	}
+	if err := (LoadConfig{MaxLoad: "Threads_running"}).Validate(); err == nil {
+		t.Error("Expected an invalid max load to fail validation")
+	}
+	if err := (LoadConfig{CheckInterval: -time.Second}).Validate(); err == nil {
+		t.Error("Expected a negative interval to fail validation")
+	}
+}
+
+func TestLoadMonitor_Check(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create sqlmock: %v", err)
+	}
+	defer db.Close()
+
+	pauseFile := filepath.Join(t.TempDir(), "pause")
+	if err := os.WriteFile(pauseFile, nil, 0o644); err != nil {
+		t.Fatalf("Failed to create pause file: %v", err)
+	}
+
+	monitor, err := NewLoadMonitor(db, LoadConfig{
+		MaxLoad:      "Threads_running=25",
+		CriticalLoad: "Threads_running=100",
+		Query:        "SELECT COUNT(*) FROM jobs",
+		PauseFile:    pauseFile,
+	})
+	if err != nil {
+		t.Fatalf("NewLoadMonitor() error = %v", err)
+	}
+
+	mock.ExpectQuery("SELECT VARIABLE_NAME, VARIABLE_VALUE FROM performance_schema.global_status").
+		WithArgs("Threads_running").
+		WillReturnRows(sqlmock.NewRows([]string{"VARIABLE_NAME", "VARIABLE_VALUE"}).AddRow("THREADS_RUNNING", "30"))
+	mock.ExpectQuery("SELECT COUNT\\(\\*\\) FROM jobs").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(2))
+
+	status, err := monitor.Check(context.Background())
+	if err != nil {
+		t.Fatalf("Check() error = %v", err)
+	}
+	if !status.Paused() || len(status.Critical) != 0 {
+		t.Errorf("Expected a paused, non-critical status, got %+v", status)
+	}
+	expected := "Threads_running=30 (threshold 25), throttle query returned 2, pause file exists"
+	if status.Reason() != expected {
+		t.Errorf("Reason() = %q, want %q", status.Reason(), expected)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
+func TestLoadMonitor_Wait(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create sqlmock: %v", err)
+	}
+	defer db.Close()
+
+	monitor, err := NewLoadMonitor(db, LoadConfig{
+		MaxLoad:       "Threads_running=25",
+		CriticalLoad:  "Threads_running=100",
+		CheckInterval: time.Millisecond,
+	})
+	if err != nil {
+		t.Fatalf("NewLoadMonitor() error = %v", err)
+	}
+
+	statusRows := func(value string) *sqlmock.Rows {
+		return sqlmock.NewRows([]string{"VARIABLE_NAME", "VARIABLE_VALUE"}).AddRow("Threads_running", value)
+	}
+	mock.ExpectQuery("performance_schema.global_status").WillReturnRows(statusRows("40"))
+	mock.ExpectQuery("performance_schema.global_status").WillReturnRows(statusRows("10"))
+
+	pauses := 0
+	if err := monitor.Wait(context.Background(), func(LoadStatus) { pauses++ }); err != nil {
+		t.Fatalf("Wait() error = %v", err)
+	}
+	if pauses != 1 {
+		t.Errorf("Expected one paused check, got %d", pauses)
+	}
+
+	mock.ExpectQuery("performance_schema.global_status").WillReturnRows(statusRows("150"))
+
+	err = monitor.Wait(context.Background(), nil)
+	critical, ok := err.(*CriticalLoadError)
+	if !ok || !strings.Contains(critical.Error(), "Threads_running=150 (threshold 100)") {
+		t.Errorf("Expected a critical load error, got %v", err)
+	}
+
+	mock.ExpectQuery("performance_schema.global_status").
+		WillReturnRows(sqlmock.NewRows([]string{"VARIABLE_NAME", "VARIABLE_VALUE"}))
+	if err := monitor.Wait(context.Background(), nil); err == nil || !strings.Contains(err.Error(), "unknown status variable") {
+		t.Errorf("Expected an unknown status variable error, got %v", err)
+	}
+}
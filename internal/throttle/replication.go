@@ -0,0 +1,330 @@
+package throttle
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultMaxLag is the replica lag above which execution pauses
+const DefaultMaxLag = 10 * time.Second
+
+// DefaultCheckInterval is how often lag is checked again while paused
+const DefaultCheckInterval = time.Second
+
+// Replica is the address of a replica and the credentials to read its status. Empty credentials
+// fall back to the target database credentials.
+type Replica struct {
+	Host     string `mapstructure:"host" yaml:"host"`
+	Port     int    `mapstructure:"port" yaml:"port"`
+	Username string `mapstructure:"username" yaml:"username"`
+	Password string `mapstructure:"password" yaml:"password"`
+}
+
+// Address returns host:port of the replica
+func (r Replica) Address() string {
+	return net.JoinHostPort(r.Host, strconv.Itoa(r.Port))
+}
+
+// ParseReplica parses a replica given as host[:port]
+func ParseReplica(value string) (Replica, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return Replica{}, fmt.Errorf("replica address cannot be empty")
+	}
+
+	host, portText, err := net.SplitHostPort(value)
+	if err != nil {
+		// No port given
+		return Replica{Host: strings.Trim(value, "[]"), Port: 3306}, nil
+	}
+	port, err := strconv.Atoi(portText)
+	if err != nil || port <= 0 || port > 65535 {
+		return Replica{}, fmt.Errorf("invalid port in replica address %q", value)
+	}
+	return Replica{Host: host, Port: port}, nil
+}
+
+// ReplicationConfig controls the replica lag checks between statements
+type ReplicationConfig struct {
+	// Replicas are watched in addition to the discovered ones
+	Replicas []Replica `mapstructure:"replicas" yaml:"replicas"`
+	// Discover finds the replicas of the target with SHOW REPLICAS
+	Discover bool `mapstructure:"discover" yaml:"discover"`
+	// MaxLag pauses execution while any replica lags more than this
+	MaxLag time.Duration `mapstructure:"max_lag" yaml:"max_lag"`
+	// CheckInterval is how often lag is checked again while paused
+	CheckInterval time.Duration `mapstructure:"check_interval" yaml:"check_interval"`
+	// MaxWait aborts the migration if replicas do not catch up within this time; zero waits indefinitely
+	MaxWait time.Duration `mapstructure:"max_wait" yaml:"max_wait"`
+}
+
+// Enabled returns true if any replica is watched
+func (c ReplicationConfig) Enabled() bool {
+	return c.Discover || len(c.Replicas) > 0
+}
+
+// Lag returns the configured maximum lag, or the default one
+func (c ReplicationConfig) Lag() time.Duration {
+	if c.MaxLag == 0 {
+		return DefaultMaxLag
+	}
+	return c.MaxLag
+}
+
+// Interval returns the configured check interval, or the default one
+func (c ReplicationConfig) Interval() time.Duration {
+	if c.CheckInterval == 0 {
+		return DefaultCheckInterval
+	}
+	return c.CheckInterval
+}
+
+// Validate validates the replication configuration
+func (c ReplicationConfig) Validate() error {
+	if c.MaxLag < 0 || c.CheckInterval < 0 || c.MaxWait < 0 {
+		return fmt.Errorf("replication lag settings cannot be negative")
+	}
+	for _, replica := range c.Replicas {
+		if replica.Host == "" {
+			return fmt.Errorf("replica host is required")
+		}
+		if replica.Port < 0 || replica.Port > 65535 {
+			return fmt.Errorf("invalid port %d for replica %s", replica.Port, replica.Host)
+		}
+	}
+	return nil
+}
+
+// DiscoverReplicas lists the replicas registered with a primary. Replicas only report an address
+// when report_host is set, so the others are returned by server ID in skipped.
+func DiscoverReplicas(ctx context.Context, primary *sql.DB) (replicas []Replica, skipped []string, err error) {
+	rows, err := primary.QueryContext(ctx, "SHOW REPLICAS")
+	if err != nil {
+		// Servers before 8.0.22 only know the old syntax
+		rows, err = primary.QueryContext(ctx, "SHOW SLAVE HOSTS")
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to list replicas: %w", err)
+		}
+	}
+	defer rows.Close()
+
+	records, err := scanRecords(rows)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to list replicas: %w", err)
+	}
+
+	for _, record := range records {
+		host := record["Host"]
+		port, _ := strconv.Atoi(record["Port"])
+		if host == "" || port == 0 {
+			skipped = append(skipped, "server "+field(record, "Server_Id", "Server_id"))
+			continue
+		}
+		replicas = append(replicas, Replica{Host: host, Port: port})
+	}
+
+	return replicas, skipped, nil
+}
+
+// ReplicaStatus is the replication state of one replica
+type ReplicaStatus struct {
+	Replica string
+	// Lag is nil if the replica does not know its lag, e.g. while the IO thread reconnects
+	Lag        *time.Duration
+	IORunning  bool
+	SQLRunning bool
+	SQLErrno   int
+	SQLError   string
+}
+
+// Broken returns true if the SQL thread stopped or failed, so the replica will not catch up
+func (s ReplicaStatus) Broken() bool {
+	return !s.SQLRunning || s.SQLErrno != 0
+}
+
+// String describes the lag of the replica for progress output
+func (s ReplicaStatus) String() string {
+	if s.Lag == nil {
+		return s.Replica + " lag unknown"
+	}
+	return fmt.Sprintf("%s %s behind", s.Replica, s.Lag.Round(time.Second))
+}
+
+// ReadStatus reads the replication state of a replica
+func ReadStatus(ctx context.Context, name string, replica *sql.DB) (*ReplicaStatus, error) {
+	rows, err := replica.QueryContext(ctx, "SHOW REPLICA STATUS")
+	if err != nil {
+		rows, err = replica.QueryContext(ctx, "SHOW SLAVE STATUS")
+		if err != nil {
+			return nil, fmt.Errorf("failed to read replication status of %s: %w", name, err)
+		}
+	}
+	defer rows.Close()
+
+	records, err := scanRecords(rows)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read replication status of %s: %w", name, err)
+	}
+	if len(records) == 0 {
+		return nil, fmt.Errorf("%s is not a replica", name)
+	}
+
+	// The column names changed from Slave/Master to Replica/Source in 8.0.22
+	record := records[0]
+	status := &ReplicaStatus{
+		Replica:    name,
+		IORunning:  strings.EqualFold(field(record, "Replica_IO_Running", "Slave_IO_Running"), "Yes"),
+		SQLRunning: strings.EqualFold(field(record, "Replica_SQL_Running", "Slave_SQL_Running"), "Yes"),
+		SQLError:   field(record, "Last_SQL_Error"),
+	}
+	status.SQLErrno, _ = strconv.Atoi(field(record, "Last_SQL_Errno"))
+	if seconds, err := strconv.ParseInt(field(record, "Seconds_Behind_Source", "Seconds_Behind_Master"), 10, 64); err == nil {
+		lag := time.Duration(seconds) * time.Second
+		status.Lag = &lag
+	}
+
+	return status, nil
+}
+
+// Monitor watches the lag of a set of replicas
+type Monitor struct {
+	config   ReplicationConfig
+	replicas map[string]*sql.DB
+}
+
+// NewMonitor creates a Monitor for replica connections keyed by replica name
+func NewMonitor(config ReplicationConfig, replicas map[string]*sql.DB) *Monitor {
+	return &Monitor{config: config, replicas: replicas}
+}
+
+// Check reads the status of every replica, in name order
+func (m *Monitor) Check(ctx context.Context) ([]ReplicaStatus, error) {
+	names := make([]string, 0, len(m.replicas))
+	for name := range m.replicas {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	statuses := make([]ReplicaStatus, 0, len(names))
+	for _, name := range names {
+		status, err := ReadStatus(ctx, name, m.replicas[name])
+		if err != nil {
+			return nil, err
+		}
+		statuses = append(statuses, *status)
+	}
+	return statuses, nil
+}
+
+// BrokenError reports a replica whose SQL thread stopped or failed
+type BrokenError struct {
+	Status ReplicaStatus
+}
+
+func (e *BrokenError) Error() string {
+	if e.Status.SQLErrno != 0 {
+		return fmt.Sprintf("replication SQL thread on %s failed with error %d: %s", e.Status.Replica, e.Status.SQLErrno, e.Status.SQLError)
+	}
+	return fmt.Sprintf("replication SQL thread on %s is not running", e.Status.Replica)
+}
+
+// LaggingError reports replicas that did not catch up within the maximum wait
+type LaggingError struct {
+	Waited   time.Duration
+	Statuses []ReplicaStatus
+}
+
+func (e *LaggingError) Error() string {
+	return fmt.Sprintf("replicas did not catch up within %s: %s", e.Waited.Round(time.Second), Summary(e.Statuses))
+}
+
+// Wait blocks until every replica lags no more than the maximum lag. It reports each check to
+// progress, and fails if a replica's SQL thread breaks or the replicas do not catch up in time.
+func (m *Monitor) Wait(ctx context.Context, progress func(statuses []ReplicaStatus, paused bool)) error {
+	start := time.Now()
+	for {
+		statuses, err := m.Check(ctx)
+		if err != nil {
+			return err
+		}
+
+		lagging := false
+		for _, status := range statuses {
+			if status.Broken() {
+				return &BrokenError{Status: status}
+			}
+			if status.Lag == nil || *status.Lag > m.config.Lag() {
+				lagging = true
+			}
+		}
+
+		if progress != nil {
+			progress(statuses, lagging)
+		}
+		if !lagging {
+			return nil
+		}
+		if m.config.MaxWait > 0 && time.Since(start) >= m.config.MaxWait {
+			return &LaggingError{Waited: time.Since(start), Statuses: statuses}
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(m.config.Interval()):
+		}
+	}
+}
+
+// Summary describes the lag of every replica in one line
+func Summary(statuses []ReplicaStatus) string {
+	parts := make([]string, len(statuses))
+	for i, status := range statuses {
+		parts[i] = status.String()
+	}
+	return strings.Join(parts, ", ")
+}
+
+// field returns the value of the first of the columns present in the record
+func field(record map[string]string, names ...string) string {
+	for _, name := range names {
+		if value, ok := record[name]; ok {
+			return value
+		}
+	}
+	return ""
+}
+
+// scanRecords reads rows of any shape into maps keyed by column name
+func scanRecords(rows *sql.Rows) ([]map[string]string, error) {
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+
+	records := make([]map[string]string, 0)
+	for rows.Next() {
+		values := make([]sql.NullString, len(columns))
+		dest := make([]interface{}, len(columns))
+		for i := range values {
+			dest[i] = &values[i]
+		}
+		if err := rows.Scan(dest...); err != nil {
+			return nil, err
+		}
+
+		record := make(map[string]string, len(columns))
+		for i, column := range columns {
+			record[column] = values[i].String
+		}
+		records = append(records, record)
+	}
+
+	return records, rows.Err()
+}
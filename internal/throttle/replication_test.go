@@ -0,0 +1,169 @@
+package throttle
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+var replicaStatusColumns = []string{"Replica_IO_Running", "Replica_SQL_Running", "Seconds_Behind_Source", "Last_SQL_Errno", "Last_SQL_Error"}
+
+func TestParseReplica(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected Replica
+		wantErr  bool
+	}{
+		{value: "replica1", expected: Replica{Host: "replica1", Port: 3306}},
+		{value: "replica1:3307", expected: Replica{Host: "replica1", Port: 3307}},
+		{value: "[::1]:3308", expected: Replica{Host: "::1", Port: 3308}},
+		{value: "replica1:port", wantErr: true},
+		{value: " ", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			replica, err := ParseReplica(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseReplica(%q) error = %v, wantErr %v", tt.value, err, tt.wantErr)
+			}
+			if !tt.wantErr && replica != tt.expected {
+				t.Errorf("ParseReplica(%q) = %+v, want %+v", tt.value, replica, tt.expected)
+			}
+		})
+	}
+}
+
+func TestReadStatus(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create sqlmock: %v", err)
+	}
+	defer db.Close()
+
+	// Servers before 8.0.22 reject SHOW REPLICA STATUS and use the old column names
+	mock.ExpectQuery("SHOW REPLICA STATUS").WillReturnError(errors.New("syntax error"))
+	mock.ExpectQuery("SHOW SLAVE STATUS").
+		WillReturnRows(sqlmock.NewRows([]string{"Slave_IO_Running", "Slave_SQL_Running", "Seconds_Behind_Master", "Last_SQL_Errno", "Last_SQL_Error"}).
+			AddRow("Yes", "Yes", 42, 0, ""))
+	mock.ExpectQuery("SHOW REPLICA STATUS").
+		WillReturnRows(sqlmock.NewRows(replicaStatusColumns).AddRow("Yes", "No", nil, 1062, "Duplicate entry"))
+
+	status, err := ReadStatus(context.Background(), "replica1:3306", db)
+	if err != nil {
+		t.Fatalf("ReadStatus() error = %v", err)
+	}
+	if status.Lag == nil || *status.Lag != 42*time.Second || status.Broken() {
+		t.Errorf("Unexpected status %+v", status)
+	}
+	if status.String() != "replica1:3306 42s behind" {
+		t.Errorf("Unexpected summary %q", status.String())
+	}
+
+	status, err = ReadStatus(context.Background(), "replica2:3306", db)
+	if err != nil {
+		t.Fatalf("ReadStatus() error = %v", err)
+	}
+	if status.Lag != nil || !status.Broken() || status.SQLErrno != 1062 {
+		t.Errorf("Expected a broken replica with unknown lag, got %+v", status)
+	}
+}
+
+func TestDiscoverReplicas(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create sqlmock: %v", err)
+	}
+	defer db.Close()
+
+	mock.ExpectQuery("SHOW REPLICAS").
+		WillReturnRows(sqlmock.NewRows([]string{"Server_Id", "Host", "Port", "Source_Id", "Replica_UUID"}).
+			AddRow(2, "replica1", 3306, 1, "uuid-2").
+			AddRow(3, "", 3306, 1, "uuid-3"))
+
+	replicas, skipped, err := DiscoverReplicas(context.Background(), db)
+	if err != nil {
+		t.Fatalf("DiscoverReplicas() error = %v", err)
+	}
+	if len(replicas) != 1 || replicas[0].Address() != "replica1:3306" {
+		t.Errorf("Unexpected replicas %+v", replicas)
+	}
+	if len(skipped) != 1 || skipped[0] != "server 3" {
+		t.Errorf("Expected the replica without report_host to be skipped, got %v", skipped)
+	}
+}
+
+func TestMonitor_Wait(t *testing.T) {
+	config := ReplicationConfig{MaxLag: 10 * time.Second, CheckInterval: time.Millisecond}
+
+	t.Run("pauses until caught up", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("Failed to create sqlmock: %v", err)
+		}
+		defer db.Close()
+
+		mock.ExpectQuery("SHOW REPLICA STATUS").
+			WillReturnRows(sqlmock.NewRows(replicaStatusColumns).AddRow("Yes", "Yes", 30, 0, ""))
+		mock.ExpectQuery("SHOW REPLICA STATUS").
+			WillReturnRows(sqlmock.NewRows(replicaStatusColumns).AddRow("Yes", "Yes", 2, 0, ""))
+
+		pauses := 0
+		monitor := NewMonitor(config, map[string]*sql.DB{"replica1:3306": db})
+		err = monitor.Wait(context.Background(), func(statuses []ReplicaStatus, paused bool) {
+			if paused {
+				pauses++
+			}
+		})
+		if err != nil {
+			t.Fatalf("Wait() error = %v", err)
+		}
+		if pauses != 1 {
+			t.Errorf("Expected one paused check, got %d", pauses)
+		}
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Errorf("Unfulfilled expectations: %v", err)
+		}
+	})
+
+	t.Run("aborts on a broken SQL thread", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("Failed to create sqlmock: %v", err)
+		}
+		defer db.Close()
+
+		mock.ExpectQuery("SHOW REPLICA STATUS").
+			WillReturnRows(sqlmock.NewRows(replicaStatusColumns).AddRow("Yes", "No", nil, 1146, "Table 'app.orders' doesn't exist"))
+
+		err = NewMonitor(config, map[string]*sql.DB{"replica1:3306": db}).Wait(context.Background(), nil)
+		broken, ok := err.(*BrokenError)
+		if !ok || broken.Status.SQLErrno != 1146 {
+			t.Errorf("Expected a BrokenError, got %v", err)
+		}
+	})
+
+	t.Run("gives up after the maximum wait", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("Failed to create sqlmock: %v", err)
+		}
+		defer db.Close()
+
+		for i := 0; i < 100; i++ {
+			mock.ExpectQuery("SHOW REPLICA STATUS").
+				WillReturnRows(sqlmock.NewRows(replicaStatusColumns).AddRow("Yes", "Yes", 60, 0, ""))
+		}
+
+		limited := config
+		limited.MaxWait = 5 * time.Millisecond
+		err = NewMonitor(limited, map[string]*sql.DB{"replica1:3306": db}).Wait(context.Background(), nil)
+		if _, ok := err.(*LaggingError); !ok {
+			t.Errorf("Expected a LaggingError, got %v", err)
+		}
+	})
+}